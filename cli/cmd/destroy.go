@@ -0,0 +1,295 @@
+/*
+ * GoAstra CLI - Destroy Command
+ *
+ * Undoes a `goastra generate` run: removes the files the generator wrote
+ * for a resource and unregisters it from the router and app.routes.ts.
+ */
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/channdev/goastra/cli/internal/config"
+	"github.com/channdev/goastra/cli/internal/generator"
+	"github.com/channdev/goastra/cli/internal/migrator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	destroyForce     bool
+	destroyDryRun    bool
+	destroyDropTable bool
+)
+
+var destroyCmd = &cobra.Command{
+	Use:   "destroy <api|crud|module|graphql|trpc|ent> <name>",
+	Short: "Remove a generated resource",
+	Long: `Removes the files 'goastra generate <kind> <name>' produced, as
+recorded in .goastra/manifest.json, and undoes its registration in the
+backend router and web/src/app/app.routes.ts.
+
+Files edited since they were generated are not deleted unless --force is
+given. Resources generated before the manifest existed are located by
+their generated paths and also need --force.
+
+When the resource's table migration or the migration of one of its
+join tables has already been applied, that migration (and, for the
+resource's table, the migrations altering it) is kept, and migrations
+dropping the tables are offered instead (--drop-table creates them
+without asking).
+
+Resources generated with --belongs-to, --has-many or --many-to-many
+naming this one use its model and repository and stop compiling once
+they are gone, so destroy refuses while any exist unless --force is
+given.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDestroy,
+}
+
+func init() {
+	rootCmd.AddCommand(destroyCmd)
+	destroyCmd.Flags().BoolVar(&destroyForce, "force", false, "delete files even if they were edited since generation or other resources relate to it")
+	destroyCmd.Flags().BoolVar(&destroyDryRun, "dry-run", false, "list what would be removed without changing any file")
+	destroyCmd.Flags().BoolVar(&destroyDropTable, "drop-table", false, "create drop migrations for applied table migrations without asking")
+}
+
+func runDestroy(cmd *cobra.Command, args []string) error {
+	kind := args[0]
+	name := normalizeResourceName(args[1])
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	/* Angular modules are found without the backend module */
+	paths, err := generatorPaths(cfg)
+	if err != nil && kind != "module" {
+		return err
+	}
+
+	stack, err := projectStack(cfg, paths)
+	if err != nil {
+		return err
+	}
+	if kind == "api" || kind == "crud" {
+		if err := checkPackages(paths, stack); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := generator.LoadManifest(generator.ManifestFile)
+	if err != nil {
+		return err
+	}
+
+	d, err := generator.NewDestroyer(kind, name, manifest)
+	if err != nil {
+		return err
+	}
+	d.SetPaths(paths)
+	d.SetStack(stack)
+
+	files, recorded, err := d.Files()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no files generated by 'goastra generate %s %s' were found", kind, name)
+	}
+
+	modified, err := d.Modified(files)
+	if err != nil {
+		return err
+	}
+	if len(modified) > 0 && !destroyForce {
+		if recorded {
+			color.Yellow("These files were edited since they were generated:\n")
+		} else {
+			color.Yellow("No manifest records %s %s; these files cannot be checked for edits:\n", kind, name)
+		}
+		for _, file := range modified {
+			fmt.Printf("  %s\n", file)
+		}
+		cmd.SilenceUsage = true
+		return fmt.Errorf("refusing to delete them; rerun with --force to delete anyway")
+	}
+
+	if kind == "api" || kind == "crud" {
+		if dependents := d.Dependents(); len(dependents) > 0 {
+			color.Yellow("These resources are related to %s and stop compiling without it:\n", name)
+			for _, dependent := range dependents {
+				fmt.Printf("  %s\n", dependent)
+			}
+			if !destroyForce && !destroyDryRun {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("refusing to destroy %s; destroy them first, or rerun with --force to destroy it anyway", name)
+			}
+		}
+	}
+
+	/*
+	 * An applied migration stays: the database still has the table. Join
+	 * tables come first, since they must be dropped before the table
+	 * they reference.
+	 */
+	migration := d.Migration(files)
+	alters := d.AlterMigrations(files)
+	creates := d.JoinMigrations(files)
+	if migration != "" {
+		creates = append(creates, migration)
+	}
+	var applied []string
+	for _, create := range creates {
+		ok, err := migrationApplied(paths.Migrations, create)
+		if err != nil {
+			color.Yellow("Cannot tell whether %s was applied (%v); keeping it.\n", create, err)
+			color.Yellow("Delete it by hand if it never ran.\n")
+		}
+		if ok {
+			applied = append(applied, create)
+		}
+		if !ok && err == nil {
+			continue
+		}
+		files = removeString(files, create)
+		if create == migration {
+			for _, alter := range alters {
+				files = removeString(files, alter)
+			}
+		}
+	}
+
+	if destroyDryRun {
+		fmt.Printf("Dry run, no files changed. Would remove:\n")
+		for _, file := range files {
+			fmt.Printf("  %s\n", file)
+		}
+		for _, create := range applied {
+			fmt.Printf("Would offer a migration dropping the table created by %s\n", create)
+		}
+		return nil
+	}
+
+	color.Cyan("Destroying %s: %s\n", kind, name)
+
+	if err := d.Remove(files); err != nil {
+		return fmt.Errorf("failed to remove files: %w", err)
+	}
+	for _, file := range files {
+		fmt.Printf("  remove    %s\n", file)
+	}
+
+	if kind == "api" || kind == "crud" {
+		unwired, err := d.Unwire()
+		switch {
+		case err != nil:
+			color.Yellow("  Router not updated: %v\n", err)
+		case unwired:
+			fmt.Printf("  Unregistered routes in %s\n", filepath.Join(paths.Router, "router.go"))
+		}
+	}
+
+	if kind == "crud" {
+		removed, err := d.RemoveRoute()
+		switch {
+		case err != nil:
+			color.Yellow("  Angular routes not updated: %v\n", err)
+		case removed:
+			fmt.Printf("  Removed route from web/src/app/app.routes.ts\n")
+		}
+	}
+
+	if len(applied) > 0 {
+		fmt.Println()
+		for _, create := range applied {
+			color.Yellow("%s has already been applied.\n", create)
+		}
+		if destroyDropTable || confirm("Create migrations dropping the tables?") {
+			driver, err := projectDriver(cfg, paths)
+			if err != nil {
+				return err
+			}
+			d.SetDriver(driver)
+			created, err := d.DropMigrations(applied, alters)
+			for _, path := range created {
+				fmt.Printf("  create    %s\n", path)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create drop migration: %w", err)
+			}
+			fmt.Printf("Run 'goastra migrate' to drop the tables.\n")
+		} else {
+			fmt.Printf("The tables were left in place; rerun with --drop-table to create the migrations later.\n")
+		}
+	}
+
+	color.Green("\n%s %s destroyed.\n", kind, name)
+	return nil
+}
+
+/*
+ * migrationApplied reports whether the migration file has been run
+ * against the configured database.
+ */
+func migrationApplied(dir, file string) (bool, error) {
+	dbURL := loadDatabaseURL()
+	if dbURL == "" {
+		return false, fmt.Errorf("no database connection configured")
+	}
+
+	cfg := migrator.DefaultConfig()
+	cfg.MigrationsPath = dir
+	cfg.Driver = migrator.DetectDriverFromURL(dbURL)
+	m, err := migrator.New(cfg)
+	if err != nil {
+		return false, err
+	}
+	defer m.Close()
+
+	if err := m.Connect(dbURL); err != nil {
+		return false, err
+	}
+	applied, err := m.GetAppliedMigrations()
+	if err != nil {
+		return false, err
+	}
+
+	version, _, _ := strings.Cut(filepath.Base(file), "_")
+	for _, mig := range applied {
+		if mig.Version == version {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+/*
+ * confirm asks a yes/no question on the terminal. Without a terminal
+ * the answer is no.
+ */
+func confirm(question string) bool {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func removeString(list []string, s string) []string {
+	var out []string
+	for _, item := range list {
+		if item != s {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -8,10 +8,17 @@ package cmd
 
 import (
 	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/channdev/goastra/cli/internal/codegen"
+	"github.com/channdev/goastra/cli/internal/config"
 	"github.com/channdev/goastra/cli/internal/generator"
+	"github.com/channdev/goastra/cli/internal/migrator"
 	"github.com/spf13/cobra"
 )
 
@@ -29,7 +36,13 @@ var generateCmd = &cobra.Command{
   goastra generate crud <name>     Generate full CRUD stack
   goastra generate graphql <name>  Generate GraphQL schema and resolvers
   goastra generate trpc <name>     Generate tRPC proto and service
-  goastra generate ent <name>      Generate Ent ORM schema`,
+  goastra generate ent <name>      Generate Ent ORM schema
+  goastra generate client          Generate an API client SDK
+  goastra generate eject <gen>     Copy a generator's templates for customising
+
+Generated files are rendered from templates built into the CLI. A file
+of the same name in codegen.templates (schema/templates by default)
+replaces the built-in template.`,
 }
 
 /*
@@ -38,13 +51,22 @@ var generateCmd = &cobra.Command{
  */
 var generateAPICmd = &cobra.Command{
 	Use:   "api <name>",
-	Short: "Generate REST API endpoint",
-	Long: `Generates a complete REST API endpoint:
-  - Handler with CRUD operations
+	Short: "Generate backend API endpoint",
+	Long: `Generates a complete backend API endpoint:
   - Service layer with business logic
   - Repository for data access
-  - Route registration
-  - Request/Response DTOs`,
+  - Request/Response DTOs
+  - Handler and route registration (REST), resolvers (GraphQL) or a
+    Connect service (tRPC)
+
+The API style and ORM are read from api.type and database.orm in
+goastra.json, or detected from the project layout when unset. Ent
+projects get Ent-backed repositories.
+
+Files are written to the generators.api paths in goastra.json and
+import each other under backend.module. REST packages on sqlx and
+their tests are checked with 'go vet' unless --skip-build-check is
+given; the other stacks print the code generation step to run first.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runGenerateAPI,
 }
@@ -71,7 +93,7 @@ var generateModuleCmd = &cobra.Command{
  * Generates both backend API and frontend module with all operations.
  */
 var generateCRUDCmd = &cobra.Command{
-	Use:   "crud <name>",
+	Use:   "crud <name> [field:type[:modifier]...]",
 	Short: "Generate full CRUD stack",
 	Long: `Generates a complete CRUD implementation:
   Backend:
@@ -79,15 +101,76 @@ var generateCRUDCmd = &cobra.Command{
     - Handler with Create, Read, Update, Delete
     - Service layer
     - Repository with database operations
-    - Migration file
+    - Migration file (Ent schema in Ent projects)
+    - Resolvers (GraphQL) or Connect service (tRPC) instead of the
+      handler, following api.type
   Frontend:
     - Feature module with routing
     - List component with pagination
     - Detail/View component
     - Create/Edit form component
     - Delete confirmation
-    - API service`,
-	Args: cobra.ExactArgs(1),
+    - API service using the project's REST, GraphQL or Connect client
+
+Fields are declared as name:type[:modifier...] and carried through the
+model, DTOs, migration, repository SQL and Angular components:
+
+  goastra generate crud product name:string:required price:decimal \
+    stock:int:default=0 sku:string:unique
+
+Types: string, text, email, url, uuid, int, bigint, float, decimal,
+bool, time, date, json
+Modifiers: required, unique, index, nullable, default=<value>,
+min=<n>, max=<n> (max also sizes string columns)
+Names SQL reserves, such as order, key or when, are rejected.
+
+The migration and repository SQL (placeholders, timestamps, reading
+back inserted rows) are written for the database driver in
+goastra.json, or the one the backend's go.mod requires when they
+disagree. The backend files go to the generators.api paths. The generated packages
+and their tests are checked with 'go vet' unless --skip-build-check is given.
+
+With --from-table the fields are read from an existing table instead of
+declared: column types, nullability, defaults, VARCHAR lengths, unique
+keys and foreign keys (indexed) become the field modifiers. Foreign
+keys are not mapped to relations; add --belongs-to <resource> to relate
+the resource through its <resource>_id key. The database
+is the one 'goastra migrate' connects to (DB_URL or the MYSQL_* /
+POSTGRES_* variables, from the environment or .env). The table must have
+an integer id primary key. Without both created_at and updated_at
+columns the resource has no timestamps and is listed by id. No
+migration is written; the resource name defaults to the singular table
+name:
+
+  goastra generate crud --from-table orders
+  goastra generate crud purchase --from-table orders
+
+Relations to resources generated earlier are declared with
+--belongs-to, --has-many and --many-to-many (repeatable or comma
+separated):
+
+  goastra generate crud post title:string --belongs-to user
+  goastra generate crud post title:string --belongs-to user \
+    --has-many comments --many-to-many tags
+
+--belongs-to adds a <resource>_id foreign key, indexed and deleted with
+its parent, and a nested route listing the children (GET
+/users/:id/posts). --has-many loads the children, which must have been
+generated with --belongs-to <resource>. --many-to-many writes a
+<resource>_<target> join table (an Ent edge in Ent projects) and, for
+REST APIs, GET and PUT /posts/:id/tags routes and a <target>_ids
+request member. The repository loads the relations of a whole page with
+one batched IN query each, the model and TypeScript interfaces gain the
+related members, and the Angular form picks related resources from
+selects. In Ent projects the inverse edge is added to the target's
+schema. Related resources must have been generated with generate crud;
+their tables are referenced by the names they were generated with.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if crudFromTable != "" {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: runGenerateCRUD,
 }
 
@@ -139,6 +222,71 @@ After generation, run 'go generate ./ent' to regenerate Ent code.`,
 	RunE: runGenerateEnt,
 }
 
+/*
+ * generateClientCmd creates an API client package for other services.
+ * Generates types and methods from the route table.
+ */
+var generateClientCmd = &cobra.Command{
+	Use:   "client",
+	Short: "Generate an API client SDK",
+	Long: `Generates a typed API client from the schema types and route table
+(the gin routes in --app, or an OpenAPI document given with --spec):
+  - Request and response structs for every component schema
+  - One service per route group with a method per endpoint
+  - context.Context support, retries with backoff for idempotent requests
+  - Bearer tokens with a refresh hook using the TokenPair shape
+  - Error responses decoded into *APIError
+
+TypeScript clients are generated by 'goastra typesync --target'.`,
+	Args: cobra.NoArgs,
+	RunE: runGenerateClient,
+}
+
+/*
+ * generateEjectCmd copies a generator's built-in templates into the
+ * project's templates directory.
+ */
+var generateEjectCmd = &cobra.Command{
+	Use:   "eject <generator>",
+	Short: "Copy generator templates into the project for customising",
+	Long: `Copies the built-in templates of a generator into codegen.templates
+(schema/templates by default), including the templates of the
+generators it runs. Edited copies replace the built-in templates in
+every later generate; delete a copy to go back to the default.
+
+Generators: api, crud, ent, graphql, module, trpc.
+
+Templates already in the project are kept unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGenerateEject,
+}
+
+var skipBuildCheck bool
+
+var ejectForce bool
+
+var (
+	generateDryRun bool
+	generateDiff   bool
+	generateForce  bool
+)
+
+var (
+	clientLang    string
+	clientOutput  string
+	clientPackage string
+	clientModule  string
+	clientSpec    string
+	clientApp     string
+)
+
+var (
+	crudFromTable  string
+	crudBelongsTo  []string
+	crudHasMany    []string
+	crudManyToMany []string
+)
+
 func init() {
 	rootCmd.AddCommand(generateCmd)
 	generateCmd.AddCommand(generateAPICmd)
@@ -147,41 +295,98 @@ func init() {
 	generateCmd.AddCommand(generateGraphQLCmd)
 	generateCmd.AddCommand(generateTRPCCmd)
 	generateCmd.AddCommand(generateEntCmd)
+	generateCmd.AddCommand(generateClientCmd)
+	generateCmd.AddCommand(generateEjectCmd)
+
+	generateAPICmd.Flags().BoolVar(&skipBuildCheck, "skip-build-check", false, "do not run 'go vet' on the generated packages")
+	generateCRUDCmd.Flags().BoolVar(&skipBuildCheck, "skip-build-check", false, "do not run 'go vet' on the generated packages")
+	generateCRUDCmd.Flags().StringVar(&crudFromTable, "from-table", "", "generate the resource from an existing database table")
+	generateCRUDCmd.Flags().StringSliceVar(&crudBelongsTo, "belongs-to", nil, "resources this resource belongs to (adds <resource>_id)")
+	generateCRUDCmd.Flags().StringSliceVar(&crudHasMany, "has-many", nil, "resources belonging to this resource")
+	generateCRUDCmd.Flags().StringSliceVar(&crudManyToMany, "many-to-many", nil, "resources related through a join table")
+
+	for _, c := range []*cobra.Command{generateAPICmd, generateModuleCmd, generateCRUDCmd, generateGraphQLCmd, generateTRPCCmd, generateEntCmd} {
+		c.Flags().BoolVar(&generateDryRun, "dry-run", false, "report what would be written without changing any file")
+		c.Flags().BoolVar(&generateDiff, "diff", false, "show a diff of every file that changes")
+		c.Flags().BoolVar(&generateForce, "force", false, "overwrite files edited since generation instead of merging")
+	}
+
+	generateClientCmd.Flags().StringVar(&clientLang, "lang", "go", "client language (go)")
+	generateClientCmd.Flags().StringVarP(&clientOutput, "output", "o", "clients/go/apiclient", "output directory")
+	generateClientCmd.Flags().StringVar(&clientPackage, "package", "", "package name (defaults to the output directory name)")
+	generateClientCmd.Flags().StringVar(&clientModule, "module", "", "also write a go.mod declaring this module path")
+	generateClientCmd.Flags().StringVar(&clientSpec, "spec", "", "OpenAPI document (JSON or YAML) to generate from instead of analysing routes")
+	generateClientCmd.Flags().StringVar(&clientApp, "app", "app", "backend application directory whose routes are analysed")
+
+	generateEjectCmd.Flags().BoolVar(&ejectForce, "force", false, "overwrite templates already in the project")
 }
 
 /*
  * runGenerateAPI executes the API generation workflow.
  * Creates all backend components for a REST endpoint.
  */
-func runGenerateAPI(cmd *cobra.Command, args []string) error {
+func runGenerateAPI(cmd *cobra.Command, args []string) (err error) {
 	name := args[0]
 	normalizedName := normalizeResourceName(name)
 
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	paths, err := generatorPaths(cfg)
+	if err != nil {
+		return err
+	}
+
+	out, err := newWriter("api", normalizedName, args[1:], paths.Templates)
+	if err != nil {
+		return err
+	}
+	defer keepWritten(out, &err)
+
 	color.Cyan("Generating API endpoint: %s\n", normalizedName)
 
+	stack, err := projectStack(cfg, paths)
+	if err != nil {
+		return err
+	}
+	if err := checkPackages(paths, stack); err != nil {
+		return err
+	}
+	driver, err := projectDriver(cfg, paths)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Project stack: %s\n", stack)
+
 	gen := generator.NewAPIGenerator(normalizedName)
+	gen.SetDriver(driver)
+	gen.SetPaths(paths)
+	gen.SetStack(stack)
+	gen.SetWriter(out)
 
-	if err := gen.GenerateHandler(); err != nil {
-		return fmt.Errorf("failed to generate handler: %w", err)
+	if _, err := gen.GenerateModel(); err != nil {
+		return fmt.Errorf("failed to generate model: %w", err)
 	}
 
-	if err := gen.GenerateService(); err != nil {
-		return fmt.Errorf("failed to generate service: %w", err)
+	if err := gen.GenerateAPI(); err != nil {
+		return fmt.Errorf("failed to generate API: %w", err)
 	}
 
-	if err := gen.GenerateRepository(); err != nil {
-		return fmt.Errorf("failed to generate repository: %w", err)
+	if err := finishWrite(out); err != nil {
+		return err
 	}
-
-	if err := gen.GenerateRoutes(); err != nil {
-		return fmt.Errorf("failed to generate routes: %w", err)
+	if out.DryRun() {
+		return nil
 	}
 
 	color.Green("API endpoint generated successfully!\n")
-	fmt.Printf("\nGenerated files:\n")
-	fmt.Printf("  app/internal/handlers/%s_handler.go\n", normalizedName)
-	fmt.Printf("  app/internal/services/%s_service.go\n", normalizedName)
-	fmt.Printf("  app/internal/repository/%s_repository.go\n", normalizedName)
+	if stack.API == "rest" {
+		wired, wireErr := gen.WireRoutes()
+		reportWiring(paths, wired, wireErr)
+	}
+	finishStack(paths, stack, normalizedName)
 
 	return nil
 }
@@ -190,13 +395,38 @@ func runGenerateAPI(cmd *cobra.Command, args []string) error {
  * runGenerateModule executes the Angular module generation.
  * Creates feature module with lazy loading configuration.
  */
-func runGenerateModule(cmd *cobra.Command, args []string) error {
+func runGenerateModule(cmd *cobra.Command, args []string) (err error) {
 	name := args[0]
 	normalizedName := normalizeResourceName(name)
 
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	paths, err := generatorPaths(cfg)
+	if err != nil {
+		return err
+	}
+
+	out, err := newWriter("module", normalizedName, args[1:], paths.Templates)
+	if err != nil {
+		return err
+	}
+	defer keepWritten(out, &err)
+
 	color.Cyan("Generating Angular module: %s\n", normalizedName)
 
+	stack, err := projectStack(cfg, paths)
+	if err != nil {
+		return err
+	}
+
 	gen := generator.NewModuleGenerator(normalizedName)
+	gen.SetFeaturesPath(cfg.Generators.Module.BasePath)
+	gen.SetTemplatesPath(paths.Templates)
+	gen.SetAPI(stack.API)
+	gen.SetWriter(out)
 
 	if err := gen.GenerateModule(); err != nil {
 		return fmt.Errorf("failed to generate module: %w", err)
@@ -214,12 +444,12 @@ func runGenerateModule(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to generate service: %w", err)
 	}
 
-	color.Green("Angular module generated successfully!\n")
-	fmt.Printf("\nGenerated files:\n")
-	fmt.Printf("  web/src/app/features/%s/%s.module.ts\n", normalizedName, normalizedName)
-	fmt.Printf("  web/src/app/features/%s/%s-routing.module.ts\n", normalizedName, normalizedName)
-	fmt.Printf("  web/src/app/features/%s/%s.component.ts\n", normalizedName, normalizedName)
-	fmt.Printf("  web/src/app/features/%s/%s.service.ts\n", normalizedName, normalizedName)
+	if err := finishWrite(out); err != nil {
+		return err
+	}
+	if !out.DryRun() {
+		color.Green("Angular module generated successfully!\n")
+	}
 
 	return nil
 }
@@ -228,60 +458,564 @@ func runGenerateModule(cmd *cobra.Command, args []string) error {
  * runGenerateCRUD executes full-stack CRUD generation.
  * Combines API and module generation with additional CRUD components.
  */
-func runGenerateCRUD(cmd *cobra.Command, args []string) error {
+func runGenerateCRUD(cmd *cobra.Command, args []string) (err error) {
+	var (
+		table    *generator.Table
+		dbDriver string
+		fields   []generator.Field
+		warnings []string
+		inputs   []string
+	)
+	if crudFromTable != "" {
+		table, dbDriver, err = introspectTable(crudFromTable)
+		if err != nil {
+			return err
+		}
+		fields, warnings, err = table.Fields()
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			args = []string{table.ResourceName()}
+		}
+		inputs = []string{"--from-table", table.Name}
+	} else {
+		fields, err = generator.ParseFields(args[1:])
+		if err != nil {
+			return err
+		}
+		inputs = args[1:]
+	}
+
+	relations, err := generator.ParseRelations(crudBelongsTo, crudHasMany, crudManyToMany)
+	if err != nil {
+		return err
+	}
+	fields, err = generator.AddRelationFields(fields, relations)
+	if err != nil {
+		return err
+	}
+	inputs = append(inputs, generator.RelationInputs(relations)...)
+
 	name := args[0]
 	normalizedName := normalizeResourceName(name)
 
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	paths, err := generatorPaths(cfg)
+	if err != nil {
+		return err
+	}
+
+	out, err := newWriter("crud", normalizedName, inputs, paths.Templates)
+	if err != nil {
+		return err
+	}
+	defer keepWritten(out, &err)
+
+	stack, err := projectStack(cfg, paths)
+	if err != nil {
+		return err
+	}
+	if err := checkPackages(paths, stack); err != nil {
+		return err
+	}
+
+	driver, err := projectDriver(cfg, paths)
+	if err != nil {
+		return err
+	}
+
 	color.Cyan("Generating CRUD stack: %s\n", normalizedName)
+	fmt.Printf("Project stack: %s\n", stack)
 
 	gen := generator.NewCRUDGenerator(normalizedName)
+	if table != nil {
+		reportTable(table, fields, warnings)
+		if dbDriver != driver {
+			color.Yellow("The database is %s but the backend uses %s; generating SQL for %s\n", dbDriver, driver, driver)
+		}
+		gen.SetTable(table.Name)
+		gen.SetTimestamps(table.Timestamps())
+	}
+	gen.SetFields(fields)
+	gen.SetRelations(relations)
+	gen.SetDriver(driver)
+	gen.SetPaths(paths)
+	gen.SetStack(stack)
+	gen.SetWriter(out)
+
+	if err := gen.CheckRelations(); err != nil {
+		return err
+	}
+	reportRelations(gen, relations, stack)
+
+	/* generators.crud.generateTests adds a step before the routes */
+	steps := 6
+	if cfg.Generators.CRUD.GenerateTests {
+		steps = 7
+	}
 
-	color.Yellow("[1/6] Generating model...\n")
+	color.Yellow("[%d/%d] Generating model...\n", 1, steps)
 	if err := gen.GenerateModel(); err != nil {
 		return fmt.Errorf("failed to generate model: %w", err)
 	}
 
-	color.Yellow("[2/6] Generating API...\n")
+	color.Yellow("[%d/%d] Generating API...\n", 2, steps)
 	if err := gen.GenerateAPI(); err != nil {
 		return fmt.Errorf("failed to generate API: %w", err)
 	}
 
-	color.Yellow("[3/6] Generating migration...\n")
-	if err := gen.GenerateMigration(); err != nil {
-		return fmt.Errorf("failed to generate migration: %w", err)
+	if !out.DryRun() && stack.API == "rest" {
+		wired, wireErr := gen.WireRoutes()
+		reportWiring(paths, wired, wireErr)
 	}
 
-	color.Yellow("[4/6] Generating Angular module...\n")
+	if stack.ORM == "ent" {
+		color.Yellow("[%d/%d] Generating Ent schema...\n", 3, steps)
+		fmt.Printf("  No migration written: Ent creates the table from the schema\n")
+		if err := gen.GenerateSchema(); err != nil {
+			return fmt.Errorf("failed to generate schema: %w", err)
+		}
+		if err := gen.AddInverseEdges(); err != nil {
+			return fmt.Errorf("failed to add inverse edges: %w", err)
+		}
+	} else if table != nil {
+		color.Yellow("[%d/%d] Skipping migration...\n", 3, steps)
+		fmt.Printf("  No migration written: the %s table already exists\n", table.Name)
+	} else {
+		color.Yellow("[%d/%d] Generating migration...\n", 3, steps)
+		if err := generateMigration(gen, paths); err != nil {
+			return fmt.Errorf("failed to generate migration: %w", err)
+		}
+	}
+	if stack.ORM != "ent" {
+		if err := gen.GenerateJoinMigrations(); err != nil {
+			return fmt.Errorf("failed to generate join table migration: %w", err)
+		}
+	}
+
+	color.Yellow("[%d/%d] Generating Angular module...\n", 4, steps)
 	if err := gen.GenerateModule(); err != nil {
 		return fmt.Errorf("failed to generate module: %w", err)
 	}
 
-	color.Yellow("[5/6] Generating CRUD components...\n")
+	color.Yellow("[%d/%d] Generating CRUD components...\n", 5, steps)
 	if err := gen.GenerateComponents(); err != nil {
 		return fmt.Errorf("failed to generate components: %w", err)
 	}
 
-	color.Yellow("[6/6] Updating routes...\n")
-	if err := gen.UpdateRoutes(); err != nil {
-		return fmt.Errorf("failed to update routes: %w", err)
+	if cfg.Generators.CRUD.GenerateTests {
+		color.Yellow("[%d/%d] Generating tests...\n", 6, steps)
+		if err := gen.GenerateTests(); err != nil {
+			return fmt.Errorf("failed to generate tests: %w", err)
+		}
+	}
+
+	color.Yellow("[%d/%d] Updating routes...\n", steps, steps)
+	if out.DryRun() {
+		fmt.Printf("  Router and app.routes.ts are not updated in a dry run\n")
+	} else {
+		updated, err := gen.UpdateRoutes()
+		switch {
+		case err != nil:
+			color.Yellow("  Angular routes not updated: %v\n", err)
+			fmt.Printf("  Add the following route to web/src/app/app.routes.ts:\n\n%s\n", gen.RouteSnippet())
+		case updated:
+			fmt.Printf("  Added route to web/src/app/app.routes.ts\n")
+		default:
+			fmt.Printf("  Route already present in web/src/app/app.routes.ts\n")
+		}
+	}
+
+	if err := finishWrite(out); err != nil {
+		return err
+	}
+	if out.DryRun() {
+		return nil
+	}
+
+	color.Green("CRUD stack generated successfully!\n")
+	finishStack(paths, stack, normalizedName)
+
+	return nil
+}
+
+/*
+ * generateMigration writes the resource's table migration. A create
+ * migration is only regenerated in place while it has not run; once it
+ * has, or when it cannot be told, new columns go into an alter
+ * migration so the database and the generated code stay in step.
+ */
+func generateMigration(gen *generator.CRUDGenerator, paths generator.Paths) error {
+	existing := gen.ExistingMigration()
+	if existing == "" {
+		return gen.GenerateMigration()
+	}
+
+	altered := len(gen.AlterMigrations()) > 0
+	if !altered {
+		applied, err := migrationApplied(paths.Migrations, existing)
+		if err != nil {
+			color.Yellow("  Cannot tell whether %s was applied (%v); adding new columns in a new migration\n", existing, err)
+		}
+		if !applied && err == nil {
+			return gen.GenerateMigration()
+		}
+	}
+
+	warnings, err := gen.GenerateAlterMigration()
+	for _, warning := range warnings {
+		color.Yellow("  %s\n", warning)
+	}
+	return err
+}
+
+/*
+ * introspectTable reads an existing table from the database that
+ * loadDatabaseURL resolves, and returns it with the database's driver.
+ */
+func introspectTable(name string) (*generator.Table, string, error) {
+	dbURL := loadDatabaseURL()
+	if dbURL == "" {
+		return nil, "", fmt.Errorf("no database connection configured (set DB_URL or the MYSQL_* / POSTGRES_* variables)")
+	}
+
+	cfg := migrator.DefaultConfig()
+	cfg.Driver = migrator.DetectDriverFromURL(dbURL)
+	m, err := migrator.New(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	defer m.Close()
+
+	if err := m.Connect(dbURL); err != nil {
+		return nil, "", err
+	}
+	table, err := generator.IntrospectTable(m.DB(), cfg.Driver, name)
+	if err != nil {
+		return nil, "", err
+	}
+	return table, cfg.Driver, nil
+}
+
+/*
+ * reportTable prints the fields read from an introspected table, its
+ * foreign keys and the columns that could not be carried over exactly.
+ */
+func reportTable(table *generator.Table, fields []generator.Field, warnings []string) {
+	fmt.Printf("Table: %s (%d fields)\n", table.Name, len(fields))
+	for _, key := range table.ForeignKeys() {
+		fmt.Printf("  Foreign key: %s\n", key)
+	}
+	if !table.Timestamps() {
+		fmt.Printf("  No created_at and updated_at columns: rows are listed by id\n")
+	}
+	for _, warning := range warnings {
+		color.Yellow("  %s\n", warning)
+	}
+}
+
+/*
+ * reportRelations prints the resource's relations and the nested
+ * routes they add, which only REST APIs serve.
+ */
+func reportRelations(gen *generator.CRUDGenerator, relations []generator.Relation, stack generator.Stack) {
+	for _, rel := range relations {
+		fmt.Printf("Relation: %s\n", rel)
+	}
+	routes := gen.NestedRoutes()
+	if len(routes) == 0 {
+		return
+	}
+	if stack.API != "rest" {
+		color.Yellow("  Nested routes are only generated for REST APIs; the service methods are available to %s\n", stack.API)
+		return
+	}
+	for _, route := range routes {
+		fmt.Printf("  Route: %s\n", route)
+	}
+}
+
+/*
+ * newWriter opens the project manifest and returns a writer recording
+ * files for the named generator, resource and remaining arguments,
+ * honouring the --dry-run and --force flags. The generator's template
+ * overrides in templates are parsed first, so a broken one fails the
+ * command before anything is written.
+ */
+func newWriter(kind, name string, args []string, templates string) (*generator.Writer, error) {
+	if err := generator.ValidateTemplates(templates, kind); err != nil {
+		return nil, err
+	}
+
+	manifest, err := generator.LoadManifest(generator.ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+
+	out := generator.NewWriter(manifest)
+	out.SetGenerator(kind, append([]string{name}, args...))
+	out.SetDryRun(generateDryRun)
+	out.SetForce(generateForce)
+	return out, nil
+}
+
+/*
+ * keepWritten saves the manifest when a command fails after writing some
+ * files, so they are recorded and merged rather than overwritten on the
+ * next run.
+ */
+func keepWritten(out *generator.Writer, err *error) {
+	if *err == nil {
+		return
+	}
+	if saveErr := out.Save(); saveErr != nil {
+		*err = fmt.Errorf("%w (failed to save manifest: %v)", *err, saveErr)
+	}
+}
+
+/*
+ * finishWrite saves the manifest and lists what happened to each file,
+ * with diffs when --diff is given. Merge conflicts are reported but do
+ * not fail the command: the markers are left in the files to resolve.
+ */
+func finishWrite(out *generator.Writer) error {
+	if err := out.Save(); err != nil {
+		return fmt.Errorf("failed to save manifest: %w", err)
 	}
 
-	color.Green("\nCRUD stack generated successfully!\n")
+	if out.DryRun() {
+		fmt.Printf("\nDry run, no files written:\n")
+	} else {
+		fmt.Printf("\nFiles:\n")
+	}
+	for _, change := range out.Changes() {
+		line := fmt.Sprintf("  %-9s %s", change.Action, change.Path)
+		switch change.Action {
+		case generator.Conflicted:
+			color.Red("%s (conflicts: %d)\n", line, change.Conflicts)
+		case generator.Merged:
+			color.Yellow("%s (kept local edits)\n", line)
+		default:
+			fmt.Println(line)
+		}
+		if generateDiff && change.Diff != "" {
+			fmt.Print(change.Diff)
+		}
+	}
 
+	if conflicts := out.Conflicts(); conflicts > 0 {
+		color.Yellow("\n%d file(s) have conflicts between local edits and the new output.\n", conflicts)
+		color.Yellow("Resolve the <<<<<<< / >>>>>>> markers, or rerun with --force to discard local edits.\n")
+	}
+	fmt.Println()
 	return nil
 }
 
+/*
+ * reportWiring prints the outcome of registering an API in the router.
+ * Failing to edit the router is not fatal: the routes file is written
+ * and can be wired by hand.
+ */
+func reportWiring(paths generator.Paths, wired bool, err error) {
+	router := filepath.Join(paths.Router, "router.go")
+	switch {
+	case err != nil:
+		color.Yellow("  Router not updated: %v\n", err)
+		color.Yellow("  Wire the handler and call its Register function in setupRoutes manually.\n")
+	case wired:
+		fmt.Printf("  Registered routes in %s\n", router)
+	default:
+		fmt.Printf("  Routes already registered in %s\n", router)
+	}
+}
+
+/*
+ * generatorPaths resolves the backend output directories from the
+ * generators.api paths and the module they are imported under. The
+ * module path comes from backend.module, or from the go.mod enclosing
+ * the handlers when it is not set; the two must agree. The GraphQL,
+ * proto and Ent directories are the ones `goastra new` creates under
+ * the module root. Templates is codegen.templates.
+ */
+func generatorPaths(cfg *config.Config) (generator.Paths, error) {
+	api := cfg.Generators.API
+	paths := generator.Paths{
+		Module:     cfg.Backend.Module,
+		Handlers:   api.HandlerPath,
+		Services:   api.ServicePath,
+		Repository: api.RepositoryPath,
+		Models:     api.ModelPath,
+		Router:     api.RouterPath,
+		Database:   api.DatabasePath,
+		Validator:  api.ValidatorPath,
+		Migrations: cfg.Database.MigrationsPath,
+		Features:   cfg.Generators.Module.BasePath,
+		RPC:        api.RPCPath,
+		Templates:  cfg.Codegen.Templates,
+	}
+
+	root, module, err := generator.FindModule(api.HandlerPath)
+	switch {
+	case err == nil:
+		paths.Root = root
+		if paths.Module == "" {
+			paths.Module = module
+		} else if paths.Module != module {
+			return paths, fmt.Errorf("backend.module %q in goastra.json does not match module %q in %s", paths.Module, module, filepath.Join(root, "go.mod"))
+		}
+	case paths.Module == "":
+		return paths, fmt.Errorf("cannot determine the backend module path: set backend.module in goastra.json")
+	default:
+		paths.Root = strings.Split(filepath.ToSlash(filepath.Clean(api.HandlerPath)), "/")[0]
+	}
+
+	paths.Graph = filepath.Join(paths.Root, "graph")
+	paths.Proto = filepath.Join(paths.Root, "proto", "v1")
+	paths.Ent = filepath.Join(paths.Root, "ent")
+	return paths, nil
+}
+
+/*
+ * projectStack returns the API style and ORM generated code targets,
+ * from goastra.json or else the backend's layout.
+ */
+func projectStack(cfg *config.Config, paths generator.Paths) (generator.Stack, error) {
+	return generator.DetectStack(cfg.API.Type, cfg.Database.ORM, paths.Root)
+}
+
+/*
+ * checkPackages verifies that the packages the stack's generated code
+ * is wired into exist: the router and validator of a REST backend, and
+ * the database and RPC packages of a tRPC one. Their paths are
+ * generators.api routerPath, validatorPath, databasePath and rpcPath.
+ */
+func checkPackages(paths generator.Paths, stack generator.Stack) error {
+	type pkg struct {
+		name, path, key string
+	}
+	var pkgs []pkg
+	switch stack.API {
+	case "rest":
+		pkgs = []pkg{{"router", filepath.Join(paths.Router, "router.go"), "routerPath"}, {"validator", paths.Validator, "validatorPath"}}
+	case "trpc":
+		pkgs = []pkg{{"database", paths.Database, "databasePath"}, {"RPC", paths.RPC, "rpcPath"}}
+	}
+	for _, p := range pkgs {
+		if _, err := os.Stat(p.path); err != nil {
+			return fmt.Errorf("%s package not found: %s does not exist; set generators.api.%s in goastra.json", p.name, p.path, p.key)
+		}
+	}
+	return nil
+}
+
+/*
+ * projectDriver returns the database generated SQL targets. A driver
+ * in goastra.json that the backend does not use is reported, since the
+ * backend's own driver wins.
+ */
+func projectDriver(cfg *config.Config, paths generator.Paths) (string, error) {
+	driver, err := generator.DetectDriver(cfg.Database.Driver, paths.Root)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Database.Driver != "" && driver != cfg.Database.Driver {
+		color.Yellow("database.driver is %q in goastra.json but the backend uses %s; generating SQL for %s\n", cfg.Database.Driver, driver, driver)
+	}
+	return driver, nil
+}
+
+/*
+ * finishStack build-checks REST code on sqlx. Code for the other stacks
+ * needs Ent, gqlgen or buf output that does not exist yet, so the steps
+ * producing it are listed instead.
+ */
+func finishStack(paths generator.Paths, stack generator.Stack, name string) {
+	if stack == generator.DefaultStack() {
+		checkBuild(paths)
+		return
+	}
+
+	fmt.Printf("\nNext steps:\n")
+	step := 1
+	if stack.ORM == "ent" {
+		fmt.Printf("  %d. Run: go generate ./ent (in %s)\n", step, paths.Root)
+		step++
+	}
+	switch stack.API {
+	case "graphql":
+		fmt.Printf("  %d. Run: go generate ./graph (in %s)\n", step, paths.Root)
+	case "trpc":
+		gen := generator.NewTRPCGenerator(name)
+		fmt.Printf("  %d. Run: buf generate (in %s and web)\n", step, paths.Root)
+		fmt.Printf("  %d. Register the service in main.go:\n\n%s\n", step+1, gen.RegisterSnippet())
+	default:
+		fmt.Printf("  %d. Run: go build ./... (in %s)\n", step, paths.Root)
+	}
+}
+
+/*
+ * checkBuild compiles the generated packages and their tests with go
+ * vet and reports errors as a warning, since the files are already
+ * written and can be fixed by hand.
+ */
+func checkBuild(paths generator.Paths) {
+	if skipBuildCheck {
+		return
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		color.Yellow("\nSkipping build check: go not found in PATH\n")
+		return
+	}
+
+	fmt.Printf("\nChecking generated packages and tests with go vet...\n")
+	err := generator.BuildCheck(paths, paths.Handlers, paths.Services, paths.Repository, paths.Models, paths.Router)
+	if err != nil {
+		color.Yellow("  Build check failed:\n%v\n", err)
+		return
+	}
+	fmt.Printf("  Build OK\n")
+}
+
 /*
  * runGenerateGraphQL executes GraphQL generation workflow.
  * Creates schema and resolver files.
  */
-func runGenerateGraphQL(cmd *cobra.Command, args []string) error {
+func runGenerateGraphQL(cmd *cobra.Command, args []string) (err error) {
 	name := args[0]
 	normalizedName := normalizeResourceName(name)
 
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	paths, err := generatorPaths(cfg)
+	if err != nil {
+		return err
+	}
+
+	stack, err := projectStack(cfg, paths)
+	if err != nil {
+		return err
+	}
+
+	out, err := newWriter("graphql", normalizedName, args[1:], paths.Templates)
+	if err != nil {
+		return err
+	}
+	defer keepWritten(out, &err)
+
 	color.Cyan("Generating GraphQL schema and resolvers: %s\n", normalizedName)
 
 	gen := generator.NewGraphQLGenerator(normalizedName)
+	gen.SetPaths(paths)
+	gen.SetStack(stack)
+	gen.SetWriter(out)
 
 	if err := gen.GenerateSchema(); err != nil {
 		return fmt.Errorf("failed to generate schema: %w", err)
@@ -291,10 +1025,14 @@ func runGenerateGraphQL(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to generate resolver: %w", err)
 	}
 
+	if err := finishWrite(out); err != nil {
+		return err
+	}
+	if out.DryRun() {
+		return nil
+	}
+
 	color.Green("GraphQL artifacts generated successfully!\n")
-	fmt.Printf("\nGenerated files:\n")
-	fmt.Printf("  app/graph/%s.graphqls\n", normalizedName)
-	fmt.Printf("  app/graph/%s.resolvers.go\n", normalizedName)
 	fmt.Printf("\nNext steps:\n")
 	fmt.Printf("  1. Add fields to the schema\n")
 	fmt.Printf("  2. Run: go generate ./...\n")
@@ -307,13 +1045,40 @@ func runGenerateGraphQL(cmd *cobra.Command, args []string) error {
  * runGenerateTRPC executes tRPC generation workflow.
  * Creates proto and service files.
  */
-func runGenerateTRPC(cmd *cobra.Command, args []string) error {
+func runGenerateTRPC(cmd *cobra.Command, args []string) (err error) {
 	name := args[0]
 	normalizedName := normalizeResourceName(name)
 
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	paths, err := generatorPaths(cfg)
+	if err != nil {
+		return err
+	}
+
+	stack, err := projectStack(cfg, paths)
+	if err != nil {
+		return err
+	}
+	if err := checkPackages(paths, stack); err != nil {
+		return err
+	}
+
+	out, err := newWriter("trpc", normalizedName, args[1:], paths.Templates)
+	if err != nil {
+		return err
+	}
+	defer keepWritten(out, &err)
+
 	color.Cyan("Generating tRPC proto and service: %s\n", normalizedName)
 
 	gen := generator.NewTRPCGenerator(normalizedName)
+	gen.SetPaths(paths)
+	gen.SetStack(stack)
+	gen.SetWriter(out)
 
 	if err := gen.GenerateProto(); err != nil {
 		return fmt.Errorf("failed to generate proto: %w", err)
@@ -323,10 +1088,14 @@ func runGenerateTRPC(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to generate service: %w", err)
 	}
 
+	if err := finishWrite(out); err != nil {
+		return err
+	}
+	if out.DryRun() {
+		return nil
+	}
+
 	color.Green("tRPC artifacts generated successfully!\n")
-	fmt.Printf("\nGenerated files:\n")
-	fmt.Printf("  app/proto/v1/%s.proto\n", strings.ReplaceAll(normalizedName, "-", "_"))
-	fmt.Printf("  app/internal/rpc/%s_service.go\n", strings.ReplaceAll(normalizedName, "-", "_"))
 	fmt.Printf("\nNext steps:\n")
 	fmt.Printf("  1. Add fields to the proto\n")
 	fmt.Printf("  2. Run: buf generate\n")
@@ -340,21 +1109,44 @@ func runGenerateTRPC(cmd *cobra.Command, args []string) error {
  * runGenerateEnt executes Ent schema generation workflow.
  * Creates entity schema file.
  */
-func runGenerateEnt(cmd *cobra.Command, args []string) error {
+func runGenerateEnt(cmd *cobra.Command, args []string) (err error) {
 	name := args[0]
 	normalizedName := normalizeResourceName(name)
 
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	paths, err := generatorPaths(cfg)
+	if err != nil {
+		return err
+	}
+
+	out, err := newWriter("ent", normalizedName, args[1:], paths.Templates)
+	if err != nil {
+		return err
+	}
+	defer keepWritten(out, &err)
+
 	color.Cyan("Generating Ent schema: %s\n", normalizedName)
 
 	gen := generator.NewEntGenerator(normalizedName)
+	gen.SetPaths(paths)
+	gen.SetWriter(out)
 
 	if err := gen.GenerateSchema(); err != nil {
 		return fmt.Errorf("failed to generate schema: %w", err)
 	}
 
+	if err := finishWrite(out); err != nil {
+		return err
+	}
+	if out.DryRun() {
+		return nil
+	}
+
 	color.Green("Ent schema generated successfully!\n")
-	fmt.Printf("\nGenerated files:\n")
-	fmt.Printf("  app/ent/schema/%s.go\n", strings.ReplaceAll(normalizedName, "-", "_"))
 	fmt.Printf("\nNext steps:\n")
 	fmt.Printf("  1. Add fields to the schema\n")
 	fmt.Printf("  2. Run: go generate ./ent\n")
@@ -363,6 +1155,107 @@ func runGenerateEnt(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+/*
+ * runGenerateClient executes API client generation.
+ * Reads the route table and writes the client package.
+ */
+func runGenerateClient(cmd *cobra.Command, args []string) error {
+	if clientLang != "go" {
+		return fmt.Errorf("unsupported client language: %s (use 'go'; TypeScript clients are generated by 'goastra typesync --target')", clientLang)
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	mappings, err := typeMappings(cfg)
+	if err != nil {
+		return err
+	}
+
+	pkgName := clientPackage
+	if pkgName == "" {
+		pkgName = strings.NewReplacer("-", "", ".", "", "_", "").Replace(strings.ToLower(filepath.Base(clientOutput)))
+	}
+	if !token.IsIdentifier(pkgName) {
+		return fmt.Errorf("invalid package name: %q (use --package)", pkgName)
+	}
+
+	outputPath, err := filepath.Abs(clientOutput)
+	if err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+
+	endpoints, err := loadEndpoints(cfg, clientSpec, clientApp, nil, mappings)
+	if err != nil {
+		return err
+	}
+
+	color.Cyan("Generating Go client: %s\n", pkgName)
+
+	gen := codegen.NewGoClientGenerator(outputPath, pkgName)
+	gen.SetModule(clientModule)
+	removed, err := gen.Generate(endpoints)
+	if err != nil {
+		return fmt.Errorf("failed to generate client: %w", err)
+	}
+	for _, path := range removed {
+		color.Yellow("Removed stale %s\n", path)
+	}
+
+	color.Green("Go client generated successfully!\n")
+	fmt.Printf("\nGenerated %d endpoints in %d services:\n", len(endpoints.Endpoints), len(endpoints.Groups()))
+	fmt.Printf("  %s\n", clientOutput)
+
+	return nil
+}
+
+/*
+ * runGenerateEject copies a generator's templates into the project's
+ * templates directory.
+ */
+func runGenerateEject(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	dir := cfg.Codegen.Templates
+	if dir == "" {
+		return fmt.Errorf("codegen.templates is not set in goastra.json")
+	}
+
+	if _, err := generator.TemplateNames(args[0]); err != nil {
+		return err
+	}
+
+	color.Cyan("Ejecting %s templates to %s\n", args[0], dir)
+
+	results, err := generator.EjectTemplates(args[0], dir, ejectForce)
+	if err != nil {
+		return err
+	}
+
+	skipped := 0
+	fmt.Printf("\nFiles:\n")
+	for _, result := range results {
+		if result.Skipped {
+			skipped++
+			color.Yellow("  %-9s %s (already customised)\n", "skipped", result.Path)
+			continue
+		}
+		fmt.Printf("  %-9s %s\n", "created", result.Path)
+	}
+
+	if skipped > 0 {
+		color.Yellow("\nRerun with --force to replace the %d skipped template(s) with the defaults.\n", skipped)
+	}
+	color.Green("\nTemplates ejected. Edit them to change what goastra generate writes.\n")
+
+	return nil
+}
+
 /*
  * normalizeResourceName converts input to lowercase with hyphens.
  * Ensures consistent naming across all generated files.
@@ -0,0 +1,181 @@
+/*
+ * GoAstra CLI - OpenAPI Command
+ *
+ * Generates an OpenAPI 3 specification by statically analysing the
+ * gin routes of the backend and the Go types its handlers exchange.
+ */
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/channdev/goastra/cli/internal/codegen"
+	"github.com/channdev/goastra/cli/internal/config"
+	"github.com/channdev/goastra/cli/internal/routes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	openapiOutput string
+	openapiFormat string
+	openapiApp    string
+)
+
+/*
+ * openapiCmd writes an OpenAPI description of the backend API.
+ */
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Generate an OpenAPI 3 spec from routes and types",
+	Long: `Generates an OpenAPI 3 specification for the backend:
+  - Follows gin route groups, Use() middleware and helper functions such as
+    the generated Register<Name>Routes to find every endpoint
+  - Reads request bodies from c.ShouldBindJSON/BindJSON, query parameters
+    from c.Query/DefaultQuery/ShouldBindQuery and responses from c.JSON
+  - Maps the schema package and handler types to components, expanding
+    generic types such as PaginatedResponse[User]
+  - Turns validate tags into schema constraints (required, min, max,
+    email, oneof, ...)
+  - Marks routes behind middleware.Auth with bearer authentication
+  - Writes JSON or YAML depending on the output extension or --format`,
+	RunE: runOpenAPI,
+}
+
+func init() {
+	rootCmd.AddCommand(openapiCmd)
+
+	openapiCmd.Flags().StringVarP(&openapiOutput, "output", "o", "openapi.json", "output file (.json, .yaml or .yml)")
+	openapiCmd.Flags().StringVar(&openapiFormat, "format", "", "output format (json, yaml); inferred from --output by default")
+	openapiCmd.Flags().StringVar(&openapiApp, "app", "app", "backend application directory")
+}
+
+/*
+ * runOpenAPI analyses the backend and writes the specification.
+ */
+func runOpenAPI(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	format := codegen.OpenAPIFormat(strings.ToLower(openapiFormat))
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(openapiOutput)) {
+		case ".yaml", ".yml":
+			format = codegen.OpenAPIYAML
+		default:
+			format = codegen.OpenAPIJSON
+		}
+	}
+	if format != codegen.OpenAPIJSON && format != codegen.OpenAPIYAML {
+		return fmt.Errorf("invalid format: %s (use 'json' or 'yaml')", openapiFormat)
+	}
+
+	mappings, err := typeMappings(cfg)
+	if err != nil {
+		return err
+	}
+
+	data, err := renderOpenAPI(cfg, openapiApp, mappings, format)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(openapiOutput); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(openapiOutput, data, 0644); err != nil {
+		return err
+	}
+
+	color.Green("OpenAPI spec written to %s\n", openapiOutput)
+	return nil
+}
+
+/*
+ * renderOpenAPI analyses the routes of the app directory and renders
+ * them with the schema package types as an OpenAPI document.
+ */
+func renderOpenAPI(cfg *config.Config, app string, mappings codegen.TypeMappings, format codegen.OpenAPIFormat) ([]byte, error) {
+	if _, err := os.Stat(app); os.IsNotExist(err) {
+		return nil, fmt.Errorf("app directory not found: %s", app)
+	}
+
+	color.Cyan("Analysing routes in %s...\n", app)
+
+	analysis, err := routes.Analyze(app, cfg.API.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	color.Yellow("Found %d routes\n", len(analysis.Routes))
+
+	title := cfg.Name
+	if title == "" {
+		title = "GoAstra API"
+	}
+
+	generator := codegen.NewOpenAPIGenerator(title, cfg.API.Version)
+	generator.SetTypeMappings(mappings)
+	generator.SetAPIPrefix(cfg.API.Prefix)
+	if cfg.Backend.Port != 0 {
+		generator.AddServer(fmt.Sprintf("http://localhost:%d", cfg.Backend.Port))
+	}
+
+	if _, err := os.Stat(cfg.Codegen.SchemaPath); err == nil {
+		if err := generator.AddSchemaPackage(cfg.Codegen.SchemaPath); err != nil {
+			return nil, fmt.Errorf("failed to load schema types: %w", err)
+		}
+	}
+
+	data, err := generator.Render(analysis, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate OpenAPI spec: %w", err)
+	}
+	return data, nil
+}
+
+/*
+ * loadEndpoints reads the route table from the OpenAPI document at spec,
+ * or from the gin routes of app when spec is empty. known holds the
+ * generated TypeScript model names.
+ */
+func loadEndpoints(cfg *config.Config, spec, app string, known map[string]bool, mappings codegen.TypeMappings) (*codegen.EndpointSet, error) {
+	var data []byte
+	var err error
+	if spec != "" {
+		data, err = os.ReadFile(spec)
+	} else {
+		data, err = renderOpenAPI(cfg, app, mappings, codegen.OpenAPIJSON)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := codegen.ParseAPISpec(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return codegen.NewEndpointSet(parsed, cfg.API.Prefix, known, mappings)
+}
+
+/*
+ * typeMappings converts codegen.typeMappings from goastra.json.
+ */
+func typeMappings(cfg *config.Config) (codegen.TypeMappings, error) {
+	mappings := make(codegen.TypeMappings, len(cfg.Codegen.TypeMappings))
+	for goType, mapping := range cfg.Codegen.TypeMappings {
+		if mapping.Type == "" {
+			return nil, fmt.Errorf("typeMappings[%q]: missing type", goType)
+		}
+		mappings[goType] = codegen.TypeMapping{TS: mapping.Type, Import: mapping.Import}
+	}
+	return mappings, nil
+}
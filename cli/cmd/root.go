@@ -35,6 +35,7 @@ Usage:
   goastra build             Build for production
   goastra generate          Generate code artifacts
   goastra typesync          Sync Go types to TypeScript
+  goastra openapi           Generate an OpenAPI 3 spec
   goastra test              Run test suites
   goastra migrate           Database migration management`,
 	Version: "1.0.0",
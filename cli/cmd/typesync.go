@@ -11,9 +11,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/channdev/goastra/cli/internal/codegen"
+	"github.com/channdev/goastra/cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -21,6 +23,14 @@ var (
 	typesyncOutput  string
 	typesyncWatch   bool
 	typesyncService bool
+	typesyncEmbed   string
+	typesyncEnums   string
+	typesyncCheck   bool
+	typesyncZod     bool
+	typesyncSpec    string
+	typesyncApp     string
+	typesyncTarget  string
+	typesyncLayout  string
 )
 
 /*
@@ -31,19 +41,42 @@ var typesyncCmd = &cobra.Command{
 	Use:   "typesync",
 	Short: "Sync Go types to TypeScript",
 	Long: `Generates TypeScript interfaces from Go struct definitions:
-  - Parses schema/types/*.go files
-  - Generates TypeScript interfaces in web/src/app/core/models/
-  - Optionally generates Angular services for API calls
-  - Supports watch mode for continuous synchronization`,
+  - Type-checks the schema package (codegen.schemaPath, plus any in
+    codegen.schemaPaths) and resolves named, external and well-known
+    types (time.Time, uuid.UUID, ...)
+  - Generates TypeScript interfaces in codegen.outputPath, one file per
+    type or grouped per source file, per package or in a single bundle
+    (--layout or codegen.layout)
+  - Applies codegen.typeMappings and per-field ts:"Type,readonly,optional,nullable" tags
+  - Optionally generates API clients from the API route table: the gin
+    routes in --app, or an OpenAPI document given with --spec
+  - Clients target Angular services, a plain fetch client, React Query
+    hooks or Vue composables (--target or codegen.target)
+  - Optionally generates Zod validation schemas (--zod) that services
+    use to check responses in dev builds
+  - Emits embedded structs as 'extends' clauses or flattened fields
+  - Emits named types with const blocks as unions or enums
+    (mark a type with //typesync:enum or //typesync:ignore to opt in or out)
+  - Deletes outputs of types that no longer exist (tracked in ` + codegen.ManifestName + `)
+  - Supports watch mode for continuous synchronization
+  - --check renders in memory and fails with a diff if outputs are stale`,
 	RunE: runTypesync,
 }
 
 func init() {
 	rootCmd.AddCommand(typesyncCmd)
 
-	typesyncCmd.Flags().StringVarP(&typesyncOutput, "output", "o", "web/src/app/core/models", "output directory for TypeScript files")
+	typesyncCmd.Flags().StringVarP(&typesyncOutput, "output", "o", "web/src/app/core/models", "output directory for TypeScript files (overrides codegen.outputPath)")
 	typesyncCmd.Flags().BoolVarP(&typesyncWatch, "watch", "w", false, "watch for changes and regenerate")
-	typesyncCmd.Flags().BoolVar(&typesyncService, "services", false, "also generate Angular services")
+	typesyncCmd.Flags().BoolVar(&typesyncService, "services", false, "also generate API clients")
+	typesyncCmd.Flags().StringVar(&typesyncEmbed, "embed", "extends", "embedded struct output (extends, flatten)")
+	typesyncCmd.Flags().StringVar(&typesyncEnums, "enums", "union", "constant group output (union, enum)")
+	typesyncCmd.Flags().StringVar(&typesyncLayout, "layout", "", "module layout (per-type, per-file, per-package, bundle); overrides codegen.layout")
+	typesyncCmd.Flags().BoolVar(&typesyncCheck, "check", false, "verify generated files are up to date without writing")
+	typesyncCmd.Flags().BoolVar(&typesyncZod, "zod", false, "also generate Zod validation schemas (or set codegen.zod)")
+	typesyncCmd.Flags().StringVar(&typesyncSpec, "spec", "", "OpenAPI document (JSON or YAML) to generate services from instead of analysing routes")
+	typesyncCmd.Flags().StringVar(&typesyncApp, "app", "app", "backend application directory whose routes services are generated from")
+	typesyncCmd.Flags().StringVar(&typesyncTarget, "target", "", "client target (angular, fetch, react-query, vue); implies --services")
 }
 
 /*
@@ -51,23 +84,74 @@ func init() {
  * Parses Go files and generates corresponding TypeScript.
  */
 func runTypesync(cmd *cobra.Command, args []string) error {
-	schemaPath := "schema/types"
-	outputPath, err := filepath.Abs(typesyncOutput)
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	schemaPath := cfg.Codegen.SchemaPath
+	output := cfg.Codegen.OutputPath
+	if cmd.Flags().Changed("output") {
+		output = typesyncOutput
+	}
+
+	outputPath, err := filepath.Abs(output)
 	if err != nil {
 		return fmt.Errorf("invalid output path: %w", err)
 	}
 
-	if _, err := os.Stat(schemaPath); os.IsNotExist(err) {
-		return fmt.Errorf("schema directory not found: %s", schemaPath)
+	mappings, err := typeMappings(cfg)
+	if err != nil {
+		return err
+	}
+
+	embedMode := codegen.EmbedMode(typesyncEmbed)
+	if embedMode != codegen.EmbedExtends && embedMode != codegen.EmbedFlatten {
+		return fmt.Errorf("invalid embed mode: %s (use 'extends' or 'flatten')", typesyncEmbed)
+	}
+
+	enumStyle := codegen.EnumStyle(typesyncEnums)
+	if enumStyle != codegen.EnumUnion && enumStyle != codegen.EnumTS {
+		return fmt.Errorf("invalid enum style: %s (use 'union' or 'enum')", typesyncEnums)
+	}
+
+	schemaPaths := append([]string{schemaPath}, cfg.Codegen.SchemaPaths...)
+	for _, path := range schemaPaths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("schema directory not found: %s", path)
+		}
+	}
+
+	layout := codegen.Layout(cfg.Codegen.Layout)
+	if cmd.Flags().Changed("layout") {
+		layout = codegen.Layout(typesyncLayout)
+	}
+	if layout == "" {
+		layout = codegen.LayoutPerType
+	}
+	if !validLayout(layout) {
+		return fmt.Errorf("invalid layout: %s (use 'per-type', 'per-file', 'per-package' or 'bundle')", layout)
+	}
+
+	target := codegen.ClientTarget(cfg.Codegen.Target)
+	if cmd.Flags().Changed("target") {
+		target = codegen.ClientTarget(typesyncTarget)
+	}
+	if !validTarget(target) {
+		return fmt.Errorf("invalid client target: %s (use 'angular', 'fetch', 'react-query' or 'vue')", target)
 	}
 
-	if err := os.MkdirAll(outputPath, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	if typesyncCheck && typesyncWatch {
+		return fmt.Errorf("--check cannot be combined with --watch")
 	}
 
 	color.Cyan("Syncing Go types to TypeScript...\n")
 
 	parser := codegen.NewGoParser(schemaPath)
+	for _, path := range cfg.Codegen.SchemaPaths {
+		parser.AddSchemaPath(path)
+	}
+	parser.SetTypeMappings(mappings)
 	types, err := parser.Parse()
 	if err != nil {
 		return fmt.Errorf("failed to parse Go types: %w", err)
@@ -76,22 +160,71 @@ func runTypesync(cmd *cobra.Command, args []string) error {
 	color.Yellow("Found %d type definitions\n", len(types))
 
 	tsGenerator := codegen.NewTypeScriptGenerator(outputPath)
-	if err := tsGenerator.Generate(types); err != nil {
+	tsGenerator.SetEmbedMode(embedMode)
+	tsGenerator.SetEnumStyle(enumStyle)
+	tsGenerator.SetLayout(layout)
+	tsGenerator.SetTypeMappings(mappings)
+	tsGenerator.SetReadonly(cfg.Codegen.Readonly)
+	out := codegen.NewOutput()
+	if err := tsGenerator.Render(types, out); err != nil {
 		return fmt.Errorf("failed to generate TypeScript: %w", err)
 	}
 
-	if typesyncService {
-		color.Yellow("Generating Angular services...\n")
-		serviceGen := codegen.NewServiceGenerator(outputPath)
-		if err := serviceGen.Generate(types); err != nil {
+	zod := typesyncZod || cfg.Codegen.Zod
+	if zod {
+		zodGenerator := codegen.NewZodGenerator(outputPath)
+		zodGenerator.SetEnumStyle(enumStyle)
+		zodGenerator.SetLayout(layout)
+		zodGenerator.SetTypeMappings(mappings)
+		if err := zodGenerator.Render(types, out); err != nil {
+			return fmt.Errorf("failed to generate Zod schemas: %w", err)
+		}
+	}
+
+	if err := out.AddManifest(outputPath); err != nil {
+		return err
+	}
+
+	if typesyncService || cmd.Flags().Changed("target") {
+		color.Yellow("Generating %s API client...\n", target)
+		servicePath, err := filepath.Abs(cfg.Codegen.ServiceOutput)
+		if err != nil {
+			return fmt.Errorf("invalid service output path: %w", err)
+		}
+		endpoints, err := typesyncEndpoints(cfg, types, mappings)
+		if err != nil {
+			return err
+		}
+		serviceGen := codegen.NewServiceGenerator(servicePath)
+		serviceGen.SetTarget(target)
+		serviceGen.SetSchemas(zod)
+		if target != codegen.TargetAngular {
+			serviceGen.SetModelsImport(relativeImport(servicePath, outputPath))
+		}
+		if err := serviceGen.Render(endpoints, out); err != nil {
 			return fmt.Errorf("failed to generate services: %w", err)
 		}
+		if err := out.AddManifest(servicePath); err != nil {
+			return err
+		}
+	}
+
+	if typesyncCheck {
+		return checkTypesync(cmd, out)
+	}
+
+	removed, err := out.Write()
+	if err != nil {
+		return fmt.Errorf("failed to write TypeScript: %w", err)
+	}
+	for _, path := range removed {
+		color.Yellow("Removed stale %s\n", path)
 	}
 
 	color.Green("TypeScript generation complete!\n")
 	fmt.Printf("Output: %s\n", outputPath)
 
-	if typesyncWatch {
+	if typesyncWatch || cfg.Codegen.Watch {
 		color.Cyan("Watching for changes... (Ctrl+C to stop)\n")
 		return watchForChanges(schemaPath, outputPath)
 	}
@@ -99,6 +232,83 @@ func runTypesync(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+/*
+ * validTarget reports whether target is a supported client target.
+ */
+func validTarget(target codegen.ClientTarget) bool {
+	for _, known := range codegen.ClientTargets {
+		if target == known {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * validLayout reports whether layout is a supported module layout.
+ */
+func validLayout(layout codegen.Layout) bool {
+	for _, known := range codegen.Layouts {
+		if layout == known {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * relativeImport returns the TypeScript module specifier of dir as
+ * seen from files in from, e.g. "../models".
+ */
+func relativeImport(from, dir string) string {
+	rel, err := filepath.Rel(from, dir)
+	if err != nil {
+		return dir
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, ".") {
+		rel = "./" + rel
+	}
+	return rel
+}
+
+/*
+ * typesyncEndpoints reads the route table from --spec, or from the gin
+ * routes of --app, and resolves its types against the generated models.
+ */
+func typesyncEndpoints(cfg *config.Config, types []codegen.TypeDef, mappings codegen.TypeMappings) (*codegen.EndpointSet, error) {
+	known := make(map[string]bool, len(types))
+	for _, typeDef := range types {
+		known[typeDef.Name] = true
+	}
+	return loadEndpoints(cfg, typesyncSpec, typesyncApp, known, mappings)
+}
+
+/*
+ * checkTypesync prints a diff of pending changes and fails when the
+ * generated files on disk are out of date.
+ */
+func checkTypesync(cmd *cobra.Command, out *codegen.Output) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	diff, err := out.Check(cwd)
+	if err != nil {
+		return err
+	}
+
+	if diff == "" {
+		color.Green("Generated TypeScript is up to date\n")
+		return nil
+	}
+
+	fmt.Print(diff)
+	cmd.SilenceUsage = true
+	return fmt.Errorf("generated TypeScript is out of date; run 'goastra typesync'")
+}
+
 /*
  * watchForChanges monitors the schema directory for modifications.
  * Triggers regeneration when Go files are updated.
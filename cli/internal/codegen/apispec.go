@@ -0,0 +1,459 @@
+/*
+ * GoAstra CLI - API Specification
+ *
+ * Reads the subset of an OpenAPI 3 document that client generators
+ * need: operations, parameters, bodies, responses, security and
+ * component schemas. Object properties keep their document order so
+ * generated interfaces match the spec.
+ */
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+ * APISpec is a parsed OpenAPI document.
+ */
+type APISpec struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components APIComponents                         `json:"components"`
+	Security   []map[string][]string                 `json:"security"`
+}
+
+/*
+ * APIComponents holds reusable schemas and parameters.
+ */
+type APIComponents struct {
+	Schemas    map[string]*Schema       `json:"schemas"`
+	Parameters map[string]*APIParameter `json:"parameters"`
+}
+
+/*
+ * APIOperation is one method of a path item.
+ */
+type APIOperation struct {
+	OperationID string                  `json:"operationId"`
+	Summary     string                  `json:"summary"`
+	Description string                  `json:"description"`
+	Tags        []string                `json:"tags"`
+	Deprecated  bool                    `json:"deprecated"`
+	Parameters  []*APIParameter         `json:"parameters"`
+	RequestBody *APIRequestBody         `json:"requestBody"`
+	Responses   map[string]*APIResponse `json:"responses"`
+	Security    *[]map[string][]string  `json:"security"`
+}
+
+/*
+ * APIParameter is a path, query or header parameter.
+ */
+type APIParameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+/*
+ * APIRequestBody is an operation's request body.
+ */
+type APIRequestBody struct {
+	Required bool                    `json:"required"`
+	Content  map[string]APIMediaType `json:"content"`
+}
+
+/*
+ * APIResponse is one response of an operation.
+ */
+type APIResponse struct {
+	Description string                  `json:"description"`
+	Content     map[string]APIMediaType `json:"content"`
+}
+
+/*
+ * APIMediaType holds the schema of a body.
+ */
+type APIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+/*
+ * Schema is an OpenAPI 3.0 schema object. GoType is the `x-go-type`
+ * extension written for generic instantiations such as
+ * PaginatedResponse[User], and GoTypeParams the `x-go-type-params`
+ * naming the type parameters of the generic.
+ */
+type Schema struct {
+	Ref                  string           `json:"$ref"`
+	Type                 string           `json:"type"`
+	Format               string           `json:"format"`
+	Description          string           `json:"description"`
+	Nullable             bool             `json:"nullable"`
+	Enum                 []interface{}    `json:"enum"`
+	Items                *Schema          `json:"items"`
+	Properties           SchemaProperties `json:"properties"`
+	Required             []string         `json:"required"`
+	AdditionalProperties *Schema          `json:"-"`
+	AllOf                []*Schema        `json:"allOf"`
+	OneOf                []*Schema        `json:"oneOf"`
+	AnyOf                []*Schema        `json:"anyOf"`
+	GoType               string           `json:"x-go-type"`
+	GoTypeParams         []string         `json:"x-go-type-params"`
+}
+
+/*
+ * SchemaProperty is a named property of an object schema.
+ */
+type SchemaProperty struct {
+	Name   string
+	Schema *Schema
+}
+
+/*
+ * SchemaProperties lists object properties in document order.
+ */
+type SchemaProperties []SchemaProperty
+
+/*
+ * UnmarshalJSON decodes a properties object, keeping key order.
+ */
+func (p *SchemaProperties) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var schema Schema
+		if err := dec.Decode(&schema); err != nil {
+			return err
+		}
+		*p = append(*p, SchemaProperty{Name: token.(string), Schema: &schema})
+	}
+	return nil
+}
+
+/*
+ * UnmarshalJSON decodes a schema; additionalProperties may be a boolean
+ * or a schema.
+ */
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	var raw struct {
+		plain
+		AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = Schema(raw.plain)
+
+	switch ap := bytes.TrimSpace(raw.AdditionalProperties); {
+	case len(ap) == 0 || string(ap) == "false":
+	case string(ap) == "true":
+		s.AdditionalProperties = &Schema{}
+	default:
+		s.AdditionalProperties = &Schema{}
+		return json.Unmarshal(ap, s.AdditionalProperties)
+	}
+	return nil
+}
+
+/*
+ * ParseAPISpec parses an OpenAPI document in JSON or YAML.
+ */
+func ParseAPISpec(data []byte) (*APISpec, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := yamlNodeToJSON(&buf, &node); err != nil {
+			return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+		}
+		data = buf.Bytes()
+	}
+
+	var spec APISpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	return &spec, nil
+}
+
+/*
+ * SpecOperation is an operation together with its path and method.
+ */
+type SpecOperation struct {
+	Path   string
+	Method string
+	*APIOperation
+}
+
+/*
+ * Operations returns every operation sorted by path, then in HTTP
+ * method order. Path-level parameters are merged into each operation.
+ */
+func (s *APISpec) Operations() ([]SpecOperation, error) {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var ops []SpecOperation
+	for _, path := range paths {
+		item := s.Paths[path]
+
+		var shared []*APIParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("%s: invalid parameters: %w", path, err)
+			}
+		}
+
+		for _, method := range []string{"get", "post", "put", "patch", "delete", "head", "options"} {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op APIOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			params, err := s.mergeParameters(shared, op.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			op.Parameters = params
+			ops = append(ops, SpecOperation{Path: path, Method: strings.ToUpper(method), APIOperation: &op})
+		}
+	}
+	return ops, nil
+}
+
+/*
+ * RequiresAuth reports whether an operation needs credentials, using
+ * the document-level security requirement when it declares none.
+ */
+func (s *APISpec) RequiresAuth(op *APIOperation) bool {
+	security := s.Security
+	if op.Security != nil {
+		security = *op.Security
+	}
+	for _, requirement := range security {
+		if len(requirement) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * ResolveSchema follows a component reference.
+ */
+func (s *APISpec) ResolveSchema(schema *Schema) *Schema {
+	for depth := 0; schema != nil && schema.Ref != "" && depth < 32; depth++ {
+		schema = s.Components.Schemas[RefName(schema.Ref)]
+	}
+	return schema
+}
+
+/*
+ * genericInstance is a component instantiating a Go generic whose type
+ * arguments are named types: PaginatedResult[*Post] has the argument
+ * Post. Params names the type parameters, T or T1..Tn when the spec
+ * does not say.
+ */
+type genericInstance struct {
+	Generic string
+	Args    []string
+	Params  []string
+}
+
+/*
+ * genericInstances returns the instantiation components of generics that
+ * one generic declaration can replace: every type argument is a named
+ * type, and the instantiations of a generic differ only in them. named
+ * reports whether an argument is a type of the generated code.
+ */
+func (s *APISpec) genericInstances(named func(string) bool) map[string]genericInstance {
+	instances := make(map[string]genericInstance)
+	shapes := make(map[string]string)
+	uniform := make(map[string]bool)
+
+	for name, component := range s.Components.Schemas {
+		generic, args, ok := splitGenericType(component.GoType)
+		if !ok || s.Components.Schemas[generic] != nil {
+			continue
+		}
+
+		inst := genericInstance{Generic: generic, Params: component.GoTypeParams}
+		seen := make(map[string]bool)
+		for _, arg := range args {
+			ident := strings.TrimPrefix(arg, "*")
+			if seen[ident] || strings.IndexFunc(ident, func(r rune) bool { return !isIdentRune(r) }) >= 0 || !named(ident) {
+				inst.Args = nil
+				break
+			}
+			seen[ident] = true
+			inst.Args = append(inst.Args, ident)
+		}
+		if len(inst.Params) != len(args) {
+			inst.Params = make([]string, len(args))
+			for i := range args {
+				inst.Params[i] = "T"
+				if len(args) > 1 {
+					inst.Params[i] = fmt.Sprintf("T%d", i+1)
+				}
+			}
+		}
+
+		shape := ""
+		if len(inst.Args) == len(args) {
+			shape = genericShape(component, inst)
+		}
+		if previous, ok := uniform[generic]; !ok {
+			uniform[generic] = shape != ""
+			shapes[generic] = shape
+		} else {
+			uniform[generic] = previous && shape == shapes[generic]
+		}
+		instances[name] = inst
+	}
+
+	for name, inst := range instances {
+		if !uniform[inst.Generic] {
+			delete(instances, name)
+		}
+	}
+	return instances
+}
+
+/*
+ * genericShape encodes an instantiation with references to its type
+ * arguments replaced by the type parameters, so instantiations of one
+ * generic compare equal when nothing else differs.
+ */
+func genericShape(component *Schema, inst genericInstance) string {
+	plain := *component
+	plain.GoType = ""
+	plain.GoTypeParams = nil
+	data, err := json.Marshal(plain)
+	if err != nil {
+		return ""
+	}
+	shape := string(data)
+	for i, arg := range inst.Args {
+		ref, _ := json.Marshal("#/components/schemas/" + arg)
+		shape = strings.ReplaceAll(shape, `"$ref":`+string(ref), `"$ref":"<`+inst.Params[i]+`>"`)
+	}
+	return shape
+}
+
+/*
+ * RefName returns the component name of a local reference.
+ */
+func RefName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func (s *APISpec) mergeParameters(shared, own []*APIParameter) ([]*APIParameter, error) {
+	var merged []*APIParameter
+	index := make(map[string]int)
+
+	for _, param := range append(append([]*APIParameter(nil), shared...), own...) {
+		if param.Ref != "" {
+			resolved, ok := s.Components.Parameters[RefName(param.Ref)]
+			if !ok {
+				return nil, fmt.Errorf("unresolved parameter %s", param.Ref)
+			}
+			param = resolved
+		}
+		key := param.In + ":" + param.Name
+		if i, ok := index[key]; ok {
+			merged[i] = param
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, param)
+	}
+	return merged, nil
+}
+
+/*
+ * yamlNodeToJSON converts a YAML document to JSON, keeping mapping order.
+ */
+func yamlNodeToJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return yamlNodeToJSON(buf, node.Content[0])
+	case yaml.AliasNode:
+		return yamlNodeToJSON(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := yamlNodeToJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := yamlNodeToJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			buf.WriteString("null")
+		case "!!bool":
+			b, err := strconv.ParseBool(strings.ToLower(node.Value))
+			if err != nil {
+				return err
+			}
+			buf.WriteString(strconv.FormatBool(b))
+		case "!!int", "!!float":
+			if _, err := strconv.ParseFloat(node.Value, 64); err != nil {
+				data, _ := json.Marshal(node.Value)
+				buf.Write(data)
+				return nil
+			}
+			buf.WriteString(node.Value)
+		default:
+			data, _ := json.Marshal(node.Value)
+			buf.Write(data)
+		}
+	}
+	return nil
+}
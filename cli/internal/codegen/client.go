@@ -0,0 +1,690 @@
+/*
+ * GoAstra CLI - Client Targets
+ *
+ * Renders the framework-agnostic API client: a fetch-based ApiClient
+ * with a configurable base URL, token provider, abort signals and typed
+ * errors, one class per endpoint group, and optional React Query hooks
+ * or Vue composables wrapping those classes.
+ */
+package codegen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+/*
+ * renderClient renders the fetch client and the wrappers of the
+ * selected target.
+ */
+func (g *ServiceGenerator) renderClient(endpoints *EndpointSet) error {
+	groups := endpoints.Groups()
+
+	g.out.Add(filepath.Join(g.outputPath, "client.ts"), clientRuntime)
+	for _, group := range groups {
+		g.generateClientClass(endpoints, group)
+	}
+
+	switch g.target {
+	case TargetReactQuery:
+		g.out.Add(filepath.Join(g.outputPath, "react.ts"), reactRuntime)
+		for _, group := range groups {
+			g.generateHooks(endpoints, group, reactHooks)
+		}
+	case TargetVue:
+		g.out.Add(filepath.Join(g.outputPath, "vue.ts"), vueRuntime)
+		for _, group := range groups {
+			g.generateHooks(endpoints, group, vueHooks)
+		}
+	}
+
+	if decls := endpoints.Declarations(); len(decls) > 0 {
+		g.out.Add(filepath.Join(g.outputPath, "api-types.ts"), g.apiTypesFile(endpoints))
+	}
+
+	g.generateClientIndex(endpoints, groups)
+	return nil
+}
+
+/*
+ * clientRuntime is the request layer shared by all generated classes.
+ */
+const clientRuntime = `/*
+ * API client for generated services
+ * Auto-generated by GoAstra typesync
+ */
+
+/*
+ * TokenProvider returns the bearer token sent with each request, or
+ * nothing for anonymous requests.
+ */
+export type TokenProvider = () => string | null | undefined | Promise<string | null | undefined>;
+
+/*
+ * ClientConfig configures an ApiClient. baseUrl includes the API
+ * prefix, for example http://localhost:8080/api/v1. validate checks
+ * responses against the Zod schemas passed by generated methods.
+ */
+export interface ClientConfig {
+  baseUrl: string;
+  token?: TokenProvider;
+  headers?: Record<string, string>;
+  fetch?: typeof fetch;
+  validate?: boolean;
+}
+
+/*
+ * RequestOptions are accepted by every generated method.
+ */
+export interface RequestOptions {
+  signal?: AbortSignal;
+  headers?: Record<string, string>;
+}
+
+/*
+ * ApiRequest describes a single call made by a generated method.
+ */
+export interface ApiRequest extends RequestOptions {
+  method: string;
+  path: string;
+  query?: object;
+  body?: unknown;
+  schema?: { parse(value: unknown): unknown };
+}
+
+/*
+ * FieldError is one entry of a validation error response.
+ */
+export interface FieldError {
+  field: string;
+  message: string;
+  tag: string;
+  value?: string;
+}
+
+/*
+ * ApiErrorBody is the error payload returned by the backend.
+ */
+export interface ApiErrorBody {
+  error?: string;
+  errors?: FieldError[];
+}
+
+/*
+ * ApiError is thrown for responses outside the 2xx range.
+ */
+export class ApiError extends Error {
+  readonly status: number;
+  readonly body: ApiErrorBody | string | undefined;
+  readonly response: Response;
+
+  constructor(response: Response, body: ApiErrorBody | string | undefined) {
+    super(errorMessage(response, body));
+    this.name = 'ApiError';
+    this.status = response.status;
+    this.body = body;
+    this.response = response;
+  }
+
+  /*
+   * fieldErrors returns the validation errors of the response, if any.
+   */
+  get fieldErrors(): FieldError[] {
+    return this.body && typeof this.body === 'object' && this.body.errors ? this.body.errors : [];
+  }
+}
+
+/*
+ * isApiError narrows an unknown error to ApiError.
+ */
+export function isApiError(error: unknown): error is ApiError {
+  return error instanceof ApiError;
+}
+
+/*
+ * ApiClient sends requests for the generated API classes.
+ */
+export class ApiClient {
+  constructor(private readonly config: ClientConfig) {}
+
+  /*
+   * request performs a call and returns the decoded response body.
+   * It throws ApiError for error statuses.
+   */
+  async request<T>(req: ApiRequest): Promise<T> {
+    const headers: Record<string, string> = { Accept: 'application/json', ...this.config.headers, ...req.headers };
+    if (req.body !== undefined) {
+      headers['Content-Type'] = 'application/json';
+    }
+    const token = this.config.token ? await this.config.token() : undefined;
+    if (token) {
+      headers['Authorization'] = 'Bearer ' + token;
+    }
+
+    const send = this.config.fetch ?? fetch;
+    const response = await send(this.url(req.path, req.query), {
+      method: req.method,
+      headers,
+      body: req.body === undefined ? undefined : JSON.stringify(req.body),
+      signal: req.signal,
+    });
+
+    const body = await readBody(response);
+    if (!response.ok) {
+      throw new ApiError(response, body as ApiErrorBody | string | undefined);
+    }
+    if (this.config.validate && req.schema) {
+      req.schema.parse(body);
+    }
+    return body as T;
+  }
+
+  private url(path: string, query?: object): string {
+    const url = this.config.baseUrl.replace(/\/+$/, '') + path;
+    if (!query) {
+      return url;
+    }
+    const search = new URLSearchParams();
+    for (const [key, value] of Object.entries(query)) {
+      if (value === undefined || value === null) {
+        continue;
+      }
+      for (const item of Array.isArray(value) ? value : [value]) {
+        search.append(key, String(item));
+      }
+    }
+    const encoded = search.toString();
+    return encoded ? url + '?' + encoded : url;
+  }
+}
+
+async function readBody(response: Response): Promise<unknown> {
+  if (response.status === 204) {
+    return undefined;
+  }
+  const text = await response.text();
+  if (!text) {
+    return undefined;
+  }
+  if ((response.headers.get('Content-Type') ?? '').includes('json')) {
+    return JSON.parse(text);
+  }
+  return text;
+}
+
+function errorMessage(response: Response, body: ApiErrorBody | string | undefined): string {
+  if (body && typeof body === 'object' && body.error) {
+    return body.error;
+  }
+  if (body && typeof body === 'object' && body.errors?.length) {
+    return body.errors.map((error) => error.message).join(', ');
+  }
+  return response.status + ' ' + response.statusText;
+}
+`
+
+/*
+ * reactRuntime provides the client to React Query hooks.
+ */
+const reactRuntime = `/*
+ * React bindings for the generated API client
+ * Auto-generated by GoAstra typesync
+ */
+import { ReactNode, createContext, createElement, useContext } from 'react';
+import { UseMutationOptions, UseQueryOptions } from '@tanstack/react-query';
+import { ApiClient, ApiError } from './client';
+
+const ApiClientContext = createContext<ApiClient | null>(null);
+
+/*
+ * ApiClientProvider makes a client available to the generated hooks.
+ */
+export function ApiClientProvider(props: { client: ApiClient; children?: ReactNode }) {
+  return createElement(ApiClientContext.Provider, { value: props.client }, props.children);
+}
+
+/*
+ * useApiClient returns the client of the nearest ApiClientProvider.
+ */
+export function useApiClient(): ApiClient {
+  const client = useContext(ApiClientContext);
+  if (!client) {
+    throw new Error('useApiClient must be used inside an ApiClientProvider');
+  }
+  return client;
+}
+
+/*
+ * QueryOptions are the React Query options accepted by query hooks.
+ */
+export type QueryOptions<T> = Omit<UseQueryOptions<T, ApiError>, 'queryKey' | 'queryFn'>;
+
+/*
+ * MutationOptions are the React Query options accepted by mutation hooks.
+ */
+export type MutationOptions<T, V> = Omit<UseMutationOptions<T, ApiError, V>, 'mutationFn'>;
+`
+
+/*
+ * vueRuntime provides the client and request state to Vue composables.
+ */
+const vueRuntime = `/*
+ * Vue bindings for the generated API client
+ * Auto-generated by GoAstra typesync
+ */
+import { InjectionKey, Ref, inject, provide, ref, shallowRef, watchEffect } from 'vue';
+import { ApiClient, ApiError } from './client';
+
+/*
+ * ApiClientKey injects the client; use app.provide(ApiClientKey, client)
+ * or provideApiClient in a root component.
+ */
+export const ApiClientKey: InjectionKey<ApiClient> = Symbol('ApiClient');
+
+/*
+ * provideApiClient makes a client available to descendant components.
+ */
+export function provideApiClient(client: ApiClient): void {
+  provide(ApiClientKey, client);
+}
+
+/*
+ * useApiClient returns the provided client.
+ */
+export function useApiClient(): ApiClient {
+  const client = inject(ApiClientKey, null);
+  if (!client) {
+    throw new Error('useApiClient requires an ApiClient provided with ApiClientKey');
+  }
+  return client;
+}
+
+/*
+ * ApiQuery is the reactive state of a query composable.
+ */
+export interface ApiQuery<T> {
+  data: Ref<T | undefined>;
+  error: Ref<ApiError | Error | undefined>;
+  loading: Ref<boolean>;
+  refresh: () => void;
+}
+
+/*
+ * useApiQuery runs fetcher immediately and again whenever the reactive
+ * values it reads change, aborting the previous request.
+ */
+export function useApiQuery<T>(fetcher: (signal: AbortSignal) => Promise<T>): ApiQuery<T> {
+  const data = shallowRef<T>();
+  const error = shallowRef<ApiError | Error>();
+  const loading = ref(false);
+  const version = ref(0);
+
+  watchEffect((onCleanup) => {
+    void version.value;
+    const controller = new AbortController();
+    onCleanup(() => controller.abort());
+
+    loading.value = true;
+    error.value = undefined;
+    fetcher(controller.signal).then(
+      (value) => {
+        if (!controller.signal.aborted) {
+          data.value = value;
+          loading.value = false;
+        }
+      },
+      (err: Error) => {
+        if (!controller.signal.aborted) {
+          error.value = err;
+          loading.value = false;
+        }
+      },
+    );
+  });
+
+  return { data, error, loading, refresh: () => version.value++ };
+}
+
+/*
+ * ApiMutation is the reactive state of a mutation composable.
+ */
+export interface ApiMutation<V, T> {
+  data: Ref<T | undefined>;
+  error: Ref<ApiError | Error | undefined>;
+  loading: Ref<boolean>;
+  execute: (variables: V) => Promise<T>;
+}
+
+/*
+ * useApiMutation wraps a call that runs when execute is invoked.
+ */
+export function useApiMutation<V, T>(mutate: (variables: V) => Promise<T>): ApiMutation<V, T> {
+  const data = shallowRef<T>();
+  const error = shallowRef<ApiError | Error>();
+  const loading = ref(false);
+
+  async function execute(variables: V): Promise<T> {
+    loading.value = true;
+    error.value = undefined;
+    try {
+      const value = await mutate(variables);
+      data.value = value;
+      return value;
+    } catch (err) {
+      error.value = err as Error;
+      throw err;
+    } finally {
+      loading.value = false;
+    }
+  }
+
+  return { data, error, loading, execute };
+}
+`
+
+/*
+ * clientName returns the class, file and property names for a group:
+ * "users" becomes UsersApi in users-api.ts, reachable as api.users.
+ */
+func clientName(group string) (string, string, string) {
+	service, _ := serviceName(group)
+	base := strings.TrimSuffix(service, "ApiService")
+	return base + "Api", toKebabCase(base) + "-api", toCamelCaseTS(base)
+}
+
+/*
+ * clientImports writes the model, schema and declared type imports of
+ * a group file.
+ */
+func (g *ServiceGenerator) clientImports(sb *strings.Builder, endpoints *EndpointSet, methods, validated []Endpoint) {
+	tsTypes := endpointTypes(methods)
+
+	if UsesZod(validated) {
+		sb.WriteString("import { z } from 'zod';\n")
+	}
+	models := endpoints.ModelRefs(tsTypes...)
+	models = append(models, SchemaRefs(validated)...)
+	if len(models) > 0 {
+		sb.WriteString(fmt.Sprintf("import { %s } from '%s';\n", strings.Join(models, ", "), g.modelsImport))
+	}
+	if declared := endpoints.DeclaredRefs(tsTypes...); len(declared) > 0 {
+		sb.WriteString(fmt.Sprintf("import { %s } from './api-types';\n", strings.Join(declared, ", ")))
+	}
+}
+
+func (g *ServiceGenerator) generateClientClass(endpoints *EndpointSet, group string) {
+	className, fileName, _ := clientName(group)
+	methods := endpoints.InGroup(group)
+	validated := g.validated(methods)
+
+	var sb strings.Builder
+	sb.WriteString("/*\n")
+	sb.WriteString(fmt.Sprintf(" * %s\n", className))
+	sb.WriteString(" * Auto-generated by GoAstra typesync\n")
+	sb.WriteString(" */\n")
+	g.clientImports(&sb, endpoints, methods, validated)
+	sb.WriteString("import { ApiClient, RequestOptions } from './client';\n\n")
+
+	sb.WriteString(fmt.Sprintf("export class %s {\n", className))
+	sb.WriteString("  constructor(private readonly client: ApiClient) {}\n")
+
+	for _, endpoint := range methods {
+		sb.WriteString("\n")
+		writeMethodDoc(&sb, endpoint, "  ")
+
+		args := append(methodParams(endpoint), "options?: RequestOptions")
+		sb.WriteString(fmt.Sprintf("  %s(%s): Promise<%s> {\n", endpoint.Name, strings.Join(args, ", "), endpoint.Response))
+
+		fields := []string{
+			fmt.Sprintf("method: '%s'", endpoint.Method),
+			"path: " + pathExpression(endpoint),
+		}
+		if len(endpoint.QueryParams) > 0 {
+			fields = append(fields, "query: params")
+		}
+		if hasBody(endpoint) {
+			fields = append(fields, "body")
+		} else if endpoint.Method == "POST" || endpoint.Method == "PUT" || endpoint.Method == "PATCH" {
+			fields = append(fields, "body: {}")
+		}
+		if g.schemas && endpoint.Schema != "" {
+			fields = append(fields, "schema: "+endpoint.Schema)
+		}
+		fields = append(fields, "...options")
+
+		sb.WriteString(fmt.Sprintf("    return this.client.request<%s>({ %s });\n", endpoint.Response, strings.Join(fields, ", ")))
+		sb.WriteString("  }\n")
+	}
+
+	sb.WriteString("}\n")
+
+	g.out.Add(filepath.Join(g.outputPath, fileName+".ts"), sb.String())
+}
+
+/*
+ * hookStyle renders the framework wrappers of a group.
+ */
+type hookStyle struct {
+	suffix  string
+	imports func(queries, mutations bool) []string
+	query   func(sb *strings.Builder, endpoint Endpoint, className, group string)
+	mutate  func(sb *strings.Builder, endpoint Endpoint, className, variables string)
+}
+
+var reactHooks = hookStyle{
+	suffix: "queries",
+	imports: func(queries, mutations bool) []string {
+		var names, local []string
+		if mutations {
+			names = append(names, "useMutation")
+			local = append(local, "MutationOptions")
+		}
+		if queries {
+			names = append(names, "useQuery")
+			local = append(local, "QueryOptions")
+		}
+		local = append(local, "useApiClient")
+		return []string{
+			fmt.Sprintf("import { %s } from '@tanstack/react-query';", strings.Join(names, ", ")),
+			fmt.Sprintf("import { %s } from './react';", strings.Join(local, ", ")),
+		}
+	},
+	query: func(sb *strings.Builder, endpoint Endpoint, className, group string) {
+		params := append(methodParams(endpoint), fmt.Sprintf("options?: QueryOptions<%s>", endpoint.Response))
+		args := methodArgs(endpoint)
+		key := append([]string{tsLiteral("string", group), tsLiteral("string", endpoint.Name)}, args...)
+
+		sb.WriteString(fmt.Sprintf("export function %s(%s) {\n", hookName(endpoint), strings.Join(params, ", ")))
+		sb.WriteString("  const client = useApiClient();\n")
+		sb.WriteString("  return useQuery({\n")
+		sb.WriteString(fmt.Sprintf("    queryKey: [%s],\n", strings.Join(key, ", ")))
+		sb.WriteString(fmt.Sprintf("    queryFn: ({ signal }) => new %s(client).%s(%s),\n", className, endpoint.Name, strings.Join(append(args, "{ signal }"), ", ")))
+		sb.WriteString("    ...options,\n")
+		sb.WriteString("  });\n")
+		sb.WriteString("}\n")
+	},
+	mutate: func(sb *strings.Builder, endpoint Endpoint, className, variables string) {
+		sb.WriteString(fmt.Sprintf("export function %s(options?: MutationOptions<%s, %s>) {\n", hookName(endpoint), endpoint.Response, variables))
+		sb.WriteString("  const client = useApiClient();\n")
+		sb.WriteString("  return useMutation({\n")
+		sb.WriteString(fmt.Sprintf("    mutationFn: %s,\n", mutationFn(endpoint, className, "client", variables)))
+		sb.WriteString("    ...options,\n")
+		sb.WriteString("  });\n")
+		sb.WriteString("}\n")
+	},
+}
+
+var vueHooks = hookStyle{
+	suffix: "composables",
+	imports: func(queries, mutations bool) []string {
+		var lines, local []string
+		if queries {
+			lines = append(lines, "import { MaybeRefOrGetter, toValue } from 'vue';")
+			local = append(local, "ApiQuery", "useApiQuery")
+		}
+		if mutations {
+			local = append(local, "useApiMutation")
+		}
+		local = append(local, "useApiClient")
+		return append(lines, fmt.Sprintf("import { %s } from './vue';", strings.Join(local, ", ")))
+	},
+	query: func(sb *strings.Builder, endpoint Endpoint, className, group string) {
+		var params, args []string
+		for _, param := range methodParams(endpoint) {
+			name, tsType, _ := strings.Cut(param, ": ")
+			if strings.HasSuffix(name, "?") {
+				tsType += " | undefined"
+			}
+			params = append(params, fmt.Sprintf("%s: MaybeRefOrGetter<%s>", name, tsType))
+		}
+		for _, arg := range methodArgs(endpoint) {
+			args = append(args, fmt.Sprintf("toValue(%s)", arg))
+		}
+
+		sb.WriteString(fmt.Sprintf("export function %s(%s): ApiQuery<%s> {\n", hookName(endpoint), strings.Join(params, ", "), endpoint.Response))
+		sb.WriteString(fmt.Sprintf("  const api = new %s(useApiClient());\n", className))
+		sb.WriteString(fmt.Sprintf("  return useApiQuery((signal) => api.%s(%s));\n", endpoint.Name, strings.Join(append(args, "{ signal }"), ", ")))
+		sb.WriteString("}\n")
+	},
+	mutate: func(sb *strings.Builder, endpoint Endpoint, className, variables string) {
+		sb.WriteString(fmt.Sprintf("export function %s() {\n", hookName(endpoint)))
+		sb.WriteString("  const client = useApiClient();\n")
+		sb.WriteString(fmt.Sprintf("  return useApiMutation<%s, %s>(%s);\n", variables, endpoint.Response, mutationFn(endpoint, className, "client", variables)))
+		sb.WriteString("}\n")
+	},
+}
+
+/*
+ * hookName prefixes the method name with "use": listUser becomes
+ * useListUser.
+ */
+func hookName(endpoint Endpoint) string {
+	return "use" + strings.ToUpper(endpoint.Name[:1]) + endpoint.Name[1:]
+}
+
+/*
+ * mutationVariables returns the variables type of a mutation: an
+ * object holding the method parameters, or void when it has none.
+ */
+func mutationVariables(endpoint Endpoint) string {
+	params := methodParams(endpoint)
+	if len(params) == 0 {
+		return "void"
+	}
+	return "{ " + strings.Join(params, "; ") + " }"
+}
+
+/*
+ * mutationFn renders the function a mutation calls with its variables.
+ */
+func mutationFn(endpoint Endpoint, className, client, variables string) string {
+	args := methodArgs(endpoint)
+	if len(args) == 0 {
+		return fmt.Sprintf("() => new %s(%s).%s()", className, client, endpoint.Name)
+	}
+	return fmt.Sprintf("({ %s }: %s) => new %s(%s).%s(%s)",
+		strings.Join(args, ", "), variables, className, client, endpoint.Name, strings.Join(args, ", "))
+}
+
+/*
+ * generateHooks renders queries for GET endpoints and mutations for
+ * the others.
+ */
+func (g *ServiceGenerator) generateHooks(endpoints *EndpointSet, group string, style hookStyle) {
+	className, fileName, _ := clientName(group)
+	methods := endpoints.InGroup(group)
+
+	queries, mutations := false, false
+	for _, endpoint := range methods {
+		if endpoint.Method == "GET" {
+			queries = true
+		} else {
+			mutations = true
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("/*\n")
+	sb.WriteString(fmt.Sprintf(" * %s %s\n", className, style.suffix))
+	sb.WriteString(" * Auto-generated by GoAstra typesync\n")
+	sb.WriteString(" */\n")
+	for _, line := range style.imports(queries, mutations) {
+		sb.WriteString(line + "\n")
+	}
+	g.clientImports(&sb, endpoints, methods, nil)
+	sb.WriteString(fmt.Sprintf("import { %s } from './%s';\n", className, fileName))
+
+	for _, endpoint := range methods {
+		sb.WriteString("\n")
+		writeMethodDoc(&sb, endpoint, "")
+		if endpoint.Method == "GET" {
+			style.query(&sb, endpoint, className, group)
+		} else {
+			style.mutate(&sb, endpoint, className, mutationVariables(endpoint))
+		}
+	}
+
+	base := strings.TrimSuffix(fileName, "-api")
+	g.out.Add(filepath.Join(g.outputPath, base+"."+style.suffix+".ts"), sb.String())
+}
+
+/*
+ * generateClientIndex exports every file and a createApi factory that
+ * builds all group classes around one client.
+ */
+func (g *ServiceGenerator) generateClientIndex(endpoints *EndpointSet, groups []string) {
+	var sb strings.Builder
+
+	sb.WriteString("/*\n")
+	sb.WriteString(" * GoAstra Generated API Client - Barrel Export\n")
+	sb.WriteString(" * Auto-generated by GoAstra typesync\n")
+	sb.WriteString(" */\n")
+	sb.WriteString("import { ApiClient, ClientConfig } from './client';\n")
+	for _, group := range groups {
+		className, fileName, _ := clientName(group)
+		sb.WriteString(fmt.Sprintf("import { %s } from './%s';\n", className, fileName))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("export * from './client';\n")
+	if len(endpoints.Declarations()) > 0 {
+		sb.WriteString("export * from './api-types';\n")
+	}
+	for _, group := range groups {
+		_, fileName, _ := clientName(group)
+		sb.WriteString(fmt.Sprintf("export * from './%s';\n", fileName))
+	}
+	switch g.target {
+	case TargetReactQuery:
+		sb.WriteString("export * from './react';\n")
+	case TargetVue:
+		sb.WriteString("export * from './vue';\n")
+	}
+	for _, group := range groups {
+		_, fileName, _ := clientName(group)
+		base := strings.TrimSuffix(fileName, "-api")
+		switch g.target {
+		case TargetReactQuery:
+			sb.WriteString(fmt.Sprintf("export * from './%s.queries';\n", base))
+		case TargetVue:
+			sb.WriteString(fmt.Sprintf("export * from './%s.composables';\n", base))
+		}
+	}
+
+	sb.WriteString("\n/*\n")
+	sb.WriteString(" * createApi returns every API group bound to one client.\n")
+	sb.WriteString(" */\n")
+	sb.WriteString("export function createApi(config: ClientConfig | ApiClient) {\n")
+	sb.WriteString("  const client = config instanceof ApiClient ? config : new ApiClient(config);\n")
+	sb.WriteString("  return {\n")
+	sb.WriteString("    client,\n")
+	for _, group := range groups {
+		className, _, property := clientName(group)
+		sb.WriteString(fmt.Sprintf("    %s: new %s(client),\n", property, className))
+	}
+	sb.WriteString("  };\n")
+	sb.WriteString("}\n")
+
+	g.out.Add(filepath.Join(g.outputPath, "index.ts"), sb.String())
+}
@@ -0,0 +1,148 @@
+/*
+ * GoAstra CLI - Ordered Documents
+ *
+ * An insertion-ordered object model for generated JSON and YAML
+ * documents, so keys appear in a stable, readable order instead of the
+ * alphabetical order of encoding/json maps.
+ */
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+ * orderedMap is a JSON object that keeps keys in insertion order.
+ * Values are *orderedMap, []interface{}, string, bool, int or json.Number.
+ */
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: make(map[string]interface{})}
+}
+
+/*
+ * Set adds or replaces a key, keeping its original position.
+ */
+func (m *orderedMap) Set(key string, value interface{}) *orderedMap {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+	return m
+}
+
+func (m *orderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+func (m *orderedMap) Len() int {
+	return len(m.keys)
+}
+
+/*
+ * MarshalJSON encodes the object with keys in insertion order.
+ */
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+/*
+ * encodeJSON renders a document as indented JSON.
+ */
+func encodeJSON(doc *orderedMap) ([]byte, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+/*
+ * encodeYAML renders a document as block-style YAML through yaml.v3
+ * nodes, which keep the insertion order of every object.
+ */
+func encodeYAML(doc *orderedMap) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlNode(doc)); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+ * yamlNode converts a document value to a YAML node. Strings are tagged
+ * !!str so the encoder quotes any that would read back as another type.
+ */
+func yamlNode(value interface{}) *yaml.Node {
+	switch v := value.(type) {
+	case *orderedMap:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range v.keys {
+			node.Content = append(node.Content, yamlScalar("!!str", key), yamlNode(v.values[key]))
+		}
+		return node
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+		return node
+	case string:
+		return yamlScalar("!!str", v)
+	case bool:
+		return yamlScalar("!!bool", strconv.FormatBool(v))
+	case int:
+		return yamlScalar("!!int", strconv.Itoa(v))
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return yamlScalar("!!int", v.String())
+		}
+		return yamlScalar("!!float", v.String())
+	case nil:
+		return yamlScalar("!!null", "null")
+	}
+	data, _ := json.Marshal(value)
+	return yamlScalar("!!str", string(data))
+}
+
+func yamlScalar(tag, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
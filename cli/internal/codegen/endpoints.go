@@ -0,0 +1,696 @@
+/*
+ * GoAstra CLI - API Endpoints
+ *
+ * Turns the operations of an API specification into endpoints with
+ * TypeScript parameter, body and response types for client generators.
+ * Schemas that match generated models reference them; other component
+ * schemas are declared alongside the clients.
+ */
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+ * Endpoint is an operation ready for client generation. Path is
+ * relative to the API base URL and keeps {param} placeholders. Body and
+ * Response are TypeScript types; BodySchema and ResponseSchema keep the
+ * source schemas for generators of other languages.
+ */
+type Endpoint struct {
+	Name         string
+	Group        string
+	Method       string
+	Path         string
+	Summary      string
+	Description  string
+	Deprecated   bool
+	Auth         bool
+	PathParams   []EndpointParam
+	QueryParams  []EndpointParam
+	Body         string
+	BodyRequired bool
+	Response     string
+	Schema       string
+
+	BodySchema     *Schema
+	ResponseSchema *Schema
+}
+
+/*
+ * EndpointParam is a typed path or query parameter. Name is the
+ * parameter in the URL and Ident the TypeScript identifier.
+ */
+type EndpointParam struct {
+	Name        string
+	Ident       string
+	Type        string
+	Required    bool
+	Description string
+	Schema      *Schema
+}
+
+/*
+ * EndpointSet holds the endpoints of a specification with the model
+ * names they import and the component types declared for them.
+ */
+type EndpointSet struct {
+	Endpoints []Endpoint
+	spec      *APISpec
+	mappings  TypeMappings
+	known     map[string]bool
+	declared  map[string]string
+	uses      []string
+	order     []string
+	generics  map[string]genericInstance
+	params    map[string]string
+}
+
+/*
+ * NewEndpointSet converts the operations under prefix into endpoints.
+ * Paths outside prefix are skipped when the spec has any path under it;
+ * otherwise paths are taken as relative to the API base URL. known holds
+ * the generated model names.
+ */
+func NewEndpointSet(spec *APISpec, prefix string, known map[string]bool, mappings TypeMappings) (*EndpointSet, error) {
+	ops, err := spec.Operations()
+	if err != nil {
+		return nil, err
+	}
+
+	set := &EndpointSet{
+		spec:     spec,
+		mappings: mappings,
+		known:    known,
+		declared: make(map[string]string),
+	}
+	set.generics = spec.genericInstances(func(name string) bool {
+		return known[name] || spec.Components.Schemas[name] != nil
+	})
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	prefixed := false
+	for _, op := range ops {
+		if prefix != "" && (op.Path == prefix || strings.HasPrefix(op.Path, prefix+"/")) {
+			prefixed = true
+		}
+	}
+
+	used := make(map[string]map[string]bool)
+	for _, op := range ops {
+		path := op.Path
+		if prefixed {
+			if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+				continue
+			}
+			path = strings.TrimPrefix(path, prefix)
+			if path == "" {
+				path = "/"
+			}
+		}
+
+		endpoint := set.endpoint(op, path)
+		if used[endpoint.Group] == nil {
+			used[endpoint.Group] = make(map[string]bool)
+		}
+		name := endpoint.Name
+		for i := 2; used[endpoint.Group][name]; i++ {
+			name = fmt.Sprintf("%s%d", endpoint.Name, i)
+		}
+		used[endpoint.Group][name] = true
+		endpoint.Name = name
+
+		set.Endpoints = append(set.Endpoints, endpoint)
+	}
+
+	return set, nil
+}
+
+/*
+ * Spec returns the specification the endpoints were read from.
+ */
+func (s *EndpointSet) Spec() *APISpec {
+	return s.spec
+}
+
+/*
+ * Groups returns the endpoint groups in sorted order.
+ */
+func (s *EndpointSet) Groups() []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, endpoint := range s.Endpoints {
+		if !seen[endpoint.Group] {
+			seen[endpoint.Group] = true
+			groups = append(groups, endpoint.Group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+/*
+ * InGroup returns the endpoints of a group in specification order.
+ */
+func (s *EndpointSet) InGroup(group string) []Endpoint {
+	var endpoints []Endpoint
+	for _, endpoint := range s.Endpoints {
+		if endpoint.Group == group {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+/*
+ * Declarations returns the TypeScript declarations of component types
+ * that are not generated models, in first-use order.
+ */
+func (s *EndpointSet) Declarations() []string {
+	decls := make([]string, 0, len(s.order))
+	for _, name := range s.order {
+		decls = append(decls, s.declared[name])
+	}
+	return decls
+}
+
+/*
+ * DeclarationTypes returns the TypeScript types the declarations use.
+ */
+func (s *EndpointSet) DeclarationTypes() []string {
+	return append([]string(nil), s.uses...)
+}
+
+/*
+ * IsDeclared reports whether name is a declared component type rather
+ * than a generated model.
+ */
+func (s *EndpointSet) IsDeclared(name string) bool {
+	_, ok := s.declared[name]
+	return ok
+}
+
+/*
+ * ModelRefs returns the generated models referenced by TypeScript types.
+ */
+func (s *EndpointSet) ModelRefs(tsTypes ...string) []string {
+	return s.refs(tsTypes, func(ident string) bool { return s.known[ident] })
+}
+
+/*
+ * DeclaredRefs returns the declared component types referenced by
+ * TypeScript types.
+ */
+func (s *EndpointSet) DeclaredRefs(tsTypes ...string) []string {
+	return s.refs(tsTypes, s.IsDeclared)
+}
+
+func (s *EndpointSet) refs(tsTypes []string, match func(string) bool) []string {
+	seen := make(map[string]bool)
+	var refs []string
+	for _, tsType := range tsTypes {
+		for _, ident := range strings.FieldsFunc(tsType, func(r rune) bool { return !isIdentRune(r) }) {
+			if match(ident) && !seen[ident] {
+				seen[ident] = true
+				refs = append(refs, ident)
+			}
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+func (s *EndpointSet) endpoint(op SpecOperation, path string) Endpoint {
+	endpoint := Endpoint{
+		Method:      op.Method,
+		Path:        path,
+		Summary:     strings.TrimSpace(op.Summary),
+		Description: strings.TrimSpace(op.Description),
+		Deprecated:  op.Deprecated,
+		Auth:        s.spec.RequiresAuth(op.APIOperation),
+		Response:    "void",
+	}
+
+	endpoint.Group = groupName(op, path)
+	endpoint.Name = methodName(op, path)
+
+	for _, param := range op.Parameters {
+		p := EndpointParam{
+			Name:        param.Name,
+			Ident:       paramIdent(param.Name),
+			Type:        s.tsType(param.Schema),
+			Required:    param.Required || param.In == "path",
+			Description: strings.TrimSpace(param.Description),
+			Schema:      param.Schema,
+		}
+		switch param.In {
+		case "path":
+			endpoint.PathParams = append(endpoint.PathParams, p)
+		case "query":
+			endpoint.QueryParams = append(endpoint.QueryParams, p)
+		}
+	}
+
+	if body := op.RequestBody; body != nil {
+		if media, ok := jsonMedia(body.Content); ok {
+			endpoint.Body = s.tsType(media.Schema)
+			endpoint.BodyRequired = body.Required
+			endpoint.BodySchema = media.Schema
+		}
+	}
+
+	if response := successResponse(op.Responses); response != nil {
+		if media, ok := jsonMedia(response.Content); ok {
+			endpoint.Response = s.tsType(media.Schema)
+			endpoint.Schema = s.zodSchema(media.Schema)
+			endpoint.ResponseSchema = media.Schema
+		}
+	}
+
+	return endpoint
+}
+
+/*
+ * tsType converts a schema to a TypeScript type, declaring component
+ * types that are not generated models.
+ */
+func (s *EndpointSet) tsType(schema *Schema) string {
+	if schema == nil {
+		return "unknown"
+	}
+
+	tsType := s.baseTSType(schema)
+	if schema.Nullable && tsType != "unknown" && !strings.HasSuffix(tsType, " | null") {
+		tsType += " | null"
+	}
+	return tsType
+}
+
+func (s *EndpointSet) baseTSType(schema *Schema) string {
+	if schema.Ref != "" {
+		return s.refTSType(RefName(schema.Ref))
+	}
+
+	switch {
+	case len(schema.AllOf) == 1:
+		return s.tsType(schema.AllOf[0])
+	case len(schema.AllOf) > 1:
+		return s.joinTypes(schema.AllOf, " & ")
+	case len(schema.OneOf) > 0:
+		return s.joinTypes(schema.OneOf, " | ")
+	case len(schema.AnyOf) > 0:
+		return s.joinTypes(schema.AnyOf, " | ")
+	case len(schema.Enum) > 0:
+		literals := make([]string, len(schema.Enum))
+		for i, value := range schema.Enum {
+			if str, ok := value.(string); ok {
+				literals[i] = tsLiteral("string", str)
+			} else {
+				literals[i] = fmt.Sprint(value)
+			}
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		elem := s.tsType(schema.Items)
+		if strings.Contains(elem, " | ") || strings.Contains(elem, " & ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case "object", "":
+		if len(schema.Properties) > 0 {
+			return s.objectLiteral(schema)
+		}
+		if schema.AdditionalProperties != nil {
+			return fmt.Sprintf("Record<string, %s>", s.tsType(schema.AdditionalProperties))
+		}
+		if schema.Type == "object" {
+			return "Record<string, unknown>"
+		}
+	}
+	return "unknown"
+}
+
+func (s *EndpointSet) joinTypes(schemas []*Schema, sep string) string {
+	parts := make([]string, len(schemas))
+	for i, schema := range schemas {
+		parts[i] = s.tsType(schema)
+	}
+	return strings.Join(parts, sep)
+}
+
+func (s *EndpointSet) objectLiteral(schema *Schema) string {
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	members := make([]string, len(schema.Properties))
+	for i, prop := range schema.Properties {
+		optional := "?"
+		if required[prop.Name] {
+			optional = ""
+		}
+		members[i] = fmt.Sprintf("%s%s: %s", propertyKey(prop.Name), optional, s.tsType(prop.Schema))
+	}
+	return "{ " + strings.Join(members, "; ") + " }"
+}
+
+/*
+ * refTSType resolves a component reference. Generic instantiations map
+ * back to their generated generic model or to one declared generic type;
+ * models are referenced by name; other components are declared.
+ */
+func (s *EndpointSet) refTSType(name string) string {
+	if param, ok := s.params[name]; ok {
+		return param
+	}
+	component := s.spec.Components.Schemas[name]
+
+	if component != nil && component.GoType != "" {
+		generic, args, ok := splitGenericType(component.GoType)
+		if ok && s.known[generic] {
+			for _, arg := range args {
+				for _, ident := range typeIdents(arg) {
+					if !s.known[ident] && s.spec.Components.Schemas[ident] != nil {
+						s.declare(ident)
+					}
+				}
+			}
+			return s.mappings.tsType(component.GoType)
+		}
+	}
+
+	if tsType, ok := s.declareGeneric(name); ok {
+		return tsType
+	}
+
+	if s.known[name] {
+		return name
+	}
+	if component == nil {
+		return "unknown"
+	}
+	s.declare(name)
+	return name
+}
+
+/*
+ * declare renders a component that has no generated model.
+ */
+func (s *EndpointSet) declare(name string) {
+	if _, ok := s.declared[name]; ok {
+		return
+	}
+	s.declared[name] = ""
+	s.order = append(s.order, name)
+
+	params := s.params
+	s.params = nil
+	s.declared[name] = s.render(name, s.spec.Components.Schemas[name])
+	s.params = params
+}
+
+/*
+ * declareGeneric declares one generic type for the instantiations of a
+ * Go generic that is not a generated model, so PaginatedResult[*Post]
+ * is referenced as PaginatedResult<Post> and PaginatedResult<T> is
+ * declared once. It reports false for instantiations that need a
+ * declaration of their own.
+ */
+func (s *EndpointSet) declareGeneric(name string) (string, bool) {
+	inst, ok := s.generics[name]
+	if !ok || s.known[inst.Generic] {
+		return "", false
+	}
+
+	params := make(map[string]string, len(inst.Args))
+	tsArgs := make([]string, len(inst.Args))
+	for i, arg := range inst.Args {
+		tsArgs[i] = s.refTSType(arg)
+		params[arg] = inst.Params[i]
+	}
+
+	if _, ok := s.declared[inst.Generic]; !ok {
+		s.declared[inst.Generic] = ""
+		s.order = append(s.order, inst.Generic)
+
+		outer := s.params
+		s.params = params
+		header := fmt.Sprintf("%s<%s>", inst.Generic, strings.Join(inst.Params, ", "))
+		s.declared[inst.Generic] = s.render(header, s.spec.Components.Schemas[name])
+		s.params = outer
+	}
+	return fmt.Sprintf("%s<%s>", inst.Generic, strings.Join(tsArgs, ", ")), true
+}
+
+/*
+ * render writes the declaration of a component under name.
+ */
+func (s *EndpointSet) render(name string, component *Schema) string {
+	var sb strings.Builder
+	parseGoDoc(component.Description, s.link).write(&sb, "")
+
+	if len(component.Properties) == 0 || component.Type != "object" && component.Type != "" {
+		tsType := s.tsType(component)
+		s.uses = append(s.uses, tsType)
+		sb.WriteString(fmt.Sprintf("export type %s = %s;\n", name, tsType))
+		return sb.String()
+	}
+
+	required := make(map[string]bool)
+	for _, prop := range component.Required {
+		required[prop] = true
+	}
+	sb.WriteString(fmt.Sprintf("export interface %s {\n", name))
+	for _, prop := range component.Properties {
+		optional := "?"
+		if required[prop.Name] {
+			optional = ""
+		}
+		doc := parseGoDoc(prop.Schema.Description, s.link)
+		if prop.Schema.Format != "" {
+			doc.tags = append(doc.tags, "@format "+prop.Schema.Format)
+		}
+		doc.write(&sb, "  ")
+		tsType := s.tsType(prop.Schema)
+		s.uses = append(s.uses, tsType)
+		sb.WriteString(fmt.Sprintf("  %s%s: %s;\n", propertyKey(prop.Name), optional, tsType))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+/*
+ * link resolves a doc link to a generated model or declared component.
+ */
+func (s *EndpointSet) link(name string) (string, bool) {
+	return name, s.known[name] || s.spec.Components.Schemas[name] != nil
+}
+
+/*
+ * zodSchema returns the generated Zod schema expression validating a
+ * response, or "" when it is not built from generated models.
+ */
+func (s *EndpointSet) zodSchema(schema *Schema) string {
+	if schema == nil {
+		return ""
+	}
+
+	var expr string
+	switch {
+	case schema.Ref != "":
+		name := RefName(schema.Ref)
+		component := s.spec.Components.Schemas[name]
+		if component != nil && component.GoType != "" {
+			expr = s.zodGoType(component.GoType)
+		} else if s.known[name] {
+			expr = name + "Schema"
+		}
+	case len(schema.AllOf) == 1:
+		expr = s.zodSchema(schema.AllOf[0])
+	case schema.Type == "array":
+		if items := s.zodSchema(schema.Items); items != "" {
+			expr = "z.array(" + items + ")"
+		}
+	}
+
+	if expr != "" && schema.Nullable {
+		expr += ".nullable()"
+	}
+	return expr
+}
+
+/*
+ * zodGoType builds the schema for a generic instantiation whose type
+ * arguments are generated models: Page[User] becomes PageSchema(UserSchema).
+ */
+func (s *EndpointSet) zodGoType(goType string) string {
+	if strings.HasPrefix(goType, "[]") {
+		if elem := s.zodGoType(goType[2:]); elem != "" {
+			return "z.array(" + elem + ")"
+		}
+		return ""
+	}
+	if name, args, ok := splitGenericType(goType); ok {
+		if !s.known[name] {
+			return ""
+		}
+		schemas := make([]string, len(args))
+		for i, arg := range args {
+			if schemas[i] = s.zodGoType(arg); schemas[i] == "" {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%sSchema(%s)", name, strings.Join(schemas, ", "))
+	}
+	if s.known[goType] {
+		return goType + "Schema"
+	}
+	return ""
+}
+
+/*
+ * UsesZod reports whether any endpoint schema needs the z namespace.
+ */
+func UsesZod(endpoints []Endpoint) bool {
+	for _, endpoint := range endpoints {
+		if strings.Contains(endpoint.Schema, "z.") {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * SchemaRefs lists the generated Zod schemas used by endpoints.
+ */
+func SchemaRefs(endpoints []Endpoint) []string {
+	seen := make(map[string]bool)
+	var refs []string
+	for _, endpoint := range endpoints {
+		for _, ident := range strings.FieldsFunc(endpoint.Schema, func(r rune) bool { return !isIdentRune(r) }) {
+			if strings.HasSuffix(ident, "Schema") && !seen[ident] {
+				seen[ident] = true
+				refs = append(refs, ident)
+			}
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+/*
+ * successResponse picks the lowest 2xx response, falling back to default.
+ */
+func successResponse(responses map[string]*APIResponse) *APIResponse {
+	var codes []string
+	for code := range responses {
+		if len(code) == 3 && code[0] == '2' {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	if len(codes) > 0 {
+		return responses[codes[0]]
+	}
+	return responses["default"]
+}
+
+func jsonMedia(content map[string]APIMediaType) (APIMediaType, bool) {
+	if media, ok := content["application/json"]; ok {
+		return media, true
+	}
+	for mediaType, media := range content {
+		if strings.HasSuffix(mediaType, "+json") {
+			return media, true
+		}
+	}
+	return APIMediaType{}, false
+}
+
+/*
+ * groupName groups an operation by its first tag or path segment.
+ */
+func groupName(op SpecOperation, path string) string {
+	if len(op.Tags) > 0 && op.Tags[0] != "" {
+		return op.Tags[0]
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" && !strings.HasPrefix(segment, "{") {
+			return segment
+		}
+	}
+	return "root"
+}
+
+/*
+ * methodName derives a client method name from the operationId, or from
+ * the method and path when there is none.
+ */
+func methodName(op SpecOperation, path string) string {
+	source := op.OperationID
+	if source == "" {
+		source = strings.ToLower(op.Method) + " " + path
+	}
+
+	words := strings.FieldsFunc(source, func(r rune) bool { return !isIdentRune(r) || r == '_' })
+	var sb strings.Builder
+	for i, word := range words {
+		if i == 0 {
+			sb.WriteString(strings.ToLower(word[:1]) + word[1:])
+		} else {
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+
+	name := sb.String()
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "call" + exportName(name)
+	}
+	return name
+}
+
+/*
+ * paramIdent converts a parameter name such as page_size or X-Id into a
+ * TypeScript identifier.
+ */
+func paramIdent(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return !isIdentRune(r) || r == '_' })
+	if len(words) == 0 {
+		return "param"
+	}
+	ident := strings.ToLower(words[0][:1]) + words[0][1:]
+	for _, word := range words[1:] {
+		ident += strings.ToUpper(word[:1]) + word[1:]
+	}
+	if ident[0] >= '0' && ident[0] <= '9' {
+		ident = "p" + ident
+	}
+	return ident
+}
+
+/*
+ * propertyKey quotes object keys that are not valid identifiers.
+ */
+func propertyKey(name string) string {
+	for i, r := range name {
+		if !(isIdentRune(r) || r == '$') || i == 0 && r >= '0' && r <= '9' {
+			return tsLiteral("string", name)
+		}
+	}
+	if name == "" {
+		return "''"
+	}
+	return name
+}
@@ -0,0 +1,142 @@
+package codegen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/*
+ * genericSpec lists posts and tags in PaginatedResult pages, which share
+ * one generic declaration. The Holder instantiations do not, as Featured
+ * is always a Post, and neither does a Box of a string.
+ */
+const genericSpec = `{
+  "openapi": "3.0.3",
+  "paths": {
+    "/posts": {"get": {"operationId": "listPosts", "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/PaginatedResultPost"}}}}}}},
+    "/tags": {"get": {"operationId": "listTags", "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/PaginatedResultTag"}}}}}}},
+    "/holders/post": {"get": {"operationId": "getPostHolder", "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/HolderPost"}}}}}}},
+    "/holders/tag": {"get": {"operationId": "getTagHolder", "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/HolderTag"}}}}}}},
+    "/boxes": {"get": {"operationId": "getBox", "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/BoxString"}}}}}}}
+  },
+  "components": {
+    "schemas": {
+      "Post": {"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]},
+      "Tag": {"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]},
+      "PaginatedResultPost": {
+        "type": "object",
+        "description": "PaginatedResult wraps a page of T.",
+        "properties": {
+          "data": {"type": "array", "items": {"allOf": [{"$ref": "#/components/schemas/Post"}], "nullable": true}},
+          "total": {"type": "integer"}
+        },
+        "required": ["data", "total"],
+        "x-go-type": "PaginatedResult[*Post]",
+        "x-go-type-params": ["T"]
+      },
+      "PaginatedResultTag": {
+        "type": "object",
+        "description": "PaginatedResult wraps a page of T.",
+        "properties": {
+          "data": {"type": "array", "items": {"allOf": [{"$ref": "#/components/schemas/Tag"}], "nullable": true}},
+          "total": {"type": "integer"}
+        },
+        "required": ["data", "total"],
+        "x-go-type": "PaginatedResult[*Tag]",
+        "x-go-type-params": ["T"]
+      },
+      "HolderPost": {
+        "type": "object",
+        "properties": {"item": {"$ref": "#/components/schemas/Post"}, "featured": {"$ref": "#/components/schemas/Post"}},
+        "required": ["item", "featured"],
+        "x-go-type": "Holder[Post]",
+        "x-go-type-params": ["E"]
+      },
+      "HolderTag": {
+        "type": "object",
+        "properties": {"item": {"$ref": "#/components/schemas/Tag"}, "featured": {"$ref": "#/components/schemas/Post"}},
+        "required": ["item", "featured"],
+        "x-go-type": "Holder[Tag]",
+        "x-go-type-params": ["E"]
+      },
+      "BoxString": {
+        "type": "object",
+        "properties": {"value": {"type": "string"}},
+        "required": ["value"],
+        "x-go-type": "Box[string]",
+        "x-go-type-params": ["V"]
+      }
+    }
+  }
+}`
+
+func TestEndpointSetGenerics(t *testing.T) {
+	spec, err := ParseAPISpec([]byte(genericSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		known    map[string]bool
+		response map[string]string
+		declared []string
+	}{
+		{
+			name: "declared components",
+			response: map[string]string{
+				"listPosts":     "PaginatedResult<Post>",
+				"listTags":      "PaginatedResult<Tag>",
+				"getPostHolder": "HolderPost",
+				"getTagHolder":  "HolderTag",
+				"getBox":        "BoxString",
+			},
+			declared: []string{
+				"export interface BoxString {\n  value: string;\n}\n",
+				"export interface HolderPost {\n  item: Post;\n  featured: Post;\n}\n",
+				"export interface Post {\n  id: number;\n}\n",
+				"export interface HolderTag {\n  item: Tag;\n  featured: Post;\n}\n",
+				"export interface Tag {\n  name: string;\n}\n",
+				"/** PaginatedResult wraps a page of T. */\nexport interface PaginatedResult<T> {\n  data: (T | null)[];\n  total: number;\n}\n",
+			},
+		},
+		{
+			name:  "generated models",
+			known: map[string]bool{"Post": true, "Tag": true},
+			response: map[string]string{
+				"listPosts":     "PaginatedResult<Post>",
+				"listTags":      "PaginatedResult<Tag>",
+				"getPostHolder": "HolderPost",
+				"getTagHolder":  "HolderTag",
+			},
+			declared: []string{
+				"export interface BoxString {\n  value: string;\n}\n",
+				"export interface HolderPost {\n  item: Post;\n  featured: Post;\n}\n",
+				"export interface HolderTag {\n  item: Tag;\n  featured: Post;\n}\n",
+				"/** PaginatedResult wraps a page of T. */\nexport interface PaginatedResult<T> {\n  data: (T | null)[];\n  total: number;\n}\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := NewEndpointSet(spec, "", tt.known, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			responses := make(map[string]string)
+			for _, endpoint := range set.Endpoints {
+				responses[endpoint.Name] = endpoint.Response
+			}
+			for name, want := range tt.response {
+				if got := responses[name]; got != want {
+					t.Errorf("%s response = %q, want %q", name, got, want)
+				}
+			}
+
+			if declared := set.Declarations(); !reflect.DeepEqual(declared, tt.declared) {
+				t.Errorf("declarations =\n%s\nwant\n%s", strings.Join(declared, "\n"), strings.Join(tt.declared, "\n"))
+			}
+		})
+	}
+}
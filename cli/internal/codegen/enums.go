@@ -0,0 +1,105 @@
+/*
+ * GoAstra CLI - Enum Detection
+ *
+ * Helpers for named basic types with constants in the schema package,
+ * which typesync emits as TypeScript unions or enums. Constant values
+ * come from the type checker, so iota and constant expressions are exact.
+ */
+package codegen
+
+import (
+	"go/ast"
+	"strconv"
+	"strings"
+)
+
+const (
+	markerEnum   = "enum"
+	markerIgnore = "ignore"
+)
+
+/*
+ * EnumValue represents one constant of an enum type.
+ * Value holds the unquoted string or the decimal number.
+ */
+type EnumValue struct {
+	Name  string
+	Value string
+	Doc   string
+}
+
+/*
+ * typeMarker returns the typesync marker found in a type's comments.
+ * Markers are written as `//typesync:enum` or `//typesync:ignore`.
+ */
+func typeMarker(groups ...*ast.CommentGroup) string {
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		for _, comment := range group.List {
+			text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+			switch {
+			case strings.HasPrefix(text, "typesync:"+markerEnum):
+				return markerEnum
+			case strings.HasPrefix(text, "typesync:"+markerIgnore):
+				return markerIgnore
+			}
+		}
+	}
+	return ""
+}
+
+/*
+ * enumLabel derives a human-readable label from a constant name,
+ * dropping the type prefix: RolePowerUser becomes "Power User".
+ */
+func enumLabel(typeName, constName string) string {
+	name := strings.TrimPrefix(constName, typeName)
+	if name == "" || name[0] == '_' {
+		name = strings.TrimLeft(name, "_")
+	}
+	if name == "" {
+		name = constName
+	}
+
+	var sb strings.Builder
+	for i, r := range name {
+		if r == '_' {
+			sb.WriteRune(' ')
+			continue
+		}
+		if i > 0 && r >= 'A' && r <= 'Z' && name[i-1] != '_' && !(name[i-1] >= 'A' && name[i-1] <= 'Z') {
+			sb.WriteRune(' ')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+/*
+ * enumMemberName returns a TypeScript enum member name for a constant.
+ */
+func enumMemberName(typeName, constName string) string {
+	name := strings.TrimLeft(strings.TrimPrefix(constName, typeName), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return constName
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+/*
+ * tsLiteral renders an enum value as a TypeScript literal.
+ */
+func tsLiteral(underlying, value string) string {
+	if underlying == "string" {
+		return "'" + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), "'", `\'`) + "'"
+	}
+	if underlying == "bool" {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,1206 @@
+/*
+ * GoAstra CLI - Go Client Generator
+ *
+ * Generates a Go client package from the API route table: structs for
+ * the component schemas, one service per endpoint group with a method
+ * per endpoint, and a runtime with context support, retries with
+ * backoff, bearer token refresh and APIError decoding.
+ */
+package codegen
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/imports"
+)
+
+/*
+ * GoClientGenerator creates a Go client package from endpoints.
+ */
+type GoClientGenerator struct {
+	outputPath string
+	pkgName    string
+	module     string
+	spec       *APISpec
+	names      map[string]string
+	generics   map[string]string
+	params     map[string]string
+	out        *Output
+}
+
+/*
+ * runtimeNames are declared by the client runtime. Components named
+ * APIError or TokenPair use the runtime types; other clashes are
+ * renamed.
+ */
+var runtimeNames = map[string]bool{
+	"APIError":           true,
+	"TokenPair":          true,
+	"Client":             true,
+	"New":                true,
+	"Option":             true,
+	"RefreshFunc":        true,
+	"RetryPolicy":        true,
+	"DefaultRetryPolicy": true,
+}
+
+/*
+ * goInitialisms are upper-cased in Go identifiers.
+ */
+var goInitialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "https": true, "id": true,
+	"ip": true, "json": true, "jwt": true, "sql": true, "ttl": true,
+	"uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+/*
+ * NewGoClientGenerator creates a new generator instance that writes
+ * package pkgName into outputPath.
+ */
+func NewGoClientGenerator(outputPath, pkgName string) *GoClientGenerator {
+	return &GoClientGenerator{outputPath: outputPath, pkgName: pkgName}
+}
+
+/*
+ * SetModule makes the generator write a go.mod declaring module, so the
+ * client can be required by other services.
+ */
+func (g *GoClientGenerator) SetModule(module string) {
+	g.module = module
+}
+
+/*
+ * Generate writes the client package for endpoints.
+ */
+func (g *GoClientGenerator) Generate(endpoints *EndpointSet) ([]string, error) {
+	out := NewOutput()
+	if err := g.Render(endpoints, out); err != nil {
+		return nil, err
+	}
+	if err := out.AddManifest(g.outputPath); err != nil {
+		return nil, err
+	}
+	return out.Write()
+}
+
+/*
+ * Render renders the runtime, component types and services into out.
+ */
+func (g *GoClientGenerator) Render(endpoints *EndpointSet, out *Output) error {
+	g.out = out
+	g.spec = endpoints.Spec()
+	g.nameComponents()
+
+	if err := g.add("client.go", strings.ReplaceAll(goClientRuntime, goClientPackage, g.pkgName)); err != nil {
+		return err
+	}
+	if err := g.add("types.go", g.typesFile()); err != nil {
+		return err
+	}
+
+	groups := endpoints.Groups()
+	params := make(map[string]bool)
+	for _, group := range groups {
+		service, _ := goServiceName(group)
+		file := toSnakeCaseGo(strings.TrimSuffix(service, "Service")) + "_service.go"
+		if err := g.add(file, g.serviceFile(group, endpoints.InGroup(group), params)); err != nil {
+			return err
+		}
+	}
+	if err := g.add("services.go", g.servicesFile(groups)); err != nil {
+		return err
+	}
+
+	if g.module != "" {
+		g.out.Add(filepath.Join(g.outputPath, "go.mod"), fmt.Sprintf("module %s\n\ngo 1.21\n", g.module))
+	}
+	return nil
+}
+
+/*
+ * add formats a Go file, resolving its standard library imports.
+ */
+func (g *GoClientGenerator) add(name, body string) error {
+	src := "// Code generated by goastra. DO NOT EDIT.\n\n" + body
+	src = strings.Replace(src, "package "+goClientPackage, "package "+g.pkgName, 1)
+
+	formatted, err := imports.Process(name, []byte(src), nil)
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", name, err)
+	}
+	g.out.Add(filepath.Join(g.outputPath, name), string(formatted))
+	return nil
+}
+
+/*
+ * goClientPackage is the package clause placeholder of generated files.
+ */
+const goClientPackage = "apiclient"
+
+/*
+ * nameComponents assigns an exported Go name to every component schema.
+ */
+func (g *GoClientGenerator) nameComponents() {
+	g.names = make(map[string]string)
+	used := make(map[string]bool)
+	for name := range runtimeNames {
+		used[name] = true
+	}
+
+	for _, name := range g.componentNames() {
+		if name == "APIError" || name == "TokenPair" {
+			g.names[name] = name
+			continue
+		}
+		ident := goIdent(name)
+		if used[ident] {
+			ident += "Model"
+		}
+		for i := 2; used[ident]; i++ {
+			ident = fmt.Sprintf("%s%d", strings.TrimRight(ident, "0123456789"), i)
+		}
+		used[ident] = true
+		g.names[name] = ident
+	}
+	g.nameGenerics(used)
+}
+
+/*
+ * nameGenerics declares one generic type for the instantiations of a Go
+ * generic whose type arguments are other components:
+ * PaginatedResult[*Post] and PaginatedResult[*Tag] become
+ * PaginatedResult[Post] and PaginatedResult[Tag] of a single
+ * PaginatedResult[T any]. Instantiations that are not structs keep a
+ * type of their own.
+ */
+func (g *GoClientGenerator) nameGenerics(used map[string]bool) {
+	g.generics = make(map[string]string)
+	instances := g.spec.genericInstances(func(name string) bool {
+		_, ok := g.names[name]
+		return ok
+	})
+
+	var declared []string
+	idents := make(map[string]string)
+	for _, name := range g.componentNames() {
+		inst, ok := instances[name]
+		if !ok || len(g.spec.Components.Schemas[name].Properties) == 0 {
+			continue
+		}
+		ident, ok := idents[inst.Generic]
+		if !ok {
+			ident = goIdent(inst.Generic)
+			for i := 2; used[ident]; i++ {
+				ident = fmt.Sprintf("%s%d", goIdent(inst.Generic), i)
+			}
+			used[ident] = true
+			idents[inst.Generic] = ident
+			declared = append(declared, name)
+		}
+		g.generics[name] = ""
+
+		args := make([]string, len(inst.Args))
+		for i, arg := range inst.Args {
+			args[i] = g.names[arg]
+		}
+		g.names[name] = fmt.Sprintf("%s[%s]", ident, strings.Join(args, ", "))
+	}
+
+	for _, name := range declared {
+		inst := instances[name]
+		schema := g.spec.Components.Schemas[name]
+		ident := idents[inst.Generic]
+
+		g.params = make(map[string]string, len(inst.Args))
+		for i, arg := range inst.Args {
+			g.params[arg] = inst.Params[i]
+		}
+		var sb strings.Builder
+		writeGoDoc(&sb, schema.Description, fmt.Sprintf("%s mirrors the %s schemas.", ident, inst.Generic))
+		sb.WriteString(fmt.Sprintf("type %s[%s any] %s\n", ident, strings.Join(inst.Params, ", "), g.structType(schema)))
+		g.params = nil
+		g.generics[name] = sb.String()
+	}
+}
+
+func (g *GoClientGenerator) componentNames() []string {
+	names := make([]string, 0, len(g.spec.Components.Schemas))
+	for name := range g.spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+ * typesFile declares a Go type for each component schema.
+ */
+func (g *GoClientGenerator) typesFile() string {
+	var sb strings.Builder
+	sb.WriteString("package " + goClientPackage + "\n")
+
+	for _, name := range g.componentNames() {
+		if name == "APIError" || name == "TokenPair" {
+			continue
+		}
+		if generic, ok := g.generics[name]; ok {
+			if generic != "" {
+				sb.WriteString("\n" + generic)
+			}
+			continue
+		}
+		schema := g.spec.Components.Schemas[name]
+		ident := g.names[name]
+
+		sb.WriteString("\n")
+		writeGoDoc(&sb, schema.Description, fmt.Sprintf("%s mirrors the %s schema.", ident, name))
+
+		switch {
+		case len(schema.Enum) > 0 && (schema.Type == "string" || schema.Type == "integer"):
+			g.writeEnum(&sb, ident, schema)
+		case len(schema.Properties) > 0:
+			sb.WriteString(fmt.Sprintf("type %s %s\n", ident, g.structType(schema)))
+		default:
+			goType := g.baseGoType(schema)
+			if goType == "interface{}" || goType == "json.RawMessage" {
+				sb.WriteString(fmt.Sprintf("type %s = %s\n", ident, goType))
+			} else {
+				sb.WriteString(fmt.Sprintf("type %s %s\n", ident, goType))
+			}
+		}
+	}
+	return sb.String()
+}
+
+/*
+ * writeEnum declares a named basic type with a constant per value.
+ */
+func (g *GoClientGenerator) writeEnum(sb *strings.Builder, ident string, schema *Schema) {
+	underlying := "string"
+	if schema.Type == "integer" {
+		underlying = "int"
+	}
+	sb.WriteString(fmt.Sprintf("type %s %s\n\n", ident, underlying))
+
+	sb.WriteString("const (\n")
+	used := make(map[string]bool)
+	for _, value := range schema.Enum {
+		text := fmt.Sprint(value)
+		literal := strconv.Quote(text)
+		if underlying == "int" {
+			literal = text
+		}
+		suffix := goIdent(text)
+		if underlying == "int" {
+			suffix = strings.TrimPrefix(suffix, "V")
+		}
+		name := ident + suffix
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%s%d", ident, suffix, i)
+		}
+		used[name] = true
+		sb.WriteString(fmt.Sprintf("\t%s %s = %s\n", name, ident, literal))
+	}
+	sb.WriteString(")\n")
+}
+
+/*
+ * structType renders an object schema as a struct type. Optional
+ * properties are omitted when empty.
+ */
+func (g *GoClientGenerator) structType(schema *Schema) string {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString("struct {\n")
+	used := make(map[string]bool)
+	for _, property := range schema.Properties {
+		name := goIdent(property.Name)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%d", goIdent(property.Name), i)
+		}
+		used[name] = true
+
+		tag := property.Name
+		if !required[property.Name] {
+			tag += ",omitempty"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s `json:%s`\n", name, g.goType(property.Schema), strconv.Quote(tag)))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+/*
+ * goType converts a schema to a Go type; nullable values become
+ * pointers.
+ */
+func (g *GoClientGenerator) goType(schema *Schema) string {
+	if schema == nil {
+		return "interface{}"
+	}
+	goType := g.baseGoType(schema)
+	if schema.Nullable && !isReferenceGoType(goType) {
+		return "*" + goType
+	}
+	return goType
+}
+
+func (g *GoClientGenerator) baseGoType(schema *Schema) string {
+	if schema.Ref != "" {
+		if param, ok := g.params[RefName(schema.Ref)]; ok {
+			return param
+		}
+		if name, ok := g.names[RefName(schema.Ref)]; ok {
+			return name
+		}
+		return "interface{}"
+	}
+
+	switch {
+	case len(schema.AllOf) == 1:
+		return g.goType(schema.AllOf[0])
+	case len(schema.AllOf) > 1 || len(schema.OneOf) > 0 || len(schema.AnyOf) > 0:
+		return "json.RawMessage"
+	}
+
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		switch schema.Format {
+		case "int32":
+			return "int32"
+		case "int64":
+			return "int64"
+		}
+		return "int"
+	case "number":
+		if schema.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(schema.Items)
+	case "object", "":
+		if len(schema.Properties) > 0 {
+			return g.structType(schema)
+		}
+		if schema.AdditionalProperties != nil {
+			return "map[string]" + g.goType(schema.AdditionalProperties)
+		}
+		if schema.Type == "object" {
+			return "map[string]interface{}"
+		}
+	}
+	return "interface{}"
+}
+
+/*
+ * isReferenceGoType reports whether a Go type already has a nil value.
+ */
+func isReferenceGoType(goType string) bool {
+	for _, prefix := range []string{"*", "[]", "map[", "interface{}", "json.RawMessage"} {
+		if strings.HasPrefix(goType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * isStructType reports whether schema is a reference to an object
+ * component, which methods return by pointer.
+ */
+func (g *GoClientGenerator) isStructType(schema *Schema) bool {
+	if schema == nil || schema.Ref == "" || schema.Nullable {
+		return false
+	}
+	resolved := g.spec.ResolveSchema(schema)
+	name := RefName(schema.Ref)
+	return resolved != nil && len(resolved.Properties) > 0 || name == "APIError" || name == "TokenPair"
+}
+
+/*
+ * goServiceName returns the service type and Client field of a group:
+ * "users" becomes UsersService reachable as client.Users.
+ */
+func goServiceName(group string) (string, string) {
+	service, _ := serviceName(group)
+	base := strings.TrimSuffix(service, "ApiService")
+	return base + "Service", base
+}
+
+/*
+ * servicesFile declares the service fields of Client.
+ */
+func (g *GoClientGenerator) servicesFile(groups []string) string {
+	var sb strings.Builder
+	sb.WriteString("package " + goClientPackage + "\n\n")
+	sb.WriteString("/*\n * services holds the endpoint groups of a Client.\n */\n")
+	sb.WriteString("type services struct {\n")
+	for _, group := range groups {
+		service, field := goServiceName(group)
+		sb.WriteString(fmt.Sprintf("%s *%s\n", field, service))
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("func (c *Client) initServices() {\n")
+	for _, group := range groups {
+		service, field := goServiceName(group)
+		sb.WriteString(fmt.Sprintf("c.%s = &%s{client: c}\n", field, service))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+/*
+ * serviceFile renders the service of a group with a method per
+ * endpoint. params tracks query parameter struct names across groups.
+ */
+func (g *GoClientGenerator) serviceFile(group string, endpoints []Endpoint, params map[string]bool) string {
+	service, field := goServiceName(group)
+
+	var sb strings.Builder
+	sb.WriteString("package " + goClientPackage + "\n\n")
+	sb.WriteString(fmt.Sprintf("/*\n * %s calls the %s endpoints.\n */\n", service, group))
+	sb.WriteString(fmt.Sprintf("type %s struct {\n\tclient *Client\n}\n", service))
+
+	for _, endpoint := range endpoints {
+		method := exportName(endpoint.Name)
+
+		paramsType := ""
+		if len(endpoint.QueryParams) > 0 {
+			paramsType = method + "Params"
+			if params[paramsType] {
+				paramsType = field + paramsType
+			}
+			params[paramsType] = true
+
+			sb.WriteString("\n")
+			writeGoDoc(&sb, "", fmt.Sprintf("%s holds the query parameters of %s.\nOptional parameters are omitted when zero.", paramsType, method))
+			sb.WriteString(fmt.Sprintf("type %s struct {\n", paramsType))
+			for _, param := range endpoint.QueryParams {
+				if param.Description != "" {
+					sb.WriteString(fmt.Sprintf("/* %s */\n", strings.ReplaceAll(param.Description, "*/", "* /")))
+				}
+				sb.WriteString(fmt.Sprintf("%s %s\n", goIdent(param.Name), g.paramType(param)))
+			}
+			sb.WriteString("}\n")
+		}
+
+		sb.WriteString("\n")
+		g.writeMethod(&sb, service, method, paramsType, endpoint)
+	}
+	return sb.String()
+}
+
+/*
+ * writeMethod renders one endpoint as a service method.
+ */
+func (g *GoClientGenerator) writeMethod(sb *strings.Builder, service, method, paramsType string, endpoint Endpoint) {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("%s calls %s %s.", method, endpoint.Method, endpoint.Path))
+	if endpoint.Summary != "" {
+		lines = append(lines, strings.Split(endpoint.Summary, "\n")...)
+	}
+	if endpoint.Description != "" {
+		lines = append(lines, strings.Split(endpoint.Description, "\n")...)
+	}
+	if endpoint.Auth {
+		lines = append(lines, "Requires authentication.")
+	}
+	if endpoint.Deprecated {
+		lines = append(lines, "Deprecated: the endpoint is deprecated.")
+	}
+	sb.WriteString("/*\n")
+	for _, line := range lines {
+		sb.WriteString(strings.TrimRight(" * "+strings.ReplaceAll(line, "*/", "* /"), " ") + "\n")
+	}
+	sb.WriteString(" */\n")
+
+	args := []string{"ctx context.Context"}
+	for _, param := range endpoint.PathParams {
+		args = append(args, fmt.Sprintf("%s %s", goParamIdent(param.Ident), g.paramType(param)))
+	}
+	if hasBody(endpoint) {
+		args = append(args, "body "+g.goType(endpoint.BodySchema))
+	}
+	requiredQuery := false
+	for _, param := range endpoint.QueryParams {
+		requiredQuery = requiredQuery || param.Required
+	}
+	if paramsType != "" {
+		if requiredQuery {
+			args = append(args, "params "+paramsType)
+		} else {
+			args = append(args, "params *"+paramsType)
+		}
+	}
+
+	result := "error"
+	outType := ""
+	pointer := false
+	if endpoint.ResponseSchema != nil {
+		outType = g.goType(endpoint.ResponseSchema)
+		pointer = g.isStructType(endpoint.ResponseSchema)
+		if pointer {
+			result = fmt.Sprintf("(*%s, error)", outType)
+		} else {
+			result = fmt.Sprintf("(%s, error)", outType)
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("func (s *%s) %s(%s) %s {\n", service, method, strings.Join(args, ", "), result))
+
+	fields := []string{
+		fmt.Sprintf("method: %s", strconv.Quote(endpoint.Method)),
+		"path: " + g.pathExpression(endpoint),
+	}
+
+	if paramsType != "" {
+		sb.WriteString("query := url.Values{}\n")
+		if !requiredQuery {
+			sb.WriteString("if params != nil {\n")
+		}
+		for _, param := range endpoint.QueryParams {
+			g.writeQueryParam(sb, param)
+		}
+		if !requiredQuery {
+			sb.WriteString("}\n")
+		}
+		fields = append(fields, "query: query")
+	}
+	if hasBody(endpoint) {
+		fields = append(fields, "body: body")
+	} else if endpoint.Method == "POST" || endpoint.Method == "PUT" || endpoint.Method == "PATCH" {
+		fields = append(fields, "body: struct{}{}")
+	}
+	if endpoint.Auth {
+		fields = append(fields, "auth: true")
+	}
+	call := fmt.Sprintf("s.client.do(ctx, request{%s}", strings.Join(fields, ", "))
+
+	switch {
+	case outType == "":
+		sb.WriteString(fmt.Sprintf("return %s, nil)\n", call))
+	case pointer:
+		sb.WriteString(fmt.Sprintf("var out %s\n", outType))
+		sb.WriteString(fmt.Sprintf("if err := %s, &out); err != nil {\nreturn nil, err\n}\n", call))
+		sb.WriteString("return &out, nil\n")
+	default:
+		sb.WriteString(fmt.Sprintf("var out %s\n", outType))
+		sb.WriteString(fmt.Sprintf("err := %s, &out)\n", call))
+		sb.WriteString("return out, err\n")
+	}
+	sb.WriteString("}\n")
+}
+
+/*
+ * paramType returns the Go type of a path or query parameter.
+ */
+func (g *GoClientGenerator) paramType(param EndpointParam) string {
+	if param.Schema == nil {
+		return "string"
+	}
+	return g.baseGoType(param.Schema)
+}
+
+/*
+ * pathExpression renders the request path, escaping parameters.
+ */
+func (g *GoClientGenerator) pathExpression(endpoint Endpoint) string {
+	path := endpoint.Path
+	var parts []string
+	for len(path) > 0 {
+		start := strings.Index(path, "{")
+		end := strings.Index(path, "}")
+		if start < 0 || end < start {
+			parts = append(parts, strconv.Quote(path))
+			break
+		}
+		if start > 0 {
+			parts = append(parts, strconv.Quote(path[:start]))
+		}
+
+		name := path[start+1 : end]
+		value := "fmt.Sprint(" + goParamIdent(paramIdent(name)) + ")"
+		for _, param := range endpoint.PathParams {
+			if param.Name == name {
+				value = g.formatValue(g.paramType(param), goParamIdent(param.Ident))
+			}
+		}
+		parts = append(parts, "url.PathEscape("+value+")")
+		path = path[end+1:]
+	}
+	if len(parts) == 0 {
+		return `"/"`
+	}
+	return strings.Join(parts, " + ")
+}
+
+/*
+ * writeQueryParam adds a parameter to the query, skipping zero values
+ * of optional parameters.
+ */
+func (g *GoClientGenerator) writeQueryParam(sb *strings.Builder, param EndpointParam) {
+	goType := g.paramType(param)
+	expr := "params." + goIdent(param.Name)
+
+	if strings.HasPrefix(goType, "[]") {
+		sb.WriteString(fmt.Sprintf("for _, v := range %s {\n", expr))
+		sb.WriteString(fmt.Sprintf("query.Add(%s, %s)\n", strconv.Quote(param.Name), g.formatValue(goType[2:], "v")))
+		sb.WriteString("}\n")
+		return
+	}
+
+	set := fmt.Sprintf("query.Set(%s, %s)\n", strconv.Quote(param.Name), g.formatValue(goType, expr))
+	if param.Required {
+		sb.WriteString(set)
+		return
+	}
+	sb.WriteString(fmt.Sprintf("if %s {\n%s}\n", g.nonZero(goType, expr), set))
+}
+
+/*
+ * underlying returns the basic Go type of a named enum type.
+ */
+func (g *GoClientGenerator) underlying(goType string) string {
+	for name, ident := range g.names {
+		if ident != goType {
+			continue
+		}
+		schema := g.spec.Components.Schemas[name]
+		if len(schema.Enum) > 0 && schema.Type == "string" {
+			return "string"
+		}
+		if len(schema.Enum) > 0 && schema.Type == "integer" {
+			return "int"
+		}
+	}
+	return goType
+}
+
+/*
+ * formatValue renders a value of goType as a string.
+ */
+func (g *GoClientGenerator) formatValue(goType, expr string) string {
+	switch g.underlying(goType) {
+	case "string":
+		if goType != "string" {
+			return "string(" + expr + ")"
+		}
+		return expr
+	case "int", "int32", "int64":
+		return "strconv.FormatInt(int64(" + expr + "), 10)"
+	case "float32", "float64":
+		return "strconv.FormatFloat(float64(" + expr + "), 'g', -1, 64)"
+	case "bool":
+		return "strconv.FormatBool(" + expr + ")"
+	case "time.Time":
+		return expr + ".Format(time.RFC3339)"
+	}
+	return "fmt.Sprint(" + expr + ")"
+}
+
+/*
+ * nonZero renders a condition that is true when expr is set.
+ */
+func (g *GoClientGenerator) nonZero(goType, expr string) string {
+	switch g.underlying(goType) {
+	case "string":
+		return expr + ` != ""`
+	case "int", "int32", "int64", "float32", "float64":
+		return expr + " != 0"
+	case "bool":
+		return expr
+	case "time.Time":
+		return "!" + expr + ".IsZero()"
+	}
+	if isReferenceGoType(goType) {
+		return expr + " != nil"
+	}
+	return "true"
+}
+
+/*
+ * goIdent converts a schema or property name such as created_at or
+ * avatarUrl into an exported Go identifier: CreatedAt, AvatarURL.
+ */
+func goIdent(name string) string {
+	var words []string
+	for _, field := range strings.FieldsFunc(name, func(r rune) bool { return !isIdentRune(r) || r == '_' }) {
+		start := 0
+		for i := 1; i < len(field); i++ {
+			if field[i] >= 'A' && field[i] <= 'Z' && field[i-1] >= 'a' && field[i-1] <= 'z' {
+				words = append(words, field[start:i])
+				start = i
+			}
+		}
+		words = append(words, field[start:])
+	}
+
+	var sb strings.Builder
+	for _, word := range words {
+		if goInitialisms[strings.ToLower(word)] {
+			sb.WriteString(strings.ToUpper(word))
+		} else {
+			sb.WriteString(exportName(word))
+		}
+	}
+
+	ident := sb.String()
+	if ident == "" || ident[0] >= '0' && ident[0] <= '9' {
+		ident = "V" + ident
+	}
+	return ident
+}
+
+/*
+ * goParamIdent avoids Go keywords and names used by generated methods.
+ */
+func goParamIdent(ident string) string {
+	switch ident {
+	case "ctx", "body", "params", "query", "out", "err", "s", "v",
+		"break", "case", "chan", "const", "continue", "default", "defer", "else",
+		"fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+		"map", "package", "range", "return", "select", "struct", "switch", "type", "var":
+		return ident + "Param"
+	}
+	return ident
+}
+
+/*
+ * toSnakeCaseGo converts an identifier to a snake_case file name.
+ */
+func toSnakeCaseGo(s string) string {
+	return strings.ReplaceAll(toKebabCase(s), "-", "_")
+}
+
+/*
+ * writeGoDoc writes description, or fallback when it is empty, as a
+ * block comment.
+ */
+func writeGoDoc(sb *strings.Builder, description, fallback string) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		description = fallback
+	}
+	sb.WriteString("/*\n")
+	for _, line := range strings.Split(description, "\n") {
+		sb.WriteString(strings.TrimRight(" * "+strings.ReplaceAll(line, "*/", "* /"), " ") + "\n")
+	}
+	sb.WriteString(" */\n")
+}
+
+/*
+ * goClientRuntime is the request layer of the generated package.
+ */
+const goClientRuntime = `/*
+ * Package apiclient is a generated client for the GoAstra API.
+ *
+ * Create a client with New and call endpoints through its services:
+ *
+ *	client := apiclient.New("http://localhost:8080/api/v1",
+ *		apiclient.WithTokens(tokens),
+ *		apiclient.WithTokenRefresh(refresh))
+ *	users, err := client.Users.ListUser(ctx, nil)
+ *
+ * Errors returned for non-2xx responses are *APIError values.
+ */
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+ * TokenPair holds the access and refresh tokens issued by the API.
+ */
+type TokenPair struct {
+	AccessToken  string ` + "`json:\"access_token\"`" + `
+	RefreshToken string ` + "`json:\"refresh_token\"`" + `
+	ExpiresIn    int64  ` + "`json:\"expires_in\"`" + `
+	TokenType    string ` + "`json:\"token_type\"`" + `
+}
+
+/*
+ * RefreshFunc exchanges a refresh token for a new token pair. The client
+ * calls it once when an authenticated request is rejected with 401 and
+ * retries the request with the new access token.
+ */
+type RefreshFunc func(ctx context.Context, refreshToken string) (*TokenPair, error)
+
+/*
+ * APIError is returned for responses outside the 2xx range. Message
+ * also holds the "error" field of plain error responses and Details the
+ * "errors" list of validation failures.
+ */
+type APIError struct {
+	StatusCode int         ` + "`json:\"-\"`" + `
+	Code       string      ` + "`json:\"code\"`" + `
+	Message    string      ` + "`json:\"message\"`" + `
+	Details    interface{} ` + "`json:\"details,omitempty\"`" + `
+	Body       []byte      ` + "`json:\"-\"`" + `
+}
+
+/*
+ * Error implements the error interface.
+ */
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("api: %d %s", e.StatusCode, message)
+}
+
+/*
+ * RetryPolicy controls retries of idempotent requests (GET, HEAD, PUT,
+ * DELETE, OPTIONS) after network errors and 429, 502, 503 and 504
+ * responses. Backoff doubles from MinBackoff up to MaxBackoff with
+ * jitter; Retry-After headers take precedence.
+ */
+type RetryPolicy struct {
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+/*
+ * DefaultRetryPolicy retries up to three times.
+ */
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 3, MinBackoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}
+
+/*
+ * Option configures a Client.
+ */
+type Option func(*Client)
+
+/*
+ * WithHTTPClient sets the HTTP client used for requests.
+ */
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+/*
+ * WithTokens sets the initial access and refresh tokens.
+ */
+func WithTokens(tokens TokenPair) Option {
+	return func(c *Client) { c.tokens = tokens }
+}
+
+/*
+ * WithTokenRefresh sets the hook that renews expired access tokens.
+ */
+func WithTokenRefresh(refresh RefreshFunc) Option {
+	return func(c *Client) { c.refresh = refresh }
+}
+
+/*
+ * WithRetryPolicy replaces DefaultRetryPolicy; use RetryPolicy{} to
+ * disable retries.
+ */
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) { c.retry = policy }
+}
+
+/*
+ * WithHeader adds a header to every request.
+ */
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Add(key, value) }
+}
+
+/*
+ * Client calls the API. It is safe for concurrent use.
+ */
+type Client struct {
+	services
+
+	baseURL    string
+	httpClient *http.Client
+	retry      RetryPolicy
+	header     http.Header
+	refresh    RefreshFunc
+
+	mu     sync.Mutex
+	tokens TokenPair
+}
+
+/*
+ * New creates a client for the API at baseURL, including the API
+ * prefix, e.g. http://localhost:8080/api/v1.
+ */
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy,
+		header:     make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.initServices()
+	return c
+}
+
+/*
+ * Tokens returns the current token pair.
+ */
+func (c *Client) Tokens() TokenPair {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens
+}
+
+/*
+ * SetTokens replaces the token pair, e.g. after logging in.
+ */
+func (c *Client) SetTokens(tokens TokenPair) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens = tokens
+}
+
+/*
+ * request describes one call made by a generated method.
+ */
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	auth   bool
+}
+
+/*
+ * do sends a request, retrying and refreshing tokens as configured, and
+ * decodes a successful response into out.
+ */
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	var payload []byte
+	if req.body != nil {
+		data, err := json.Marshal(req.body)
+		if err != nil {
+			return fmt.Errorf("api: encode request: %w", err)
+		}
+		payload = data
+	}
+
+	refreshed := false
+	for retries := 0; ; {
+		resp, token, err := c.send(ctx, req, payload)
+		if err != nil {
+			if ctx.Err() != nil || !c.retryable(req, retries) {
+				return err
+			}
+			if err := c.wait(ctx, retries, ""); err != nil {
+				return err
+			}
+			retries++
+			continue
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && req.auth && c.refresh != nil && !refreshed {
+			refreshed = true
+			apiErr := decodeResponse(resp, nil)
+			ok, err := c.refreshTokens(ctx, token)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return apiErr
+			}
+			continue
+		}
+
+		if retryStatus(resp.StatusCode) && c.retryable(req, retries) {
+			retryAfter := resp.Header.Get("Retry-After")
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if err := c.wait(ctx, retries, retryAfter); err != nil {
+				return err
+			}
+			retries++
+			continue
+		}
+
+		return decodeResponse(resp, out)
+	}
+}
+
+/*
+ * send performs a single attempt and returns the access token it used.
+ */
+func (c *Client) send(ctx context.Context, req request, payload []byte) (*http.Response, string, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for key, values := range c.header {
+		httpReq.Header[key] = append([]string(nil), values...)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	token := c.Tokens().AccessToken
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	return resp, token, err
+}
+
+/*
+ * refreshTokens renews the tokens unless another request already did
+ * since used was sent. It reports whether the request can be retried.
+ */
+func (c *Client) refreshTokens(ctx context.Context, used string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tokens.AccessToken != used {
+		return true, nil
+	}
+	if c.tokens.RefreshToken == "" {
+		return false, nil
+	}
+
+	tokens, err := c.refresh(ctx, c.tokens.RefreshToken)
+	if err != nil {
+		return false, fmt.Errorf("api: refresh token: %w", err)
+	}
+	if tokens == nil {
+		return false, nil
+	}
+	c.tokens = *tokens
+	return true, nil
+}
+
+func (c *Client) retryable(req request, retries int) bool {
+	if retries >= c.retry.MaxRetries {
+		return false
+	}
+	switch req.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func retryStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+/*
+ * wait sleeps before a retry or returns early when ctx is done.
+ */
+func (c *Client) wait(ctx context.Context, retries int, retryAfter string) error {
+	delay := c.retry.MinBackoff << uint(retries)
+	if delay <= 0 || delay > c.retry.MaxBackoff {
+		delay = c.retry.MaxBackoff
+	}
+	if delay > 0 {
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+/*
+ * decodeResponse closes the body and decodes it into out, or into an
+ * APIError for error statuses.
+ */
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("api: read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Body: data}
+		var payload struct {
+			Code    string      ` + "`json:\"code\"`" + `
+			Message string      ` + "`json:\"message\"`" + `
+			Details interface{} ` + "`json:\"details\"`" + `
+			Error   string      ` + "`json:\"error\"`" + `
+			Errors  interface{} ` + "`json:\"errors\"`" + `
+		}
+		if json.Unmarshal(data, &payload) == nil {
+			apiErr.Code, apiErr.Message, apiErr.Details = payload.Code, payload.Message, payload.Details
+			if apiErr.Message == "" {
+				apiErr.Message = payload.Error
+			}
+			if apiErr.Details == nil {
+				apiErr.Details = payload.Errors
+			}
+		}
+		return apiErr
+	}
+
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("api: decode response: %w", err)
+	}
+	return nil
+}
+`
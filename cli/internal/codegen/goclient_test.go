@@ -0,0 +1,246 @@
+package codegen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/channdev/goastra/cli/internal/routes"
+)
+
+/*
+ * generateGoClient writes the Go client of the routes testdata app as a
+ * module in a temporary directory and returns the directory.
+ */
+func generateGoClient(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	analysis, err := routes.Analyze("../routes/testdata/app", "/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	openapi := NewOpenAPIGenerator("Test API", "1.0.0")
+	openapi.SetAPIPrefix("/api/v1")
+	data, err := openapi.Render(analysis, OpenAPIJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := ParseAPISpec(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoints, err := NewEndpointSet(spec, "/api/v1", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	gen := NewGoClientGenerator(dir, "apiclient")
+	gen.SetModule("example.com/apiclient")
+	if _, err := gen.Generate(endpoints); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+/*
+ * goCommand runs the go tool in the client module, which has no
+ * dependencies to download.
+ */
+func goCommand(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func TestGoClientVet(t *testing.T) {
+	dir := generateGoClient(t)
+	goCommand(t, dir, "vet", "./...")
+
+	data, err := os.ReadFile(filepath.Join(dir, "users_service.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"func (s *UsersService) GetUser(ctx context.Context, id int) (*User, error)",
+		"type ListUserParams struct {\n\tPage     int\n\tPageSize int\n\tSort     string\n}",
+		"func (s *UsersService) DeleteUser(ctx context.Context, id string) error",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("users_service.go does not contain %q", want)
+		}
+	}
+}
+
+/*
+ * goClientRuntimeTest exercises retries and token refresh of a
+ * generated client against an httptest.Server.
+ */
+const goClientRuntimeTest = `package apiclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fastRetries = WithRetryPolicy(RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+func server(t *testing.T, attempts *int32, handle func(attempt int32, w http.ResponseWriter, r *http.Request)) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handle(atomic.AddInt32(attempts, 1), w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func statusOf(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+func TestRetryGet(t *testing.T) {
+	var attempts int32
+	url := server(t, &attempts, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		if attempt < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(` + "`" + `{"id": 7, "name": "ada"}` + "`" + `))
+	})
+
+	user, err := New(url, fastRetries).Users.GetUser(context.Background(), 7)
+	if err != nil || user.ID != 7 {
+		t.Fatalf("GetUser = %v, %v", user, err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestNoRetryPost(t *testing.T) {
+	var attempts int32
+	url := server(t, &attempts, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, err := New(url, fastRetries).Users.CreateUser(context.Background(), CreateUserInput{Name: "ada"})
+	if statusOf(err) != http.StatusServiceUnavailable {
+		t.Fatalf("CreateUser error = %v, want 503", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestRefreshOn401(t *testing.T) {
+	tests := []struct {
+		name     string
+		accepted string
+		status   int
+		attempts int32
+	}{
+		{"refreshed token accepted", "Bearer fresh", 0, 2},
+		{"refreshed token rejected", "", http.StatusUnauthorized, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts, refreshes int32
+			url := server(t, &attempts, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != tt.accepted {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			})
+			refresh := func(ctx context.Context, refreshToken string) (*TokenPair, error) {
+				atomic.AddInt32(&refreshes, 1)
+				if refreshToken != "refresh" {
+					t.Errorf("refresh token = %q", refreshToken)
+				}
+				return &TokenPair{AccessToken: "fresh", RefreshToken: "refresh"}, nil
+			}
+
+			client := New(url, fastRetries, WithTokens(TokenPair{AccessToken: "stale", RefreshToken: "refresh"}), WithTokenRefresh(refresh))
+			err := client.Users.DeleteUser(context.Background(), "7")
+			if statusOf(err) != tt.status {
+				t.Fatalf("DeleteUser error = %v, want status %d", err, tt.status)
+			}
+			if refreshes != 1 || attempts != tt.attempts {
+				t.Errorf("refreshes = %d, attempts = %d, want 1, %d", refreshes, attempts, tt.attempts)
+			}
+			if got := client.Tokens().AccessToken; got != "fresh" {
+				t.Errorf("access token = %q, want fresh", got)
+			}
+		})
+	}
+}
+`
+
+func TestGoClientRuntime(t *testing.T) {
+	dir := generateGoClient(t)
+	if err := os.WriteFile(filepath.Join(dir, "client_test.go"), []byte(goClientRuntimeTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	goCommand(t, dir, "test", "./...")
+}
+
+func TestGoClientGenerics(t *testing.T) {
+	spec, err := ParseAPISpec([]byte(genericSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := NewEndpointSet(spec, "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := NewOutput()
+	if err := NewGoClientGenerator("apiclient", "apiclient").Render(set, out); err != nil {
+		t.Fatal(err)
+	}
+
+	types := out.files[filepath.Join("apiclient", "types.go")]
+	for _, want := range []string{
+		"type PaginatedResult[T any] struct {\n\tData  []*T `json:\"data\"`\n\tTotal int  `json:\"total\"`\n}\n",
+		"type HolderPost struct {",
+		"type HolderTag struct {",
+		"type BoxString struct {",
+	} {
+		if !strings.Contains(types, want) {
+			t.Errorf("types.go does not contain %q:\n%s", want, types)
+		}
+	}
+	for _, unwanted := range []string{"PaginatedResultPost", "PaginatedResultTag"} {
+		if strings.Contains(types, unwanted) {
+			t.Errorf("types.go declares %s", unwanted)
+		}
+	}
+
+	services := ""
+	for path, content := range out.files {
+		if strings.HasSuffix(path, "_service.go") {
+			services += content
+		}
+	}
+	for _, want := range []string{"(*PaginatedResult[Post], error)", "(*PaginatedResult[Tag], error)", "(*HolderPost, error)", "(*HolderTag, error)"} {
+		if !strings.Contains(services, want) {
+			t.Errorf("services do not return %s", want)
+		}
+	}
+}
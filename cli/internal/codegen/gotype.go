@@ -0,0 +1,235 @@
+/*
+ * GoAstra CLI - Go Type Strings
+ *
+ * Helpers for reading Go type expressions rendered by the parser
+ * and mapping them to their TypeScript equivalents.
+ */
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+ * goTypeToTSType converts a Go type string to a TypeScript type
+ * using only the built-in mappings.
+ */
+func goTypeToTSType(goType string) string {
+	return TypeMappings(nil).tsType(goType)
+}
+
+/*
+ * tsType converts a Go type string to a TypeScript type.
+ * Generic instantiations such as Page[User] become Page<User>.
+ */
+func (m TypeMappings) tsType(goType string) string {
+	goType = strings.TrimSpace(goType)
+
+	/* Handle pointers */
+	if strings.HasPrefix(goType, "*") {
+		elem := m.tsType(goType[1:])
+		if strings.HasSuffix(elem, " | null") {
+			return elem
+		}
+		return elem + " | null"
+	}
+
+	/* Handle slices */
+	if strings.HasPrefix(goType, "[]") {
+		elem := m.tsType(goType[2:])
+		if strings.Contains(elem, " | ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	}
+
+	/* Handle well-known types such as time.Time */
+	if mapping, ok := m.lookup(goType); ok {
+		return mapping.TS
+	}
+
+	/* Handle maps */
+	if key, value, ok := splitMapType(goType); ok {
+		return fmt.Sprintf("Record<%s, %s>", m.tsType(key), m.tsType(value))
+	}
+
+	/* Handle generic instantiations */
+	if name, args, ok := splitGenericType(goType); ok {
+		tsArgs := make([]string, len(args))
+		for i, arg := range args {
+			tsArgs[i] = m.tsType(arg)
+		}
+		return fmt.Sprintf("%s<%s>", name, strings.Join(tsArgs, ", "))
+	}
+
+	/* Basic type mappings */
+	switch goType {
+	case "string":
+		return "string"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64", "byte", "rune":
+		return "number"
+	case "bool":
+		return "boolean"
+	case "interface{}", "any":
+		return "unknown"
+	default:
+		/* Assume custom type reference */
+		return goType
+	}
+}
+
+/*
+ * typeParamsToTS renders a TypeScript type parameter list.
+ * Constraints made of basic types become `extends` bounds; others are dropped.
+ */
+func typeParamsToTS(params []TypeParam) string {
+	if len(params) == 0 {
+		return ""
+	}
+
+	rendered := make([]string, len(params))
+	for i, param := range params {
+		rendered[i] = param.Name
+		if bound := constraintToTS(param.Constraint); bound != "" {
+			rendered[i] += " extends " + bound
+		}
+	}
+
+	return "<" + strings.Join(rendered, ", ") + ">"
+}
+
+/*
+ * fieldTSType returns the TypeScript type of a field, honouring
+ * a `ts` struct tag override.
+ */
+func (m TypeMappings) fieldTSType(field FieldDef) string {
+	if field.TSType != "" {
+		return field.TSType
+	}
+	return m.tsType(field.Type)
+}
+
+/*
+ * mappingImports returns the import statements needed by mapped types
+ * referenced from the given Go type strings, sorted by module.
+ */
+func (m TypeMappings) mappingImports(goTypes ...string) []string {
+	seen := make(map[string]bool)
+	var imports []string
+	for _, goType := range goTypes {
+		for _, ident := range typeIdents(goType) {
+			mapping, ok := m.lookup(ident)
+			if !ok || mapping.Import == "" {
+				continue
+			}
+			name := strings.FieldsFunc(mapping.TS, func(r rune) bool { return !isIdentRune(r) && r != '$' })
+			if len(name) == 0 {
+				continue
+			}
+			stmt := fmt.Sprintf("import { %s } from '%s';", name[0], mapping.Import)
+			if !seen[stmt] {
+				seen[stmt] = true
+				imports = append(imports, stmt)
+			}
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+func constraintToTS(constraint string) string {
+	if constraint == "" || constraint == "any" || constraint == "interface{}" {
+		return ""
+	}
+
+	var bounds []string
+	seen := make(map[string]bool)
+	for _, term := range strings.Split(constraint, "|") {
+		term = strings.TrimPrefix(strings.TrimSpace(term), "~")
+		tsType := goTypeToTSType(term)
+		if tsType != "string" && tsType != "number" && tsType != "boolean" {
+			return ""
+		}
+		if !seen[tsType] {
+			seen[tsType] = true
+			bounds = append(bounds, tsType)
+		}
+	}
+
+	return strings.Join(bounds, " | ")
+}
+
+/*
+ * splitMapType splits map[K]V into its key and value types.
+ */
+func splitMapType(goType string) (string, string, bool) {
+	if !strings.HasPrefix(goType, "map[") {
+		return "", "", false
+	}
+
+	end := matchingBracket(goType, 3)
+	if end < 0 {
+		return "", "", false
+	}
+
+	return goType[4:end], goType[end+1:], true
+}
+
+/*
+ * splitGenericType splits Name[A, B] into its name and type arguments.
+ */
+func splitGenericType(goType string) (string, []string, bool) {
+	open := strings.IndexByte(goType, '[')
+	if open <= 0 || !strings.HasSuffix(goType, "]") || matchingBracket(goType, open) != len(goType)-1 {
+		return "", nil, false
+	}
+
+	return goType[:open], splitTopLevel(goType[open+1 : len(goType)-1]), true
+}
+
+/*
+ * splitTopLevel splits a comma-separated list, ignoring nested brackets
+ * (including TypeScript generics).
+ */
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '[', '(', '{', '<':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case '>':
+			if i == 0 || s[i-1] != '=' {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+func matchingBracket(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,110 @@
+/*
+ * GoAstra CLI - Output Layout
+ *
+ * Decides which TypeScript module each generated type is written to,
+ * and how modules import each other.
+ */
+package codegen
+
+import (
+	"path"
+	"strings"
+)
+
+/*
+ * Layout controls how generated types are grouped into modules.
+ */
+type Layout string
+
+const (
+	LayoutPerType    Layout = "per-type"
+	LayoutPerFile    Layout = "per-file"
+	LayoutPerPackage Layout = "per-package"
+	LayoutBundle     Layout = "bundle"
+)
+
+/*
+ * Layouts lists the supported layouts.
+ */
+var Layouts = []Layout{LayoutPerType, LayoutPerFile, LayoutPerPackage, LayoutBundle}
+
+/*
+ * bundleModule is the module holding every type in the bundle layout.
+ */
+const bundleModule = "models"
+
+/*
+ * modulePaths maps each type name to its module: a slash-separated
+ * path relative to the output directory, without extension.
+ *
+ * per-type writes one file per type. per-file writes one module per Go
+ * source file; with several schema packages, and for types pulled in
+ * from other packages, modules are nested in a directory per package.
+ * per-package writes one module per Go package and bundle a single one.
+ */
+func modulePaths(types []TypeDef, layout Layout) map[string]string {
+	schemaPackages := make(map[string]bool)
+	for _, typeDef := range types {
+		if !typeDef.External {
+			schemaPackages[typeDef.Package] = true
+		}
+	}
+
+	modules := make(map[string]string, len(types))
+	for _, typeDef := range types {
+		var module string
+		switch layout {
+		case LayoutPerFile:
+			module = kebabFileName(typeDef)
+			if typeDef.External || len(schemaPackages) > 1 {
+				module = toKebabCase(typeDef.Package) + "/" + module
+			}
+		case LayoutPerPackage:
+			module = toKebabCase(typeDef.Package)
+		case LayoutBundle:
+			module = bundleModule
+		default:
+			module = moduleName(typeDef)
+		}
+		if path.Base(module) == "index" {
+			module += "-types"
+		}
+		modules[typeDef.Name] = module
+	}
+	return modules
+}
+
+/*
+ * kebabFileName returns the kebab-case name of a type's source file,
+ * falling back to its package for types without one.
+ */
+func kebabFileName(typeDef TypeDef) string {
+	name := strings.TrimSuffix(typeDef.File, ".go")
+	if name == "" {
+		name = typeDef.Package
+	}
+	return strings.ReplaceAll(toKebabCase(name), "_", "-")
+}
+
+/*
+ * relativeModule returns the import specifier of module to as seen
+ * from module from, e.g. "./user" or "../billing/invoice".
+ */
+func relativeModule(from, to string) string {
+	fromParts := strings.Split(path.Dir(from), "/")
+	toParts := strings.Split(to, "/")
+	if fromParts[0] == "." {
+		fromParts = nil
+	}
+
+	common := 0
+	for common < len(fromParts) && common < len(toParts)-1 && fromParts[common] == toParts[common] {
+		common++
+	}
+
+	rel := strings.Repeat("../", len(fromParts)-common) + strings.Join(toParts[common:], "/")
+	if !strings.HasPrefix(rel, ".") {
+		rel = "./" + rel
+	}
+	return rel
+}
@@ -0,0 +1,920 @@
+/*
+ * GoAstra CLI - OpenAPI Generator
+ *
+ * Builds an OpenAPI 3 description from the routes found by static
+ * analysis and the Go types they exchange. Schema types become
+ * components, generic instantiations are expanded into concrete
+ * components tagged with their Go type (`x-go-type`), and `validate`
+ * tags become schema constraints.
+ */
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/channdev/goastra/cli/internal/routes"
+	"golang.org/x/tools/go/packages"
+)
+
+/*
+ * OpenAPIFormat selects the output encoding.
+ */
+type OpenAPIFormat string
+
+const (
+	OpenAPIJSON OpenAPIFormat = "json"
+	OpenAPIYAML OpenAPIFormat = "yaml"
+)
+
+const (
+	componentPrefix    = "#/components/schemas/"
+	bearerSchemeName   = "bearerAuth"
+	unknownDescription = "Response not determined by static analysis"
+)
+
+/*
+ * OpenAPIGenerator creates an OpenAPI 3.0 document.
+ */
+type OpenAPIGenerator struct {
+	title     string
+	version   string
+	apiPrefix string
+	servers   []string
+	resolver  *typeResolver
+	schemaPkg *packages.Package
+	defs      map[string]TypeDef
+	done      map[string]bool
+	pending   []string
+	schemas   *orderedMap
+}
+
+/*
+ * operation holds a route with its types resolved to Go type strings.
+ */
+type operation struct {
+	route     routes.Route
+	body      string
+	query     []queryField
+	responses []string
+}
+
+type queryField struct {
+	name     string
+	goType   string
+	validate string
+}
+
+/*
+ * NewOpenAPIGenerator creates a new generator instance.
+ */
+func NewOpenAPIGenerator(title, version string) *OpenAPIGenerator {
+	resolver := newTypeResolver(nil)
+	resolver.externalEnums = true
+	return &OpenAPIGenerator{
+		title:    title,
+		version:  version,
+		resolver: resolver,
+	}
+}
+
+/*
+ * SetTypeMappings registers project-specific type mappings.
+ */
+func (g *OpenAPIGenerator) SetTypeMappings(mappings TypeMappings) {
+	g.resolver.mappings = mappings
+}
+
+/*
+ * SetAPIPrefix sets the route prefix stripped when deriving tags.
+ */
+func (g *OpenAPIGenerator) SetAPIPrefix(prefix string) {
+	g.apiPrefix = strings.TrimSuffix(prefix, "/")
+}
+
+/*
+ * AddServer adds a server URL to the document.
+ */
+func (g *OpenAPIGenerator) AddServer(url string) {
+	g.servers = append(g.servers, url)
+}
+
+/*
+ * AddSchemaPackage loads the schema package, whose types all become
+ * components with their names kept.
+ */
+func (g *OpenAPIGenerator) AddSchemaPackage(schemaPath string) error {
+	pkg, err := loadPackage(schemaPath)
+	if err != nil {
+		return err
+	}
+	g.schemaPkg = pkg
+	return nil
+}
+
+/*
+ * Render builds the document for the analysed routes and encodes it.
+ */
+func (g *OpenAPIGenerator) Render(analysis *routes.Analysis, format OpenAPIFormat) ([]byte, error) {
+	if g.schemaPkg != nil {
+		/* Use the app's copy of the schema package so handler types match */
+		schema := g.schemaPkg
+		packages.Visit(analysis.Packages, nil, func(pkg *packages.Package) {
+			if pkg.PkgPath == g.schemaPkg.PkgPath && pkg.TypesInfo != nil {
+				schema = pkg
+			}
+		})
+
+		g.resolver.addPackage(schema)
+		g.resolver.setLocal(schema.Types)
+		for _, obj := range declaredTypes(schema) {
+			if g.resolver.markers[obj] == markerIgnore {
+				continue
+			}
+			if err := g.resolver.declare(obj); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, pkg := range analysis.Packages {
+		g.resolver.addPackage(pkg)
+	}
+
+	ops := make([]operation, 0, len(analysis.Routes))
+	for _, route := range analysis.Routes {
+		op, err := g.resolveOperation(route)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", route.Method, route.Path, err)
+		}
+		ops = append(ops, op)
+	}
+
+	defs, err := g.resolver.definitions()
+	if err != nil {
+		return nil, err
+	}
+
+	g.defs = make(map[string]TypeDef, len(defs))
+	g.done = make(map[string]bool)
+	g.schemas = newOrderedMap()
+	for _, def := range defs {
+		g.defs[def.Name] = def
+	}
+	for _, def := range defs {
+		if len(def.TypeParams) == 0 {
+			g.addComponent(def.Name)
+		}
+	}
+	g.flushComponents()
+
+	doc := newOrderedMap()
+	doc.Set("openapi", "3.0.3")
+	doc.Set("info", newOrderedMap().Set("title", g.title).Set("version", g.version))
+	if len(g.servers) > 0 {
+		var servers []interface{}
+		for _, url := range g.servers {
+			servers = append(servers, newOrderedMap().Set("url", url))
+		}
+		doc.Set("servers", servers)
+	}
+	doc.Set("paths", g.paths(ops))
+
+	/* Paths may reference new generic instantiations */
+	g.flushComponents()
+
+	components := newOrderedMap()
+	components.Set("schemas", g.sortedSchemas())
+	components.Set("securitySchemes", newOrderedMap().Set(bearerSchemeName, newOrderedMap().
+		Set("type", "http").
+		Set("scheme", "bearer").
+		Set("bearerFormat", "JWT")))
+	doc.Set("components", components)
+
+	if format == OpenAPIYAML {
+		return encodeYAML(doc)
+	}
+	return encodeJSON(doc)
+}
+
+/*
+ * resolveOperation resolves the Go types a route exchanges, scheduling
+ * the structs they use for output.
+ */
+func (g *OpenAPIGenerator) resolveOperation(route routes.Route) (operation, error) {
+	op := operation{route: route}
+	hint := exportName(strings.ReplaceAll(route.Handler, ".", ""))
+
+	if route.Body != nil {
+		body, err := g.resolver.resolve(route.Body, hint+"Request")
+		if err != nil {
+			return op, err
+		}
+		op.body = body
+	}
+
+	if route.Query != nil {
+		if st, ok := route.Query.Underlying().(*types.Struct); ok {
+			for i := 0; i < st.NumFields(); i++ {
+				field := st.Field(i)
+				tag := reflect.StructTag(st.Tag(i))
+				name, _, _ := strings.Cut(tag.Get("form"), ",")
+				if !field.Exported() || name == "-" {
+					continue
+				}
+				if name == "" {
+					name = field.Name()
+				}
+				goType, err := g.resolver.resolve(field.Type(), hint+"Query")
+				if err != nil {
+					return op, err
+				}
+				op.query = append(op.query, queryField{name: name, goType: goType, validate: tag.Get("validate")})
+			}
+		}
+	}
+
+	for i, response := range route.Responses {
+		op.responses = append(op.responses, "")
+		if response.Type == nil {
+			continue
+		}
+		goType, err := g.resolver.resolve(response.Type, hint+"Response")
+		if err != nil {
+			return op, err
+		}
+		op.responses[i] = goType
+	}
+
+	return op, nil
+}
+
+/*
+ * paths builds the paths object, grouping operations by path.
+ */
+func (g *OpenAPIGenerator) paths(ops []operation) *orderedMap {
+	paths := newOrderedMap()
+	used := make(map[string]bool)
+
+	for _, op := range ops {
+		path, params := openAPIPath(op.route.Path)
+		item, ok := paths.Get(path)
+		if !ok {
+			item = newOrderedMap()
+			paths.Set(path, item)
+		}
+		item.(*orderedMap).Set(strings.ToLower(op.route.Method), g.operation(op, params, used))
+	}
+
+	return paths
+}
+
+func (g *OpenAPIGenerator) operation(op operation, pathParams []string, used map[string]bool) *orderedMap {
+	route := op.route
+	result := newOrderedMap()
+
+	if tag := g.tag(route.Path); tag != "" {
+		result.Set("tags", []interface{}{tag})
+	}
+
+	if route.Doc != "" {
+		summary, description, _ := strings.Cut(docText(route.Doc), "\n")
+		result.Set("summary", strings.TrimSpace(summary))
+		if description = strings.TrimSpace(description); description != "" {
+			result.Set("description", description)
+		}
+	}
+
+	id := operationID(route)
+	for i := 2; used[id]; i++ {
+		id = fmt.Sprintf("%s%d", operationID(route), i)
+	}
+	used[id] = true
+	result.Set("operationId", id)
+
+	integers := make(map[string]bool, len(route.IntegerParams))
+	for _, name := range route.IntegerParams {
+		integers[name] = true
+	}
+	var params []interface{}
+	for _, name := range pathParams {
+		kind := "string"
+		if integers[name] {
+			kind = "integer"
+		}
+		params = append(params, newOrderedMap().
+			Set("name", name).
+			Set("in", "path").
+			Set("required", true).
+			Set("schema", newOrderedMap().Set("type", kind)))
+	}
+	for _, param := range route.QueryParams {
+		schema := newOrderedMap().Set("type", "string")
+		if param.Integer {
+			schema.Set("type", "integer")
+		}
+		if n, err := strconv.Atoi(param.Default); err == nil && param.Integer {
+			schema.Set("default", n)
+		} else if param.Default != "" {
+			schema.Set("default", param.Default)
+		}
+		params = append(params, newOrderedMap().
+			Set("name", param.Name).
+			Set("in", "query").
+			Set("schema", schema))
+	}
+	for _, field := range op.query {
+		rules := parseValidateTag(field.validate)
+		schema := g.schema(field.goType)
+		applyConstraints(schema, rules)
+		param := newOrderedMap().Set("name", field.name).Set("in", "query")
+		if rules.required {
+			param.Set("required", true)
+		}
+		params = append(params, param.Set("schema", schema))
+	}
+	if len(params) > 0 {
+		result.Set("parameters", params)
+	}
+
+	if op.body != "" {
+		result.Set("requestBody", newOrderedMap().
+			Set("required", true).
+			Set("content", jsonContent(g.schema(op.body))))
+	}
+
+	type response struct {
+		status int
+		goType string
+	}
+	var written []response
+	hasUnauthorized := false
+	for i, r := range route.Responses {
+		written = append(written, response{r.Status, op.responses[i]})
+		hasUnauthorized = hasUnauthorized || r.Status == http.StatusUnauthorized
+	}
+
+	/* middleware.Auth rejects requests without a valid token */
+	if route.Auth && !hasUnauthorized {
+		written = append(written, response{status: http.StatusUnauthorized})
+	}
+	sort.SliceStable(written, func(i, j int) bool { return written[i].status < written[j].status })
+
+	responses := newOrderedMap()
+	for _, r := range written {
+		result := newOrderedMap().Set("description", statusDescription(r.status))
+		if r.goType != "" {
+			result.Set("content", jsonContent(g.schema(r.goType)))
+		}
+		responses.Set(strconv.Itoa(r.status), result)
+	}
+	if responses.Len() == 0 {
+		responses.Set("default", newOrderedMap().Set("description", unknownDescription))
+	}
+	result.Set("responses", responses)
+
+	if route.Auth {
+		result.Set("security", []interface{}{newOrderedMap().Set(bearerSchemeName, []interface{}{})})
+	}
+
+	return result
+}
+
+/*
+ * tag groups operations by the first path segment after the API prefix.
+ */
+func (g *OpenAPIGenerator) tag(path string) string {
+	if g.apiPrefix != "" && (path == g.apiPrefix || strings.HasPrefix(path, g.apiPrefix+"/")) {
+		path = strings.TrimPrefix(path, g.apiPrefix)
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" && !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			return segment
+		}
+	}
+	return ""
+}
+
+/*
+ * schema converts a Go type string into a schema object.
+ */
+func (g *OpenAPIGenerator) schema(goType string) *orderedMap {
+	goType = strings.TrimSpace(goType)
+
+	if strings.HasPrefix(goType, "*") {
+		return nullable(g.schema(goType[1:]))
+	}
+
+	if strings.HasPrefix(goType, "[]") {
+		return newOrderedMap().Set("type", "array").Set("items", g.schema(goType[2:]))
+	}
+
+	if mapping, ok := g.resolver.mappings.lookup(goType); ok {
+		return mappingSchema(mapping)
+	}
+
+	if _, value, ok := splitMapType(goType); ok {
+		return newOrderedMap().Set("type", "object").Set("additionalProperties", g.schema(value))
+	}
+
+	if _, _, ok := splitGenericType(goType); ok {
+		name := componentName(goType)
+		g.instantiate(name, goType)
+		return ref(name)
+	}
+
+	if schema := basicSchema(goType); schema != nil {
+		return schema
+	}
+
+	if _, ok := g.defs[goType]; ok {
+		return ref(goType)
+	}
+
+	/* Unknown types accept any value */
+	return newOrderedMap()
+}
+
+/*
+ * addComponent renders the component for a non-generic definition.
+ */
+func (g *OpenAPIGenerator) addComponent(name string) {
+	if g.done[name] {
+		return
+	}
+	g.done[name] = true
+	g.schemas.Set(name, g.definitionSchema(g.defs[name], nil))
+}
+
+/*
+ * instantiate schedules a concrete component for a generic type such as
+ * PaginatedResponse[User].
+ */
+func (g *OpenAPIGenerator) instantiate(name, goType string) {
+	if g.done[name] {
+		return
+	}
+	g.done[name] = true
+	g.pending = append(g.pending, goType)
+}
+
+func (g *OpenAPIGenerator) flushComponents() {
+	for len(g.pending) > 0 {
+		goType := g.pending[0]
+		g.pending = g.pending[1:]
+
+		base, args, _ := splitGenericType(goType)
+		def, ok := g.defs[base]
+		if !ok {
+			g.schemas.Set(componentName(goType), newOrderedMap())
+			continue
+		}
+
+		subst := make(map[string]string)
+		params := make([]interface{}, len(def.TypeParams))
+		for i, param := range def.TypeParams {
+			if i < len(args) {
+				subst[param.Name] = args[i]
+			}
+			params[i] = param.Name
+		}
+		schema := g.definitionSchema(def, subst)
+		schema.Set("x-go-type", goType)
+		schema.Set("x-go-type-params", params)
+		g.schemas.Set(componentName(goType), schema)
+	}
+}
+
+/*
+ * definitionSchema renders an enum or object schema. subst replaces type
+ * parameters in field types for generic instantiations.
+ */
+func (g *OpenAPIGenerator) definitionSchema(def TypeDef, subst map[string]string) *orderedMap {
+	schema := newOrderedMap()
+
+	if def.IsEnum() {
+		base := basicSchema(def.Underlying)
+		if base == nil {
+			base = newOrderedMap().Set("type", "string")
+		}
+		kind, _ := base.Get("type")
+		schema.Set("type", kind)
+		if def.Doc != "" {
+			schema.Set("description", docText(def.Doc))
+		}
+		var values []interface{}
+		for _, value := range def.Enum {
+			values = append(values, enumJSONValue(kind.(string), value.Value))
+		}
+		schema.Set("enum", values)
+		return schema
+	}
+
+	schema.Set("type", "object")
+	if def.Doc != "" {
+		schema.Set("description", docText(def.Doc))
+	}
+
+	properties := newOrderedMap()
+	var required []interface{}
+	for _, field := range def.Fields {
+		goType := substituteTypeParams(field.Type, subst)
+		rules := parseValidateTag(field.Validate)
+
+		property := g.schema(goType)
+		if field.Doc != "" {
+			property = describe(property, docText(field.Doc))
+		}
+		applyConstraints(property, rules)
+
+		/* encoding/json uses the field name for untagged fields */
+		name := field.JSONName
+		if name == "" {
+			name = field.Name
+		}
+		properties.Set(name, property)
+
+		if !field.Optional || rules.required {
+			required = append(required, name)
+		}
+	}
+	schema.Set("properties", properties)
+	if len(required) > 0 {
+		schema.Set("required", required)
+	}
+
+	return schema
+}
+
+func (g *OpenAPIGenerator) sortedSchemas() *orderedMap {
+	names := append([]string(nil), g.schemas.keys...)
+	sort.Strings(names)
+	sorted := newOrderedMap()
+	for _, name := range names {
+		sorted.Set(name, g.schemas.values[name])
+	}
+	return sorted
+}
+
+/*
+ * applyConstraints maps go-playground validator rules onto the schema
+ * keywords for its type. References are left unchanged. Under
+ * omitempty the validator skips the checks for the zero value, so the
+ * schema becomes anyOf the constrained schema and the zero value.
+ */
+func applyConstraints(schema *orderedMap, rules validateRules) {
+	if !rules.omitempty {
+		constrain(schema, rules)
+		return
+	}
+
+	constrained := newOrderedMap()
+	for _, key := range schema.keys {
+		if key != "description" {
+			constrained.Set(key, schema.values[key])
+		}
+	}
+	if !constrain(constrained, rules) {
+		return
+	}
+
+	value, _ := schema.Get("type")
+	kind, _ := value.(string)
+	zero := newOrderedMap().Set("type", kind)
+	switch kind {
+	case "string":
+		zero.Set("enum", []interface{}{""})
+	case "integer", "number":
+		zero.Set("enum", []interface{}{0})
+	case "array":
+		zero.Set("maxItems", 0)
+	}
+
+	description, described := schema.Get("description")
+	schema.keys, schema.values = nil, make(map[string]interface{})
+	schema.Set("anyOf", []interface{}{constrained, zero})
+	if described {
+		schema.Set("description", description)
+	}
+}
+
+/*
+ * constrain sets the schema keywords of the validate rules and reports
+ * whether it added any.
+ */
+func constrain(schema *orderedMap, rules validateRules) bool {
+	value, _ := schema.Get("type")
+	kind, _ := value.(string)
+	keywords := schema.Len()
+
+	var lower, upper string
+	switch kind {
+	case "string":
+		lower, upper = "minLength", "maxLength"
+		if rules.required {
+			schema.Set("minLength", 1)
+		}
+	case "integer", "number":
+		lower, upper = "minimum", "maximum"
+	case "array":
+		lower, upper = "minItems", "maxItems"
+	default:
+		return false
+	}
+
+	for _, check := range rules.checks {
+		number, isNumber := jsonNumber(check.param)
+		switch check.name {
+		case "min", "gte":
+			if isNumber && (check.name == "min" || kind != "string") {
+				schema.Set(lower, number)
+			}
+		case "max", "lte":
+			if isNumber && (check.name == "max" || kind != "string") {
+				schema.Set(upper, number)
+			}
+		case "len":
+			if isNumber && kind != "integer" && kind != "number" {
+				schema.Set(lower, number)
+				schema.Set(upper, number)
+			}
+		case "gt", "lt":
+			if isNumber && (kind == "integer" || kind == "number") {
+				if check.name == "gt" {
+					schema.Set("minimum", number).Set("exclusiveMinimum", true)
+				} else {
+					schema.Set("maximum", number).Set("exclusiveMaximum", true)
+				}
+			}
+		case "email":
+			if kind == "string" {
+				schema.Set("format", "email")
+			}
+		case "url", "uri":
+			if kind == "string" {
+				schema.Set("format", "uri")
+			}
+		case "uuid", "uuid4":
+			if kind == "string" {
+				schema.Set("format", "uuid")
+			}
+		case "oneof":
+			var values []interface{}
+			for _, value := range oneofValues(check.param) {
+				values = append(values, enumJSONValue(kind, value))
+			}
+			schema.Set("enum", values)
+		}
+	}
+
+	return schema.Len() != keywords
+}
+
+/*
+ * basicSchema returns the schema of a Go basic type, or nil.
+ */
+func basicSchema(goType string) *orderedMap {
+	schema := newOrderedMap()
+	switch goType {
+	case "string":
+		return schema.Set("type", "string")
+	case "bool":
+		return schema.Set("type", "boolean")
+	case "int32", "uint32", "rune":
+		return schema.Set("type", "integer").Set("format", "int32")
+	case "int64", "uint64":
+		return schema.Set("type", "integer").Set("format", "int64")
+	case "int", "int8", "int16", "uint", "uint8", "uint16", "byte", "uintptr":
+		return schema.Set("type", "integer")
+	case "float32":
+		return schema.Set("type", "number").Set("format", "float")
+	case "float64":
+		return schema.Set("type", "number").Set("format", "double")
+	case "interface{}", "any":
+		return schema
+	}
+	return nil
+}
+
+/*
+ * mappingSchema derives a schema from a TypeScript type mapping.
+ */
+func mappingSchema(mapping TypeMapping) *orderedMap {
+	ts := strings.TrimSpace(mapping.TS)
+	isNullable := strings.HasSuffix(ts, " | null")
+	ts = strings.TrimSuffix(ts, " | null")
+
+	schema := newOrderedMap()
+	switch {
+	case ts == "string" || ts == "number" || ts == "boolean":
+		schema.Set("type", ts)
+	case strings.HasSuffix(ts, "[]"):
+		item := mappingSchema(TypeMapping{TS: strings.TrimSuffix(ts, "[]")})
+		schema.Set("type", "array").Set("items", item)
+	case strings.HasPrefix(ts, "{"):
+		schema.Set("type", "object")
+	}
+	if mapping.Format != "" {
+		schema.Set("format", mapping.Format)
+	}
+	if isNullable {
+		schema.Set("nullable", true)
+	}
+	return schema
+}
+
+func ref(name string) *orderedMap {
+	return newOrderedMap().Set("$ref", componentPrefix+name)
+}
+
+/*
+ * nullable marks a schema nullable. References cannot carry sibling
+ * keywords in OpenAPI 3.0, so they are wrapped in allOf.
+ */
+func nullable(schema *orderedMap) *orderedMap {
+	if _, ok := schema.Get("$ref"); ok {
+		return newOrderedMap().Set("allOf", []interface{}{schema}).Set("nullable", true)
+	}
+	if schema.Len() == 0 {
+		return schema
+	}
+	return schema.Set("nullable", true)
+}
+
+func describe(schema *orderedMap, description string) *orderedMap {
+	if _, ok := schema.Get("$ref"); ok {
+		return newOrderedMap().Set("allOf", []interface{}{schema}).Set("description", description)
+	}
+	return schema.Set("description", description)
+}
+
+func jsonContent(schema *orderedMap) *orderedMap {
+	return newOrderedMap().Set("application/json", newOrderedMap().Set("schema", schema))
+}
+
+/*
+ * openAPIPath converts gin parameters (:id, *path) to {id} and returns
+ * the parameter names.
+ */
+func openAPIPath(path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+/*
+ * operationID derives an identifier from the handler name: handleLogin
+ * becomes login and UserHandler.List becomes listUser. Routes without a
+ * named handler use the method and path.
+ */
+func operationID(route routes.Route) string {
+	if route.Handler == "" {
+		var parts []string
+		parts = append(parts, strings.ToLower(route.Method))
+		for _, segment := range strings.Split(route.Path, "/") {
+			segment = strings.TrimLeft(segment, ":*")
+			if segment != "" {
+				parts = append(parts, exportName(identifier(segment)))
+			}
+		}
+		return strings.Join(parts, "")
+	}
+
+	recv, name, isMethod := strings.Cut(route.Handler, ".")
+	if !isMethod {
+		name, recv = recv, ""
+	}
+	if trimmed := strings.TrimPrefix(name, "handle"); trimmed != name && trimmed != "" {
+		name = trimmed
+	}
+	id := strings.ToLower(name[:1]) + name[1:]
+	if strings.HasSuffix(recv, "Handler") && recv != "Handler" {
+		id += strings.TrimSuffix(recv, "Handler")
+	}
+	return id
+}
+
+func identifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isIdentRune(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+/*
+ * componentName names a generic instantiation:
+ * PaginatedResponse[[]User] becomes PaginatedResponseUserList.
+ */
+func componentName(goType string) string {
+	goType = strings.TrimSpace(goType)
+	switch {
+	case strings.HasPrefix(goType, "*"):
+		return componentName(goType[1:])
+	case strings.HasPrefix(goType, "[]"):
+		return componentName(goType[2:]) + "List"
+	case goType == "interface{}" || goType == "any":
+		return "Any"
+	}
+	if _, value, ok := splitMapType(goType); ok {
+		return componentName(value) + "Map"
+	}
+	if name, args, ok := splitGenericType(goType); ok {
+		for _, arg := range args {
+			name += componentName(arg)
+		}
+		return name
+	}
+	if i := strings.LastIndexAny(goType, "./"); i >= 0 {
+		goType = goType[i+1:]
+	}
+	return exportName(identifier(goType))
+}
+
+/*
+ * substituteTypeParams replaces type parameter identifiers in a Go type
+ * string with their arguments.
+ */
+func substituteTypeParams(goType string, subst map[string]string) string {
+	if len(subst) == 0 {
+		return goType
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(goType); {
+		if !isIdentRune(rune(goType[i])) {
+			sb.WriteByte(goType[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(goType) && (isIdentRune(rune(goType[j])) || goType[j] == '.' || goType[j] == '/') {
+			j++
+		}
+		word := goType[i:j]
+		if replacement, ok := subst[word]; ok {
+			sb.WriteString(replacement)
+		} else {
+			sb.WriteString(word)
+		}
+		i = j
+	}
+	return sb.String()
+}
+
+func enumJSONValue(kind, value string) interface{} {
+	if kind == "integer" || kind == "number" {
+		if number, ok := jsonNumber(value); ok {
+			return number
+		}
+	}
+	if kind == "boolean" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func jsonNumber(s string) (json.Number, bool) {
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return "", false
+	}
+	return json.Number(s), true
+}
+
+/*
+ * docText strips the leading asterisks of block comment lines.
+ */
+func docText(doc string) string {
+	lines := strings.Split(strings.TrimSpace(doc), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "*" {
+			line = ""
+		}
+		lines[i] = strings.TrimPrefix(line, "* ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func statusDescription(status int) string {
+	if text := http.StatusText(status); text != "" {
+		return text
+	}
+	return "Status " + strconv.Itoa(status)
+}
@@ -0,0 +1,49 @@
+package codegen
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyConstraintsOneof(t *testing.T) {
+	schema := newOrderedMap().Set("type", "string")
+	applyConstraints(schema, parseValidateTag("oneof='in progress' done"))
+
+	got, _ := schema.Get("enum")
+	want := []interface{}{"in progress", "done"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("enum = %v, want %v", got, want)
+	}
+}
+
+func TestApplyConstraintsOmitempty(t *testing.T) {
+	tests := []struct {
+		name string
+		kind string
+		tag  string
+		want string
+	}{
+		{"email", "string", "omitempty,email", `{"anyOf":[{"type":"string","format":"email"},{"type":"string","enum":[""]}],"description":"d"}`},
+		{"min length", "string", "omitempty,min=1", `{"anyOf":[{"type":"string","minLength":1},{"type":"string","enum":[""]}],"description":"d"}`},
+		{"minimum", "integer", "omitempty,gte=1", `{"anyOf":[{"type":"integer","minimum":1},{"type":"integer","enum":[0]}],"description":"d"}`},
+		{"items", "array", "omitempty,min=1", `{"anyOf":[{"type":"array","minItems":1},{"type":"array","maxItems":0}],"description":"d"}`},
+		{"no checks", "string", "omitempty", `{"type":"string","description":"d"}`},
+		{"without omitempty", "string", "email", `{"type":"string","description":"d","format":"email"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := newOrderedMap().Set("type", tt.kind).Set("description", "d")
+			applyConstraints(schema, parseValidateTag(tt.tag))
+
+			got, err := json.Marshal(schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("applyConstraints(%q) = %s, want %s", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
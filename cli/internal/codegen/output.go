@@ -0,0 +1,218 @@
+/*
+ * GoAstra CLI - Generated Output
+ *
+ * Collects rendered files in memory so a typesync run can be written,
+ * checked against disk, or pruned as a unit. Each output directory gets
+ * a manifest of the files typesync generated there, which lets later
+ * runs delete outputs whose Go types no longer exist.
+ */
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/channdev/goastra/cli/internal/textdiff"
+)
+
+/*
+ * ManifestName is the manifest file written into each output directory.
+ */
+const ManifestName = ".typesync-manifest.json"
+
+/*
+ * Output holds rendered files keyed by absolute path.
+ */
+type Output struct {
+	files     map[string]string
+	manifests []string
+}
+
+/*
+ * manifest lists the files generated into a directory, relative to it.
+ */
+type manifest struct {
+	GeneratedBy string   `json:"generatedBy"`
+	Files       []string `json:"files"`
+}
+
+/*
+ * NewOutput creates an empty output set.
+ */
+func NewOutput() *Output {
+	return &Output{files: make(map[string]string)}
+}
+
+/*
+ * Add records a rendered file.
+ */
+func (o *Output) Add(path, content string) {
+	o.files[filepath.Clean(path)] = content
+}
+
+/*
+ * Paths returns the rendered file paths in sorted order.
+ */
+func (o *Output) Paths() []string {
+	paths := make([]string, 0, len(o.files))
+	for path := range o.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+/*
+ * AddManifest renders the manifest for dir, listing every file
+ * rendered beneath it. Call it after all generators have run.
+ */
+func (o *Output) AddManifest(dir string) error {
+	dir = filepath.Clean(dir)
+
+	m := manifest{GeneratedBy: "goastra typesync", Files: []string{}}
+	for _, path := range o.Paths() {
+		if rel, ok := relativeTo(dir, path); ok {
+			m.Files = append(m.Files, filepath.ToSlash(rel))
+		}
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, ManifestName)
+	o.Add(path, string(data)+"\n")
+	o.manifests = append(o.manifests, path)
+	return nil
+}
+
+/*
+ * Stale returns files listed in the manifests on disk that this output
+ * no longer renders and that still exist.
+ */
+func (o *Output) Stale() ([]string, error) {
+	var stale []string
+
+	for _, path := range o.manifests {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var previous manifest
+		if err := json.Unmarshal(data, &previous); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+		}
+
+		dir := filepath.Dir(path)
+		for _, file := range previous.Files {
+			full := filepath.Join(dir, filepath.FromSlash(file))
+			if _, ok := relativeTo(dir, full); !ok {
+				continue
+			}
+			if _, rendered := o.files[full]; rendered {
+				continue
+			}
+			if _, err := os.Stat(full); err == nil {
+				stale = append(stale, full)
+			}
+		}
+	}
+
+	sort.Strings(stale)
+	return stale, nil
+}
+
+/*
+ * Write writes every rendered file and removes stale ones.
+ * It returns the removed paths.
+ */
+func (o *Output) Write() ([]string, error) {
+	stale, err := o.Stale()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range o.Paths() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(o.files[path]), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return stale, nil
+}
+
+/*
+ * Check compares the rendered files with disk and returns a unified diff
+ * of the changes a write would make, including stale file removals.
+ * Paths in the diff are shown relative to base.
+ */
+func (o *Output) Check(base string) (string, error) {
+	stale, err := o.Stale()
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+
+	for _, path := range o.Paths() {
+		current, err := readIfExists(path)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(textdiff.Unified("a/"+displayPath(base, path), "b/"+displayPath(base, path), current, o.files[path]))
+	}
+
+	for _, path := range stale {
+		current, err := readIfExists(path)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(textdiff.Unified("a/"+displayPath(base, path), "/dev/null", current, ""))
+	}
+
+	return sb.String(), nil
+}
+
+func readIfExists(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return string(data), err
+}
+
+/*
+ * relativeTo returns path relative to dir if path lies beneath it.
+ */
+func relativeTo(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return rel, true
+}
+
+func displayPath(base, path string) string {
+	if rel, ok := relativeTo(base, path); ok {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
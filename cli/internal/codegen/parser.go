@@ -1,171 +1,339 @@
 /*
  * GoAstra CLI - Go Parser
  *
- * Parses Go source files to extract struct definitions
- * for TypeScript code generation.
+ * Loads the schema package with full type information and extracts
+ * struct and enum definitions for TypeScript code generation.
  */
 package codegen
 
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
-	"os"
+	"go/types"
 	"path/filepath"
-	"reflect"
+	"sort"
 	"strings"
+
+	"golang.org/x/tools/go/packages"
 )
 
 /*
  * GoParser extracts type definitions from Go source files.
  */
 type GoParser struct {
-	schemaPath string
+	schemaPaths []string
+	mappings    TypeMappings
 }
 
 /*
  * TypeDef represents a parsed Go type definition.
+ * Fields holds every field visible in the JSON encoding, including
+ * fields promoted from embedded structs, in encoding order.
+ * Package and File name the declaring package and source file;
+ * External is set for types pulled in from outside the schema packages.
  */
 type TypeDef struct {
-	Name   string
-	Fields []FieldDef
-	Doc    string
+	Name       string
+	TypeParams []TypeParam
+	Fields     []FieldDef
+	Embeds     []EmbedDef
+	Underlying string
+	Enum       []EnumValue
+	Doc        string
+	Package    string
+	File       string
+	External   bool
+}
+
+/*
+ * IsEnum reports whether the type is a named basic type with constants.
+ */
+func (t TypeDef) IsEnum() bool {
+	return t.Underlying != ""
+}
+
+/*
+ * TypeParam represents a generic type parameter and its constraint.
+ */
+type TypeParam struct {
+	Name       string
+	Constraint string
 }
 
 /*
  * FieldDef represents a struct field definition.
+ * EmbeddedFrom names the embedded struct a promoted field came from.
+ * TSType and Readonly come from a `ts:"Type,readonly"` struct tag and
+ * Validate holds the go-playground `validate` tag.
  */
 type FieldDef struct {
+	Name         string
+	Type         string
+	JSONName     string
+	Optional     bool
+	Doc          string
+	EmbeddedFrom string
+	TSType       string
+	Readonly     bool
+	Validate     string
+}
+
+/*
+ * EmbedDef represents an untagged embedded struct whose fields are promoted.
+ * Omit lists the JSON names of the embedded type hidden by shadowing.
+ */
+type EmbedDef struct {
 	Name     string
-	Type     string
-	JSONName string
-	Optional bool
-	Doc      string
+	TypeArgs []string
+	Pointer  bool
+	Omit     []string
 }
 
 /*
  * NewGoParser creates a new parser instance.
  */
 func NewGoParser(schemaPath string) *GoParser {
-	return &GoParser{schemaPath: schemaPath}
+	return &GoParser{schemaPaths: []string{schemaPath}}
 }
 
 /*
- * Parse reads all Go files in the schema path and extracts types.
+ * AddSchemaPath adds another schema package. Its types are emitted
+ * after those of the packages added before it.
  */
-func (p *GoParser) Parse() ([]TypeDef, error) {
-	var types []TypeDef
+func (p *GoParser) AddSchemaPath(schemaPath string) {
+	p.schemaPaths = append(p.schemaPaths, schemaPath)
+}
+
+/*
+ * SetTypeMappings registers project-specific type mappings. Mapped types
+ * are referenced by name instead of being expanded.
+ */
+func (p *GoParser) SetTypeMappings(mappings TypeMappings) {
+	p.mappings = mappings
+}
 
-	files, err := filepath.Glob(filepath.Join(p.schemaPath, "*.go"))
+/*
+ * Parse loads the schema packages with go/packages and extracts types.
+ * Every struct and enum declared in the packages is emitted in source
+ * order, followed by structs from other packages that they reference.
+ */
+func (p *GoParser) Parse() ([]TypeDef, error) {
+	pkgs, err := loadPackages(p.schemaPaths)
 	if err != nil {
-		return nil, fmt.Errorf("failed to glob schema files: %w", err)
+		return nil, err
 	}
 
-	fset := token.NewFileSet()
+	resolver := newTypeResolver(p.mappings)
+	for _, pkg := range pkgs {
+		resolver.addPackage(pkg)
+		resolver.setLocal(pkg.Types)
+	}
 
-	for _, file := range files {
-		fileDefs, err := p.parseFile(fset, file)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	for _, pkg := range pkgs {
+		for _, obj := range declaredTypes(pkg) {
+			if resolver.markers[obj] == markerIgnore {
+				continue
+			}
+			if err := resolver.declare(obj); err != nil {
+				return nil, err
+			}
 		}
-		types = append(types, fileDefs...)
 	}
 
-	return types, nil
+	return resolver.definitions()
 }
 
-func (p *GoParser) parseFile(fset *token.FileSet, filename string) ([]TypeDef, error) {
-	src, err := os.ReadFile(filename)
+/*
+ * loadPackage type-checks the Go package in dir.
+ * Load and type errors are reported together as a single error.
+ */
+func loadPackage(dir string) (*packages.Package, error) {
+	pkgs, err := loadPackages([]string{dir})
 	if err != nil {
 		return nil, err
 	}
+	return pkgs[0], nil
+}
 
-	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
-	if err != nil {
-		return nil, err
+/*
+ * loadPackages type-checks the Go packages in dirs in a single load,
+ * so types shared between them resolve to the same objects. The
+ * directories must belong to the same module as the first one.
+ */
+func loadPackages(dirs []string) ([]*packages.Package, error) {
+	absDirs := make([]string, len(dirs))
+	for i, dir := range dirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema path: %w", err)
+		}
+		absDirs[i] = absDir
 	}
 
-	var types []TypeDef
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Dir: absDirs[0],
+	}
 
-	for _, decl := range f.Decls {
-		genDecl, ok := decl.(*ast.GenDecl)
-		if !ok || genDecl.Tok != token.TYPE {
-			continue
+	patterns := make([]string, len(absDirs))
+	for i, absDir := range absDirs {
+		rel, err := filepath.Rel(absDirs[0], absDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema path: %w", err)
+		}
+		patterns[i] = "./" + filepath.ToSlash(rel)
+		if rel == "." {
+			patterns[i] = "."
 		}
+	}
 
-		for _, spec := range genDecl.Specs {
-			typeSpec, ok := spec.(*ast.TypeSpec)
-			if !ok {
-				continue
-			}
+	loaded, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema package: %w", err)
+	}
 
-			structType, ok := typeSpec.Type.(*ast.StructType)
-			if !ok {
-				continue
+	for _, pkg := range loaded {
+		if len(pkg.Errors) > 0 {
+			var msgs []string
+			for _, e := range pkg.Errors {
+				msgs = append(msgs, e.Error())
 			}
+			return nil, fmt.Errorf("failed to type-check schema package:\n  %s", strings.Join(msgs, "\n  "))
+		}
+	}
 
-			typeDef := TypeDef{
-				Name: typeSpec.Name.Name,
+	/* Match packages back to their directories to keep the given order */
+	pkgs := make([]*packages.Package, len(absDirs))
+	for _, pkg := range loaded {
+		if len(pkg.GoFiles) == 0 {
+			continue
+		}
+		dir := filepath.Dir(pkg.GoFiles[0])
+		for i, absDir := range absDirs {
+			if dir == absDir {
+				pkgs[i] = pkg
 			}
+		}
+	}
+	for i, pkg := range pkgs {
+		if pkg == nil {
+			return nil, fmt.Errorf("expected one package in %s, found none", dirs[i])
+		}
+	}
 
-			if genDecl.Doc != nil {
-				typeDef.Doc = genDecl.Doc.Text()
-			}
+	return pkgs, nil
+}
 
-			for _, field := range structType.Fields.List {
-				if len(field.Names) == 0 {
-					continue
-				}
+/*
+ * declaredTypes returns the package's type declarations in source order,
+ * with files sorted by name for stable output.
+ */
+func declaredTypes(pkg *packages.Package) []*types.TypeName {
+	files := append([]*ast.File(nil), pkg.Syntax...)
+	sort.Slice(files, func(i, j int) bool {
+		return pkg.Fset.File(files[i].Pos()).Name() < pkg.Fset.File(files[j].Pos()).Name()
+	})
 
-				fieldDef := FieldDef{
-					Name: field.Names[0].Name,
-					Type: p.typeToString(field.Type),
+	var objs []*types.TypeName
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if obj, ok := pkg.TypesInfo.Defs[typeSpec.Name].(*types.TypeName); ok {
+					objs = append(objs, obj)
 				}
+			}
+		}
+	}
+
+	return objs
+}
 
-				if field.Tag != nil {
-					jsonTag := p.extractJSONTag(field.Tag.Value)
-					if jsonTag != "" && jsonTag != "-" {
-						fieldDef.JSONName = strings.Split(jsonTag, ",")[0]
-						fieldDef.Optional = strings.Contains(jsonTag, "omitempty")
+/*
+ * collectDocs indexes type, field and constant documentation by
+ * declaration position and records typesync markers for declared types.
+ */
+func collectDocs(pkg *packages.Package) (map[token.Pos]string, map[*types.TypeName]string) {
+	docs := make(map[token.Pos]string)
+	markers := make(map[*types.TypeName]string)
+
+	for _, file := range pkg.Syntax {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.GenDecl:
+				for _, spec := range node.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						doc := spec.Doc
+						if doc == nil && len(node.Specs) == 1 {
+							doc = node.Doc
+						}
+						if doc != nil {
+							docs[spec.Name.Pos()] = doc.Text()
+						}
+						if obj, ok := pkg.TypesInfo.Defs[spec.Name].(*types.TypeName); ok {
+							if marker := typeMarker(doc, spec.Comment); marker != "" {
+								markers[obj] = marker
+							}
+						}
+					case *ast.ValueSpec:
+						doc := spec.Doc
+						if doc == nil {
+							doc = spec.Comment
+						}
+						if doc != nil {
+							for _, name := range spec.Names {
+								docs[name.Pos()] = doc.Text()
+							}
+						}
 					}
 				}
-
-				if field.Doc != nil {
-					fieldDef.Doc = field.Doc.Text()
+			case *ast.Field:
+				doc := node.Doc
+				if doc == nil {
+					doc = node.Comment
+				}
+				if doc == nil {
+					return true
+				}
+				for _, name := range node.Names {
+					docs[name.Pos()] = doc.Text()
+				}
+				if len(node.Names) == 0 {
+					docs[embeddedPos(node.Type)] = doc.Text()
 				}
-
-				typeDef.Fields = append(typeDef.Fields, fieldDef)
 			}
-
-			types = append(types, typeDef)
-		}
+			return true
+		})
 	}
 
-	return types, nil
+	return docs, markers
 }
 
-func (p *GoParser) typeToString(expr ast.Expr) string {
+/*
+ * embeddedPos returns the position go/types records for an embedded field,
+ * which is the position of the type name itself.
+ */
+func embeddedPos(expr ast.Expr) token.Pos {
 	switch t := expr.(type) {
-	case *ast.Ident:
-		return t.Name
-	case *ast.SelectorExpr:
-		return fmt.Sprintf("%s.%s", p.typeToString(t.X), t.Sel.Name)
 	case *ast.StarExpr:
-		return "*" + p.typeToString(t.X)
-	case *ast.ArrayType:
-		return "[]" + p.typeToString(t.Elt)
-	case *ast.MapType:
-		return fmt.Sprintf("map[%s]%s", p.typeToString(t.Key), p.typeToString(t.Value))
-	case *ast.InterfaceType:
-		return "interface{}"
-	default:
-		return "unknown"
+		return embeddedPos(t.X)
+	case *ast.IndexExpr:
+		return embeddedPos(t.X)
+	case *ast.IndexListExpr:
+		return embeddedPos(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Pos()
 	}
+	return expr.Pos()
 }
 
-func (p *GoParser) extractJSONTag(tag string) string {
-	tag = strings.Trim(tag, "`")
-	structTag := reflect.StructTag(tag)
-	return structTag.Get("json")
+func isIdentRune(r rune) bool {
+	return r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
 }
@@ -0,0 +1,940 @@
+/*
+ * GoAstra CLI - Type Resolver
+ *
+ * Converts go/types types into the Go type strings consumed by the
+ * generators. Named types are followed to their underlying kinds,
+ * json.Marshaler and encoding.TextMarshaler are honoured, and structs
+ * are expanded with encoding/json field visibility rules.
+ */
+package codegen
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+/*
+ * typeResolver resolves types relative to optional local packages.
+ * Local types keep their names; structs from other packages are pulled
+ * in on demand and renamed if they collide. With externalEnums, named
+ * basic types from other packages that have constants become enums too.
+ */
+type typeResolver struct {
+	local         map[*types.Package]bool
+	mappings      TypeMappings
+	docs          map[token.Pos]string
+	markers       map[*types.TypeName]string
+	enums         map[*types.TypeName][]*types.Const
+	untyped       []*types.Const
+	scanned       map[*types.Package]bool
+	externalEnums bool
+	reserved      map[string]bool
+	used          map[string]bool
+	entries       []*typeEntry
+	byObj         map[*types.TypeName]*typeEntry
+	building      *typeEntry
+	fset          *token.FileSet
+}
+
+/*
+ * typeEntry is a type scheduled for output. Anonymous structs hoisted
+ * out of fields have no object and belong to the entry that declared them.
+ */
+type typeEntry struct {
+	name   string
+	obj    *types.TypeName
+	st     *types.Struct
+	def    *TypeDef
+	parent *typeEntry
+	filled bool
+}
+
+/*
+ * fieldCandidate is a field reachable from a struct, with the index path
+ * used by Go's JSON encoder to resolve shadowing and ordering.
+ */
+type fieldCandidate struct {
+	def      FieldDef
+	key      string
+	tagged   bool
+	pointer  bool
+	required bool
+	index    []int
+}
+
+var (
+	jsonMarshalerType = newMarshalerInterface("MarshalJSON")
+	textMarshalerType = newMarshalerInterface("MarshalText")
+)
+
+func newTypeResolver(mappings TypeMappings) *typeResolver {
+	return &typeResolver{
+		mappings: mappings,
+		local:    make(map[*types.Package]bool),
+		docs:     make(map[token.Pos]string),
+		markers:  make(map[*types.TypeName]string),
+		enums:    make(map[*types.TypeName][]*types.Const),
+		scanned:  make(map[*types.Package]bool),
+		reserved: make(map[string]bool),
+		used:     make(map[string]bool),
+		byObj:    make(map[*types.TypeName]*typeEntry),
+	}
+}
+
+/*
+ * addPackage indexes the documentation, markers and constants of a
+ * package loaded with syntax.
+ */
+func (r *typeResolver) addPackage(pkg *packages.Package) {
+	docs, markers := collectDocs(pkg)
+	for pos, doc := range docs {
+		r.docs[pos] = doc
+	}
+	for obj, marker := range markers {
+		r.markers[obj] = marker
+	}
+	r.fset = pkg.Fset
+	r.scanPackage(pkg.Types)
+}
+
+/*
+ * setLocal makes pkg a local package: its type names are reserved,
+ * and its untyped constants may join `typesync:enum` types.
+ */
+func (r *typeResolver) setLocal(pkg *types.Package) {
+	r.local[pkg] = true
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		switch obj := scope.Lookup(name).(type) {
+		case *types.TypeName:
+			r.reserved[name] = true
+		case *types.Const:
+			if basic, ok := obj.Type().(*types.Basic); ok && basic.Info()&types.IsUntyped != 0 {
+				r.untyped = append(r.untyped, obj)
+			}
+		}
+	}
+	sort.Slice(r.untyped, func(i, j int) bool { return r.untyped[i].Pos() < r.untyped[j].Pos() })
+}
+
+/*
+ * scanPackage collects the typed constants of a package once, so named
+ * basic types with constants resolve to enums.
+ */
+func (r *typeResolver) scanPackage(pkg *types.Package) {
+	if pkg == nil || r.scanned[pkg] {
+		return
+	}
+	r.scanned[pkg] = true
+
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok {
+			continue
+		}
+		if named, ok := c.Type().(*types.Named); ok && named.Obj().Pkg() == pkg {
+			r.enums[named.Obj()] = append(r.enums[named.Obj()], c)
+		}
+	}
+
+	for obj, consts := range r.enums {
+		if obj.Pkg() == pkg {
+			sort.Slice(consts, func(i, j int) bool { return consts[i].Pos() < consts[j].Pos() })
+		}
+	}
+}
+
+/*
+ * declare schedules a type declared in the local package for output.
+ * Structs and enums are emitted; other named types are resolved to
+ * their underlying type wherever they are used.
+ */
+func (r *typeResolver) declare(obj *types.TypeName) error {
+	if obj.IsAlias() {
+		return nil
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok || r.mappingKey(obj) != "" || r.customJSON(named) != "" {
+		return nil
+	}
+
+	if basic, ok := named.Underlying().(*types.Basic); ok {
+		values := r.enumValues(obj)
+		if len(values) == 0 {
+			return nil
+		}
+		entry := r.newEntry(r.localName(obj), obj, nil)
+		entry.def = &TypeDef{
+			Name:       entry.name,
+			Underlying: basic.Name(),
+			Enum:       values,
+			Doc:        r.docs[obj.Pos()],
+		}
+		r.setSource(entry.def, obj)
+		entry.filled = true
+		return nil
+	}
+
+	if st, ok := named.Underlying().(*types.Struct); ok {
+		r.register(obj, st)
+	}
+
+	return nil
+}
+
+/*
+ * definitions builds every scheduled type, including types pulled in
+ * while building others, and returns them in scheduling order.
+ */
+func (r *typeResolver) definitions() ([]TypeDef, error) {
+	for i := 0; i < len(r.entries); i++ {
+		entry := r.entries[i]
+		if entry.filled {
+			continue
+		}
+		r.building = entry
+		def, err := r.buildStruct(entry)
+		if err != nil {
+			return nil, err
+		}
+		entry.def = def
+		entry.filled = true
+	}
+
+	defs := make([]TypeDef, len(r.entries))
+	for i, entry := range r.entries {
+		defs[i] = *entry.def
+	}
+	return defs, nil
+}
+
+/*
+ * resolve converts a type to its Go type string. The hint names
+ * anonymous structs that have to be hoisted into their own definition.
+ */
+func (r *typeResolver) resolve(t types.Type, hint string) (string, error) {
+	switch t := t.(type) {
+	case *types.Basic:
+		return resolveBasic(t)
+	case *types.Pointer:
+		elem, err := r.resolve(t.Elem(), hint)
+		if err != nil {
+			return "", err
+		}
+		return "*" + elem, nil
+	case *types.Slice:
+		if isByte(t.Elem()) {
+			return "string", nil
+		}
+		elem, err := r.resolve(t.Elem(), hint)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case *types.Array:
+		elem, err := r.resolve(t.Elem(), hint)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case *types.Map:
+		key, err := r.resolveMapKey(t.Key())
+		if err != nil {
+			return "", err
+		}
+		value, err := r.resolve(t.Elem(), hint)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("map[%s]%s", key, value), nil
+	case *types.Struct:
+		return r.hoist(t, hint), nil
+	case *types.Interface:
+		return "interface{}", nil
+	case *types.TypeParam:
+		return t.Obj().Name(), nil
+	case *types.Named:
+		return r.resolveNamed(t, hint)
+	case *types.Alias:
+		if key := r.mappingKey(t.Obj()); key != "" {
+			return key, nil
+		}
+		return r.resolve(types.Unalias(t), hint)
+	case *types.Chan, *types.Signature:
+		return "", fmt.Errorf("unsupported type %s (add `json:\"-\"` to skip it)", t)
+	}
+
+	if u := t.Underlying(); u != t {
+		return r.resolve(u, hint)
+	}
+	return "", fmt.Errorf("unsupported type %s", t)
+}
+
+func (r *typeResolver) resolveNamed(t *types.Named, hint string) (string, error) {
+	obj := t.Obj()
+
+	if key := r.mappingKey(obj); key != "" {
+		return key, nil
+	}
+
+	if custom := r.customJSON(t); custom != "" {
+		return custom, nil
+	}
+
+	if entry := r.byObj[obj]; entry != nil && entry.def != nil && entry.def.IsEnum() {
+		return entry.name, nil
+	}
+
+	/* Named basic types from other packages become enums if they have constants */
+	if basic, ok := t.Underlying().(*types.Basic); ok && r.externalEnums && !r.local[obj.Pkg()] && r.byObj[obj] == nil {
+		r.scanPackage(obj.Pkg())
+		if values := r.enumValues(obj); len(values) > 0 {
+			entry := r.newEntry(r.externalName(obj), obj, nil)
+			entry.def = &TypeDef{
+				Name:       entry.name,
+				Underlying: basic.Name(),
+				Enum:       values,
+				Doc:        r.docs[obj.Pos()],
+			}
+			r.setSource(entry.def, obj)
+			entry.filled = true
+			return entry.name, nil
+		}
+	}
+
+	if _, ok := t.Underlying().(*types.Struct); ok {
+		origin := t.Origin()
+		name := r.register(origin.Obj(), origin.Underlying().(*types.Struct))
+		if t.TypeArgs().Len() == 0 {
+			return name, nil
+		}
+		args := make([]string, t.TypeArgs().Len())
+		for i := range args {
+			arg, err := r.resolve(t.TypeArgs().At(i), hint)
+			if err != nil {
+				return "", err
+			}
+			args[i] = arg
+		}
+		return fmt.Sprintf("%s[%s]", name, strings.Join(args, ", ")), nil
+	}
+
+	return r.resolve(t.Underlying(), hint)
+}
+
+/*
+ * resolveMapKey resolves a map key following encoding/json: string and
+ * integer keys are used directly and TextMarshaler keys become strings.
+ */
+func (r *typeResolver) resolveMapKey(t types.Type) (string, error) {
+	if named, ok := t.(*types.Named); ok && implements(named, textMarshalerType) {
+		return "string", nil
+	}
+	if basic, ok := t.Underlying().(*types.Basic); ok {
+		if basic.Info()&(types.IsString|types.IsInteger) != 0 {
+			return r.resolve(t, "")
+		}
+	}
+	return "", fmt.Errorf("unsupported map key type %s", t)
+}
+
+/*
+ * customJSON returns the type string for types that control their own
+ * encoding: json.Marshaler output is unknown, TextMarshaler is a string.
+ */
+func (r *typeResolver) customJSON(t *types.Named) string {
+	if implements(t, jsonMarshalerType) {
+		return "interface{}"
+	}
+	if implements(t, textMarshalerType) {
+		return "string"
+	}
+	return ""
+}
+
+/*
+ * mappingKey returns the mapping key for a mapped type, if any. Project
+ * mappings may name a type by import path, package name or, for schema
+ * types, bare name; the first key found is returned.
+ */
+func (r *typeResolver) mappingKey(obj *types.TypeName) string {
+	if obj.Pkg() == nil {
+		return ""
+	}
+
+	keys := []string{
+		obj.Pkg().Path() + "." + obj.Name(),
+		obj.Pkg().Name() + "." + obj.Name(),
+	}
+	if r.local[obj.Pkg()] {
+		keys = append(keys, obj.Name())
+	}
+
+	for _, key := range keys {
+		if _, ok := r.mappings[key]; ok {
+			return key
+		}
+	}
+	if _, ok := wellKnownTypes[keys[0]]; ok {
+		return keys[0]
+	}
+	return ""
+}
+
+/*
+ * register schedules a named struct and returns its output name.
+ * Types from other packages are prefixed with their package name
+ * when the plain name is already taken.
+ */
+func (r *typeResolver) register(obj *types.TypeName, st *types.Struct) string {
+	if entry := r.byObj[obj]; entry != nil {
+		return entry.name
+	}
+
+	name := r.localName(obj)
+	if !r.local[obj.Pkg()] {
+		name = r.externalName(obj)
+	}
+
+	return r.newEntry(name, obj, st).name
+}
+
+/*
+ * localName names a type from a local package, prefixing the package
+ * name when another local package already declared the plain name.
+ */
+func (r *typeResolver) localName(obj *types.TypeName) string {
+	name := obj.Name()
+	if r.used[name] {
+		name = exportName(obj.Pkg().Name()) + name
+	}
+	return name
+}
+
+/*
+ * setSource records the package and file declaring obj, which the
+ * generators use to lay out modules.
+ */
+func (r *typeResolver) setSource(def *TypeDef, obj *types.TypeName) {
+	if obj.Pkg() != nil {
+		def.Package = obj.Pkg().Name()
+		def.External = !r.local[obj.Pkg()]
+	}
+	if r.fset == nil {
+		return
+	}
+	if file := r.fset.File(obj.Pos()); file != nil {
+		def.File = filepath.Base(file.Name())
+	}
+}
+
+/*
+ * externalName names a type from another package, prefixing the
+ * package name when the plain name is already taken.
+ */
+func (r *typeResolver) externalName(obj *types.TypeName) string {
+	name := obj.Name()
+	if r.reserved[name] || r.used[name] {
+		name = exportName(obj.Pkg().Name()) + name
+	}
+	return name
+}
+
+/*
+ * hoist schedules an anonymous struct as a named definition.
+ */
+func (r *typeResolver) hoist(st *types.Struct, hint string) string {
+	for _, entry := range r.entries {
+		if entry.obj == nil && entry.st == st {
+			return entry.name
+		}
+	}
+	if hint == "" {
+		hint = "Anonymous"
+	}
+	entry := r.newEntry(hint, nil, st)
+	entry.parent = r.building
+	return entry.name
+}
+
+func (r *typeResolver) newEntry(name string, obj *types.TypeName, st *types.Struct) *typeEntry {
+	unique := name
+	for i := 2; r.used[unique] || (obj == nil && r.reserved[unique]); i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	r.used[unique] = true
+
+	entry := &typeEntry{name: unique, obj: obj, st: st}
+	r.entries = append(r.entries, entry)
+	if obj != nil {
+		r.byObj[obj] = entry
+	}
+	return entry
+}
+
+/*
+ * buildStruct resolves the fields and embeds of a scheduled struct.
+ */
+func (r *typeResolver) buildStruct(entry *typeEntry) (*TypeDef, error) {
+	def := &TypeDef{Name: entry.name}
+
+	if entry.parent != nil && entry.parent.def != nil {
+		def.Package = entry.parent.def.Package
+		def.File = entry.parent.def.File
+		def.External = entry.parent.def.External
+	}
+
+	if entry.obj != nil {
+		r.setSource(def, entry.obj)
+		def.Doc = r.docs[entry.obj.Pos()]
+		if named, ok := entry.obj.Type().(*types.Named); ok {
+			params := named.TypeParams()
+			for i := 0; i < params.Len(); i++ {
+				param := params.At(i)
+				def.TypeParams = append(def.TypeParams, TypeParam{
+					Name:       param.Obj().Name(),
+					Constraint: r.constraintString(param.Constraint()),
+				})
+			}
+		}
+	}
+
+	visible, err := r.visibleFields(entry.st, entry.name)
+	if err != nil {
+		return nil, err
+	}
+
+	embedNames := make(map[int]string)
+	for i := 0; i < entry.st.NumFields(); i++ {
+		field := entry.st.Field(i)
+		named, pointer, ok := promotedEmbed(field, entry.st.Tag(i))
+		if !ok {
+			continue
+		}
+
+		/* Mapped types have no generated interface; inline their fields */
+		origin := named.Origin()
+		if r.mappingKey(origin.Obj()) != "" {
+			continue
+		}
+
+		embed := EmbedDef{
+			Name:    r.register(origin.Obj(), origin.Underlying().(*types.Struct)),
+			Pointer: pointer,
+		}
+		embedNames[i] = embed.Name
+
+		for j := 0; j < named.TypeArgs().Len(); j++ {
+			arg, err := r.resolve(named.TypeArgs().At(j), entry.name+field.Name())
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", entry.name, field.Name(), err)
+			}
+			embed.TypeArgs = append(embed.TypeArgs, arg)
+		}
+
+		shown := make(map[string]bool)
+		for _, c := range visible {
+			if len(c.index) > 1 && c.index[0] == i {
+				shown[c.key] = true
+			}
+		}
+
+		inner, err := r.visibleFields(named.Underlying().(*types.Struct), embed.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range inner {
+			if !shown[c.key] {
+				embed.Omit = append(embed.Omit, c.key)
+			}
+		}
+
+		def.Embeds = append(def.Embeds, embed)
+	}
+
+	for _, c := range visible {
+		field := c.def
+		if len(c.index) > 1 {
+			field.EmbeddedFrom = embedNames[c.index[0]]
+			if c.pointer && !c.required {
+				field.Optional = true
+			}
+		}
+		def.Fields = append(def.Fields, field)
+	}
+
+	return def, nil
+}
+
+/*
+ * visibleFields walks a struct and its embedded structs breadth-first and
+ * applies encoding/json dominance rules: the shallowest field wins, a tagged
+ * field beats untagged ones at the same depth, and remaining ties are dropped.
+ */
+func (r *typeResolver) visibleFields(st *types.Struct, owner string) ([]fieldCandidate, error) {
+	type level struct {
+		st      *types.Struct
+		index   []int
+		pointer bool
+	}
+
+	var candidates []fieldCandidate
+	visited := make(map[*types.Struct]bool)
+	current := []level{{st: st}}
+
+	for len(current) > 0 {
+		var next []level
+		seen := make(map[*types.Struct]bool)
+
+		for _, lvl := range current {
+			if visited[lvl.st] {
+				continue
+			}
+			seen[lvl.st] = true
+
+			for i := 0; i < lvl.st.NumFields(); i++ {
+				field := lvl.st.Field(i)
+				tagName, opts, skip := parseJSONTag(lvl.st.Tag(i))
+				if skip {
+					continue
+				}
+				index := append(append([]int(nil), lvl.index...), i)
+
+				if named, pointer, ok := promotedEmbed(field, lvl.st.Tag(i)); ok {
+					next = append(next, level{
+						st:      named.Underlying().(*types.Struct),
+						index:   index,
+						pointer: lvl.pointer || pointer,
+					})
+					continue
+				}
+				if !field.Exported() {
+					continue
+				}
+
+				c, err := r.newCandidate(field, lvl.st.Tag(i), tagName, opts, owner)
+				if err != nil {
+					return nil, err
+				}
+				c.index = index
+				c.pointer = lvl.pointer
+				candidates = append(candidates, c)
+			}
+		}
+
+		for s := range seen {
+			visited[s] = true
+		}
+		current = next
+	}
+
+	return dominantFields(candidates), nil
+}
+
+func (r *typeResolver) newCandidate(field *types.Var, tag, tagName, opts, owner string) (fieldCandidate, error) {
+	goType, err := r.resolve(field.Type(), owner+exportName(field.Name()))
+	if err != nil {
+		return fieldCandidate{}, fmt.Errorf("%s.%s: %w", owner, field.Name(), err)
+	}
+
+	/* The ",string" option quotes numbers and booleans */
+	if hasTagOption(opts, "string") {
+		base := strings.TrimPrefix(goType, "*")
+		if basic, ok := types.Universe.Lookup(base).(*types.TypeName); ok {
+			if b, ok := basic.Type().(*types.Basic); ok && b.Info()&(types.IsNumeric|types.IsBoolean) != 0 {
+				goType = strings.TrimSuffix(goType, base) + "string"
+			}
+		}
+	}
+
+	def := FieldDef{
+		Name:     field.Name(),
+		Type:     goType,
+		JSONName: tagName,
+		Optional: hasTagOption(opts, "omitempty"),
+		Doc:      r.docs[field.Pos()],
+		Validate: reflect.StructTag(tag).Get("validate"),
+	}
+
+	required, err := applyTSTag(&def, reflect.StructTag(tag).Get("ts"))
+	if err != nil {
+		return fieldCandidate{}, fmt.Errorf("%s.%s: %w", owner, field.Name(), err)
+	}
+
+	key := tagName
+	if key == "" {
+		key = toCamelCaseTS(field.Name())
+	}
+
+	return fieldCandidate{def: def, key: key, tagged: tagName != "", required: required}, nil
+}
+
+/*
+ * applyTSTag applies a `ts:"Type,options"` struct tag to a field. The type
+ * replaces the generated TypeScript type; options are readonly, optional,
+ * required, nullable and nonnull. It reports whether the field was
+ * explicitly marked required.
+ */
+func applyTSTag(def *FieldDef, tag string) (bool, error) {
+	if tag == "" {
+		return false, nil
+	}
+
+	parts := splitTopLevel(tag)
+	def.TSType = parts[0]
+
+	required := false
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "":
+		case "readonly":
+			def.Readonly = true
+		case "optional":
+			def.Optional = true
+		case "required":
+			def.Optional = false
+			required = true
+		case "nullable":
+			if def.TSType != "" {
+				if !strings.HasSuffix(def.TSType, " | null") {
+					def.TSType += " | null"
+				}
+			} else if !strings.HasPrefix(def.Type, "*") {
+				def.Type = "*" + def.Type
+			}
+		case "nonnull":
+			def.TSType = strings.TrimSuffix(def.TSType, " | null")
+			def.Type = strings.TrimPrefix(def.Type, "*")
+		default:
+			return false, fmt.Errorf("unknown ts tag option %q", opt)
+		}
+	}
+
+	return required, nil
+}
+
+/*
+ * constraintString renders a type parameter constraint. Only unions of
+ * basic types are kept; other constraints render as "any".
+ */
+func (r *typeResolver) constraintString(t types.Type) string {
+	iface, ok := t.Underlying().(*types.Interface)
+	if !ok || iface.Empty() {
+		return "any"
+	}
+
+	var terms []string
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		switch embedded := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			for j := 0; j < embedded.Len(); j++ {
+				term := embedded.Term(j)
+				name, err := r.resolve(term.Type(), "")
+				if err != nil {
+					return "any"
+				}
+				if term.Tilde() {
+					name = "~" + name
+				}
+				terms = append(terms, name)
+			}
+		case *types.Basic:
+			terms = append(terms, embedded.Name())
+		default:
+			return "any"
+		}
+	}
+
+	if len(terms) == 0 {
+		return "any"
+	}
+	return strings.Join(terms, " | ")
+}
+
+/*
+ * enumValues returns the constants of a named basic type. A type marked
+ * `typesync:enum` also collects untyped constants prefixed with its name,
+ * and a type marked `typesync:ignore` has none.
+ */
+func (r *typeResolver) enumValues(obj *types.TypeName) []EnumValue {
+	if r.markers[obj] == markerIgnore {
+		return nil
+	}
+
+	consts := r.enums[obj]
+	if r.markers[obj] == markerEnum {
+		for _, c := range r.untyped {
+			if c.Pkg() == obj.Pkg() && strings.HasPrefix(c.Name(), obj.Name()) {
+				consts = append(consts, c)
+			}
+		}
+	}
+
+	var values []EnumValue
+	seen := make(map[string]bool)
+	for _, c := range consts {
+		if c.Name() == "_" {
+			continue
+		}
+		value, ok := constValueString(c.Val())
+		if !ok || seen[value] {
+			continue
+		}
+		seen[value] = true
+		values = append(values, EnumValue{Name: c.Name(), Value: value, Doc: r.docs[c.Pos()]})
+	}
+
+	return values
+}
+
+func constValueString(value constant.Value) (string, bool) {
+	switch value.Kind() {
+	case constant.String:
+		return constant.StringVal(value), true
+	case constant.Int:
+		return value.ExactString(), true
+	case constant.Float:
+		f, _ := constant.Float64Val(value)
+		return strconv.FormatFloat(f, 'g', -1, 64), true
+	}
+	return "", false
+}
+
+func resolveBasic(t *types.Basic) (string, error) {
+	info := t.Info()
+	switch {
+	case info&types.IsUntyped != 0, info&types.IsComplex != 0, t.Kind() == types.UnsafePointer:
+		return "", fmt.Errorf("unsupported type %s", t)
+	case t.Kind() == types.Uintptr:
+		return "uint64", nil
+	}
+	return t.Name(), nil
+}
+
+/*
+ * promotedEmbed reports whether a struct field is an untagged embedded
+ * struct whose fields are promoted into the outer JSON object.
+ */
+func promotedEmbed(field *types.Var, tag string) (*types.Named, bool, bool) {
+	if !field.Embedded() {
+		return nil, false, false
+	}
+	if tagName, _, skip := parseJSONTag(tag); skip || tagName != "" {
+		return nil, false, false
+	}
+
+	t := field.Type()
+	pointer := false
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+		pointer = true
+	}
+
+	named, ok := t.(*types.Named)
+	if !ok {
+		return nil, false, false
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil, false, false
+	}
+	return named, pointer, true
+}
+
+func dominantFields(candidates []fieldCandidate) []fieldCandidate {
+	byKey := make(map[string][]fieldCandidate)
+	var keys []string
+	for _, c := range candidates {
+		if _, ok := byKey[c.key]; !ok {
+			keys = append(keys, c.key)
+		}
+		byKey[c.key] = append(byKey[c.key], c)
+	}
+
+	var result []fieldCandidate
+	for _, key := range keys {
+		group := byKey[key]
+		sort.SliceStable(group, func(i, j int) bool {
+			if len(group[i].index) != len(group[j].index) {
+				return len(group[i].index) < len(group[j].index)
+			}
+			return group[i].tagged && !group[j].tagged
+		})
+
+		if len(group) > 1 && len(group[0].index) == len(group[1].index) && group[0].tagged == group[1].tagged {
+			continue
+		}
+		result = append(result, group[0])
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].index, result[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	return result
+}
+
+func parseJSONTag(tag string) (name, opts string, skip bool) {
+	value := reflect.StructTag(tag).Get("json")
+	if value == "-" {
+		return "", "", true
+	}
+	name, opts, _ = strings.Cut(value, ",")
+	return name, opts, false
+}
+
+func hasTagOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * implements reports whether a named type or its pointer implements iface.
+ */
+func implements(t *types.Named, iface *types.Interface) bool {
+	return types.Implements(t, iface) || types.Implements(types.NewPointer(t), iface)
+}
+
+/*
+ * newMarshalerInterface builds interface{ <method>() ([]byte, error) }.
+ */
+func newMarshalerInterface(method string) *types.Interface {
+	results := types.NewTuple(
+		types.NewVar(token.NoPos, nil, "", types.NewSlice(types.Typ[types.Byte])),
+		types.NewVar(token.NoPos, nil, "", types.Universe.Lookup("error").Type()),
+	)
+	sig := types.NewSignatureType(nil, nil, nil, nil, results, false)
+	return types.NewInterfaceType([]*types.Func{types.NewFunc(token.NoPos, nil, method, sig)}, nil).Complete()
+}
+
+func isByte(t types.Type) bool {
+	basic, ok := t.(*types.Basic)
+	return ok && basic.Kind() == types.Byte
+}
+
+func exportName(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
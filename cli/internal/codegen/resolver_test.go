@@ -0,0 +1,106 @@
+package codegen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var schemaFixture = sync.OnceValues(func() ([]TypeDef, error) {
+	parser := NewGoParser("testdata/schema")
+	parser.SetTypeMappings(schemaMappings)
+	return parser.Parse()
+})
+
+var schemaMappings = TypeMappings{"money.Amount": {TS: "Big", Import: "big.js"}}
+
+/*
+ * describeDef renders the parts of a definition the resolver decides:
+ * type parameters, fields with their type and origin, embeds and enum
+ * values.
+ */
+func describeDef(def TypeDef) string {
+	var parts []string
+	for _, param := range def.TypeParams {
+		parts = append(parts, fmt.Sprintf("param %s %s", param.Name, param.Constraint))
+	}
+	for _, field := range def.Fields {
+		part := fmt.Sprintf("%s %s", field.JSONName, field.Type)
+		if field.EmbeddedFrom != "" {
+			part += " from " + field.EmbeddedFrom
+		}
+		parts = append(parts, part)
+	}
+	for _, embed := range def.Embeds {
+		parts = append(parts, fmt.Sprintf("embed %s omit %v", embed.Name, embed.Omit))
+	}
+	for _, value := range def.Enum {
+		parts = append(parts, fmt.Sprintf("%s = %s", value.Name, value.Value))
+	}
+	if def.Underlying != "" {
+		parts = append(parts, "underlying "+def.Underlying)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func TestResolveSchema(t *testing.T) {
+	defs, err := schemaFixture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]TypeDef, len(defs))
+	var names []string
+	for _, def := range defs {
+		byName[def.Name] = def
+		names = append(names, def.Name)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Base", "id int; name string"},
+		{"Article", "id int from Base; name string; status Status; price money.Amount; embed Base omit [name]"},
+		{"Status", "StatusDraft = draft; StatusPublished = published; underlying string"},
+		{"Page", "param T any; items []T; total int"},
+		{"Feed", "articles Page[Article]; tags Page[string]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, ok := byName[tt.name]
+			if !ok {
+				t.Fatalf("%s not resolved (got %v)", tt.name, names)
+			}
+			if got := describeDef(def); got != tt.want {
+				t.Errorf("%s = %s\nwant %s", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	/* One Page serves both instantiations, and mapped types are not expanded */
+	want := []string{"Base", "Article", "Status", "Page", "Feed"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("definitions = %v, want %v", names, want)
+	}
+	imports := schemaMappings.mappingImports(byName["Article"].Fields[3].Type)
+	if !reflect.DeepEqual(imports, []string{"import { Big } from 'big.js';"}) {
+		t.Errorf("imports of money.Amount = %v", imports)
+	}
+}
+
+func TestResolveUnsupported(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"testdata/unsupported/channel", "Stream.Events: unsupported type chan string"},
+		{"testdata/unsupported/callback", "Hook.Run: unsupported type func(string) error"},
+	}
+	for _, tt := range tests {
+		if _, err := NewGoParser(tt.dir).Parse(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Parse error = %v, want %q", tt.dir, err, tt.want)
+		}
+	}
+}
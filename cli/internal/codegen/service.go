@@ -1,241 +1,491 @@
 /*
  * GoAstra CLI - Service Generator
  *
- * Generates Angular services from Go type definitions.
- * Creates type-safe API clients with CRUD operations.
+ * Generates API clients from the API route table. Each endpoint group
+ * (OpenAPI tag or first path segment) becomes a service whose methods
+ * match the registered routes: their path and query parameters, request
+ * bodies, response types and auth requirements. Angular services are the
+ * default; fetch, React Query and Vue targets are rendered in client.go.
  */
 package codegen
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 )
 
 /*
- * ServiceGenerator creates Angular service files from Go types.
+ * ClientTarget selects the framework generated clients are written for.
+ */
+type ClientTarget string
+
+const (
+	TargetAngular    ClientTarget = "angular"
+	TargetFetch      ClientTarget = "fetch"
+	TargetReactQuery ClientTarget = "react-query"
+	TargetVue        ClientTarget = "vue"
+)
+
+/*
+ * ClientTargets lists the supported targets in documentation order.
+ */
+var ClientTargets = []ClientTarget{TargetAngular, TargetFetch, TargetReactQuery, TargetVue}
+
+/*
+ * ServiceGenerator creates API client files from endpoints.
  */
 type ServiceGenerator struct {
-	outputPath string
+	outputPath   string
+	target       ClientTarget
+	modelsImport string
+	schemas      bool
+	out          *Output
 }
 
 /*
- * NewServiceGenerator creates a new generator instance.
+ * NewServiceGenerator creates a new generator instance that writes
+ * Angular services into outputPath.
  */
 func NewServiceGenerator(outputPath string) *ServiceGenerator {
-	return &ServiceGenerator{outputPath: outputPath}
+	return &ServiceGenerator{outputPath: outputPath, target: TargetAngular, modelsImport: "@core/models"}
+}
+
+/*
+ * SetTarget selects the client framework.
+ */
+func (g *ServiceGenerator) SetTarget(target ClientTarget) {
+	g.target = target
+}
+
+/*
+ * SetModelsImport sets the module generated models are imported from,
+ * "@core/models" by default.
+ */
+func (g *ServiceGenerator) SetModelsImport(path string) {
+	g.modelsImport = path
+}
+
+/*
+ * SetSchemas makes services validate responses against the generated
+ * Zod schemas when Angular runs in dev mode.
+ */
+func (g *ServiceGenerator) SetSchemas(schemas bool) {
+	g.schemas = schemas
 }
 
 /*
- * Generate creates Angular service files for applicable types.
+ * Generate creates client files for all endpoint groups.
  */
-func (g *ServiceGenerator) Generate(types []TypeDef) error {
-	servicePath := filepath.Join(filepath.Dir(g.outputPath), "services", "generated")
-	if err := os.MkdirAll(servicePath, 0755); err != nil {
-		return fmt.Errorf("failed to create services directory: %w", err)
+func (g *ServiceGenerator) Generate(endpoints *EndpointSet) error {
+	out := NewOutput()
+	if err := g.Render(endpoints, out); err != nil {
+		return err
 	}
+	_, err := out.Write()
+	return err
+}
 
-	for _, typeDef := range types {
-		/* Skip utility types */
-		if g.isUtilityType(typeDef.Name) {
-			continue
-		}
+/*
+ * Render renders a service per endpoint group into out, with shared
+ * helpers and the component types that have no generated model.
+ */
+func (g *ServiceGenerator) Render(endpoints *EndpointSet, out *Output) error {
+	g.out = out
 
-		if err := g.generateService(typeDef, servicePath); err != nil {
-			return err
-		}
+	switch g.target {
+	case TargetAngular:
+	case TargetFetch, TargetReactQuery, TargetVue:
+		return g.renderClient(endpoints)
+	default:
+		return fmt.Errorf("unknown client target: %s", g.target)
 	}
 
-	return g.generateServicesIndex(types, servicePath)
-}
+	groups := endpoints.Groups()
+	for _, group := range groups {
+		g.generateService(endpoints, group)
+	}
 
-func (g *ServiceGenerator) isUtilityType(name string) bool {
-	utilityTypes := []string{
-		"BaseModel",
-		"PaginatedResponse",
-		"APIError",
+	if decls := endpoints.Declarations(); len(decls) > 0 {
+		g.out.Add(filepath.Join(g.outputPath, "api-types.ts"), g.apiTypesFile(endpoints))
 	}
 
-	for _, ut := range utilityTypes {
-		if name == ut {
-			return true
-		}
+	g.out.Add(filepath.Join(g.outputPath, "query.ts"), queryHelper)
+	if g.schemas {
+		g.out.Add(filepath.Join(g.outputPath, "validation.ts"), validationHelper)
 	}
 
-	return false
+	return g.generateServicesIndex(endpoints, groups)
 }
 
-func (g *ServiceGenerator) generateService(typeDef TypeDef, servicePath string) error {
-	var sb strings.Builder
+/*
+ * queryHelper converts typed query parameter objects for ApiService.
+ */
+const queryHelper = `/*
+ * Query parameter helpers for generated services
+ * Auto-generated by GoAstra typesync
+ */
+
+/*
+ * toQueryParams converts a parameter object to string values, dropping
+ * undefined and null entries.
+ */
+export function toQueryParams(params?: object): Record<string, string> | undefined {
+  if (!params) {
+    return undefined;
+  }
+  const result: Record<string, string> = {};
+  for (const [key, value] of Object.entries(params)) {
+    if (value !== undefined && value !== null) {
+      result[key] = String(value);
+    }
+  }
+  return result;
+}
+
+/*
+ * withQuery appends query parameters to a path for methods that do not
+ * take them separately.
+ */
+export function withQuery(path: string, params?: object): string {
+  const query = new URLSearchParams(toQueryParams(params)).toString();
+  return query ? ` + "`${path}?${query}`" + ` : path;
+}
+`
+
+/*
+ * validationHelper is the rxjs operator services use to check responses.
+ */
+const validationHelper = `/*
+ * Response validation for generated services
+ * Auto-generated by GoAstra typesync
+ */
+import { isDevMode } from '@angular/core';
+import { MonoTypeOperatorFunction, map } from 'rxjs';
+import { ZodType } from 'zod';
+
+/*
+ * parseInDev validates each emitted value against a schema in dev builds
+ * and passes it through unchanged in production.
+ */
+export function parseInDev<T>(schema: ZodType<unknown>): MonoTypeOperatorFunction<T> {
+  return map((value: T) => {
+    if (isDevMode()) {
+      schema.parse(value);
+    }
+    return value;
+  });
+}
+`
+
+/*
+ * serviceName returns the class and file base names for a group:
+ * "users" becomes UsersApiService in users-api.service.ts.
+ */
+func serviceName(group string) (string, string) {
+	words := strings.FieldsFunc(group, func(r rune) bool { return !isIdentRune(r) || r == '_' })
+	var class strings.Builder
+	for _, word := range words {
+		class.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	name := class.String()
+	if name == "" || name == "Api" {
+		name = "Root"
+	}
+	return name + "ApiService", toKebabCase(name) + "-api.service"
+}
+
+func (g *ServiceGenerator) generateService(endpoints *EndpointSet, group string) {
+	className, fileName := serviceName(group)
+	methods := endpoints.InGroup(group)
+
+	tsTypes := endpointTypes(methods)
+	validated := g.validated(methods)
 
-	kebabName := toKebabCase(typeDef.Name)
-	pluralName := toPlural(kebabName)
+	usesQuery, usesPath := false, false
+	for _, endpoint := range methods {
+		if len(endpoint.QueryParams) > 0 {
+			if endpoint.Method == "GET" {
+				usesQuery = true
+			} else {
+				usesPath = true
+			}
+		}
+	}
 
+	var sb strings.Builder
 	sb.WriteString("/*\n")
-	sb.WriteString(fmt.Sprintf(" * %s API Service\n", typeDef.Name))
+	sb.WriteString(fmt.Sprintf(" * %s\n", className))
 	sb.WriteString(" * Auto-generated by GoAstra typesync\n")
 	sb.WriteString(" */\n")
 	sb.WriteString("import { Injectable } from '@angular/core';\n")
 	sb.WriteString("import { Observable } from 'rxjs';\n")
 	sb.WriteString("import { ApiService } from '@core/services/api.service';\n")
-	sb.WriteString(fmt.Sprintf("import { %s } from '@core/models';\n\n", typeDef.Name))
-
-	/* Paginated response interface */
-	sb.WriteString(fmt.Sprintf("export interface %sPaginatedResponse {\n", typeDef.Name))
-	sb.WriteString(fmt.Sprintf("  data: %s[];\n", typeDef.Name))
-	sb.WriteString("  total: number;\n")
-	sb.WriteString("  page: number;\n")
-	sb.WriteString("  pageSize: number;\n")
-	sb.WriteString("  totalPages: number;\n")
-	sb.WriteString("}\n\n")
-
-	/* Create DTO */
-	sb.WriteString(fmt.Sprintf("export interface Create%sDto {\n", typeDef.Name))
-	for _, field := range typeDef.Fields {
-		if g.isCreateField(field) {
-			tsType := g.goTypeToTS(field.Type)
-			jsonName := field.JSONName
-			if jsonName == "" {
-				jsonName = toCamelCaseTS(field.Name)
-			}
-			sb.WriteString(fmt.Sprintf("  %s: %s;\n", jsonName, tsType))
-		}
+	if UsesZod(validated) {
+		sb.WriteString("import { z } from 'zod';\n")
 	}
-	sb.WriteString("}\n\n")
 
-	/* Update DTO */
-	sb.WriteString(fmt.Sprintf("export interface Update%sDto {\n", typeDef.Name))
-	for _, field := range typeDef.Fields {
-		if g.isUpdateField(field) {
-			tsType := g.goTypeToTS(field.Type)
-			jsonName := field.JSONName
-			if jsonName == "" {
-				jsonName = toCamelCaseTS(field.Name)
-			}
-			sb.WriteString(fmt.Sprintf("  %s?: %s;\n", jsonName, tsType))
-		}
+	models := endpoints.ModelRefs(tsTypes...)
+	models = append(models, SchemaRefs(validated)...)
+	if len(models) > 0 {
+		sb.WriteString(fmt.Sprintf("import { %s } from '%s';\n", strings.Join(models, ", "), g.modelsImport))
+	}
+	if declared := endpoints.DeclaredRefs(tsTypes...); len(declared) > 0 {
+		sb.WriteString(fmt.Sprintf("import { %s } from './api-types';\n", strings.Join(declared, ", ")))
 	}
-	sb.WriteString("}\n\n")
 
-	/* Service class */
+	var helpers []string
+	if usesQuery {
+		helpers = append(helpers, "toQueryParams")
+	}
+	if usesPath {
+		helpers = append(helpers, "withQuery")
+	}
+	if len(helpers) > 0 {
+		sb.WriteString(fmt.Sprintf("import { %s } from './query';\n", strings.Join(helpers, ", ")))
+	}
+	if len(validated) > 0 {
+		sb.WriteString("import { parseInDev } from './validation';\n")
+	}
+	sb.WriteString("\n")
+
 	sb.WriteString("@Injectable({\n")
 	sb.WriteString("  providedIn: 'root'\n")
 	sb.WriteString("})\n")
-	sb.WriteString(fmt.Sprintf("export class %sApiService {\n", typeDef.Name))
-	sb.WriteString(fmt.Sprintf("  private readonly basePath = '/%s';\n\n", pluralName))
-
-	sb.WriteString("  constructor(private api: ApiService) {}\n\n")
-
-	/* List method */
-	sb.WriteString(fmt.Sprintf("  list(page = 1, pageSize = 10): Observable<%sPaginatedResponse> {\n", typeDef.Name))
-	sb.WriteString("    return this.api.get(this.basePath, {\n")
-	sb.WriteString("      page: page.toString(),\n")
-	sb.WriteString("      page_size: pageSize.toString()\n")
-	sb.WriteString("    });\n")
-	sb.WriteString("  }\n\n")
-
-	/* GetById method */
-	sb.WriteString(fmt.Sprintf("  getById(id: number): Observable<%s> {\n", typeDef.Name))
-	sb.WriteString("    return this.api.get(`${this.basePath}/${id}`);\n")
-	sb.WriteString("  }\n\n")
-
-	/* Create method */
-	sb.WriteString(fmt.Sprintf("  create(data: Create%sDto): Observable<%s> {\n", typeDef.Name, typeDef.Name))
-	sb.WriteString("    return this.api.post(this.basePath, data);\n")
-	sb.WriteString("  }\n\n")
-
-	/* Update method */
-	sb.WriteString(fmt.Sprintf("  update(id: number, data: Update%sDto): Observable<%s> {\n", typeDef.Name, typeDef.Name))
-	sb.WriteString("    return this.api.put(`${this.basePath}/${id}`, data);\n")
-	sb.WriteString("  }\n\n")
-
-	/* Delete method */
-	sb.WriteString("  delete(id: number): Observable<void> {\n")
-	sb.WriteString("    return this.api.delete(`${this.basePath}/${id}`);\n")
-	sb.WriteString("  }\n")
+	sb.WriteString(fmt.Sprintf("export class %s {\n", className))
+	sb.WriteString("  constructor(private api: ApiService) {}\n")
+
+	for _, endpoint := range methods {
+		sb.WriteString("\n")
+		g.writeMethod(&sb, endpoint)
+	}
 
 	sb.WriteString("}\n")
 
-	filename := kebabName + "-api.service.ts"
-	path := filepath.Join(servicePath, filename)
+	g.out.Add(filepath.Join(g.outputPath, fileName+".ts"), sb.String())
+}
 
-	return os.WriteFile(path, []byte(sb.String()), 0644)
+/*
+ * endpointTypes lists the TypeScript types the endpoints reference.
+ */
+func endpointTypes(endpoints []Endpoint) []string {
+	var tsTypes []string
+	for _, endpoint := range endpoints {
+		tsTypes = append(tsTypes, endpoint.Response, endpoint.Body)
+		for _, param := range endpoint.PathParams {
+			tsTypes = append(tsTypes, param.Type)
+		}
+		for _, param := range endpoint.QueryParams {
+			tsTypes = append(tsTypes, param.Type)
+		}
+	}
+	return tsTypes
 }
 
-func (g *ServiceGenerator) generateServicesIndex(types []TypeDef, servicePath string) error {
-	var sb strings.Builder
+/*
+ * validated returns the endpoints whose responses are checked against
+ * a schema.
+ */
+func (g *ServiceGenerator) validated(endpoints []Endpoint) []Endpoint {
+	if !g.schemas {
+		return nil
+	}
+	var validated []Endpoint
+	for _, endpoint := range endpoints {
+		if endpoint.Schema != "" {
+			validated = append(validated, endpoint)
+		}
+	}
+	return validated
+}
 
-	sb.WriteString("/*\n")
-	sb.WriteString(" * GoAstra Generated Services - Barrel Export\n")
-	sb.WriteString(" * Auto-generated by GoAstra typesync\n")
-	sb.WriteString(" */\n\n")
+/*
+ * writeMethod renders one endpoint as a service method.
+ */
+func (g *ServiceGenerator) writeMethod(sb *strings.Builder, endpoint Endpoint) {
+	writeMethodDoc(sb, endpoint, "  ")
+
+	args := methodParams(endpoint)
+	sb.WriteString(fmt.Sprintf("  %s(%s): Observable<%s> {\n", endpoint.Name, strings.Join(args, ", "), endpoint.Response))
+
+	path := pathExpression(endpoint)
+	if len(endpoint.QueryParams) > 0 && endpoint.Method != "GET" {
+		path = fmt.Sprintf("withQuery(%s, params)", path)
+	}
 
-	for _, typeDef := range types {
-		if g.isUtilityType(typeDef.Name) {
-			continue
+	var call string
+	switch endpoint.Method {
+	case "GET":
+		call = fmt.Sprintf("this.api.get<%s>(%s", endpoint.Response, path)
+		if len(endpoint.QueryParams) > 0 {
+			call += ", toQueryParams(params)"
+		}
+		call += ")"
+	case "POST", "PUT", "PATCH":
+		body := "body"
+		if endpoint.Body == "" {
+			body = "{}"
 		}
-		kebabName := toKebabCase(typeDef.Name)
-		sb.WriteString(fmt.Sprintf("export * from './%s-api.service';\n", kebabName))
+		call = fmt.Sprintf("this.api.%s<%s>(%s, %s)", strings.ToLower(endpoint.Method), endpoint.Response, path, body)
+	case "DELETE":
+		call = fmt.Sprintf("this.api.delete<%s>(%s)", endpoint.Response, path)
+	default:
+		call = fmt.Sprintf("this.api.get<%s>(%s)", endpoint.Response, path)
+	}
+
+	if g.schemas && endpoint.Schema != "" {
+		call += fmt.Sprintf(".pipe(parseInDev(%s))", endpoint.Schema)
 	}
 
-	path := filepath.Join(servicePath, "index.ts")
-	return os.WriteFile(path, []byte(sb.String()), 0644)
+	sb.WriteString(fmt.Sprintf("    return %s;\n", call))
+	sb.WriteString("  }\n")
 }
 
-func (g *ServiceGenerator) isCreateField(field FieldDef) bool {
-	excludeFields := []string{"ID", "Id", "id", "CreatedAt", "UpdatedAt", "DeletedAt"}
-	for _, ef := range excludeFields {
-		if field.Name == ef {
-			return false
-		}
+/*
+ * writeMethodDoc writes the endpoint summary, description, route and
+ * auth requirement as a TSDoc comment, marking deprecated endpoints.
+ */
+func writeMethodDoc(sb *strings.Builder, endpoint Endpoint, indent string) {
+	doc := parseGoDoc(endpoint.Description, nil)
+	var lines []string
+	if endpoint.Summary != "" {
+		lines = append(lines, strings.Split(endpoint.Summary, "\n")...)
+	}
+	lines = append(lines, doc.text...)
+	lines = append(lines, fmt.Sprintf("%s %s", endpoint.Method, endpoint.Path))
+	if endpoint.Auth {
+		lines = append(lines, "Requires authentication.")
 	}
-	return true
+
+	doc.text = lines
+	doc.deprecated = doc.deprecated || endpoint.Deprecated
+	doc.write(sb, indent)
 }
 
-func (g *ServiceGenerator) isUpdateField(field FieldDef) bool {
-	excludeFields := []string{"ID", "Id", "id", "CreatedAt", "UpdatedAt", "DeletedAt"}
-	for _, ef := range excludeFields {
-		if field.Name == ef {
-			return false
+/*
+ * methodParams lists path parameters, the body and a query parameter
+ * object, in that order.
+ */
+func methodParams(endpoint Endpoint) []string {
+	var params []string
+	for _, param := range endpoint.PathParams {
+		params = append(params, fmt.Sprintf("%s: %s", param.Ident, pathParamType(param.Type)))
+	}
+
+	if hasBody(endpoint) {
+		optional := "?"
+		if endpoint.BodyRequired {
+			optional = ""
 		}
+		params = append(params, fmt.Sprintf("body%s: %s", optional, endpoint.Body))
 	}
-	return true
+
+	if len(endpoint.QueryParams) > 0 {
+		members := make([]string, len(endpoint.QueryParams))
+		required := false
+		for i, param := range endpoint.QueryParams {
+			optional := "?"
+			if param.Required {
+				optional = ""
+				required = true
+			}
+			members[i] = fmt.Sprintf("%s%s: %s", propertyKey(param.Name), optional, param.Type)
+		}
+		optional := "?"
+		if required {
+			optional = ""
+		}
+		params = append(params, fmt.Sprintf("params%s: { %s }", optional, strings.Join(members, "; ")))
+	}
+
+	return params
 }
 
-func (g *ServiceGenerator) goTypeToTS(goType string) string {
-	if strings.HasPrefix(goType, "*") {
-		return g.goTypeToTS(goType[1:]) + " | null"
+/*
+ * methodArgs returns the names of the parameters listed by methodParams.
+ */
+func methodArgs(endpoint Endpoint) []string {
+	var args []string
+	for _, param := range endpoint.PathParams {
+		args = append(args, param.Ident)
+	}
+	if hasBody(endpoint) {
+		args = append(args, "body")
 	}
+	if len(endpoint.QueryParams) > 0 {
+		args = append(args, "params")
+	}
+	return args
+}
 
-	if strings.HasPrefix(goType, "[]") {
-		return g.goTypeToTS(goType[2:]) + "[]"
+func hasBody(endpoint Endpoint) bool {
+	return endpoint.Body != "" && endpoint.Method != "GET"
+}
+
+/*
+ * pathParamType accepts numbers for string path parameters, since ids
+ * are usually held as numbers on the client.
+ */
+func pathParamType(tsType string) string {
+	if tsType == "string" || tsType == "unknown" {
+		return "string | number"
 	}
+	return tsType
+}
 
-	switch goType {
-	case "string":
-		return "string"
-	case "int", "int8", "int16", "int32", "int64",
-		"uint", "uint8", "uint16", "uint32", "uint64",
-		"float32", "float64":
-		return "number"
-	case "bool":
-		return "boolean"
-	case "interface{}", "any":
-		return "unknown"
-	case "time.Time", "Time":
-		return "string"
-	default:
-		return goType
+/*
+ * pathExpression renders the request path, interpolating parameters.
+ */
+func pathExpression(endpoint Endpoint) string {
+	if len(endpoint.PathParams) == 0 {
+		return tsLiteral("string", endpoint.Path)
 	}
+
+	path := endpoint.Path
+	for _, param := range endpoint.PathParams {
+		path = strings.ReplaceAll(path, "{"+param.Name+"}", fmt.Sprintf("${encodeURIComponent(%s)}", param.Ident))
+	}
+	return "`" + strings.ReplaceAll(path, "`", "\\`") + "`"
 }
 
-func toPlural(s string) string {
-	if strings.HasSuffix(s, "s") {
-		return s + "es"
+/*
+ * apiTypesFile declares component types that have no generated model.
+ */
+func (g *ServiceGenerator) apiTypesFile(endpoints *EndpointSet) string {
+	var sb strings.Builder
+	sb.WriteString("/*\n")
+	sb.WriteString(" * API types without a schema model\n")
+	sb.WriteString(" * Auto-generated by GoAstra typesync\n")
+	sb.WriteString(" */\n")
+
+	decls := endpoints.Declarations()
+	if models := endpoints.ModelRefs(endpoints.DeclarationTypes()...); len(models) > 0 {
+		sb.WriteString(fmt.Sprintf("import { %s } from '%s';\n", strings.Join(models, ", "), g.modelsImport))
+	}
+
+	for _, decl := range decls {
+		sb.WriteString("\n" + decl)
+	}
+	return sb.String()
+}
+
+func (g *ServiceGenerator) generateServicesIndex(endpoints *EndpointSet, groups []string) error {
+	var sb strings.Builder
+
+	sb.WriteString("/*\n")
+	sb.WriteString(" * GoAstra Generated Services - Barrel Export\n")
+	sb.WriteString(" * Auto-generated by GoAstra typesync\n")
+	sb.WriteString(" */\n\n")
+
+	if len(endpoints.Declarations()) > 0 {
+		sb.WriteString("export * from './api-types';\n")
 	}
-	if strings.HasSuffix(s, "y") {
-		return s[:len(s)-1] + "ies"
+	for _, group := range groups {
+		_, fileName := serviceName(group)
+		sb.WriteString(fmt.Sprintf("export * from './%s';\n", fileName))
 	}
-	return s + "s"
+
+	path := filepath.Join(g.outputPath, "index.ts")
+	g.out.Add(path, sb.String())
+	return nil
 }
@@ -0,0 +1,37 @@
+package schema
+
+import "github.com/channdev/goastra/cli/internal/codegen/testdata/schema/money"
+
+// Base holds the fields every record has.
+type Base struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Article declares its own name, which hides the one of its embedded Base.
+type Article struct {
+	Base
+	Name   string       `json:"name"`
+	Status Status       `json:"status"`
+	Price  money.Amount `json:"price"`
+}
+
+// Status is the publication state of an article.
+type Status string
+
+const (
+	StatusDraft     Status = "draft"
+	StatusPublished Status = "published"
+)
+
+// Page is a page of items.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+// Feed lists articles and tags in pages.
+type Feed struct {
+	Articles Page[Article] `json:"articles"`
+	Tags     Page[string]  `json:"tags"`
+}
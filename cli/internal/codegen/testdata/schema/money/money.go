@@ -0,0 +1,6 @@
+package money
+
+// Amount is a decimal amount of money.
+type Amount struct {
+	cents int64
+}
@@ -0,0 +1,7 @@
+package callback
+
+// Hook has a func field JSON cannot encode.
+type Hook struct {
+	Name string             `json:"name"`
+	Run  func(string) error `json:"run"`
+}
@@ -0,0 +1,6 @@
+package channel
+
+// Stream has a channel field JSON cannot encode.
+type Stream struct {
+	Events chan string `json:"events"`
+}
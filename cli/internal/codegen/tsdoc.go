@@ -0,0 +1,353 @@
+package codegen
+
+/*
+ * GoAstra CLI - TSDoc Rendering
+ *
+ * Converts Go doc comments into TSDoc so editor tooltips in the
+ * frontend show the backend's documentation.
+ */
+
+import (
+	"regexp"
+	"strings"
+)
+
+/*
+ * tsDoc is a doc comment converted from Go conventions.
+ * deprecated is set by a "Deprecated:" paragraph and deprecatedText
+ * holds its explanation.
+ */
+type tsDoc struct {
+	text           []string
+	examples       [][]string
+	tags           []string
+	deprecated     bool
+	deprecatedText []string
+}
+
+/*
+ * docLinkPattern matches Go doc links such as [User] or [models.User].
+ */
+var docLinkPattern = regexp.MustCompile(`\[\*?([A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*)\]`)
+
+/*
+ * exampleHeading matches a paragraph introducing example code.
+ */
+var exampleHeading = regexp.MustCompile(`(?i)^(for )?examples?:$`)
+
+/*
+ * parseGoDoc converts a Go doc comment to TSDoc. Paragraphs become
+ * text, indented blocks become fenced code or lists, the "Deprecated:"
+ * paragraph becomes @deprecated and code following an "Example:"
+ * paragraph becomes @example. Doc links are resolved with link, which
+ * returns the TypeScript name of a Go identifier or false.
+ */
+func parseGoDoc(doc string, link func(name string) (string, bool)) tsDoc {
+	var result tsDoc
+	inExample := false
+
+	for _, block := range docBlocks(commentLines(doc)) {
+		if !block.indented {
+			first := block.lines[0]
+			switch {
+			case strings.HasPrefix(first, "Deprecated:"):
+				result.deprecated = true
+				lines := append([]string{strings.TrimSpace(strings.TrimPrefix(first, "Deprecated:"))}, block.lines[1:]...)
+				result.deprecatedText = trimBlank(resolveLinks(lines, link))
+				inExample = false
+				continue
+			case len(block.lines) == 1 && exampleHeading.MatchString(first):
+				inExample = true
+				continue
+			case strings.HasPrefix(first, "Example:"):
+				lines := append([]string{strings.TrimSpace(strings.TrimPrefix(first, "Example:"))}, block.lines[1:]...)
+				result.examples = append(result.examples, trimBlank(lines))
+				inExample = false
+				continue
+			}
+			inExample = false
+			result.text = appendParagraph(result.text, resolveLinks(block.lines, link))
+			continue
+		}
+
+		if isDocList(block.lines) {
+			inExample = false
+			result.text = appendParagraph(result.text, resolveLinks(docList(block.lines), link))
+			continue
+		}
+
+		code := append(append([]string{"```"}, dedent(block.lines)...), "```")
+		if inExample {
+			result.examples = append(result.examples, code)
+			continue
+		}
+		result.text = appendParagraph(result.text, code)
+	}
+
+	return result
+}
+
+/*
+ * empty reports whether the doc has nothing to render.
+ */
+func (d tsDoc) empty() bool {
+	return len(d.text) == 0 && len(d.examples) == 0 && len(d.tags) == 0 && !d.deprecated
+}
+
+/*
+ * write renders the doc as a /** comment at the given indentation,
+ * using the single-line form when it fits on one line.
+ */
+func (d tsDoc) write(sb *strings.Builder, indent string) {
+	lines := append([]string(nil), d.text...)
+	for _, example := range d.examples {
+		lines = append(lines, "@example")
+		lines = append(lines, example...)
+	}
+	lines = append(lines, d.tags...)
+	if d.deprecated {
+		deprecated := "@deprecated"
+		if len(d.deprecatedText) > 0 {
+			deprecated += " " + d.deprecatedText[0]
+		}
+		lines = append(lines, deprecated)
+		if len(d.deprecatedText) > 1 {
+			lines = append(lines, d.deprecatedText[1:]...)
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	for i, line := range lines {
+		line = strings.ReplaceAll(line, "*/", "*\\/")
+		if strings.HasPrefix(line, "@") && !isTSDocTag(line) {
+			line = "\\" + line
+		}
+		lines[i] = line
+	}
+
+	if len(lines) == 1 && !strings.HasPrefix(lines[0], "```") {
+		sb.WriteString(indent + "/** " + lines[0] + " */\n")
+		return
+	}
+	sb.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		sb.WriteString(strings.TrimRight(indent+" * "+line, " ") + "\n")
+	}
+	sb.WriteString(indent + " */\n")
+}
+
+/*
+ * isTSDocTag reports whether a line starts with a tag emitted by write.
+ */
+func isTSDocTag(line string) bool {
+	tag, _, _ := strings.Cut(line, " ")
+	switch tag {
+	case "@example", "@deprecated", "@format":
+		return true
+	}
+	return false
+}
+
+type docBlock struct {
+	indented bool
+	lines    []string
+}
+
+/*
+ * commentLines splits comment text into lines, removing the leading
+ * "*" of block comments while keeping code indentation.
+ */
+func commentLines(doc string) []string {
+	lines := strings.Split(strings.Trim(doc, "\n"), "\n")
+
+	starred := true
+	for _, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "*") {
+			starred = false
+			break
+		}
+	}
+
+	for i, line := range lines {
+		if starred {
+			line = strings.TrimLeft(line, " \t")
+			line = strings.TrimPrefix(line, "*")
+			line = strings.TrimPrefix(line, " ")
+		}
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return trimBlank(lines)
+}
+
+/*
+ * docBlocks groups lines into paragraphs and indented blocks.
+ * Blank lines inside an indented block are kept.
+ */
+func docBlocks(lines []string) []docBlock {
+	var blocks []docBlock
+	var current *docBlock
+
+	for _, line := range lines {
+		if line == "" {
+			if current != nil && current.indented {
+				current.lines = append(current.lines, line)
+				continue
+			}
+			current = nil
+			continue
+		}
+
+		indented := line[0] == ' ' || line[0] == '\t'
+		if current == nil || current.indented != indented {
+			if current != nil && current.indented {
+				current.lines = trimBlank(current.lines)
+			}
+			blocks = append(blocks, docBlock{indented: indented})
+			current = &blocks[len(blocks)-1]
+		}
+		if !indented {
+			line = strings.TrimSpace(line)
+		}
+		current.lines = append(current.lines, line)
+	}
+	if current != nil && current.indented {
+		current.lines = trimBlank(current.lines)
+	}
+
+	return blocks
+}
+
+/*
+ * isDocList reports whether an indented block is a list, which Go
+ * doc comments mark by starting the first line with a bullet or number.
+ */
+func isDocList(lines []string) bool {
+	_, ok := listItem(strings.TrimSpace(lines[0]))
+	return ok
+}
+
+/*
+ * listItem returns the text of a list item line in Markdown form.
+ */
+func listItem(line string) (string, bool) {
+	for _, bullet := range []string{"- ", "* ", "+ ", "• "} {
+		if strings.HasPrefix(line, bullet) {
+			return "- " + strings.TrimSpace(strings.TrimPrefix(line, bullet)), true
+		}
+	}
+	digits := 0
+	for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && digits+1 < len(line) && (line[digits] == '.' || line[digits] == ')') && line[digits+1] == ' ' {
+		return line[:digits] + ". " + strings.TrimSpace(line[digits+2:]), true
+	}
+	return "", false
+}
+
+/*
+ * docList renders list items, joining continuation lines.
+ */
+func docList(lines []string) []string {
+	var items []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if item, ok := listItem(line); ok || len(items) == 0 {
+			if !ok {
+				item = line
+			}
+			items = append(items, item)
+			continue
+		}
+		items[len(items)-1] += " " + line
+	}
+	return items
+}
+
+/*
+ * dedent removes the indentation shared by all non-blank lines and
+ * expands tabs so code renders the same in every editor.
+ */
+func dedent(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = strings.ReplaceAll(line, "\t", "  ")
+	}
+
+	common := -1
+	for _, line := range out {
+		if line == "" {
+			continue
+		}
+		width := len(line) - len(strings.TrimLeft(line, " "))
+		if common < 0 || width < common {
+			common = width
+		}
+	}
+	for i, line := range out {
+		if len(line) >= common && common > 0 {
+			out[i] = line[common:]
+		}
+	}
+	return out
+}
+
+/*
+ * resolveLinks rewrites Go doc links as {@link} tags when they name a
+ * generated type and as inline code otherwise.
+ */
+func resolveLinks(lines []string, link func(name string) (string, bool)) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = docLinkPattern.ReplaceAllStringFunc(line, func(match string) string {
+			name := docLinkPattern.FindStringSubmatch(match)[1]
+			parts := strings.Split(name, ".")
+			for j := range parts {
+				if link == nil {
+					break
+				}
+				target, ok := link(parts[j])
+				if !ok {
+					continue
+				}
+				if j == len(parts)-1 {
+					return "{@link " + target + "}"
+				}
+				return "{@link " + target + " | " + strings.Join(parts[j:], ".") + "}"
+			}
+			return "`" + name + "`"
+		})
+	}
+	return out
+}
+
+/*
+ * appendParagraph appends lines to text, separated by a blank line.
+ */
+func appendParagraph(text, lines []string) []string {
+	if len(lines) == 0 {
+		return text
+	}
+	if len(text) > 0 {
+		text = append(text, "")
+	}
+	return append(text, lines...)
+}
+
+/*
+ * trimBlank removes leading and trailing blank lines.
+ */
+func trimBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -8,60 +8,216 @@ package codegen
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 )
 
+/*
+ * EmbedMode controls how embedded Go structs are emitted.
+ */
+type EmbedMode string
+
+const (
+	EmbedExtends EmbedMode = "extends"
+	EmbedFlatten EmbedMode = "flatten"
+)
+
+/*
+ * EnumStyle controls how Go constant groups are emitted.
+ */
+type EnumStyle string
+
+const (
+	EnumUnion EnumStyle = "union"
+	EnumTS    EnumStyle = "enum"
+)
+
 /*
  * TypeScriptGenerator creates TypeScript files from Go types.
  */
 type TypeScriptGenerator struct {
 	outputPath string
+	embedMode  EmbedMode
+	enumStyle  EnumStyle
+	mappings   TypeMappings
+	readonly   bool
+	layout     Layout
+	known      map[string]string
+	out        *Output
 }
 
 /*
  * NewTypeScriptGenerator creates a new generator instance.
  */
 func NewTypeScriptGenerator(outputPath string) *TypeScriptGenerator {
-	return &TypeScriptGenerator{outputPath: outputPath}
+	return &TypeScriptGenerator{outputPath: outputPath, embedMode: EmbedExtends, enumStyle: EnumUnion, layout: LayoutPerType}
+}
+
+/*
+ * SetEmbedMode selects between `extends` clauses and flattened fields
+ * for embedded structs.
+ */
+func (g *TypeScriptGenerator) SetEmbedMode(mode EmbedMode) {
+	g.embedMode = mode
+}
+
+/*
+ * SetEnumStyle selects between string-literal unions and TypeScript enums.
+ */
+func (g *TypeScriptGenerator) SetEnumStyle(style EnumStyle) {
+	g.enumStyle = style
+}
+
+/*
+ * SetLayout selects how types are grouped into modules.
+ */
+func (g *TypeScriptGenerator) SetLayout(layout Layout) {
+	g.layout = layout
+}
+
+/*
+ * SetTypeMappings registers project-specific type mappings.
+ */
+func (g *TypeScriptGenerator) SetTypeMappings(mappings TypeMappings) {
+	g.mappings = mappings
+}
+
+/*
+ * SetReadonly marks every generated interface field readonly.
+ */
+func (g *TypeScriptGenerator) SetReadonly(readonly bool) {
+	g.readonly = readonly
 }
 
 /*
  * Generate creates TypeScript interface files for all types.
  */
 func (g *TypeScriptGenerator) Generate(types []TypeDef) error {
-	if err := os.MkdirAll(g.outputPath, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	out := NewOutput()
+	if err := g.Render(types, out); err != nil {
+		return err
 	}
+	_, err := out.Write()
+	return err
+}
+
+/*
+ * Render renders TypeScript interface files for all types into out,
+ * grouped into modules by the layout.
+ */
+func (g *TypeScriptGenerator) Render(types []TypeDef, out *Output) error {
+	g.out = out
+	g.known = modulePaths(types, g.layout)
 
-	/* Generate individual type files */
+	var modules []string
+	members := make(map[string][]TypeDef)
 	for _, typeDef := range types {
-		if err := g.generateTypeFile(typeDef); err != nil {
-			return err
+		module := g.known[typeDef.Name]
+		if _, ok := members[module]; !ok {
+			modules = append(modules, module)
 		}
+		members[module] = append(members[module], typeDef)
+	}
+
+	/* Generate module files */
+	for _, module := range modules {
+		g.generateModule(module, members[module])
 	}
 
 	/* Generate index barrel file */
-	return g.generateIndex(types)
+	return g.generateIndex(modules)
 }
 
-func (g *TypeScriptGenerator) generateTypeFile(typeDef TypeDef) error {
+/*
+ * generateModule writes the types of one module, importing the types
+ * they reference from other modules.
+ */
+func (g *TypeScriptGenerator) generateModule(module string, types []TypeDef) {
 	var sb strings.Builder
 
 	sb.WriteString("/*\n")
-	if typeDef.Doc != "" {
-		sb.WriteString(fmt.Sprintf(" * %s", typeDef.Doc))
-	} else {
-		sb.WriteString(fmt.Sprintf(" * %s interface\n", typeDef.Name))
+	switch {
+	case len(types) > 1:
+		sb.WriteString(fmt.Sprintf(" * %s module\n", module))
+	case types[0].IsEnum():
+		sb.WriteString(fmt.Sprintf(" * %s enum\n", types[0].Name))
+	default:
+		sb.WriteString(fmt.Sprintf(" * %s interface\n", types[0].Name))
 	}
 	sb.WriteString(" * Auto-generated by GoAstra typesync\n")
 	sb.WriteString(" */\n\n")
 
-	sb.WriteString(fmt.Sprintf("export interface %s {\n", typeDef.Name))
+	var goTypes []string
+	var refModules []string
+	refs := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, typeDef := range types {
+		if typeDef.IsEnum() {
+			continue
+		}
+		fields := g.moduleFields(typeDef)
+		goTypes = append(goTypes, typeStrings(typeDef, fields)...)
+		for _, ref := range g.referencedTypes(typeDef, fields) {
+			target := g.known[ref]
+			if target == module || seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			if _, ok := refs[target]; !ok {
+				refModules = append(refModules, target)
+			}
+			refs[target] = append(refs[target], ref)
+		}
+	}
 
-	for _, field := range typeDef.Fields {
-		tsType := g.goTypeToTS(field.Type)
+	imports := g.mappings.mappingImports(goTypes...)
+	for _, stmt := range imports {
+		sb.WriteString(stmt + "\n")
+	}
+	for _, target := range refModules {
+		sb.WriteString(fmt.Sprintf("import { %s } from '%s';\n", strings.Join(refs[target], ", "), relativeModule(module, target)))
+	}
+	if len(imports)+len(refModules) > 0 {
+		sb.WriteString("\n")
+	}
+
+	for i, typeDef := range types {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if typeDef.IsEnum() {
+			g.writeEnum(&sb, typeDef)
+		} else {
+			g.writeInterface(&sb, typeDef)
+		}
+	}
+
+	path := filepath.Join(g.outputPath, filepath.FromSlash(module)+".ts")
+	g.out.Add(path, sb.String())
+}
+
+/*
+ * moduleFields returns the fields written for a struct: its own fields
+ * when embeds become extends clauses, otherwise all of them.
+ */
+func (g *TypeScriptGenerator) moduleFields(typeDef TypeDef) []FieldDef {
+	if g.embedMode == EmbedFlatten {
+		return typeDef.Fields
+	}
+	return ownFields(typeDef)
+}
+
+func (g *TypeScriptGenerator) writeInterface(sb *strings.Builder, typeDef TypeDef) {
+	heritage := ""
+	if g.embedMode != EmbedFlatten {
+		heritage = g.extendsClause(typeDef)
+	}
+
+	parseGoDoc(typeDef.Doc, g.link).write(sb, "")
+	sb.WriteString(fmt.Sprintf("export interface %s%s%s {\n", typeDef.Name, typeParamsToTS(typeDef.TypeParams), heritage))
+
+	for _, field := range g.moduleFields(typeDef) {
+		tsType := g.mappings.fieldTSType(field)
 		jsonName := field.JSONName
 		if jsonName == "" {
 			jsonName = toCamelCaseTS(field.Name)
@@ -72,89 +228,212 @@ func (g *TypeScriptGenerator) generateTypeFile(typeDef TypeDef) error {
 			optionalMarker = "?"
 		}
 
-		if field.Doc != "" {
-			sb.WriteString(fmt.Sprintf("  /* %s */\n", strings.TrimSpace(field.Doc)))
+		readonlyMarker := ""
+		if g.readonly || field.Readonly {
+			readonlyMarker = "readonly "
 		}
 
-		sb.WriteString(fmt.Sprintf("  %s%s: %s;\n", jsonName, optionalMarker, tsType))
+		g.fieldDoc(field).write(sb, "  ")
+
+		sb.WriteString(fmt.Sprintf("  %s%s%s: %s;\n", readonlyMarker, jsonName, optionalMarker, tsType))
 	}
 
 	sb.WriteString("}\n")
+}
+
+/*
+ * writeEnum emits a union type or enum for a Go constant group,
+ * with a values array and labels for building dropdowns.
+ */
+func (g *TypeScriptGenerator) writeEnum(sb *strings.Builder, typeDef TypeDef) {
+	refs := make([]string, len(typeDef.Enum))
 
-	filename := toKebabCase(typeDef.Name) + ".interface.ts"
-	path := filepath.Join(g.outputPath, filename)
+	parseGoDoc(typeDef.Doc, g.link).write(sb, "")
+	if g.enumStyle == EnumTS {
+		sb.WriteString(fmt.Sprintf("export enum %s {\n", typeDef.Name))
+		for i, value := range typeDef.Enum {
+			member := enumMemberName(typeDef.Name, value.Name)
+			refs[i] = typeDef.Name + "." + member
+			parseGoDoc(value.Doc, g.link).write(sb, "  ")
+			sb.WriteString(fmt.Sprintf("  %s = %s,\n", member, tsLiteral(typeDef.Underlying, value.Value)))
+		}
+		sb.WriteString("}\n\n")
+	} else {
+		for i, value := range typeDef.Enum {
+			refs[i] = tsLiteral(typeDef.Underlying, value.Value)
+		}
+		sb.WriteString(fmt.Sprintf("export type %s = %s;\n\n", typeDef.Name, strings.Join(refs, " | ")))
+	}
 
-	return os.WriteFile(path, []byte(sb.String()), 0644)
+	sb.WriteString(fmt.Sprintf("export const %sValues: readonly %s[] = [%s];\n\n", typeDef.Name, typeDef.Name, strings.Join(refs, ", ")))
+
+	sb.WriteString(fmt.Sprintf("export const %sLabels: Record<%s, string> = {\n", typeDef.Name, typeDef.Name))
+	for i, value := range typeDef.Enum {
+		key := refs[i]
+		if g.enumStyle == EnumTS {
+			key = "[" + key + "]"
+		}
+		sb.WriteString(fmt.Sprintf("  %s: '%s',\n", key, enumLabel(typeDef.Name, value.Name)))
+	}
+	sb.WriteString("};\n")
 }
 
-func (g *TypeScriptGenerator) generateIndex(types []TypeDef) error {
-	var sb strings.Builder
+/*
+ * fieldDoc converts a field's doc comment, adding a @format hint when
+ * the field's type is mapped to a string with a known format.
+ */
+func (g *TypeScriptGenerator) fieldDoc(field FieldDef) tsDoc {
+	doc := parseGoDoc(field.Doc, g.link)
+	if field.TSType != "" {
+		return doc
+	}
+	goType := field.Type
+	for strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") {
+		goType = strings.TrimPrefix(strings.TrimPrefix(goType, "*"), "[]")
+	}
+	if mapping, ok := g.mappings.lookup(goType); ok && mapping.Format != "" {
+		doc.tags = append(doc.tags, "@format "+mapping.Format)
+	}
+	return doc
+}
 
-	sb.WriteString("/*\n")
-	sb.WriteString(" * GoAstra Models - Barrel Export\n")
-	sb.WriteString(" * Auto-generated by GoAstra typesync\n")
-	sb.WriteString(" */\n\n")
+/*
+ * link resolves a Go doc link to a generated type.
+ */
+func (g *TypeScriptGenerator) link(name string) (string, bool) {
+	return name, g.known[name] != ""
+}
 
-	for _, typeDef := range types {
-		filename := toKebabCase(typeDef.Name) + ".interface"
-		sb.WriteString(fmt.Sprintf("export * from './%s';\n", filename))
+/*
+ * moduleName returns the file name, without extension, for a type.
+ */
+func moduleName(typeDef TypeDef) string {
+	if typeDef.IsEnum() {
+		return toKebabCase(typeDef.Name) + ".enum"
 	}
+	return toKebabCase(typeDef.Name) + ".interface"
+}
 
-	path := filepath.Join(g.outputPath, "index.ts")
-	return os.WriteFile(path, []byte(sb.String()), 0644)
+/*
+ * extendsClause renders the heritage clause for promoted embeds.
+ * Shadowed fields are removed with Omit and pointer embeds become Partial.
+ */
+func (g *TypeScriptGenerator) extendsClause(typeDef TypeDef) string {
+	if len(typeDef.Embeds) == 0 {
+		return ""
+	}
+
+	var parents []string
+	for _, embed := range typeDef.Embeds {
+		parent := embed.Name
+		if len(embed.TypeArgs) > 0 {
+			parent = g.mappings.tsType(fmt.Sprintf("%s[%s]", embed.Name, strings.Join(embed.TypeArgs, ", ")))
+		}
+		if len(embed.Omit) > 0 {
+			quoted := make([]string, len(embed.Omit))
+			for i, name := range embed.Omit {
+				quoted[i] = "'" + name + "'"
+			}
+			parent = fmt.Sprintf("Omit<%s, %s>", parent, strings.Join(quoted, " | "))
+		}
+		if embed.Pointer {
+			parent = fmt.Sprintf("Partial<%s>", parent)
+		}
+		parents = append(parents, parent)
+	}
+
+	return " extends " + strings.Join(parents, ", ")
 }
 
 /*
- * goTypeToTS converts a Go type string to TypeScript type.
+ * referencedTypes lists the generated types a file must import.
  */
-func (g *TypeScriptGenerator) goTypeToTS(goType string) string {
-	/* Handle pointers */
-	if strings.HasPrefix(goType, "*") {
-		return g.goTypeToTS(goType[1:]) + " | null"
+func (g *TypeScriptGenerator) referencedTypes(typeDef TypeDef, fields []FieldDef) []string {
+	seen := map[string]bool{typeDef.Name: true}
+	var refs []string
+
+	add := func(name string) {
+		if g.known[name] != "" && !seen[name] {
+			seen[name] = true
+			refs = append(refs, name)
+		}
 	}
 
-	/* Handle slices */
-	if strings.HasPrefix(goType, "[]") {
-		return g.goTypeToTS(goType[2:]) + "[]"
+	if g.embedMode != EmbedFlatten {
+		for _, embed := range typeDef.Embeds {
+			add(embed.Name)
+			for _, arg := range embed.TypeArgs {
+				for _, ident := range typeIdents(arg) {
+					add(ident)
+				}
+			}
+		}
 	}
+	for _, field := range fields {
+		for _, ident := range typeIdents(field.Type) {
+			add(ident)
+		}
+	}
+
+	return refs
+}
 
-	/* Handle maps */
-	if strings.HasPrefix(goType, "map[") {
-		/* Extract key and value types */
-		inner := goType[4 : len(goType)-1]
-		parts := strings.SplitN(inner, "]", 2)
-		if len(parts) == 2 {
-			keyType := g.goTypeToTS(parts[0])
-			valType := g.goTypeToTS(parts[1])
-			return fmt.Sprintf("Record<%s, %s>", keyType, valType)
+/*
+ * typeStrings lists the Go type strings referenced by a file:
+ * the given fields and the type arguments of embeds.
+ */
+func typeStrings(typeDef TypeDef, fields []FieldDef) []string {
+	var goTypes []string
+	for _, embed := range typeDef.Embeds {
+		goTypes = append(goTypes, embed.TypeArgs...)
+	}
+	for _, field := range fields {
+		if field.TSType == "" {
+			goTypes = append(goTypes, field.Type)
 		}
-		return "Record<string, unknown>"
 	}
+	return goTypes
+}
 
-	/* Basic type mappings */
-	switch goType {
-	case "string":
-		return "string"
-	case "int", "int8", "int16", "int32", "int64",
-		"uint", "uint8", "uint16", "uint32", "uint64",
-		"float32", "float64":
-		return "number"
-	case "bool":
-		return "boolean"
-	case "interface{}", "any":
-		return "unknown"
-	case "time.Time":
-		return "string"
-	case "Time":
-		return "string"
-	default:
-		/* Check for time package types */
-		if strings.HasPrefix(goType, "time.") {
-			return "string"
+/*
+ * ownFields returns the fields declared directly on a type,
+ * excluding those promoted from embedded structs.
+ */
+func ownFields(typeDef TypeDef) []FieldDef {
+	var fields []FieldDef
+	for _, field := range typeDef.Fields {
+		if field.EmbeddedFrom == "" {
+			fields = append(fields, field)
 		}
-		/* Assume custom type reference */
-		return goType
 	}
+	return fields
+}
+
+/*
+ * typeIdents extracts the identifiers referenced by a Go type string,
+ * keeping package paths such as "github.com/google/uuid.UUID" whole.
+ */
+func typeIdents(goType string) []string {
+	return strings.FieldsFunc(goType, func(r rune) bool {
+		return !(r == '.' || r == '/' || r == '-' || isIdentRune(r))
+	})
+}
+
+func (g *TypeScriptGenerator) generateIndex(modules []string) error {
+	var sb strings.Builder
+
+	sb.WriteString("/*\n")
+	sb.WriteString(" * GoAstra Models - Barrel Export\n")
+	sb.WriteString(" * Auto-generated by GoAstra typesync\n")
+	sb.WriteString(" */\n\n")
+
+	for _, module := range modules {
+		sb.WriteString(fmt.Sprintf("export * from './%s';\n", module))
+	}
+
+	path := filepath.Join(g.outputPath, "index.ts")
+	g.out.Add(path, sb.String())
+	return nil
 }
 
 func toCamelCaseTS(s string) string {
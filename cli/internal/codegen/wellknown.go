@@ -0,0 +1,81 @@
+/*
+ * GoAstra CLI - Well-Known Type Mappings
+ *
+ * Built-in TypeScript mappings for common Go types from the standard
+ * library and popular modules whose JSON encoding is not obvious from
+ * their underlying Go type.
+ */
+package codegen
+
+/*
+ * TypeMapping describes how a Go named type is represented in TypeScript.
+ * Import names the module the TypeScript type is imported from, if any.
+ * Format is an optional hint such as "date-time" for documentation.
+ */
+type TypeMapping struct {
+	TS     string `json:"type"`
+	Import string `json:"import,omitempty"`
+	Format string `json:"format,omitempty"`
+}
+
+/*
+ * wellKnownTypes maps fully qualified Go type names to TypeScript types.
+ */
+var wellKnownTypes = map[string]TypeMapping{
+	"time.Time":                {TS: "string", Format: "date-time"},
+	"time.Duration":            {TS: "number"},
+	"encoding/json.RawMessage": {TS: "unknown"},
+	"encoding/json.Number":     {TS: "number"},
+	"math/big.Int":             {TS: "number"},
+	"math/big.Float":           {TS: "string", Format: "decimal"},
+	"net/netip.Addr":           {TS: "string", Format: "ip"},
+	"net.IP":                   {TS: "string", Format: "ip"},
+
+	"database/sql.NullString":  {TS: "{ String: string; Valid: boolean }"},
+	"database/sql.NullBool":    {TS: "{ Bool: boolean; Valid: boolean }"},
+	"database/sql.NullByte":    {TS: "{ Byte: number; Valid: boolean }"},
+	"database/sql.NullInt16":   {TS: "{ Int16: number; Valid: boolean }"},
+	"database/sql.NullInt32":   {TS: "{ Int32: number; Valid: boolean }"},
+	"database/sql.NullInt64":   {TS: "{ Int64: number; Valid: boolean }"},
+	"database/sql.NullFloat64": {TS: "{ Float64: number; Valid: boolean }"},
+	"database/sql.NullTime":    {TS: "{ Time: string; Valid: boolean }"},
+
+	"github.com/google/uuid.UUID":               {TS: "string", Format: "uuid"},
+	"github.com/google/uuid.NullUUID":           {TS: "string | null", Format: "uuid"},
+	"github.com/gofrs/uuid.UUID":                {TS: "string", Format: "uuid"},
+	"github.com/gofrs/uuid.NullUUID":            {TS: "string | null", Format: "uuid"},
+	"github.com/satori/go.uuid.UUID":            {TS: "string", Format: "uuid"},
+	"github.com/shopspring/decimal.Decimal":     {TS: "string", Format: "decimal"},
+	"github.com/shopspring/decimal.NullDecimal": {TS: "string | null", Format: "decimal"},
+	"github.com/lib/pq.NullTime":                {TS: "{ Time: string; Valid: boolean }"},
+	"github.com/lib/pq.StringArray":             {TS: "string[]"},
+	"github.com/lib/pq.Int64Array":              {TS: "number[]"},
+	"github.com/lib/pq.Float64Array":            {TS: "number[]"},
+	"github.com/lib/pq.BoolArray":               {TS: "boolean[]"},
+	"gopkg.in/guregu/null.v4.String":            {TS: "string | null"},
+	"gopkg.in/guregu/null.v4.Int":               {TS: "number | null"},
+	"gopkg.in/guregu/null.v4.Float":             {TS: "number | null"},
+	"gopkg.in/guregu/null.v4.Bool":              {TS: "boolean | null"},
+	"gopkg.in/guregu/null.v4.Time":              {TS: "string | null", Format: "date-time"},
+}
+
+/*
+ * TypeMappings holds project-specific mappings from goastra.json.
+ * Keys are Go type names, either fully qualified
+ * ("github.com/shopspring/decimal.Decimal"), package-qualified
+ * ("decimal.Decimal") or bare for schema and basic types ("int64").
+ * They take precedence over the built-in table.
+ */
+type TypeMappings map[string]TypeMapping
+
+/*
+ * lookup returns the mapping for a type string produced by the
+ * resolver, if it names a mapped type.
+ */
+func (m TypeMappings) lookup(goType string) (TypeMapping, bool) {
+	if mapping, ok := m[goType]; ok {
+		return mapping, true
+	}
+	mapping, ok := wellKnownTypes[goType]
+	return mapping, ok
+}
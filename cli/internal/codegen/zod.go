@@ -0,0 +1,576 @@
+/*
+ * GoAstra CLI - Zod Schema Generator
+ *
+ * Generates Zod validation schemas next to the TypeScript interfaces.
+ * Schemas follow the Go field types, pointer and omitempty nullability,
+ * and go-playground `validate` tags, so API responses can be checked
+ * at runtime.
+ */
+package codegen
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+/*
+ * ZodGenerator creates `*.schema.ts` files from Go types.
+ */
+type ZodGenerator struct {
+	outputPath    string
+	enumStyle     EnumStyle
+	layout        Layout
+	mappings      TypeMappings
+	known         map[string]TypeDef
+	modules       map[string]string
+	schemaModules map[string]string
+	out           *Output
+}
+
+/*
+ * validateRules holds the parsed rules of a `validate` struct tag.
+ * With omitempty the checks do not apply to the zero value.
+ */
+type validateRules struct {
+	required  bool
+	omitempty bool
+	checks    []validateCheck
+}
+
+type validateCheck struct {
+	name  string
+	param string
+}
+
+/*
+ * NewZodGenerator creates a new generator instance.
+ */
+func NewZodGenerator(outputPath string) *ZodGenerator {
+	return &ZodGenerator{outputPath: outputPath, enumStyle: EnumUnion, layout: LayoutPerType}
+}
+
+/*
+ * SetEnumStyle must match the TypeScript generator so enum schemas
+ * validate the same values.
+ */
+func (g *ZodGenerator) SetEnumStyle(style EnumStyle) {
+	g.enumStyle = style
+}
+
+/*
+ * SetLayout must match the TypeScript generator: schemas are grouped
+ * like the interfaces and import them from their modules.
+ */
+func (g *ZodGenerator) SetLayout(layout Layout) {
+	g.layout = layout
+}
+
+/*
+ * SetTypeMappings registers project-specific type mappings.
+ */
+func (g *ZodGenerator) SetTypeMappings(mappings TypeMappings) {
+	g.mappings = mappings
+}
+
+/*
+ * Render renders the schemas into out, grouped into modules like the
+ * interfaces they validate, and exports them from the models barrel
+ * file.
+ */
+func (g *ZodGenerator) Render(types []TypeDef, out *Output) error {
+	g.out = out
+	g.known = make(map[string]TypeDef)
+	for _, typeDef := range types {
+		g.known[typeDef.Name] = typeDef
+	}
+	g.modules = modulePaths(types, g.layout)
+	g.schemaModules = make(map[string]string, len(types))
+
+	var modules []string
+	members := make(map[string][]TypeDef)
+	for _, typeDef := range types {
+		module := g.schemaModule(typeDef)
+		g.schemaModules[typeDef.Name] = module
+		if _, ok := members[module]; !ok {
+			modules = append(modules, module)
+		}
+		members[module] = append(members[module], typeDef)
+	}
+
+	var exports strings.Builder
+	for _, module := range modules {
+		g.renderModule(module, members[module])
+		exports.WriteString(fmt.Sprintf("export * from './%s';\n", module))
+	}
+
+	indexPath := filepath.Join(g.outputPath, "index.ts")
+	out.Add(indexPath, out.files[filepath.Clean(indexPath)]+exports.String())
+	return nil
+}
+
+/*
+ * schemaModule returns the module of a type's schema: `<type>.schema`
+ * in the per-type layout, otherwise the module of its interface with a
+ * `.schema` suffix, e.g. `models.schema` in the bundle layout.
+ */
+func (g *ZodGenerator) schemaModule(typeDef TypeDef) string {
+	switch g.layout {
+	case LayoutPerFile, LayoutPerPackage, LayoutBundle:
+		return g.modules[typeDef.Name] + ".schema"
+	}
+	return toKebabCase(typeDef.Name) + ".schema"
+}
+
+/*
+ * renderModule writes the schemas of one module, importing the types
+ * they validate and the schemas they reference from other modules.
+ * Enum schemas come first, as object schemas use them directly.
+ */
+func (g *ZodGenerator) renderModule(module string, types []TypeDef) {
+	ordered := make([]TypeDef, 0, len(types))
+	for _, typeDef := range types {
+		if typeDef.IsEnum() {
+			ordered = append(ordered, typeDef)
+		}
+	}
+	for _, typeDef := range types {
+		if !typeDef.IsEnum() {
+			ordered = append(ordered, typeDef)
+		}
+	}
+
+	var goTypes []string
+	typeImports := newImportList()
+	schemaImports := newImportList()
+	var body strings.Builder
+	for i, typeDef := range ordered {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		if typeDef.IsEnum() {
+			if g.enumStyle == EnumTS {
+				typeImports.add(g.modules[typeDef.Name], typeDef.Name)
+			}
+			body.WriteString(g.enumSchema(typeDef))
+			continue
+		}
+
+		for _, field := range typeDef.Fields {
+			if field.TSType == "" {
+				goTypes = append(goTypes, field.Type)
+			}
+		}
+		if len(typeDef.TypeParams) == 0 {
+			typeImports.add(g.modules[typeDef.Name], typeDef.Name)
+		}
+		for _, ref := range g.schemaRefs(typeDef) {
+			if target := g.schemaModules[ref]; target != module {
+				schemaImports.add(target, ref+"Schema")
+			}
+		}
+		body.WriteString(g.objectSchema(typeDef))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("/*\n")
+	if len(types) > 1 {
+		sb.WriteString(fmt.Sprintf(" * %s validation schemas\n", strings.TrimSuffix(module, ".schema")))
+	} else {
+		sb.WriteString(fmt.Sprintf(" * %s validation schema\n", types[0].Name))
+	}
+	sb.WriteString(" * Auto-generated by GoAstra typesync\n")
+	sb.WriteString(" */\n\n")
+
+	sb.WriteString("import { z } from 'zod';\n")
+	for _, stmt := range g.mappings.mappingImports(goTypes...) {
+		sb.WriteString(stmt + "\n")
+	}
+	for _, imports := range []*importList{typeImports, schemaImports} {
+		for _, target := range imports.modules {
+			sb.WriteString(fmt.Sprintf("import { %s } from '%s';\n", strings.Join(imports.names[target], ", "), relativeModule(module, target)))
+		}
+	}
+	sb.WriteString("\n")
+	sb.WriteString(body.String())
+
+	g.out.Add(filepath.Join(g.outputPath, filepath.FromSlash(module)+".ts"), sb.String())
+}
+
+/*
+ * importList collects the names imported from each module, in order.
+ */
+type importList struct {
+	modules []string
+	names   map[string][]string
+}
+
+func newImportList() *importList {
+	return &importList{names: make(map[string][]string)}
+}
+
+func (l *importList) add(module, name string) {
+	names, ok := l.names[module]
+	if !ok {
+		l.modules = append(l.modules, module)
+	}
+	for _, existing := range names {
+		if existing == name {
+			return
+		}
+	}
+	l.names[module] = append(names, name)
+}
+
+func (g *ZodGenerator) enumSchema(typeDef TypeDef) string {
+	if g.enumStyle == EnumTS {
+		return fmt.Sprintf("export const %sSchema = z.nativeEnum(%s);\n", typeDef.Name, typeDef.Name)
+	}
+	literals := make([]string, len(typeDef.Enum))
+	for i, value := range typeDef.Enum {
+		literals[i] = tsLiteral(typeDef.Underlying, value.Value)
+	}
+	return fmt.Sprintf("export const %sSchema = %s;\n", typeDef.Name, zodLiterals(typeDef.Underlying, literals))
+}
+
+func (g *ZodGenerator) objectSchema(typeDef TypeDef) string {
+	params := make(map[string]string)
+	for _, param := range typeDef.TypeParams {
+		params[param.Name] = toCamelCaseTS(param.Name) + "Schema"
+	}
+
+	var body strings.Builder
+	body.WriteString("z.object({\n")
+	for _, field := range typeDef.Fields {
+		jsonName := field.JSONName
+		if jsonName == "" {
+			jsonName = toCamelCaseTS(field.Name)
+		}
+		body.WriteString(fmt.Sprintf("  %s: %s,\n", jsonName, g.fieldSchema(field, params)))
+	}
+	body.WriteString("})")
+
+	if len(typeDef.TypeParams) > 0 {
+		generics := make([]string, len(typeDef.TypeParams))
+		args := make([]string, len(typeDef.TypeParams))
+		for i, param := range typeDef.TypeParams {
+			generics[i] = param.Name + " extends z.ZodTypeAny"
+			args[i] = fmt.Sprintf("%s: %s", params[param.Name], param.Name)
+		}
+		return fmt.Sprintf("export const %sSchema = <%s>(%s) =>\n  %s;\n",
+			typeDef.Name, strings.Join(generics, ", "), strings.Join(args, ", "),
+			strings.ReplaceAll(body.String(), "\n", "\n  "))
+	}
+	return fmt.Sprintf("export const %sSchema: z.ZodType<%s> = %s;\n", typeDef.Name, typeDef.Name, body.String())
+}
+
+/*
+ * schemaRefs lists the generated schemas a type's fields reference.
+ */
+func (g *ZodGenerator) schemaRefs(typeDef TypeDef) []string {
+	seen := map[string]bool{typeDef.Name: true}
+	var refs []string
+	for _, field := range typeDef.Fields {
+		if field.TSType != "" {
+			continue
+		}
+		for _, ident := range typeIdents(field.Type) {
+			if _, ok := g.known[ident]; ok && !seen[ident] {
+				if _, mapped := g.mappings.lookup(ident); mapped {
+					continue
+				}
+				seen[ident] = true
+				refs = append(refs, ident)
+			}
+		}
+	}
+	return refs
+}
+
+/*
+ * fieldSchema builds the schema for a field: its type, validate rules,
+ * pointer nullability and omitempty optionality.
+ */
+func (g *ZodGenerator) fieldSchema(field FieldDef, params map[string]string) string {
+	rules := parseValidateTag(field.Validate)
+
+	base := field.Type
+	nullable := strings.HasPrefix(base, "*")
+	if nullable {
+		base = base[1:]
+	}
+
+	var schema string
+	if field.TSType != "" {
+		schema = g.zodForTS(field.TSType, "")
+	} else {
+		schema = g.zodType(base, params)
+	}
+	schema = g.applyRules(schema, g.valueKind(base, field.TSType), rules)
+
+	if nullable && field.TSType == "" && !rules.required {
+		schema += ".nullable()"
+	}
+	if field.Optional && !rules.required {
+		schema += ".optional()"
+	}
+	return schema
+}
+
+/*
+ * zodType converts a Go type string to a Zod schema expression.
+ * References to other object schemas are lazy so modules may import
+ * each other in any order.
+ */
+func (g *ZodGenerator) zodType(goType string, params map[string]string) string {
+	goType = strings.TrimSpace(goType)
+
+	if strings.HasPrefix(goType, "*") {
+		inner := g.zodType(goType[1:], params)
+		if strings.HasSuffix(inner, ".nullable()") {
+			return inner
+		}
+		return inner + ".nullable()"
+	}
+	if strings.HasPrefix(goType, "[]") {
+		return "z.array(" + g.zodType(goType[2:], params) + ")"
+	}
+	if mapping, ok := g.mappings.lookup(goType); ok {
+		return g.zodForTS(mapping.TS, mapping.Format)
+	}
+	if _, value, ok := splitMapType(goType); ok {
+		return fmt.Sprintf("z.record(z.string(), %s)", g.zodType(value, params))
+	}
+	if name, args, ok := splitGenericType(goType); ok {
+		schemas := make([]string, len(args))
+		for i, arg := range args {
+			schemas[i] = g.zodType(arg, params)
+		}
+		return fmt.Sprintf("z.lazy(() => %sSchema(%s))", name, strings.Join(schemas, ", "))
+	}
+	if param, ok := params[goType]; ok {
+		return param
+	}
+
+	switch goType {
+	case "string":
+		return "z.string()"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune":
+		return "z.number().int()"
+	case "float32", "float64":
+		return "z.number()"
+	case "bool":
+		return "z.boolean()"
+	case "interface{}", "any":
+		return "z.unknown()"
+	}
+
+	if typeDef, ok := g.known[goType]; ok {
+		if typeDef.IsEnum() {
+			return goType + "Schema"
+		}
+		return fmt.Sprintf("z.lazy(() => %sSchema)", goType)
+	}
+	return "z.unknown()"
+}
+
+/*
+ * zodForTS converts a mapped TypeScript type to a Zod schema. Types it
+ * cannot express are accepted as-is with z.custom.
+ */
+func (g *ZodGenerator) zodForTS(tsType, format string) string {
+	tsType = strings.TrimSpace(tsType)
+
+	if inner, ok := strings.CutSuffix(tsType, " | null"); ok {
+		return g.zodForTS(inner, format) + ".nullable()"
+	}
+	if inner, ok := strings.CutSuffix(tsType, "[]"); ok && !strings.Contains(inner, " ") {
+		return "z.array(" + g.zodForTS(inner, "") + ")"
+	}
+
+	switch tsType {
+	case "string":
+		switch format {
+		case "date-time":
+			return "z.string().datetime({ offset: true })"
+		case "uuid":
+			return "z.string().uuid()"
+		case "ip":
+			return "z.string().ip()"
+		}
+		return "z.string()"
+	case "number":
+		return "z.number()"
+	case "boolean":
+		return "z.boolean()"
+	case "unknown", "any":
+		return "z.unknown()"
+	}
+
+	/* Inline object types such as { String: string; Valid: boolean } */
+	if strings.HasPrefix(tsType, "{") && strings.HasSuffix(tsType, "}") {
+		var props []string
+		for _, member := range strings.Split(strings.Trim(tsType, "{} "), ";") {
+			name, memberType, ok := strings.Cut(member, ":")
+			if !ok {
+				continue
+			}
+			props = append(props, fmt.Sprintf("%s: %s", strings.TrimSpace(name), g.zodForTS(memberType, "")))
+		}
+		return "z.object({ " + strings.Join(props, ", ") + " })"
+	}
+
+	return fmt.Sprintf("z.custom<%s>()", tsType)
+}
+
+/*
+ * valueKind classifies a field for validate rules: string, number or array.
+ */
+func (g *ZodGenerator) valueKind(goType, tsType string) string {
+	if tsType != "" {
+		return ""
+	}
+	if strings.HasPrefix(goType, "[]") {
+		return "array"
+	}
+	if mapping, ok := g.mappings.lookup(goType); ok {
+		switch mapping.TS {
+		case "string", "number":
+			return mapping.TS
+		}
+		return ""
+	}
+	switch g.zodType(goType, nil) {
+	case "z.string()":
+		return "string"
+	case "z.number()", "z.number().int()":
+		return "number"
+	}
+	return ""
+}
+
+/*
+ * applyRules appends Zod refinements for supported validate rules:
+ * required, min, max, len, gt, gte, lt, lte, email, url, uuid and oneof.
+ * Under omitempty the zero value is accepted as well, as the validator
+ * skips the checks for it.
+ */
+func (g *ZodGenerator) applyRules(schema, kind string, rules validateRules) string {
+	if kind == "string" && rules.required {
+		schema += ".min(1)"
+	}
+
+	base := schema
+	schema = g.applyChecks(schema, kind, rules.checks)
+	if rules.omitempty && schema != base {
+		switch kind {
+		case "string":
+			schema += ".or(z.literal(''))"
+		case "number":
+			schema += ".or(z.literal(0))"
+		case "array":
+			schema += ".or(z.tuple([]))"
+		}
+	}
+	return schema
+}
+
+/*
+ * applyChecks appends the refinements of the checks other than required.
+ */
+func (g *ZodGenerator) applyChecks(schema, kind string, checks []validateCheck) string {
+
+	for _, check := range checks {
+		switch {
+		case check.name == "oneof" && (kind == "string" || kind == "number"):
+			values := oneofValues(check.param)
+			literals := make([]string, len(values))
+			for i, value := range values {
+				if kind == "string" {
+					literals[i] = tsLiteral("string", value)
+				} else {
+					literals[i] = value
+				}
+			}
+			underlying := "string"
+			if kind == "number" {
+				underlying = "int"
+			}
+			schema = zodLiterals(underlying, literals)
+		case (check.name == "min" || check.name == "max") && kind != "":
+			schema += fmt.Sprintf(".%s(%s)", check.name, check.param)
+		case check.name == "len" && (kind == "string" || kind == "array"):
+			schema += fmt.Sprintf(".length(%s)", check.param)
+		case kind == "number" && (check.name == "gt" || check.name == "lt"):
+			schema += fmt.Sprintf(".%s(%s)", check.name, check.param)
+		case kind == "number" && check.name == "gte":
+			schema += fmt.Sprintf(".min(%s)", check.param)
+		case kind == "number" && check.name == "lte":
+			schema += fmt.Sprintf(".max(%s)", check.param)
+		case kind == "string" && (check.name == "email" || check.name == "url" || check.name == "uuid"):
+			schema += fmt.Sprintf(".%s()", check.name)
+		}
+	}
+
+	return schema
+}
+
+/*
+ * zodLiterals renders a schema accepting exactly the given literals.
+ */
+func zodLiterals(underlying string, literals []string) string {
+	if underlying == "string" && len(literals) > 0 {
+		return "z.enum([" + strings.Join(literals, ", ") + "])"
+	}
+	if len(literals) == 1 {
+		return "z.literal(" + literals[0] + ")"
+	}
+	members := make([]string, len(literals))
+	for i, literal := range literals {
+		members[i] = "z.literal(" + literal + ")"
+	}
+	return "z.union([" + strings.Join(members, ", ") + "])"
+}
+
+/*
+ * parseValidateTag parses a go-playground validator tag. Rules after
+ * `dive` apply to elements and are ignored.
+ */
+func parseValidateTag(tag string) validateRules {
+	var rules validateRules
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "":
+		case "dive":
+			return rules
+		case "required":
+			rules.required = true
+		case "omitempty":
+			rules.omitempty = true
+		default:
+			rules.checks = append(rules.checks, validateCheck{name: name, param: param})
+		}
+	}
+	return rules
+}
+
+/*
+ * oneofSplit matches the values of a oneof rule as go-playground does:
+ * single-quoted values may contain spaces.
+ */
+var oneofSplit = regexp.MustCompile(`'[^']*'|\S+`)
+
+/*
+ * oneofValues returns the values of a oneof rule, so that
+ * oneof='in progress' done allows "in progress" and "done".
+ */
+func oneofValues(param string) []string {
+	values := oneofSplit.FindAllString(param, -1)
+	for i, value := range values {
+		values[i] = strings.ReplaceAll(value, "'", "")
+	}
+	return values
+}
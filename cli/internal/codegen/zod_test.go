@@ -0,0 +1,147 @@
+package codegen
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyRulesOneof(t *testing.T) {
+	tests := []struct {
+		name string
+		kind string
+		tag  string
+		want string
+	}{
+		{"words", "string", "oneof=draft done", `z.enum(['draft', 'done'])`},
+		{"quoted", "string", "required,oneof='in progress' done", `z.enum(['in progress', 'done'])`},
+		{"numbers", "number", "oneof=1 2", `z.union([z.literal(1), z.literal(2)])`},
+	}
+
+	g := NewZodGenerator("")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := "z.string()"
+			if tt.kind == "number" {
+				schema = "z.number()"
+			}
+			if got := g.applyRules(schema, tt.kind, parseValidateTag(tt.tag)); got != tt.want {
+				t.Errorf("applyRules(%q) = %s, want %s", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRulesOmitempty(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		kind   string
+		tag    string
+		want   string
+	}{
+		{"email", "z.string()", "string", "omitempty,email", `z.string().email().or(z.literal(''))`},
+		{"min length", "z.string()", "string", "omitempty,min=1", `z.string().min(1).or(z.literal(''))`},
+		{"oneof", "z.string()", "string", "omitempty,oneof=draft done", `z.enum(['draft', 'done']).or(z.literal(''))`},
+		{"minimum", "z.number().int()", "number", "omitempty,gte=1", `z.number().int().min(1).or(z.literal(0))`},
+		{"array", "z.array(z.string())", "array", "omitempty,min=1", `z.array(z.string()).min(1).or(z.tuple([]))`},
+		{"no checks", "z.string()", "string", "omitempty", `z.string()`},
+		{"without omitempty", "z.string()", "string", "email", `z.string().email()`},
+	}
+
+	g := NewZodGenerator("")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.applyRules(tt.schema, tt.kind, parseValidateTag(tt.tag)); got != tt.want {
+				t.Errorf("applyRules(%q) = %s, want %s", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZodLayouts(t *testing.T) {
+	types := []TypeDef{
+		{Name: "User", Package: "types", File: "user.go", Fields: []FieldDef{
+			{Name: "Role", Type: "Role", JSONName: "role"},
+			{Name: "Address", Type: "*Address", JSONName: "address"},
+		}},
+		{Name: "Role", Package: "types", File: "user.go", Underlying: "string", Enum: []EnumValue{{Name: "RoleAdmin", Value: "admin"}}},
+		{Name: "Address", Package: "types", File: "address.go", Fields: []FieldDef{
+			{Name: "City", Type: "string", JSONName: "city"},
+		}},
+	}
+
+	tests := []struct {
+		layout Layout
+		files  []string
+		user   string // the module holding the User schema
+		want   []string
+	}{
+		{
+			layout: LayoutPerType,
+			files:  []string{"address.schema.ts", "role.schema.ts", "user.schema.ts"},
+			user:   "user.schema.ts",
+			want: []string{
+				"import { User } from './user.interface';",
+				"import { RoleSchema } from './role.schema';\nimport { AddressSchema } from './address.schema';",
+			},
+		},
+		{
+			layout: LayoutPerFile,
+			files:  []string{"address.schema.ts", "user.schema.ts"},
+			user:   "user.schema.ts",
+			want: []string{
+				"import { User } from './user';",
+				"import { AddressSchema } from './address.schema';",
+				"export const RoleSchema = z.enum(['admin']);\n\nexport const UserSchema",
+			},
+		},
+		{
+			layout: LayoutPerPackage,
+			files:  []string{"types.schema.ts"},
+			user:   "types.schema.ts",
+			want: []string{
+				" * types validation schemas\n",
+				"import { User, Address } from './types';\n\n",
+			},
+		},
+		{
+			layout: LayoutBundle,
+			files:  []string{"models.schema.ts"},
+			user:   "models.schema.ts",
+			want: []string{
+				"import { User, Address } from './models';\n\n",
+				"export const RoleSchema = z.enum(['admin']);\n\nexport const UserSchema",
+				"export * from './models.schema';\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.layout), func(t *testing.T) {
+			g := NewZodGenerator("out")
+			g.SetLayout(tt.layout)
+			out := NewOutput()
+			if err := g.Render(types, out); err != nil {
+				t.Fatal(err)
+			}
+
+			var files []string
+			for _, path := range out.Paths() {
+				if path != filepath.Join("out", "index.ts") {
+					files = append(files, filepath.Base(path))
+				}
+			}
+			if !reflect.DeepEqual(files, tt.files) {
+				t.Fatalf("files = %v, want %v", files, tt.files)
+			}
+
+			content := out.files[filepath.Join("out", tt.user)] + out.files[filepath.Join("out", "index.ts")]
+			for _, want := range tt.want {
+				if !strings.Contains(content, want) {
+					t.Errorf("%s does not contain %q:\n%s", tt.user, want, content)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/*
+ * GoAstra CLI - Project Configuration
+ *
+ * Loads goastra.json from the project root. Missing sections and
+ * fields fall back to the defaults written by `goastra new`.
+ */
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+/*
+ * FileName is the project configuration file name.
+ */
+const FileName = "goastra.json"
+
+/*
+ * Config mirrors the sections of goastra.json used by the CLI.
+ */
+type Config struct {
+	Name       string           `json:"name"`
+	API        APIConfig        `json:"api"`
+	Backend    BackendConfig    `json:"backend"`
+	Codegen    CodegenConfig    `json:"codegen"`
+	Database   DatabaseConfig   `json:"database"`
+	Generators GeneratorsConfig `json:"generators"`
+}
+
+/*
+ * APIConfig holds the API style (rest, graphql, trpc) and route prefix.
+ * An empty Type is detected from the project layout.
+ */
+type APIConfig struct {
+	Type    string `json:"type"`
+	Prefix  string `json:"prefix"`
+	Version string `json:"version"`
+}
+
+/*
+ * BackendConfig holds the Go module and server entrypoint.
+ */
+type BackendConfig struct {
+	Port       int    `json:"port"`
+	Module     string `json:"module"`
+	Entrypoint string `json:"entrypoint"`
+}
+
+/*
+ * CodegenConfig controls typesync and the generator templates.
+ * SchemaPaths lists schema packages in addition to SchemaPath, and
+ * Layout groups generated types into modules (per-type, per-file,
+ * per-package, bundle). TypeMappings maps Go type names to TypeScript
+ * types; Readonly marks every generated interface field readonly, Zod
+ * enables validation schemas and Target selects the client framework
+ * (angular, fetch, react-query, vue). Templates is the directory of
+ * overrides for the goastra generate templates.
+ */
+type CodegenConfig struct {
+	SchemaPath    string                 `json:"schemaPath"`
+	SchemaPaths   []string               `json:"schemaPaths"`
+	Layout        string                 `json:"layout"`
+	OutputPath    string                 `json:"outputPath"`
+	ServiceOutput string                 `json:"serviceOutput"`
+	Templates     string                 `json:"templates"`
+	Watch         bool                   `json:"watch"`
+	Readonly      bool                   `json:"readonly"`
+	Zod           bool                   `json:"zod"`
+	Target        string                 `json:"target"`
+	TypeMappings  map[string]TypeMapping `json:"typeMappings"`
+}
+
+/*
+ * TypeMapping maps a Go type to a TypeScript type, optionally
+ * imported from a module: {"type": "Decimal", "import": "decimal.js"}.
+ */
+type TypeMapping struct {
+	Type   string `json:"type"`
+	Import string `json:"import,omitempty"`
+}
+
+/*
+ * DatabaseConfig holds the database driver, ORM (sqlx, ent) and
+ * migration paths. An empty ORM is detected from the project layout,
+ * and generators use the driver the backend's go.mod requires when it
+ * disagrees with Driver.
+ */
+type DatabaseConfig struct {
+	Driver         string `json:"driver"`
+	ORM            string `json:"orm"`
+	MigrationsPath string `json:"migrationsPath"`
+	SeedsPath      string `json:"seedsPath"`
+}
+
+/*
+ * GeneratorsConfig holds per-generator settings.
+ */
+type GeneratorsConfig struct {
+	API    APIGeneratorConfig    `json:"api"`
+	Module ModuleGeneratorConfig `json:"module"`
+	CRUD   CRUDGeneratorConfig   `json:"crud"`
+}
+
+/*
+ * APIGeneratorConfig holds output paths for backend layers, and the
+ * router, database, RPC and validator packages generated code is wired
+ * into.
+ */
+type APIGeneratorConfig struct {
+	HandlerPath    string `json:"handlerPath"`
+	ServicePath    string `json:"servicePath"`
+	RepositoryPath string `json:"repositoryPath"`
+	ModelPath      string `json:"modelPath"`
+	RouterPath     string `json:"routerPath"`
+	DatabasePath   string `json:"databasePath"`
+	RPCPath        string `json:"rpcPath"`
+	ValidatorPath  string `json:"validatorPath"`
+}
+
+/*
+ * ModuleGeneratorConfig holds Angular feature module settings.
+ */
+type ModuleGeneratorConfig struct {
+	BasePath   string `json:"basePath"`
+	Style      string `json:"style"`
+	Standalone bool   `json:"standalone"`
+}
+
+/*
+ * CRUDGeneratorConfig holds CRUD generator settings. GenerateTests
+ * writes Go tests and Angular specs beside every generated resource.
+ */
+type CRUDGeneratorConfig struct {
+	GenerateMigration bool `json:"generateMigration"`
+	GenerateTests     bool `json:"generateTests"`
+}
+
+/*
+ * Default returns the configuration used when goastra.json is absent.
+ */
+func Default() *Config {
+	return &Config{
+		API: APIConfig{
+			Prefix:  "/api/v1",
+			Version: "v1",
+		},
+		Backend: BackendConfig{
+			Port:       8080,
+			Entrypoint: "app/cmd/server",
+		},
+		Codegen: CodegenConfig{
+			SchemaPath:    "schema/types",
+			OutputPath:    "web/src/app/core/models",
+			ServiceOutput: "web/src/app/core/services/generated",
+			Templates:     "schema/templates",
+			Layout:        "per-type",
+			Target:        "angular",
+		},
+		Database: DatabaseConfig{
+			Driver:         "postgres",
+			MigrationsPath: "app/migrations",
+			SeedsPath:      "app/seeds",
+		},
+		Generators: GeneratorsConfig{
+			API: APIGeneratorConfig{
+				HandlerPath:    "app/internal/handlers",
+				ServicePath:    "app/internal/services",
+				RepositoryPath: "app/internal/repository",
+				ModelPath:      "app/internal/models",
+				RouterPath:     "app/internal/router",
+				DatabasePath:   "app/internal/database",
+				RPCPath:        "app/internal/rpc",
+				ValidatorPath:  "app/internal/validator",
+			},
+			Module: ModuleGeneratorConfig{
+				BasePath:   "web/src/app/features",
+				Style:      "css",
+				Standalone: true,
+			},
+			CRUD: CRUDGeneratorConfig{
+				GenerateMigration: true,
+				GenerateTests:     true,
+			},
+		},
+	}
+}
+
+/*
+ * Load reads the configuration file at path, or goastra.json in the
+ * working directory when path is empty. A missing default file yields
+ * the defaults; a missing explicit file is an error.
+ */
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = FileName
+	}
+
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
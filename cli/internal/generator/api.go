@@ -7,8 +7,6 @@
 package generator
 
 import (
-	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 	"unicode"
@@ -21,6 +19,14 @@ type APIGenerator struct {
 	name       string
 	pascalName string
 	camelName  string
+	fields     []Field
+	driver     string
+	table      string
+	timestamps bool
+	relations  []Relation
+	paths      Paths
+	stack      Stack
+	out        *Writer
 }
 
 /*
@@ -31,502 +37,361 @@ func NewAPIGenerator(name string) *APIGenerator {
 		name:       name,
 		pascalName: toPascalCase(name),
 		camelName:  toCamelCase(name),
+		driver:     "postgres",
+		timestamps: true,
+		paths:      DefaultPaths(),
+		stack:      DefaultStack(),
+		out:        NewWriter(nil),
 	}
 }
 
 /*
- * GenerateHandler creates the HTTP handler file.
- */
-func (g *APIGenerator) GenerateHandler() error {
-	content := fmt.Sprintf(`/*
- * %s Handler
- *
- * HTTP handlers for %s CRUD operations.
+ * SetWriter sets the writer generated files go through.
  */
-package handlers
-
-import (
-	"net/http"
-	"strconv"
-
-	"github.com/gin-gonic/gin"
-)
+func (g *APIGenerator) SetWriter(out *Writer) {
+	g.out = out
+}
 
 /*
- * %sHandler manages HTTP requests for %s resources.
+ * SetPaths sets the output directories and module path.
  */
-type %sHandler struct {
-	service *%sService
+func (g *APIGenerator) SetPaths(paths Paths) {
+	g.paths = paths
 }
 
 /*
- * New%sHandler creates a new handler instance.
+ * SetDriver selects the database driver (postgres, mysql, sqlite)
+ * the repository's SQL is written for.
  */
-func New%sHandler(service *%sService) *%sHandler {
-	return &%sHandler{service: service}
+func (g *APIGenerator) SetDriver(driver string) {
+	g.driver = driver
 }
 
 /*
- * List returns all %s resources with pagination.
+ * SetTable sets the table the repository reads and writes, for
+ * resources built on an existing table.
  */
-func (h *%sHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-
-	result, err := h.service.List(c.Request.Context(), page, pageSize)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, result)
+func (g *APIGenerator) SetTable(table string) {
+	g.table = table
 }
 
 /*
- * Get returns a single %s by ID.
+ * SetTimestamps sets whether the table has the created_at and
+ * updated_at columns the repository maintains and orders by.
  */
-func (h *%sHandler) Get(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
-		return
-	}
-
-	result, err := h.service.GetByID(c.Request.Context(), uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-		return
-	}
-
-	c.JSON(http.StatusOK, result)
+func (g *APIGenerator) SetTimestamps(timestamps bool) {
+	g.timestamps = timestamps
 }
 
 /*
- * Create adds a new %s resource.
+ * SetRelations sets the resources the resource is related to, which
+ * the repository loads and the routes nest.
  */
-func (h *%sHandler) Create(c *gin.Context) {
-	var input Create%sInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	result, err := h.service.Create(c.Request.Context(), &input)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusCreated, result)
+func (g *APIGenerator) SetRelations(relations []Relation) {
+	g.relations = relations
 }
 
 /*
- * Update modifies an existing %s resource.
+ * tableName returns the resource's table, by default the snake_case
+ * resource name.
  */
-func (h *%sHandler) Update(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
-		return
-	}
-
-	var input Update%sInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	result, err := h.service.Update(c.Request.Context(), uint(id), &input)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+func (g *APIGenerator) tableName() string {
+	if g.table != "" {
+		return g.table
 	}
+	return toSnakeCase(g.name)
+}
 
-	c.JSON(http.StatusOK, result)
+/*
+ * SetStack sets the project's API style and ORM, which decide whether
+ * the service is served by gin handlers, GraphQL resolvers or a
+ * Connect service, and whether the repository uses sqlx or Ent.
+ */
+func (g *APIGenerator) SetStack(stack Stack) {
+	g.stack = stack
 }
 
 /*
- * Delete removes a %s resource.
+ * GenerateAPI writes the layers serving the resource in the project's
+ * API style: handler and routes for REST, resolvers for GraphQL or an
+ * RPC service for tRPC, on top of the service and repository.
  */
-func (h *%sHandler) Delete(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
-		return
+func (g *APIGenerator) GenerateAPI() error {
+	if err := g.GenerateService(); err != nil {
+		return err
 	}
-
-	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	if err := g.GenerateRepository(); err != nil {
+		return err
 	}
 
-	c.JSON(http.StatusNoContent, nil)
-}
+	switch g.stack.API {
+	case "graphql":
+		gen := NewGraphQLGenerator(g.name)
+		gen.SetFields(g.fields)
+		gen.SetTimestamps(g.timestamps)
+		gen.SetPaths(g.paths)
+		gen.SetStack(g.stack)
+		gen.SetServices(true)
+		gen.SetWriter(g.out)
+		return gen.GenerateAll()
+	case "trpc":
+		gen := NewTRPCGenerator(g.name)
+		gen.SetFields(g.fields)
+		gen.SetTimestamps(g.timestamps)
+		gen.SetPaths(g.paths)
+		gen.SetStack(g.stack)
+		gen.SetServices(true)
+		gen.SetWriter(g.out)
+		return gen.GenerateAll()
+	}
 
-/*
- * Create%sInput defines the request body for creating a %s.
- */
-type Create%sInput struct {
-	/* TODO: Define create input fields */
+	if err := g.GenerateHandler(); err != nil {
+		return err
+	}
+	return g.GenerateRoutes()
 }
 
 /*
- * Update%sInput defines the request body for updating a %s.
+ * GenerateSchema writes the Ent schema of the resource's table in Ent
+ * projects, where it replaces the SQL migration.
  */
-type Update%sInput struct {
-	/* TODO: Define update input fields */
-}
-`,
-		g.pascalName, g.name,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.pascalName,
-		g.name,
-		g.pascalName,
-		g.name,
-		g.pascalName,
-		g.name,
-		g.pascalName,
-		g.pascalName,
-		g.name,
-		g.pascalName,
-		g.pascalName,
-		g.name,
-		g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName,
-	)
-
-	path := filepath.Join("app/internal/handlers", g.name+"_handler.go")
-	return os.WriteFile(path, []byte(content), 0644)
+func (g *APIGenerator) GenerateSchema() error {
+	gen := NewEntGenerator(g.name)
+	gen.SetFields(g.fields)
+	gen.SetTable(g.table)
+	gen.SetTimestamps(g.timestamps)
+	gen.SetRelations(g.relations)
+	gen.SetPaths(g.paths)
+	gen.SetWriter(g.out)
+	return gen.GenerateSchema()
 }
 
 /*
- * GenerateService creates the service layer file.
+ * GenerateModel writes the model the API is built on, unless the
+ * models package already defines it. It reports whether it was written.
  */
-func (g *APIGenerator) GenerateService() error {
-	content := fmt.Sprintf(`/*
- * %s Service
- *
- * Business logic for %s operations.
- */
-package services
+func (g *APIGenerator) GenerateModel() (bool, error) {
+	if fileExists(filepath.Join(g.paths.Models, g.name+".go")) {
+		return false, nil
+	}
 
-import (
-	"context"
-)
+	model := NewCRUDGenerator(g.name)
+	model.SetFields(g.fields)
+	model.SetPaths(g.paths)
+	model.SetWriter(g.out)
+	return true, model.GenerateModel()
+}
 
 /*
- * %sService handles business logic for %s resources.
+ * SetFields sets the resource fields used for input DTOs, entity
+ * mapping and repository column lists.
  */
-type %sService struct {
-	repo *%sRepository
+func (g *APIGenerator) SetFields(fields []Field) {
+	g.fields = fields
 }
 
 /*
- * New%sService creates a new service instance.
+ * render renders a template with the resource's names, fields and relations.
  */
-func New%sService(repo *%sRepository) *%sService {
-	return &%sService{repo: repo}
+func (g *APIGenerator) render(name string, data templateData) (string, error) {
+	return renderTemplate(g.paths.Templates, name, templateData{
+		"Name":       g.name,
+		"Pascal":     g.pascalName,
+		"Camel":      g.camelName,
+		"Snake":      toSnakeCase(g.name),
+		"Plural":     toPlural(g.name),
+		"Fields":     g.fields,
+		"Relations":  g.relations,
+		"Joins":      joins(g.relations),
+		"Timestamps": g.timestamps,
+	}.with(data))
 }
 
 /*
- * List returns paginated %s resources.
+ * GenerateHandler creates the HTTP handler file.
  */
-func (s *%sService) List(ctx context.Context, page, pageSize int) (*PaginatedResult, error) {
-	offset := (page - 1) * pageSize
-
-	items, err := s.repo.FindAll(ctx, offset, pageSize)
+func (g *APIGenerator) GenerateHandler() error {
+	services := packageName(g.paths.Services)
+
+	content, err := g.render("api/handler.go.tmpl", templateData{
+		"HandlersPackage": packageName(g.paths.Handlers),
+		"ModelsImport":    g.paths.ImportPath(g.paths.Models),
+		"ModelsPackage":   packageName(g.paths.Models),
+		"ServicesImport":  g.paths.ImportPath(g.paths.Services),
+		"ServicesPackage": services,
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	total, err := s.repo.Count(ctx)
-	if err != nil {
-		return nil, err
+	path := filepath.Join(g.paths.Handlers, g.name+"_handler.go")
+	if err := g.out.WriteGo(path, content); err != nil {
+		return err
 	}
-
-	return &PaginatedResult{
-		Data:       items,
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: (total + pageSize - 1) / pageSize,
-	}, nil
+	return g.generateValidation()
 }
 
 /*
- * GetByID retrieves a single %s by ID.
+ * generateValidation writes the input validator shared by the handlers
+ * package, unless it already exists.
  */
-func (s *%sService) GetByID(ctx context.Context, id uint) (*%s, error) {
-	return s.repo.FindByID(ctx, id)
-}
-
-/*
- * Create adds a new %s resource.
- */
-func (s *%sService) Create(ctx context.Context, input *Create%sInput) (*%s, error) {
-	entity := &%s{
-		/* TODO: Map input fields */
+func (g *APIGenerator) generateValidation() error {
+	path := filepath.Join(g.paths.Handlers, "validation.go")
+	if fileExists(path) {
+		return nil
 	}
 
-	return s.repo.Create(ctx, entity)
-}
-
-/*
- * Update modifies an existing %s resource.
- */
-func (s *%sService) Update(ctx context.Context, id uint, input *Update%sInput) (*%s, error) {
-	entity, err := s.repo.FindByID(ctx, id)
+	content, err := g.render("api/validation.go.tmpl", templateData{
+		"HandlersPackage": packageName(g.paths.Handlers),
+		"ValidatorImport": g.paths.ImportPath(g.paths.Validator),
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	/* TODO: Map input fields to entity */
-
-	return s.repo.Update(ctx, entity)
+	return g.out.WriteSharedGo(path, content)
 }
 
 /*
- * Delete removes a %s resource.
+ * GenerateService creates the service layer file.
  */
-func (s *%sService) Delete(ctx context.Context, id uint) error {
-	return s.repo.Delete(ctx, id)
-}
+func (g *APIGenerator) GenerateService() error {
+	content, err := g.render("api/service.go.tmpl", templateData{
+		"ServicesPackage":   packageName(g.paths.Services),
+		"Imports":           importList(g.fields, "context"),
+		"ModelsImport":      g.paths.ImportPath(g.paths.Models),
+		"RepositoryPackage": packageName(g.paths.Repository),
+		"ModelsPackage":     packageName(g.paths.Models),
+	})
+	if err != nil {
+		return err
+	}
 
-/*
- * PaginatedResult wraps list results with pagination metadata.
- */
-type PaginatedResult struct {
-	Data       interface{}
-	Total      int
-	Page       int
-	PageSize   int
-	TotalPages int
-}
-`,
-		g.pascalName, g.name,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.pascalName,
-		g.name,
-		g.pascalName,
-		g.name,
-		g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.pascalName,
-		g.name,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName,
-	)
-
-	path := filepath.Join("app/internal/services", g.name+"_service.go")
-	return os.WriteFile(path, []byte(content), 0644)
+	path := filepath.Join(g.paths.Services, g.name+"_service.go")
+	if err := g.out.WriteGo(path, content); err != nil {
+		return err
+	}
+	return g.generatePagination()
 }
 
 /*
- * GenerateRepository creates the data access layer file.
+ * GenerateRepository creates the data access layer file, with sqlx
+ * queries or, in Ent projects, Ent queries.
  */
 func (g *APIGenerator) GenerateRepository() error {
-	content := fmt.Sprintf(`/*
- * %s Repository
- *
- * Data access layer for %s entities.
- */
-package repository
-
-import (
-	"context"
-
-	"github.com/jmoiron/sqlx"
-)
-
-/*
- * %sRepository handles database operations for %s.
- */
-type %sRepository struct {
-	db *sqlx.DB
-}
-
-/*
- * New%sRepository creates a new repository instance.
- */
-func New%sRepository(db *sqlx.DB) *%sRepository {
-	return &%sRepository{db: db}
-}
-
-/*
- * FindAll retrieves paginated %s records.
- */
-func (r *%sRepository) FindAll(ctx context.Context, offset, limit int) ([]*%s, error) {
-	var items []*%s
-
-	query := "SELECT * FROM %s ORDER BY created_at DESC LIMIT $1 OFFSET $2"
+	if g.stack.ORM == "ent" {
+		return g.generateEntRepository()
+	}
 
-	if err := r.db.SelectContext(ctx, &items, query, limit, offset); err != nil {
-		return nil, err
+	content, err := g.render("api/repository.go.tmpl", templateData{
+		"RepositoryPackage": packageName(g.paths.Repository),
+		"ModelsImport":      g.paths.ImportPath(g.paths.Models),
+		"ModelsPackage":     packageName(g.paths.Models),
+		"Table":             g.tableName(),
+		"TableKey":          tableKey(g.tableName()),
+		"Driver":            g.driver,
+	})
+	if err != nil {
+		return err
 	}
 
-	return items, nil
+	path := filepath.Join(g.paths.Repository, g.name+"_repository.go")
+	if err := g.out.WriteGo(path, content); err != nil {
+		return err
+	}
+	return g.generateQuerier()
 }
 
 /*
- * FindByID retrieves a single %s by ID.
+ * generateQuerier writes the Querier type shared by the sqlx
+ * repositories, unless it already exists.
  */
-func (r *%sRepository) FindByID(ctx context.Context, id uint) (*%s, error) {
-	var item %s
-
-	query := "SELECT * FROM %s WHERE id = $1"
-
-	if err := r.db.GetContext(ctx, &item, query, id); err != nil {
-		return nil, err
+func (g *APIGenerator) generateQuerier() error {
+	path := filepath.Join(g.paths.Repository, "querier.go")
+	if fileExists(path) {
+		return nil
 	}
 
-	return &item, nil
-}
-
-/*
- * Create inserts a new %s record.
- */
-func (r *%sRepository) Create(ctx context.Context, entity *%s) (*%s, error) {
-	query := ` + "`" + `
-		INSERT INTO %s (created_at, updated_at)
-		VALUES (NOW(), NOW())
-		RETURNING *
-	` + "`" + `
-
-	if err := r.db.GetContext(ctx, entity, query); err != nil {
-		return nil, err
+	content, err := g.render("api/querier.go.tmpl", templateData{
+		"RepositoryPackage": packageName(g.paths.Repository),
+	})
+	if err != nil {
+		return err
 	}
 
-	return entity, nil
+	return g.out.WriteSharedGo(path, content)
 }
 
 /*
- * Update modifies an existing %s record.
+ * generateEntRepository creates the repository on the Ent client. It
+ * maps Ent entities to the models the service works with.
  */
-func (r *%sRepository) Update(ctx context.Context, entity *%s) (*%s, error) {
-	query := ` + "`" + `
-		UPDATE %s
-		SET updated_at = NOW()
-		WHERE id = $1
-		RETURNING *
-	` + "`" + `
-
-	if err := r.db.GetContext(ctx, entity, query, entity.ID); err != nil {
-		return nil, err
+func (g *APIGenerator) generateEntRepository() error {
+	models := packageName(g.paths.Models)
+	entity := strings.ToLower(g.pascalName)
+
+	content, err := g.render("api/repository_ent.go.tmpl", templateData{
+		"RepositoryPackage": packageName(g.paths.Repository),
+		"EntImport":         g.paths.ImportPath(g.paths.Ent),
+		"EntityImport":      g.paths.ImportPath(filepath.Join(g.paths.Ent, entity)),
+		"ModelsImport":      g.paths.ImportPath(g.paths.Models),
+		"ModelsPackage":     models,
+		"EntityPackage":     entity,
+	})
+	if err != nil {
+		return err
 	}
 
-	return entity, nil
+	path := filepath.Join(g.paths.Repository, g.name+"_repository.go")
+	return g.out.WriteGo(path, content)
 }
 
 /*
- * Delete removes a %s record.
+ * WireRoutes registers the API in the backend router. It reports false
+ * when the router already wires it.
  */
-func (r *%sRepository) Delete(ctx context.Context, id uint) error {
-	query := "DELETE FROM %s WHERE id = $1"
-
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+func (g *APIGenerator) WireRoutes() (bool, error) {
+	wiring := NewRouterWiring(filepath.Join(g.paths.Router, routerFile), g.name)
+	wiring.SetPaths(g.paths)
+	wiring.SetStack(g.stack)
+	return wiring.Wire()
 }
 
 /*
- * Count returns the total number of %s records.
+ * generatePagination writes the PaginatedResult type shared by the
+ * services package, unless it already exists.
  */
-func (r *%sRepository) Count(ctx context.Context) (int, error) {
-	var count int
-
-	query := "SELECT COUNT(*) FROM %s"
+func (g *APIGenerator) generatePagination() error {
+	path := filepath.Join(g.paths.Services, "pagination.go")
+	if fileExists(path) {
+		return nil
+	}
 
-	if err := r.db.GetContext(ctx, &count, query); err != nil {
-		return 0, err
+	content, err := g.render("api/pagination.go.tmpl", templateData{
+		"ServicesPackage": packageName(g.paths.Services),
+	})
+	if err != nil {
+		return err
 	}
 
-	return count, nil
-}
-`,
-		g.pascalName, g.name,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName, g.pascalName,
-		g.pascalName,
-		g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName,
-		toSnakeCase(g.name),
-		g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName,
-		toSnakeCase(g.name),
-		g.name,
-		g.pascalName, g.pascalName, g.pascalName,
-		toSnakeCase(g.name),
-		g.name,
-		g.pascalName, g.pascalName, g.pascalName,
-		toSnakeCase(g.name),
-		g.name,
-		g.pascalName,
-		toSnakeCase(g.name),
-		g.name,
-		g.pascalName,
-		toSnakeCase(g.name),
-	)
-
-	path := filepath.Join("app/internal/repository", g.name+"_repository.go")
-	return os.WriteFile(path, []byte(content), 0644)
+	return g.out.WriteSharedGo(path, content)
 }
 
 /*
  * GenerateRoutes creates route registration for the API.
  */
 func (g *APIGenerator) GenerateRoutes() error {
-	content := fmt.Sprintf(`/*
- * %s Routes
- *
- * Route registration for %s API endpoints.
- */
-package router
-
-import (
-	"github.com/gin-gonic/gin"
-)
-
-/*
- * Register%sRoutes adds %s routes to the router.
- */
-func Register%sRoutes(router *gin.RouterGroup, handler *%sHandler) {
-	%s := router.Group("/%s")
-	{
-		%s.GET("", handler.List)
-		%s.GET("/:id", handler.Get)
-		%s.POST("", handler.Create)
-		%s.PUT("/:id", handler.Update)
-		%s.DELETE("/:id", handler.Delete)
+	content, err := g.render("api/routes.go.tmpl", templateData{
+		"RouterPackage":   packageName(g.paths.Router),
+		"HandlersImport":  g.paths.ImportPath(g.paths.Handlers),
+		"HandlersPackage": packageName(g.paths.Handlers),
+	})
+	if err != nil {
+		return err
 	}
-}
-`,
-		g.pascalName, g.name,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName,
-		g.camelName, toPlural(g.name),
-		g.camelName,
-		g.camelName,
-		g.camelName,
-		g.camelName,
-		g.camelName,
-	)
-
-	path := filepath.Join("app/internal/router", g.name+"_routes.go")
-	return os.WriteFile(path, []byte(content), 0644)
+
+	path := filepath.Join(g.paths.Router, g.name+"_routes.go")
+	return g.out.WriteGo(path, content)
 }
 
 /* Helper functions for name transformations */
@@ -575,3 +440,17 @@ func toPlural(s string) string {
 	}
 	return s + "s"
 }
+
+func toSingular(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies"):
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"), strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "ss"), strings.HasSuffix(s, "us"), strings.HasSuffix(s, "is"):
+		return s
+	case strings.HasSuffix(s, "s"):
+		return s[:len(s)-1]
+	}
+	return s
+}
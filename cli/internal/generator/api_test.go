@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"go/types"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/channdev/goastra/cli/internal/routes"
+	"github.com/channdev/goastra/cli/internal/scaffold"
+)
+
+/*
+ * projectPaths scaffolds the backend of a new REST project in a
+ * temporary directory and returns its generator paths.
+ */
+func projectPaths(t *testing.T) Paths {
+	t.Helper()
+	dir := t.TempDir()
+	err := scaffold.CreateProject(scaffold.Options{
+		ProjectName:  "demo",
+		ProjectPath:  dir,
+		Template:     "default",
+		DBDriver:     "postgres",
+		SkipFrontend: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := DefaultPaths()
+	for _, p := range []*string{
+		&paths.Root, &paths.Handlers, &paths.Services, &paths.Repository, &paths.Models,
+		&paths.Router, &paths.Database, &paths.Validator, &paths.Migrations, &paths.Features,
+	} {
+		*p = filepath.Join(dir, *p)
+	}
+	if _, paths.Module, err = FindModule(paths.Root); err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+func TestGeneratedHandlerBodiesAreAnalyzed(t *testing.T) {
+	if testing.Short() {
+		t.Skip("downloads the backend's dependencies")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	paths := projectPaths(t)
+	fields, err := ParseFields([]string{"name:string:required", "email:string"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen := NewAPIGenerator("widget")
+	gen.SetPaths(paths)
+	gen.SetFields(fields)
+	if _, err := gen.GenerateModel(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.GenerateAPI(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gen.WireRoutes(); err != nil {
+		t.Fatal(err)
+	}
+	if err := BuildCheck(paths, paths.Handlers, paths.Router); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := routes.Analyze(paths.Root, "/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bodies := map[string]string{
+		"POST /api/v1/widgets":    "CreateWidgetInput",
+		"PUT /api/v1/widgets/:id": "UpdateWidgetInput",
+	}
+	for _, route := range analysis.Routes {
+		key := route.Method + " " + route.Path
+		want, ok := bodies[key]
+		if !ok {
+			continue
+		}
+		delete(bodies, key)
+
+		named, ok := types.Unalias(route.Body).(*types.Named)
+		if !ok || named.Obj().Name() != want {
+			t.Errorf("%s: body = %v, want %s", key, route.Body, want)
+		}
+		if !route.Auth {
+			t.Errorf("%s: not behind the auth middleware", key)
+		}
+	}
+	for key := range bodies {
+		t.Errorf("%s: route not found in %s", key, strings.Join(routeKeys(analysis.Routes), ", "))
+	}
+}
+
+func routeKeys(rs []routes.Route) []string {
+	keys := make([]string, len(rs))
+	for i, r := range rs {
+		keys[i] = r.Method + " " + r.Path
+	}
+	return keys
+}
@@ -0,0 +1,223 @@
+/*
+ * GoAstra CLI - Migration Columns
+ *
+ * Reads the column definitions generated migrations leave a table
+ * with, so a regenerated resource can be compared with its table and
+ * the differences migrated.
+ */
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/*
+ * sqlColumn is a column definition as the migration templates write it:
+ * name, type, then NOT NULL, DEFAULT and UNIQUE. Default holds the SQL
+ * literal and is empty without a default.
+ */
+type sqlColumn struct {
+	Name    string
+	Type    string
+	NotNull bool
+	Default string
+	Unique  bool
+}
+
+/*
+ * columnChange is a column whose definition differs between the table
+ * and the resource's fields. The flags tell which parts changed.
+ */
+type columnChange struct {
+	Name    string
+	Old     sqlColumn
+	New     sqlColumn
+	Type    bool
+	Null    bool
+	Default bool
+	Unique  bool
+}
+
+/*
+ * compareColumns returns the change from old to new, and false when the
+ * definitions are the same.
+ */
+func compareColumns(old, new sqlColumn) (columnChange, bool) {
+	change := columnChange{
+		Name:    new.Name,
+		Old:     old,
+		New:     new,
+		Type:    !strings.EqualFold(old.Type, new.Type),
+		Null:    old.NotNull != new.NotNull,
+		Default: old.Default != new.Default,
+		Unique:  old.Unique != new.Unique,
+	}
+	return change, change.Type || change.Null || change.Default || change.Unique
+}
+
+/*
+ * Reversed returns the change undoing c, for the down section.
+ */
+func (c columnChange) Reversed() columnChange {
+	c.Old, c.New = c.New, c.Old
+	return c
+}
+
+/*
+ * warnings describes what can make the change fail on a table with rows.
+ */
+func (c columnChange) warnings(table string) []string {
+	var warnings []string
+	if c.Type {
+		warnings = append(warnings, fmt.Sprintf("%s changes from %s to %s: the migration fails if existing values do not convert", c.Name, c.Old.Type, c.New.Type))
+	}
+	if c.Null && c.New.NotNull {
+		warnings = append(warnings, fmt.Sprintf("%s becomes NOT NULL: the migration fails while %s has rows where it is NULL", c.Name, table))
+	}
+	if c.Unique && c.New.Unique {
+		warnings = append(warnings, fmt.Sprintf("%s becomes UNIQUE: the migration fails while %s has duplicate values", c.Name, table))
+	}
+	return warnings
+}
+
+/*
+ * columnPattern splits a definition into name, type, NOT NULL, DEFAULT
+ * and UNIQUE. A type holding any other constraint, or those out of
+ * order, comes from a hand-edited definition that is not compared.
+ */
+var columnPattern = regexp.MustCompile(`(?i)^(\S+)\s+(.+?)(\s+NOT\s+NULL)?(\s+DEFAULT\s+('(?:[^']|'')*'|\S+))?(\s+UNIQUE)?$`)
+
+var unknownConstraint = regexp.MustCompile(`(?i)\b(CHECK|REFERENCES|COLLATE|GENERATED|PRIMARY|AUTO_INCREMENT|AUTOINCREMENT|NULL|DEFAULT|UNIQUE|ON)\b`)
+
+/*
+ * parseColumn parses a column definition such as
+ * `price NUMERIC(12,2) NOT NULL DEFAULT 0`. It reports false for
+ * definitions with constraints the templates do not write.
+ */
+func parseColumn(definition string) (sqlColumn, bool) {
+	definition = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(definition), ","))
+	match := columnPattern.FindStringSubmatch(definition)
+	if match == nil || unknownConstraint.MatchString(match[2]) {
+		return sqlColumn{}, false
+	}
+	return sqlColumn{
+		Name:    strings.Trim(match[1], "\"`"),
+		Type:    strings.Join(strings.Fields(match[2]), " "),
+		NotNull: match[3] != "",
+		Default: match[5],
+		Unique:  match[6] != "",
+	}, true
+}
+
+/*
+ * The statements of generated migrations that define or change columns,
+ * each on its own line.
+ */
+var (
+	addColumnPattern   = regexp.MustCompile(`(?i)ADD COLUMN\s+(.+?)(?:,\s*ADD\s|\s+REFERENCES\s|;|$)`)
+	alterTypePattern   = regexp.MustCompile("(?i)ALTER COLUMN\\s+[\"`]?(\\w+)[\"`]?\\s+TYPE\\s+(.+?);?$")
+	alterNullPattern   = regexp.MustCompile("(?i)ALTER COLUMN\\s+[\"`]?(\\w+)[\"`]?\\s+(SET|DROP)\\s+NOT\\s+NULL")
+	setDefaultPattern  = regexp.MustCompile("(?i)ALTER COLUMN\\s+[\"`]?(\\w+)[\"`]?\\s+SET\\s+DEFAULT\\s+(.+?);?$")
+	dropDefaultPattern = regexp.MustCompile("(?i)ALTER COLUMN\\s+[\"`]?(\\w+)[\"`]?\\s+DROP\\s+DEFAULT")
+	modifyPattern      = regexp.MustCompile(`(?i)MODIFY COLUMN\s+(.+?);?$`)
+	addUniquePattern   = regexp.MustCompile("(?i)ADD\\s+(?:CONSTRAINT\\s+\\S+\\s+)?UNIQUE\\s*\\([\"`]?(\\w+)[\"`]?\\)")
+	dropUniquePattern  = regexp.MustCompile(`(?i)DROP\s+(?:CONSTRAINT|INDEX)\s+(\w+)`)
+)
+
+/*
+ * migrationColumns returns the columns the up section of a generated
+ * migration leaves table with, applied to columns: created and added
+ * columns, and the column changes of alter migrations. Columns whose
+ * definition cannot be read are recorded with an empty Type.
+ */
+func migrationColumns(content, table string, columns []sqlColumn) []sqlColumn {
+	if i := strings.Index(content, "-- +migrate Down"); i >= 0 {
+		content = content[:i]
+	}
+
+	index := func(name string) int {
+		for i, column := range columns {
+			if column.Name == name {
+				return i
+			}
+		}
+		return -1
+	}
+	define := func(definition string) {
+		column, ok := parseColumn(definition)
+		if !ok {
+			fields := strings.Fields(definition)
+			if len(fields) == 0 {
+				return
+			}
+			column = sqlColumn{Name: strings.Trim(fields[0], "\"`")}
+		}
+		if i := index(column.Name); i >= 0 {
+			columns[i] = column
+		} else {
+			columns = append(columns, column)
+		}
+	}
+	update := func(name string, apply func(*sqlColumn)) {
+		if i := index(name); i >= 0 && columns[i].Type != "" {
+			apply(&columns[i])
+		}
+	}
+
+	inTable := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(upper, "CREATE TABLE"):
+			inTable = true
+			continue
+		case inTable && strings.HasPrefix(line, ")"):
+			inTable = false
+			continue
+		case line == "" || strings.HasPrefix(line, "--") || strings.HasPrefix(line, "/*"):
+			continue
+		}
+
+		if inTable {
+			name := strings.Trim(strings.Fields(line)[0], "\"`")
+			switch strings.ToUpper(name) {
+			case "PRIMARY", "FOREIGN", "UNIQUE", "CONSTRAINT", "CHECK", "KEY", "INDEX":
+			default:
+				define(line)
+			}
+			continue
+		}
+
+		if match := addColumnPattern.FindStringSubmatch(line); match != nil {
+			define(match[1])
+		}
+		if match := alterTypePattern.FindStringSubmatch(line); match != nil {
+			update(match[1], func(c *sqlColumn) { c.Type = strings.Join(strings.Fields(match[2]), " ") })
+		}
+		if match := alterNullPattern.FindStringSubmatch(line); match != nil {
+			update(match[1], func(c *sqlColumn) { c.NotNull = strings.EqualFold(match[2], "SET") })
+		}
+		if match := setDefaultPattern.FindStringSubmatch(line); match != nil {
+			update(match[1], func(c *sqlColumn) { c.Default = match[2] })
+		}
+		if match := dropDefaultPattern.FindStringSubmatch(line); match != nil {
+			update(match[1], func(c *sqlColumn) { c.Default = "" })
+		}
+		if match := modifyPattern.FindStringSubmatch(line); match != nil {
+			if modified, ok := parseColumn(match[1]); ok {
+				update(modified.Name, func(c *sqlColumn) { modified.Unique = c.Unique; *c = modified })
+			}
+		}
+		if match := addUniquePattern.FindStringSubmatch(line); match != nil {
+			update(match[1], func(c *sqlColumn) { c.Unique = true })
+		}
+		if match := dropUniquePattern.FindStringSubmatch(line); match != nil {
+			name := strings.TrimSuffix(strings.TrimPrefix(match[1], table+"_"), "_key")
+			update(name, func(c *sqlColumn) { c.Unique = false })
+		}
+	}
+	return columns
+}
@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -20,6 +22,14 @@ type CRUDGenerator struct {
 	name       string
 	pascalName string
 	camelName  string
+	fields     []Field
+	driver     string
+	table      string
+	timestamps bool
+	relations  []Relation
+	paths      Paths
+	stack      Stack
+	out        *Writer
 }
 
 /*
@@ -30,138 +40,336 @@ func NewCRUDGenerator(name string) *CRUDGenerator {
 		name:       name,
 		pascalName: toPascalCase(name),
 		camelName:  toCamelCase(name),
+		driver:     "postgres",
+		timestamps: true,
+		paths:      DefaultPaths(),
+		stack:      DefaultStack(),
+		out:        NewWriter(nil),
 	}
 }
 
 /*
- * GenerateModel creates the Go model definition.
- */
-func (g *CRUDGenerator) GenerateModel() error {
-	content := fmt.Sprintf(`/*
- * %s Model
- *
- * Database entity and DTO definitions for %s.
+ * SetWriter sets the writer generated files go through.
  */
-package models
+func (g *CRUDGenerator) SetWriter(out *Writer) {
+	g.out = out
+}
 
-import "time"
+/*
+ * SetFields sets the resource fields carried through every layer.
+ */
+func (g *CRUDGenerator) SetFields(fields []Field) {
+	g.fields = fields
+}
 
 /*
- * %s represents the database entity.
+ * SetDriver selects the database driver (postgres, mysql, sqlite)
+ * the migration and repository are written for.
  */
-type %s struct {
-	ID        uint      ` + "`db:\"id\" json:\"id\"`" + `
-	CreatedAt time.Time ` + "`db:\"created_at\" json:\"created_at\"`" + `
-	UpdatedAt time.Time ` + "`db:\"updated_at\" json:\"updated_at\"`" + `
-	/* TODO: Add model fields */
+func (g *CRUDGenerator) SetDriver(driver string) {
+	g.driver = driver
 }
 
 /*
- * %sCreateDTO defines input for creating a %s.
+ * SetTable sets the table the resource is stored in, for resources
+ * built on an existing table rather than one the migration creates.
  */
-type %sCreateDTO struct {
-	/* TODO: Add create fields */
+func (g *CRUDGenerator) SetTable(table string) {
+	g.table = table
 }
 
 /*
- * %sUpdateDTO defines input for updating a %s.
+ * SetTimestamps sets whether the table has the created_at and
+ * updated_at columns; existing tables may lack them, in which case the
+ * resource has no timestamps and is listed by id.
  */
-type %sUpdateDTO struct {
-	/* TODO: Add update fields */
+func (g *CRUDGenerator) SetTimestamps(timestamps bool) {
+	g.timestamps = timestamps
 }
 
 /*
- * %sResponse defines the API response structure.
+ * SetRelations sets the resources the resource is related to.
  */
-type %sResponse struct {
-	ID        uint      ` + "`json:\"id\"`" + `
-	CreatedAt time.Time ` + "`json:\"created_at\"`" + `
-	UpdatedAt time.Time ` + "`json:\"updated_at\"`" + `
-	/* TODO: Add response fields */
+func (g *CRUDGenerator) SetRelations(relations []Relation) {
+	g.relations = relations
 }
 
 /*
- * ToResponse converts a %s to its response representation.
+ * tableName returns the resource's table, by default the snake_case
+ * resource name.
  */
-func (m *%s) ToResponse() *%sResponse {
-	return &%sResponse{
-		ID:        m.ID,
-		CreatedAt: m.CreatedAt,
-		UpdatedAt: m.UpdatedAt,
+func (g *CRUDGenerator) tableName() string {
+	if g.table != "" {
+		return g.table
 	}
+	return toSnakeCase(g.name)
+}
+
+/*
+ * SetPaths sets the output directories and module path.
+ */
+func (g *CRUDGenerator) SetPaths(paths Paths) {
+	g.paths = paths
 }
-`,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName, g.pascalName,
-		g.pascalName,
-	)
-
-	path := filepath.Join("app/internal/models", g.name+".go")
-	return os.WriteFile(path, []byte(content), 0644)
+
+/*
+ * SetStack sets the project's API style and ORM the backend layers and
+ * the Angular service are generated for.
+ */
+func (g *CRUDGenerator) SetStack(stack Stack) {
+	g.stack = stack
+}
+
+/*
+ * render renders a template with the resource's names, fields and relations.
+ */
+func (g *CRUDGenerator) render(name string, data templateData) (string, error) {
+	return renderTemplate(g.paths.Templates, name, templateData{
+		"Name":       g.name,
+		"Pascal":     g.pascalName,
+		"Camel":      g.camelName,
+		"Snake":      toSnakeCase(g.name),
+		"Plural":     toPlural(g.name),
+		"Fields":     g.fields,
+		"Relations":  g.relations,
+		"Joins":      joins(g.relations),
+		"Timestamps": g.timestamps,
+	}.with(data))
+}
+
+/*
+ * GenerateModel creates the Go model definition.
+ */
+func (g *CRUDGenerator) GenerateModel() error {
+	var base []string
+	if g.timestamps {
+		base = append(base, "time")
+	}
+	content, err := g.render("crud/model.go.tmpl", templateData{
+		"ModelsPackage": packageName(g.paths.Models),
+		"Imports":       importList(g.fields, base...),
+	})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(g.paths.Models, g.name+".go")
+	return g.out.WriteGo(path, content)
 }
 
 /*
- * GenerateAPI creates backend handler, service, and repository.
+ * GenerateAPI creates the backend service and repository and the
+ * handler, resolvers or RPC service serving them.
  */
 func (g *CRUDGenerator) GenerateAPI() error {
+	return g.apiGenerator().GenerateAPI()
+}
+
+/*
+ * GenerateSchema creates the Ent schema of the resource's table.
+ */
+func (g *CRUDGenerator) GenerateSchema() error {
+	return g.apiGenerator().GenerateSchema()
+}
+
+/*
+ * AddInverseEdges adds the inverse of the resource's many_to_many
+ * edges to the related Ent schemas.
+ */
+func (g *CRUDGenerator) AddInverseEdges() error {
+	gen := NewEntGenerator(g.name)
+	gen.SetRelations(g.relations)
+	gen.SetPaths(g.paths)
+	gen.SetWriter(g.out)
+	return gen.AddInverseEdges()
+}
+
+/*
+ * WireRoutes registers the API in the backend router. It reports false
+ * when the router already wires it.
+ */
+func (g *CRUDGenerator) WireRoutes() (bool, error) {
+	return g.apiGenerator().WireRoutes()
+}
+
+/*
+ * apiGenerator returns the API generator for the resource's backend.
+ */
+func (g *CRUDGenerator) apiGenerator() *APIGenerator {
 	apiGen := NewAPIGenerator(g.name)
+	apiGen.SetFields(g.fields)
+	apiGen.SetDriver(g.driver)
+	apiGen.SetTable(g.table)
+	apiGen.SetTimestamps(g.timestamps)
+	apiGen.SetRelations(g.relations)
+	apiGen.SetPaths(g.paths)
+	apiGen.SetStack(g.stack)
+	apiGen.SetWriter(g.out)
+	return apiGen
+}
 
-	if err := apiGen.GenerateHandler(); err != nil {
-		return err
+/*
+ * GenerateMigration creates a database migration file for the
+ * configured driver, with a column per field and indexes for unique
+ * and indexed fields. When the table's migration already exists it is
+ * regenerated in place rather than added again.
+ */
+func (g *CRUDGenerator) GenerateMigration() error {
+	created := time.Now()
+	table := g.tableName()
+	suffix := fmt.Sprintf("_create_%s_table.sql", table)
+
+	filename := created.Format("20060102150405") + suffix
+	existing, _ := filepath.Glob(filepath.Join(g.paths.Migrations, "*"+suffix))
+	if len(existing) > 0 {
+		filename = filepath.Base(existing[0])
+		if t, err := time.ParseInLocation("20060102150405", strings.TrimSuffix(filename, suffix), time.Local); err == nil {
+			created = t
+		}
 	}
 
-	if err := apiGen.GenerateService(); err != nil {
+	content, err := g.render("crud/migration.sql.tmpl", templateData{
+		"Table":         table,
+		"Generated":     created.Format(time.RFC3339),
+		"Driver":        g.driver,
+		"RelatedTables": g.relatedTables(),
+	})
+	if err != nil {
 		return err
 	}
 
-	if err := apiGen.GenerateRepository(); err != nil {
-		return err
+	path := filepath.Join(g.paths.Migrations, filename)
+	return g.out.Write(path, content)
+}
+
+/*
+ * ExistingMigration returns the path of the migration creating the
+ * resource's table, or "" when none has been written.
+ */
+func (g *CRUDGenerator) ExistingMigration() string {
+	existing, _ := filepath.Glob(filepath.Join(g.paths.Migrations, fmt.Sprintf("*_create_%s_table.sql", g.tableName())))
+	if len(existing) == 0 {
+		return ""
 	}
+	return existing[0]
+}
 
-	return apiGen.GenerateRoutes()
+/*
+ * AlterMigrations returns the paths of the migrations adding columns to
+ * the resource's table, oldest first.
+ */
+func (g *CRUDGenerator) AlterMigrations() []string {
+	alters, _ := filepath.Glob(filepath.Join(g.paths.Migrations, fmt.Sprintf("*_alter_%s_table.sql", g.tableName())))
+	sort.Strings(alters)
+	return alters
 }
 
 /*
- * GenerateMigration creates a database migration file.
+ * GenerateAlterMigration writes a migration adding the columns of the
+ * fields the table's existing migrations do not create, and changing
+ * the type, NOT NULL, DEFAULT and UNIQUE of columns whose field
+ * changed, for a table whose create migration may already have run and
+ * must not change. It returns warnings about what can fail on a table
+ * with rows and what it leaves alone: columns of removed fields and
+ * columns it cannot alter.
  */
-func (g *CRUDGenerator) GenerateMigration() error {
-	timestamp := time.Now().Format("20060102150405")
-	filename := fmt.Sprintf("%s_create_%s_table.sql", timestamp, toSnakeCase(g.name))
-
-	content := fmt.Sprintf(`-- Migration: Create %s table
--- Generated: %s
-
--- +migrate Up
-CREATE TABLE IF NOT EXISTS %s (
-    id SERIAL PRIMARY KEY,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-    /* TODO: Add table columns */
-);
-
-CREATE INDEX idx_%s_created_at ON %s(created_at);
-
--- +migrate Down
-DROP TABLE IF EXISTS %s;
-`,
-		toSnakeCase(g.name),
-		time.Now().Format(time.RFC3339),
-		toSnakeCase(g.name),
-		toSnakeCase(g.name), toSnakeCase(g.name),
-		toSnakeCase(g.name),
-	)
-
-	path := filepath.Join("app/migrations", filename)
-	return os.WriteFile(path, []byte(content), 0644)
+func (g *CRUDGenerator) GenerateAlterMigration() ([]string, error) {
+	existing := g.ExistingMigration()
+	if existing == "" {
+		return nil, fmt.Errorf("no migration creates the %s table", g.tableName())
+	}
+
+	table := g.tableName()
+	var columns []sqlColumn
+	for _, path := range append([]string{existing}, g.AlterMigrations()...) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		columns = migrationColumns(string(data), table, columns)
+	}
+
+	/* The columns a create migration written today would have */
+	create, err := g.render("crud/migration.sql.tmpl", templateData{
+		"Table":         table,
+		"Generated":     "",
+		"Driver":        g.driver,
+		"RelatedTables": g.relatedTables(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]sqlColumn)
+	for _, column := range migrationColumns(create, table, nil) {
+		wanted[column.Name] = column
+	}
+
+	var warnings []string
+	var added []Field
+	var changes []columnChange
+	fieldNames := map[string]bool{"id": true, "created_at": true, "updated_at": true}
+	for _, field := range g.fields {
+		fieldNames[field.Name] = true
+		current := -1
+		for i, column := range columns {
+			if column.Name == field.Name {
+				current = i
+			}
+		}
+		if current < 0 {
+			added = append(added, field)
+			if !field.Nullable && field.Default == "" {
+				warnings = append(warnings, fmt.Sprintf("%s is NOT NULL without a default: the migration fails while %s has rows; add a default or backfill it first", field.Name, table))
+			}
+			continue
+		}
+
+		have, want := columns[current], wanted[field.Name]
+		if have.Type == "" || want.Type == "" {
+			warnings = append(warnings, fmt.Sprintf("%s: the column definition was edited by hand and is not compared with the field", field.Name))
+			continue
+		}
+		change, changed := compareColumns(have, want)
+		if !changed {
+			continue
+		}
+		if g.driver == "sqlite" {
+			warnings = append(warnings, fmt.Sprintf("%s changed, but SQLite cannot alter columns; rebuild %s to change it", field.Name, table))
+			continue
+		}
+		changes = append(changes, change)
+		warnings = append(warnings, change.warnings(table)...)
+	}
+	for _, column := range columns {
+		if fieldNames[column.Name] {
+			continue
+		}
+		if column.NotNull && column.Default == "" {
+			warnings = append(warnings, fmt.Sprintf("%s is no longer a field, but the column stays NOT NULL without a default: every insert into %s fails until a migration drops the column or gives it a default", column.Name, table))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s is no longer a field; the column stays until a migration drops it", column.Name))
+		}
+	}
+	if len(added) == 0 && len(changes) == 0 {
+		return warnings, nil
+	}
+
+	now := time.Now()
+	content, err := g.render("crud/alter_migration.sql.tmpl", templateData{
+		"Table":           table,
+		"Generated":       now.Format(time.RFC3339),
+		"CreateMigration": filepath.Base(existing),
+		"Columns":         added,
+		"Changes":         changes,
+		"Driver":          g.driver,
+		"RelatedTables":   g.relatedTables(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(g.paths.Migrations, fmt.Sprintf("%s_alter_%s_table.sql", now.Format("20060102150405"), table))
+	return warnings, g.out.Write(path, content)
 }
 
 /*
@@ -169,6 +377,13 @@ DROP TABLE IF EXISTS %s;
  */
 func (g *CRUDGenerator) GenerateModule() error {
 	modGen := NewModuleGenerator(g.name)
+	modGen.SetFields(g.fields)
+	modGen.SetRelations(g.relations)
+	modGen.SetTimestamps(g.timestamps)
+	modGen.SetFeaturesPath(g.paths.Features)
+	modGen.SetTemplatesPath(g.paths.Templates)
+	modGen.SetAPI(g.stack.API)
+	modGen.SetWriter(g.out)
 
 	if err := modGen.GenerateModule(); err != nil {
 		return err
@@ -201,480 +416,82 @@ func (g *CRUDGenerator) GenerateComponents() error {
 }
 
 func (g *CRUDGenerator) generateListComponent() error {
-	dir := filepath.Join("web/src/app/features", g.name, "components", g.name+"-list")
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	dir := filepath.Join(g.paths.Features, g.name, "components", g.name+"-list")
+	content, err := g.render("crud/list.component.ts.tmpl", nil)
+	if err != nil {
 		return err
 	}
 
-	content := fmt.Sprintf(`/*
- * %s List Component
- *
- * Displays paginated list of %s resources.
- */
-import { Component, OnInit, inject, signal } from '@angular/core';
-import { CommonModule } from '@angular/common';
-import { RouterLink } from '@angular/router';
-import { %sService, %s, %sPaginatedResponse } from '../../%s.service';
-
-@Component({
-  selector: 'app-%s-list',
-  standalone: true,
-  imports: [CommonModule, RouterLink],
-  template: ` + "`" + `
-    <div class="%s-list">
-      <header class="list-header">
-        <h1>%s</h1>
-        <a routerLink="../create" class="btn btn-primary">Create New</a>
-      </header>
-
-      @if (loading()) {
-        <div class="loading">Loading...</div>
-      }
-
-      @if (error()) {
-        <div class="error">{{ error() }}</div>
-      }
-
-      <table class="data-table">
-        <thead>
-          <tr>
-            <th>ID</th>
-            <th>Created</th>
-            <th>Actions</th>
-          </tr>
-        </thead>
-        <tbody>
-          @for (item of items(); track item.id) {
-            <tr>
-              <td>{{ item.id }}</td>
-              <td>{{ item.createdAt | date:'short' }}</td>
-              <td>
-                <a [routerLink]="['..', item.id]">View</a>
-                <a [routerLink]="['..', item.id, 'edit']">Edit</a>
-                <button (click)="delete(item.id)">Delete</button>
-              </td>
-            </tr>
-          }
-        </tbody>
-      </table>
-
-      <div class="pagination">
-        <button (click)="prevPage()" [disabled]="page() === 1">Previous</button>
-        <span>Page {{ page() }} of {{ totalPages() }}</span>
-        <button (click)="nextPage()" [disabled]="page() >= totalPages()">Next</button>
-      </div>
-    </div>
-  ` + "`" + `,
-  styles: [` + "`" + `
-    .list-header {
-      display: flex;
-      justify-content: space-between;
-      align-items: center;
-      margin-bottom: 1rem;
-    }
-    .data-table {
-      width: 100%%;
-      border-collapse: collapse;
-    }
-    .data-table th,
-    .data-table td {
-      padding: 0.75rem;
-      text-align: left;
-      border-bottom: 1px solid #ddd;
-    }
-    .pagination {
-      display: flex;
-      justify-content: center;
-      gap: 1rem;
-      margin-top: 1rem;
-    }
-    .btn-primary {
-      background: #007bff;
-      color: white;
-      padding: 0.5rem 1rem;
-      text-decoration: none;
-      border-radius: 4px;
-    }
-  ` + "`" + `]
-})
-export class %sListComponent implements OnInit {
-  private service = inject(%sService);
-
-  items = signal<%s[]>([]);
-  loading = signal(false);
-  error = signal<string | null>(null);
-  page = signal(1);
-  totalPages = signal(1);
-  pageSize = 10;
-
-  ngOnInit(): void {
-    this.loadData();
-  }
-
-  loadData(): void {
-    this.loading.set(true);
-    this.error.set(null);
-
-    this.service.list(this.page(), this.pageSize).subscribe({
-      next: (response) => {
-        this.items.set(response.data);
-        this.totalPages.set(response.totalPages);
-        this.loading.set(false);
-      },
-      error: (err) => {
-        this.error.set(err.message);
-        this.loading.set(false);
-      }
-    });
-  }
-
-  prevPage(): void {
-    if (this.page() > 1) {
-      this.page.update(p => p - 1);
-      this.loadData();
-    }
-  }
-
-  nextPage(): void {
-    if (this.page() < this.totalPages()) {
-      this.page.update(p => p + 1);
-      this.loadData();
-    }
-  }
-
-  delete(id: number): void {
-    if (confirm('Are you sure you want to delete this item?')) {
-      this.service.delete(id).subscribe({
-        next: () => this.loadData(),
-        error: (err) => this.error.set(err.message)
-      });
-    }
-  }
-}
-`,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName, g.pascalName, g.name,
-		g.name,
-		g.name,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-	)
-
 	path := filepath.Join(dir, g.name+"-list.component.ts")
-	return os.WriteFile(path, []byte(content), 0644)
+	return g.out.Write(path, content)
 }
 
 func (g *CRUDGenerator) generateDetailComponent() error {
-	dir := filepath.Join("web/src/app/features", g.name, "components", g.name+"-detail")
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	dir := filepath.Join(g.paths.Features, g.name, "components", g.name+"-detail")
+	content, err := g.render("crud/detail.component.ts.tmpl", nil)
+	if err != nil {
 		return err
 	}
 
-	content := fmt.Sprintf(`/*
- * %s Detail Component
- *
- * Displays single %s resource details.
- */
-import { Component, OnInit, inject, signal } from '@angular/core';
-import { CommonModule } from '@angular/common';
-import { ActivatedRoute, RouterLink } from '@angular/router';
-import { %sService, %s } from '../../%s.service';
-
-@Component({
-  selector: 'app-%s-detail',
-  standalone: true,
-  imports: [CommonModule, RouterLink],
-  template: ` + "`" + `
-    <div class="%s-detail">
-      @if (loading()) {
-        <div class="loading">Loading...</div>
-      }
-
-      @if (error()) {
-        <div class="error">{{ error() }}</div>
-      }
-
-      @if (item()) {
-        <header>
-          <h1>%s #{{ item()!.id }}</h1>
-          <div class="actions">
-            <a [routerLink]="['..', item()!.id, 'edit']" class="btn">Edit</a>
-            <a routerLink=".." class="btn">Back to List</a>
-          </div>
-        </header>
-
-        <div class="detail-content">
-          <dl>
-            <dt>ID</dt>
-            <dd>{{ item()!.id }}</dd>
-
-            <dt>Created At</dt>
-            <dd>{{ item()!.createdAt | date:'medium' }}</dd>
-
-            <dt>Updated At</dt>
-            <dd>{{ item()!.updatedAt | date:'medium' }}</dd>
-          </dl>
-        </div>
-      }
-    </div>
-  ` + "`" + `,
-  styles: [` + "`" + `
-    header {
-      display: flex;
-      justify-content: space-between;
-      align-items: center;
-      margin-bottom: 1rem;
-    }
-    .actions {
-      display: flex;
-      gap: 0.5rem;
-    }
-    .btn {
-      padding: 0.5rem 1rem;
-      text-decoration: none;
-      border: 1px solid #ddd;
-      border-radius: 4px;
-    }
-    dl {
-      display: grid;
-      grid-template-columns: auto 1fr;
-      gap: 0.5rem 1rem;
-    }
-    dt {
-      font-weight: bold;
-    }
-  ` + "`" + `]
-})
-export class %sDetailComponent implements OnInit {
-  private service = inject(%sService);
-  private route = inject(ActivatedRoute);
-
-  item = signal<%s | null>(null);
-  loading = signal(false);
-  error = signal<string | null>(null);
-
-  ngOnInit(): void {
-    const id = Number(this.route.snapshot.paramMap.get('id'));
-    this.loadData(id);
-  }
-
-  loadData(id: number): void {
-    this.loading.set(true);
-    this.error.set(null);
-
-    this.service.getById(id).subscribe({
-      next: (data) => {
-        this.item.set(data);
-        this.loading.set(false);
-      },
-      error: (err) => {
-        this.error.set(err.message);
-        this.loading.set(false);
-      }
-    });
-  }
-}
-`,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName, g.name,
-		g.name,
-		g.name,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-	)
-
 	path := filepath.Join(dir, g.name+"-detail.component.ts")
-	return os.WriteFile(path, []byte(content), 0644)
+	return g.out.Write(path, content)
 }
 
 func (g *CRUDGenerator) generateFormComponent() error {
-	dir := filepath.Join("web/src/app/features", g.name, "components", g.name+"-form")
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	dir := filepath.Join(g.paths.Features, g.name, "components", g.name+"-form")
+	content, err := g.render("crud/form.component.ts.tmpl", templateData{
+		"Selectors":    g.selectors(),
+		"OptionLabels": g.optionLabels(),
+	})
+	if err != nil {
 		return err
 	}
 
-	content := fmt.Sprintf(`/*
- * %s Form Component
- *
- * Create and edit form for %s resources.
- */
-import { Component, OnInit, inject, signal } from '@angular/core';
-import { CommonModule } from '@angular/common';
-import { ReactiveFormsModule, FormBuilder, FormGroup, Validators } from '@angular/forms';
-import { ActivatedRoute, Router, RouterLink } from '@angular/router';
-import { %sService, %s, Create%sDto, Update%sDto } from '../../%s.service';
-
-@Component({
-  selector: 'app-%s-form',
-  standalone: true,
-  imports: [CommonModule, ReactiveFormsModule, RouterLink],
-  template: ` + "`" + `
-    <div class="%s-form">
-      <header>
-        <h1>{{ isEdit() ? 'Edit' : 'Create' }} %s</h1>
-        <a routerLink=".." class="btn">Cancel</a>
-      </header>
-
-      @if (error()) {
-        <div class="error">{{ error() }}</div>
-      }
-
-      <form [formGroup]="form" (ngSubmit)="submit()">
-        <!-- TODO: Add form fields -->
-        <div class="form-group">
-          <label for="example">Example Field</label>
-          <input type="text" id="example" formControlName="example">
-        </div>
-
-        <div class="form-actions">
-          <button type="submit" [disabled]="form.invalid || submitting()">
-            {{ submitting() ? 'Saving...' : (isEdit() ? 'Update' : 'Create') }}
-          </button>
-        </div>
-      </form>
-    </div>
-  ` + "`" + `,
-  styles: [` + "`" + `
-    header {
-      display: flex;
-      justify-content: space-between;
-      align-items: center;
-      margin-bottom: 1rem;
-    }
-    .form-group {
-      margin-bottom: 1rem;
-    }
-    .form-group label {
-      display: block;
-      margin-bottom: 0.25rem;
-      font-weight: 500;
-    }
-    .form-group input {
-      width: 100%%;
-      padding: 0.5rem;
-      border: 1px solid #ddd;
-      border-radius: 4px;
-    }
-    .form-actions {
-      margin-top: 1.5rem;
-    }
-    .btn {
-      padding: 0.5rem 1rem;
-      text-decoration: none;
-      border: 1px solid #ddd;
-      border-radius: 4px;
-    }
-    button[type="submit"] {
-      background: #007bff;
-      color: white;
-      padding: 0.5rem 1.5rem;
-      border: none;
-      border-radius: 4px;
-      cursor: pointer;
-    }
-    button:disabled {
-      opacity: 0.6;
-      cursor: not-allowed;
-    }
-    .error {
-      color: red;
-      margin-bottom: 1rem;
-    }
-  ` + "`" + `]
-})
-export class %sFormComponent implements OnInit {
-  private fb = inject(FormBuilder);
-  private service = inject(%sService);
-  private route = inject(ActivatedRoute);
-  private router = inject(Router);
-
-  form: FormGroup = this.fb.group({
-    example: ['', Validators.required]
-    /* TODO: Add form controls */
-  });
-
-  isEdit = signal(false);
-  submitting = signal(false);
-  error = signal<string | null>(null);
-  private itemId: number | null = null;
-
-  ngOnInit(): void {
-    const id = this.route.snapshot.paramMap.get('id');
-    if (id) {
-      this.isEdit.set(true);
-      this.itemId = Number(id);
-      this.loadData(this.itemId);
-    }
-  }
-
-  loadData(id: number): void {
-    this.service.getById(id).subscribe({
-      next: (data) => {
-        this.form.patchValue(data);
-      },
-      error: (err) => {
-        this.error.set(err.message);
-      }
-    });
-  }
-
-  submit(): void {
-    if (this.form.invalid) return;
-
-    this.submitting.set(true);
-    this.error.set(null);
-
-    const data = this.form.value;
-
-    const request = this.isEdit()
-      ? this.service.update(this.itemId!, data as Update%sDto)
-      : this.service.create(data as Create%sDto);
-
-    request.subscribe({
-      next: (result) => {
-        this.router.navigate(['..', result.id], { relativeTo: this.route });
-      },
-      error: (err) => {
-        this.error.set(err.message);
-        this.submitting.set(false);
-      }
-    });
-  }
+	path := filepath.Join(dir, g.name+"-form.component.ts")
+	return g.out.Write(path, content)
 }
-`,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName, g.pascalName, g.pascalName, g.name,
-		g.name,
-		g.name,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-	)
 
-	path := filepath.Join(dir, g.name+"-form.component.ts")
-	return os.WriteFile(path, []byte(content), 0644)
+/*
+ * UpdateRoutes adds a lazy route for the feature module to
+ * app.routes.ts. The route is protected with authGuard when the project
+ * has one. It reports false when the route is already present.
+ */
+func (g *CRUDGenerator) UpdateRoutes() (bool, error) {
+	src, err := os.ReadFile(angularRoutesFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read routes: %w", err)
+	}
+
+	route := g.lazyRoute()
+	tokens, _ := scanTS(string(src))
+	route.Guard = importsName(tokens, "authGuard") || fileExists(authGuardFile)
+
+	out, changed, err := insertRoute(angularRoutesFile, string(src), route)
+	if err != nil || !changed {
+		return false, err
+	}
+	return true, os.WriteFile(angularRoutesFile, []byte(out), 0644)
 }
 
 /*
- * UpdateRoutes modifies the main app routing to include the new module.
+ * RouteSnippet returns the route entry to add to app.routes.ts by hand
+ * when the file cannot be edited.
  */
-func (g *CRUDGenerator) UpdateRoutes() error {
-	/* In a real implementation, this would parse and modify app.routes.ts */
-	/* For now, we output instructions */
-	fmt.Printf("\nAdd the following route to web/src/app/app.routes.ts:\n")
-	fmt.Printf(`
-  {
-    path: '%s',
-    loadChildren: () => import('@features/%s/%s.module').then(m => m.%sModule),
-    canActivate: [authGuard]
-  }
-`, toPlural(g.name), g.name, g.name, g.pascalName)
-
-	return nil
+func (g *CRUDGenerator) RouteSnippet() string {
+	route := g.lazyRoute()
+	route.Guard = true
+	return route.render("  ", "    ")
+}
+
+func (g *CRUDGenerator) lazyRoute() lazyRoute {
+	return lazyRoute{
+		Path:   toPlural(g.name),
+		Import: fmt.Sprintf("@features/%s/%s.module", g.name, g.name),
+		Module: g.pascalName + "Module",
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+/*
+ * newTestCRUD returns a generator for a product resource writing its
+ * migrations to dir.
+ */
+func newTestCRUD(t *testing.T, driver, dir string, specs ...string) *CRUDGenerator {
+	t.Helper()
+	fields, err := ParseFields(specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := NewCRUDGenerator("product")
+	gen.SetDriver(driver)
+	gen.SetFields(fields)
+	gen.SetPaths(Paths{Migrations: dir})
+	return gen
+}
+
+var (
+	generatedTime = regexp.MustCompile(`Generated: .*`)
+	fileTimestamp = regexp.MustCompile(`\d{14}_`)
+)
+
+func TestGenerateAlterMigration(t *testing.T) {
+	before := []string{"name:string:required", "stock:int", "sku:string:nullable", "price:decimal:default=0", "legacy:string:required"}
+	after := []string{"name:string:required:unique", "stock:bigint:default=0", "sku:string:required", "price:decimal", "color:string"}
+
+	for _, driver := range []string{"postgres", "mysql"} {
+		t.Run(driver, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := newTestCRUD(t, driver, dir, before...).GenerateMigration(); err != nil {
+				t.Fatal(err)
+			}
+
+			gen := newTestCRUD(t, driver, dir, after...)
+			warnings, err := gen.GenerateAlterMigration()
+			if err != nil {
+				t.Fatal(err)
+			}
+			alters := gen.AlterMigrations()
+			if len(alters) != 1 {
+				t.Fatalf("alter migrations = %v, want one", alters)
+			}
+			content := generatedTime.ReplaceAllString(readFile(t, alters[0]), "Generated: <time>")
+			content = fileTimestamp.ReplaceAllString(content, "<timestamp>_")
+			golden(t, filepath.Join("testdata", "migrations", "alter."+driver+".golden"), content)
+
+			for _, want := range []string{
+				"stock changes from",
+				"sku becomes NOT NULL",
+				"name becomes UNIQUE",
+				"color is NOT NULL without a default",
+				"legacy is no longer a field, but the column stays NOT NULL without a default: every insert into product fails",
+			} {
+				if !containsWarning(warnings, want) {
+					t.Errorf("no warning %q in %q", want, warnings)
+				}
+			}
+
+			/* The alter migration's changes are read back: nothing is left to change */
+			rerun := newTestCRUD(t, driver, dir, after...)
+			rerun.SetWriter(NewWriter(nil))
+			warnings, err = rerun.GenerateAlterMigration()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changes := rerun.out.Changes(); len(changes) != 0 {
+				t.Errorf("rerun wrote %v", changes)
+			}
+			if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "legacy is no longer a field") {
+				t.Errorf("rerun warnings = %q, want only the dropped legacy column", warnings)
+			}
+		})
+	}
+}
+
+func TestGenerateAlterMigrationSQLite(t *testing.T) {
+	dir := t.TempDir()
+	if err := newTestCRUD(t, "sqlite", dir, "stock:int:nullable").GenerateMigration(); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := newTestCRUD(t, "sqlite", dir, "stock:int:required")
+	warnings, err := gen.GenerateAlterMigration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alters := gen.AlterMigrations(); len(alters) != 0 {
+		t.Errorf("alter migrations = %v, want none", alters)
+	}
+	if !containsWarning(warnings, "SQLite cannot alter columns") {
+		t.Errorf("warnings = %q, want SQLite unable to alter stock", warnings)
+	}
+}
+
+func TestMigrationColumns(t *testing.T) {
+	content := `-- +migrate Up
+CREATE TABLE IF NOT EXISTS "product" (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL DEFAULT 'a b' UNIQUE,
+    stock INTEGER,
+    note TEXT CHECK (note <> ''),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+ALTER TABLE "product" ADD COLUMN user_id INTEGER NOT NULL, ADD CONSTRAINT fk_product_user_id FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE;
+ALTER TABLE "product" ALTER COLUMN stock TYPE BIGINT;
+ALTER TABLE "product" ALTER COLUMN stock SET NOT NULL;
+ALTER TABLE "product" ALTER COLUMN stock SET DEFAULT 0;
+ALTER TABLE "product" DROP CONSTRAINT product_name_key;
+
+-- +migrate Down
+ALTER TABLE "product" ADD COLUMN ignored TEXT;
+`
+	want := []sqlColumn{
+		{Name: "id", Type: ""},
+		{Name: "name", Type: "VARCHAR(255)", NotNull: true, Default: "'a b'"},
+		{Name: "stock", Type: "BIGINT", NotNull: true, Default: "0"},
+		{Name: "note"},
+		{Name: "created_at", Type: "TIMESTAMP WITH TIME ZONE", Default: "CURRENT_TIMESTAMP"},
+		{Name: "user_id", Type: "INTEGER", NotNull: true},
+	}
+	got := migrationColumns(content, "product", nil)
+	if len(got) != len(want) {
+		t.Fatalf("migrationColumns() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("column %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	modified := migrationColumns("ALTER TABLE `product` MODIFY COLUMN stock BIGINT NOT NULL;\nALTER TABLE `product` ADD UNIQUE (stock);\n", "product",
+		[]sqlColumn{{Name: "stock", Type: "INT", Default: "0"}})
+	if w := (sqlColumn{Name: "stock", Type: "BIGINT", NotNull: true, Unique: true}); modified[0] != w {
+		t.Errorf("after MODIFY COLUMN = %+v, want %+v", modified[0], w)
+	}
+}
+
+func containsWarning(warnings []string, want string) bool {
+	for _, warning := range warnings {
+		if strings.Contains(warning, want) {
+			return true
+		}
+	}
+	return false
+}
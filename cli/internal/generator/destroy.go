@@ -0,0 +1,428 @@
+/*
+ * GoAstra CLI - Destroy
+ *
+ * Removes the files a generator produced for a resource and undoes its
+ * router and app.routes.ts registrations. Files are found through the
+ * generation manifest, so only what the generator wrote is deleted.
+ */
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+ * destroyKinds maps each destroyable generator to the generators whose
+ * files it removes. A CRUD stack also owns files later regenerated by
+ * `generate api` or `generate module` for the same resource.
+ */
+var destroyKinds = map[string][]string{
+	"api":     {"api"},
+	"crud":    {"crud", "api", "module"},
+	"module":  {"module"},
+	"graphql": {"graphql"},
+	"trpc":    {"trpc"},
+	"ent":     {"ent"},
+}
+
+/*
+ * DestroyKinds returns the generator names destroy accepts.
+ */
+func DestroyKinds() []string {
+	kinds := make([]string, 0, len(destroyKinds))
+	for kind := range destroyKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+/*
+ * Destroyer removes a generated resource.
+ */
+type Destroyer struct {
+	kind       string
+	name       string
+	pascalName string
+	manifest   *Manifest
+	paths      Paths
+	stack      Stack
+	driver     string
+}
+
+/*
+ * NewDestroyer creates a destroyer for the resource generated by kind.
+ */
+func NewDestroyer(kind, name string, manifest *Manifest) (*Destroyer, error) {
+	if _, ok := destroyKinds[kind]; !ok {
+		return nil, fmt.Errorf("unknown generator %q (use %s)", kind, strings.Join(DestroyKinds(), ", "))
+	}
+	return &Destroyer{
+		kind:       kind,
+		name:       name,
+		pascalName: toPascalCase(name),
+		manifest:   manifest,
+		paths:      DefaultPaths(),
+		stack:      DefaultStack(),
+		driver:     "postgres",
+	}, nil
+}
+
+/*
+ * SetPaths sets the directories the resource was generated into.
+ */
+func (d *Destroyer) SetPaths(paths Paths) {
+	d.paths = paths
+}
+
+/*
+ * SetStack sets the API style and ORM the resource was generated for.
+ */
+func (d *Destroyer) SetStack(stack Stack) {
+	d.stack = stack
+}
+
+/*
+ * SetDriver sets the SQL driver whose identifier quoting the drop
+ * migration uses.
+ */
+func (d *Destroyer) SetDriver(driver string) {
+	d.driver = driver
+}
+
+/*
+ * render renders a template with the resource's names.
+ */
+func (d *Destroyer) render(name string, data templateData) (string, error) {
+	return renderTemplate(d.paths.Templates, name, templateData{
+		"Name":   d.name,
+		"Pascal": d.pascalName,
+		"Camel":  toCamelCase(d.name),
+		"Snake":  toSnakeCase(d.name),
+		"Plural": toPlural(d.name),
+	}.with(data))
+}
+
+/*
+ * Files returns the existing files generated for the resource. They come
+ * from the manifest; when it has no record of the resource they are
+ * predicted by running the generator without writing, and recorded is
+ * false since such files cannot be checked for local edits.
+ */
+func (d *Destroyer) Files() ([]string, bool, error) {
+	var files []string
+	for _, entry := range d.manifest.Files {
+		if entry.Shared || len(entry.Inputs) == 0 || entry.Inputs[0] != d.name || !containsImport(destroyKinds[d.kind], entry.Generator) {
+			continue
+		}
+		if fileExists(entry.File) {
+			files = append(files, filepath.FromSlash(entry.File))
+		}
+	}
+	if len(files) > 0 {
+		sort.Strings(files)
+		return files, true, nil
+	}
+
+	out := NewWriter(nil)
+	out.SetDryRun(true)
+	if err := d.generate(out); err != nil {
+		return nil, false, err
+	}
+	for _, change := range out.Changes() {
+		if change.Action != Created && !change.Shared {
+			files = append(files, change.Path)
+		}
+	}
+	sort.Strings(files)
+	return files, false, nil
+}
+
+/*
+ * generate runs the resource's generator through out.
+ */
+func (d *Destroyer) generate(out *Writer) error {
+	var steps []func() error
+	switch d.kind {
+	case "api":
+		gen := NewAPIGenerator(d.name)
+		gen.SetPaths(d.paths)
+		gen.SetStack(d.stack)
+		gen.SetWriter(out)
+		steps = []func() error{gen.GenerateAPI}
+	case "crud":
+		gen := NewCRUDGenerator(d.name)
+		gen.SetPaths(d.paths)
+		gen.SetStack(d.stack)
+		gen.SetWriter(out)
+		schema := gen.GenerateMigration
+		if d.stack.ORM == "ent" {
+			schema = gen.GenerateSchema
+		}
+		steps = []func() error{gen.GenerateModel, gen.GenerateAPI, schema, gen.GenerateModule, gen.GenerateComponents, gen.GenerateTests}
+	case "module":
+		gen := NewModuleGenerator(d.name)
+		gen.SetFeaturesPath(d.paths.Features)
+		gen.SetTemplatesPath(d.paths.Templates)
+		gen.SetAPI(d.stack.API)
+		gen.SetWriter(out)
+		steps = []func() error{gen.GenerateModule, gen.GenerateRouting, gen.GenerateComponent, gen.GenerateService}
+	case "graphql":
+		gen := NewGraphQLGenerator(d.name)
+		gen.SetPaths(d.paths)
+		gen.SetStack(d.stack)
+		gen.SetWriter(out)
+		steps = []func() error{gen.GenerateSchema, gen.GenerateResolver}
+	case "trpc":
+		gen := NewTRPCGenerator(d.name)
+		gen.SetPaths(d.paths)
+		gen.SetStack(d.stack)
+		gen.SetWriter(out)
+		steps = []func() error{gen.GenerateProto, gen.GenerateService}
+	case "ent":
+		gen := NewEntGenerator(d.name)
+		gen.SetPaths(d.paths)
+		gen.SetWriter(out)
+		steps = []func() error{gen.GenerateSchema}
+	}
+
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+ * Modified returns the files whose content no longer matches what was
+ * generated. Files without a manifest entry count as modified.
+ */
+func (d *Destroyer) Modified(files []string) ([]string, error) {
+	var modified []string
+	for _, file := range files {
+		entry := d.manifest.Entry(file)
+		if entry == nil {
+			modified = append(modified, file)
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if contentHash(string(data)) != entry.Hash {
+			modified = append(modified, file)
+		}
+	}
+	return modified, nil
+}
+
+/*
+ * Migration returns the table migration among files, or "".
+ */
+func (d *Destroyer) Migration(files []string) string {
+	suffix := fmt.Sprintf("_create_%s_table.sql", toSnakeCase(d.name))
+	for _, file := range files {
+		if filepath.Dir(file) == filepath.Clean(d.paths.Migrations) && strings.HasSuffix(file, suffix) {
+			return file
+		}
+	}
+	return ""
+}
+
+/*
+ * JoinMigrations returns the migrations among files that create the
+ * join tables of the resource's many_to_many relations.
+ */
+func (d *Destroyer) JoinMigrations(files []string) []string {
+	migration := d.Migration(files)
+	var joins []string
+	for _, file := range files {
+		base := filepath.Base(file)
+		if file != migration && filepath.Dir(file) == filepath.Clean(d.paths.Migrations) &&
+			strings.Contains(base, "_create_") && strings.HasSuffix(base, "_table.sql") {
+			joins = append(joins, file)
+		}
+	}
+	return joins
+}
+
+/*
+ * Dependents returns the other generated resources related to this one
+ * through --belongs-to, --has-many or --many-to-many, by name. Their
+ * models and repositories use the resource's, so they no longer compile
+ * once it is removed.
+ */
+func (d *Destroyer) Dependents() []string {
+	flags := []string{relationFlag(BelongsTo), relationFlag(HasMany), relationFlag(ManyToMany)}
+	seen := make(map[string]bool)
+	var dependents []string
+	for _, entry := range d.manifest.Files {
+		if len(entry.Inputs) == 0 || entry.Inputs[0] == d.name || seen[entry.Inputs[0]] {
+			continue
+		}
+		for i := 1; i+1 < len(entry.Inputs); i++ {
+			if containsImport(flags, entry.Inputs[i]) && entry.Inputs[i+1] == d.name {
+				seen[entry.Inputs[0]] = true
+				dependents = append(dependents, fmt.Sprintf("%s (%s %s)", entry.Inputs[0], entry.Inputs[i], d.name))
+				break
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+/*
+ * AlterMigrations returns the files among files that add columns to the
+ * resource's table.
+ */
+func (d *Destroyer) AlterMigrations(files []string) []string {
+	suffix := fmt.Sprintf("_alter_%s_table.sql", toSnakeCase(d.name))
+	var alters []string
+	for _, file := range files {
+		if filepath.Dir(file) == filepath.Clean(d.paths.Migrations) && strings.HasSuffix(file, suffix) {
+			alters = append(alters, file)
+		}
+	}
+	return alters
+}
+
+/*
+ * Remove deletes files, drops them from the manifest and removes feature
+ * directories left empty.
+ */
+func (d *Destroyer) Remove(files []string) error {
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := d.manifest.Remove(file); err != nil {
+			return err
+		}
+		d.pruneDirs(filepath.Dir(file))
+	}
+	return d.manifest.Save()
+}
+
+/*
+ * pruneDirs removes dir and its parents while they are empty, stopping
+ * at the features directory.
+ */
+func (d *Destroyer) pruneDirs(dir string) {
+	features := filepath.Clean(d.paths.Features)
+	for {
+		rel, err := filepath.Rel(features, dir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return
+		}
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+/*
+ * Unwire removes the resource from the backend router. It reports false
+ * when the router does not wire it, which is always the case outside
+ * REST projects.
+ */
+func (d *Destroyer) Unwire() (bool, error) {
+	if d.stack.API != "rest" {
+		return false, nil
+	}
+	wiring := NewRouterWiring(filepath.Join(d.paths.Router, routerFile), d.name)
+	wiring.SetPaths(d.paths)
+	wiring.SetStack(d.stack)
+	return wiring.Unwire()
+}
+
+/*
+ * RemoveRoute removes the feature module's lazy route from
+ * app.routes.ts, recognized by the module it loads, so a route of the
+ * user's own on the same path is kept. It reports false when the route
+ * is not present.
+ */
+func (d *Destroyer) RemoveRoute() (bool, error) {
+	src, err := os.ReadFile(angularRoutesFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read routes: %w", err)
+	}
+
+	gen := NewCRUDGenerator(d.name)
+	out, changed, err := removeRoute(angularRoutesFile, string(src), gen.lazyRoute())
+	if err != nil || !changed {
+		return false, err
+	}
+	return true, os.WriteFile(angularRoutesFile, []byte(out), 0644)
+}
+
+/*
+ * DropMigrations writes a migration dropping the table created by each
+ * applied migration in creates, one second apart so they run in the
+ * order given: join tables before the resource's table, whose foreign
+ * keys they hold. The down section of a drop migration recreates the
+ * table from the up sections of its create migration and, for the
+ * resource's table, of the alter migrations at alterPaths. It returns
+ * the new migrations' paths.
+ */
+func (d *Destroyer) DropMigrations(creates, alterPaths []string) ([]string, error) {
+	now := time.Now()
+	var paths []string
+	for i, create := range creates {
+		sources := []string{create}
+		if create == d.Migration(creates) {
+			sources = append(sources, alterPaths...)
+		}
+		path, err := d.dropMigration(sources, now.Add(time.Duration(i)*time.Second))
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+/*
+ * dropMigration writes the migration dropping the table created by the
+ * first of sources, created at now.
+ */
+func (d *Destroyer) dropMigration(sources []string, now time.Time) (string, error) {
+	var sections []string
+	for _, path := range sources {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		up := string(data)
+		if i := strings.Index(up, "-- +migrate Up"); i >= 0 {
+			up = up[i+len("-- +migrate Up"):]
+		}
+		if i := strings.Index(up, "-- +migrate Down"); i >= 0 {
+			up = up[:i]
+		}
+		sections = append(sections, strings.TrimSpace(up))
+	}
+	recreate := strings.Join(sections, "\n\n")
+
+	_, table, _ := strings.Cut(strings.TrimSuffix(filepath.Base(sources[0]), "_table.sql"), "_create_")
+	content, err := d.render("crud/drop_migration.sql.tmpl", templateData{
+		"Table":           table,
+		"Driver":          d.driver,
+		"Generated":       now.Format(time.RFC3339),
+		"CreateMigration": filepath.Base(sources[0]),
+		"Recreate":        recreate,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(d.paths.Migrations, fmt.Sprintf("%s_drop_%s_table.sql", now.Format("20060102150405"), table))
+	return path, os.WriteFile(path, []byte(content), 0644)
+}
@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDestroyerRelations(t *testing.T) {
+	manifest := &Manifest{Files: []ManifestEntry{
+		{File: "app/migrations/20240101000000_create_post_table.sql", Generator: "crud", Inputs: []string{"post", "title:string", "--many-to-many", "tag"}},
+		{File: "app/migrations/20240101000001_create_post_tag_table.sql", Generator: "crud", Inputs: []string{"post", "title:string", "--many-to-many", "tag"}},
+		{File: "app/internal/models/comment.go", Generator: "crud", Inputs: []string{"comment", "body:text", "--belongs-to", "post"}},
+		{File: "app/internal/models/comment_test.go", Generator: "crud", Inputs: []string{"comment", "body:text", "--belongs-to", "post"}},
+		{File: "app/internal/models/author.go", Generator: "crud", Inputs: []string{"author", "name:string", "--has-many", "post"}},
+		{File: "app/internal/models/tag.go", Generator: "crud", Inputs: []string{"tag", "name:string"}},
+	}}
+
+	d, err := NewDestroyer("crud", "post", manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetPaths(Paths{Migrations: "app/migrations"})
+
+	want := []string{"author (--has-many post)", "comment (--belongs-to post)"}
+	if got := d.Dependents(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependents() = %v, want %v", got, want)
+	}
+
+	files := []string{
+		filepath.FromSlash("app/migrations/20240101000000_create_post_table.sql"),
+		filepath.FromSlash("app/migrations/20240101000001_create_post_tag_table.sql"),
+		filepath.FromSlash("app/migrations/20240102000000_alter_post_table.sql"),
+	}
+	if got := d.Migration(files); got != files[0] {
+		t.Errorf("Migration() = %q, want %q", got, files[0])
+	}
+	if got := d.JoinMigrations(files); !reflect.DeepEqual(got, files[1:2]) {
+		t.Errorf("JoinMigrations() = %v, want %v", got, files[1:2])
+	}
+}
+
+func TestDestroyerDropMigrations(t *testing.T) {
+	dir := t.TempDir()
+	create := filepath.Join(dir, "20240101000000_create_post_table.sql")
+	join := filepath.Join(dir, "20240101000001_create_post_tag_table.sql")
+	for path, up := range map[string]string{
+		create: "CREATE TABLE post (id SERIAL PRIMARY KEY);",
+		join:   "CREATE TABLE post_tag (post_id INTEGER NOT NULL);",
+	} {
+		if err := os.WriteFile(path, []byte("-- +migrate Up\n"+up+"\n\n-- +migrate Down\nDROP TABLE x;\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d, err := NewDestroyer("crud", "post", &Manifest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetPaths(Paths{Migrations: dir})
+
+	paths, err := d.DropMigrations([]string{join, create}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || !strings.HasSuffix(paths[0], "_drop_post_tag_table.sql") || !strings.HasSuffix(paths[1], "_drop_post_table.sql") {
+		t.Fatalf("DropMigrations() = %v, want the post_tag drop before the post drop", paths)
+	}
+	if filepath.Base(paths[0]) >= filepath.Base(paths[1]) {
+		t.Errorf("%s does not sort before %s", filepath.Base(paths[0]), filepath.Base(paths[1]))
+	}
+
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "DROP TABLE IF EXISTS") || !strings.Contains(string(data), "CREATE TABLE post_tag") {
+		t.Errorf("join drop migration:\n%s", data)
+	}
+}
+
+func TestDestroyerRemoveRoute(t *testing.T) {
+	tests := []struct {
+		fixture string
+		removed bool
+		want    string
+	}{
+		/* A hand-written route on the resource's path is not ours */
+		{"user-path.routes.ts", false, "user-path.routes.ts"},
+		{"shared-path.routes.ts", true, "shared-path.routes.removed.golden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			src := readFile(t, filepath.Join("testdata", "tsroutes", tt.fixture))
+			want := readFile(t, filepath.Join("testdata", "tsroutes", tt.want))
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, filepath.FromSlash(angularRoutesFile))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			chdir(t, dir)
+
+			d, err := NewDestroyer("crud", "widget", &Manifest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			removed, err := d.RemoveRoute()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if removed != tt.removed {
+				t.Errorf("RemoveRoute() = %v, want %v", removed, tt.removed)
+			}
+			if got := readFile(t, path); got != want {
+				t.Errorf("app.routes.ts after RemoveRoute():\n%s", got)
+			}
+		})
+	}
+}
+
+/*
+ * chdir changes the working directory for the rest of the test.
+ */
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -8,8 +8,9 @@ package generator
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 /*
@@ -20,6 +21,12 @@ type EntGenerator struct {
 	pascalName string
 	camelName  string
 	snakeName  string
+	table      string
+	timestamps bool
+	fields     []Field
+	relations  []Relation
+	paths      Paths
+	out        *Writer
 }
 
 /*
@@ -31,151 +38,109 @@ func NewEntGenerator(name string) *EntGenerator {
 		pascalName: toPascalCase(name),
 		camelName:  toCamelCase(name),
 		snakeName:  toSnakeCase(name),
+		timestamps: true,
+		paths:      DefaultPaths(),
+		out:        NewWriter(nil),
 	}
 }
 
 /*
- * GenerateSchema creates an Ent schema file for the entity.
+ * SetWriter sets the writer generated files go through.
  */
-func (g *EntGenerator) GenerateSchema() error {
-	content := fmt.Sprintf(`package schema
-
-import (
-	"time"
+func (g *EntGenerator) SetWriter(out *Writer) {
+	g.out = out
+}
 
-	"entgo.io/ent"
-	"entgo.io/ent/schema/field"
-	"entgo.io/ent/schema/index"
-)
+/*
+ * SetFields sets the entity fields written to the schema instead of
+ * the commented examples.
+ */
+func (g *EntGenerator) SetFields(fields []Field) {
+	g.fields = fields
+}
 
 /*
- * %s holds the schema definition for the %s entity.
- * Generated by GoAstra CLI
+ * SetTable maps the schema to an existing table instead of the one Ent
+ * names after the entity.
  */
-type %s struct {
-	ent.Schema
+func (g *EntGenerator) SetTable(table string) {
+	g.table = table
 }
 
 /*
- * Fields of the %s entity.
+ * SetTimestamps sets whether the schema declares the created_at and
+ * updated_at fields.
  */
-func (%s) Fields() []ent.Field {
-	return []ent.Field{
-		// TODO: Add your fields here
-		//
-		// Example fields:
-		// field.String("name").
-		//     NotEmpty().
-		//     Comment("%s name"),
-		//
-		// field.String("description").
-		//     Optional().
-		//     Comment("%s description"),
-		//
-		// field.Int("quantity").
-		//     Default(0).
-		//     NonNegative().
-		//     Comment("Quantity in stock"),
-		//
-		// field.Float("price").
-		//     Positive().
-		//     Comment("Price in USD"),
-		//
-		// field.Bool("active").
-		//     Default(true).
-		//     Comment("Whether the %s is active"),
-		//
-		// field.Enum("status").
-		//     Values("pending", "active", "archived").
-		//     Default("pending").
-		//     Comment("%s status"),
+func (g *EntGenerator) SetTimestamps(timestamps bool) {
+	g.timestamps = timestamps
+}
 
-		field.Time("created_at").
-			Default(time.Now).
-			Immutable().
-			Comment("Creation timestamp"),
+/*
+ * SetRelations sets the relations written as edges.
+ */
+func (g *EntGenerator) SetRelations(relations []Relation) {
+	g.relations = relations
+}
 
-		field.Time("updated_at").
-			Default(time.Now).
-			UpdateDefault(time.Now).
-			Comment("Last update timestamp"),
-	}
+/*
+ * SetPaths sets the directory of the ent package.
+ */
+func (g *EntGenerator) SetPaths(paths Paths) {
+	g.paths = paths
 }
 
 /*
- * Edges of the %s entity.
- * Define relationships to other entities here.
- *
- * Example edges:
- *
- * func (%s) Edges() []ent.Edge {
- *     return []ent.Edge{
- *         // One-to-many: %s belongs to a User
- *         edge.From("owner", User.Type).
- *             Ref("%ss").
- *             Unique(),
- *
- *         // Many-to-many: %s has many Tags
- *         edge.To("tags", Tag.Type),
- *     }
- * }
+ * render renders a template with the resource's names, fields and relations.
  */
-func (%s) Edges() []ent.Edge {
-	return nil
+func (g *EntGenerator) render(name string, data templateData) (string, error) {
+	return renderTemplate(g.paths.Templates, name, templateData{
+		"Name":       g.name,
+		"Pascal":     g.pascalName,
+		"Camel":      g.camelName,
+		"Snake":      toSnakeCase(g.name),
+		"Plural":     toPlural(g.name),
+		"Fields":     g.fields,
+		"Relations":  g.relations,
+		"Timestamps": g.timestamps,
+	}.with(data))
 }
 
 /*
- * Indexes of the %s entity.
+ * GenerateSchema creates an Ent schema file for the entity.
  */
-func (%s) Indexes() []ent.Index {
-	return []ent.Index{
-		// TODO: Add indexes for frequently queried fields
-		//
-		// Example indexes:
-		// index.Fields("name"),
-		// index.Fields("status"),
-		// index.Fields("created_at"),
-		//
-		// Composite index:
-		// index.Fields("status", "created_at"),
-		//
-		// Unique index:
-		// index.Fields("email").Unique(),
+func (g *EntGenerator) GenerateSchema() error {
+	/* entsql.Annotation names a table of the current schema only */
+	if strings.Contains(g.table, ".") {
+		return fmt.Errorf("ent schemas cannot map the schema-qualified table %s; use a table of the current schema", g.table)
 	}
+
+	content, err := g.render("ent/schema.go.tmpl", templateData{
+		"Imports": g.schemaImports(),
+		"Table":   g.table,
+	})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(g.paths.Ent, "schema", g.snakeName+".go")
+	return g.out.WriteGo(path, content)
 }
 
 /*
- * Mixin allows embedding common fields across schemas.
- * Uncomment to use timestamps mixin instead of explicit fields.
- *
- * func (%s) Mixin() []ent.Mixin {
- *     return []ent.Mixin{
- *         TimestampsMixin{},
- *     }
- * }
+ * schemaImports returns the standard library imports of the schema.
  */
-`,
-		g.pascalName, g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.name,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.snakeName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-	)
-
-	path := filepath.Join("app/ent/schema", g.snakeName+".go")
-	return os.WriteFile(path, []byte(content), 0644)
+func (g *EntGenerator) schemaImports() []string {
+	var imports []string
+	if g.timestamps {
+		imports = append(imports, "time")
+	}
+	for _, field := range g.fields {
+		if field.Type == "json" && !containsImport(imports, "encoding/json") {
+			imports = append(imports, "encoding/json")
+		}
+	}
+	sort.Strings(imports)
+	return imports
 }
 
 /*
@@ -0,0 +1,776 @@
+/*
+ * GoAstra CLI - Resource Fields
+ *
+ * Parses the field DSL of `goastra generate crud` and maps each field
+ * to its Go, SQL, TypeScript, GraphQL, protobuf and Ent representations.
+ */
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Field is a resource field declared as name:type[:modifier...], e.g.
+ * "price:decimal" or "stock:int:default=0". Name is the snake_case
+ * column and JSON name. Fields are NOT NULL unless Nullable. References
+ * names the resource a foreign key field points at.
+ */
+type Field struct {
+	Name       string
+	Type       string
+	Required   bool
+	Unique     bool
+	Index      bool
+	Nullable   bool
+	Default    string
+	Min        string
+	Max        string
+	References string
+}
+
+/*
+ * fieldType describes how a DSL type maps to each layer. Columns are
+ * keyed by database driver; Validate is an extra validate rule and
+ * Input the HTML input type of form controls. GraphQL and Proto are the
+ * schema types of GraphQL and tRPC APIs, and Ent the ent/schema/field
+ * builder of Ent schemas.
+ */
+type fieldType struct {
+	GoType   string
+	Import   string
+	TSType   string
+	Columns  map[string]string
+	Validate string
+	Input    string
+	GraphQL  string
+	Proto    string
+	Ent      string
+}
+
+/*
+ * fieldTypes lists the supported field types.
+ */
+var fieldTypes = map[string]fieldType{
+	"string": {GoType: "string", TSType: "string", Input: "text",
+		GraphQL: "String", Proto: "string", Ent: "String",
+		Columns: map[string]string{"postgres": "VARCHAR(%s)", "mysql": "VARCHAR(%s)", "sqlite": "TEXT"}},
+	"text": {GoType: "string", TSType: "string", Input: "textarea",
+		GraphQL: "String", Proto: "string", Ent: "Text",
+		Columns: map[string]string{"postgres": "TEXT", "mysql": "TEXT", "sqlite": "TEXT"}},
+	"email": {GoType: "string", TSType: "string", Input: "email", Validate: "email",
+		GraphQL: "String", Proto: "string", Ent: "String",
+		Columns: map[string]string{"postgres": "VARCHAR(%s)", "mysql": "VARCHAR(%s)", "sqlite": "TEXT"}},
+	"url": {GoType: "string", TSType: "string", Input: "url", Validate: "url",
+		GraphQL: "String", Proto: "string", Ent: "String",
+		Columns: map[string]string{"postgres": "VARCHAR(%s)", "mysql": "VARCHAR(%s)", "sqlite": "TEXT"}},
+	"uuid": {GoType: "string", TSType: "string", Input: "text", Validate: "uuid",
+		GraphQL: "String", Proto: "string", Ent: "String",
+		Columns: map[string]string{"postgres": "UUID", "mysql": "CHAR(36)", "sqlite": "TEXT"}},
+	"int": {GoType: "int", TSType: "number", Input: "number",
+		GraphQL: "Int", Proto: "int64", Ent: "Int",
+		Columns: map[string]string{"postgres": "INTEGER", "mysql": "INT", "sqlite": "INTEGER"}},
+	"bigint": {GoType: "int64", TSType: "number", Input: "number",
+		GraphQL: "Int", Proto: "int64", Ent: "Int64",
+		Columns: map[string]string{"postgres": "BIGINT", "mysql": "BIGINT", "sqlite": "INTEGER"}},
+	"float": {GoType: "float64", TSType: "number", Input: "number",
+		GraphQL: "Float", Proto: "double", Ent: "Float",
+		Columns: map[string]string{"postgres": "DOUBLE PRECISION", "mysql": "DOUBLE", "sqlite": "REAL"}},
+	"decimal": {GoType: "float64", TSType: "number", Input: "number",
+		GraphQL: "Float", Proto: "double", Ent: "Float",
+		Columns: map[string]string{"postgres": "NUMERIC(12,2)", "mysql": "DECIMAL(12,2)", "sqlite": "NUMERIC"}},
+	"bool": {GoType: "bool", TSType: "boolean", Input: "checkbox",
+		GraphQL: "Boolean", Proto: "bool", Ent: "Bool",
+		Columns: map[string]string{"postgres": "BOOLEAN", "mysql": "BOOLEAN", "sqlite": "BOOLEAN"}},
+	"time": {GoType: "time.Time", Import: "time", TSType: "string", Input: "datetime-local",
+		GraphQL: "Time", Proto: "string", Ent: "Time",
+		Columns: map[string]string{"postgres": "TIMESTAMP WITH TIME ZONE", "mysql": "DATETIME", "sqlite": "DATETIME"}},
+	"date": {GoType: "time.Time", Import: "time", TSType: "string", Input: "date",
+		GraphQL: "Time", Proto: "string", Ent: "Time",
+		Columns: map[string]string{"postgres": "DATE", "mysql": "DATE", "sqlite": "DATE"}},
+	"json": {GoType: "json.RawMessage", Import: "encoding/json", TSType: "unknown", Input: "textarea",
+		GraphQL: "Any", Proto: "string", Ent: "JSON",
+		Columns: map[string]string{"postgres": "JSONB", "mysql": "JSON", "sqlite": "TEXT"}},
+}
+
+/*
+ * fieldTypeAliases maps alternative spellings to field types.
+ */
+var fieldTypeAliases = map[string]string{
+	"int64":     "bigint",
+	"float64":   "float",
+	"boolean":   "bool",
+	"datetime":  "time",
+	"timestamp": "time",
+}
+
+/*
+ * reservedFields are columns every generated resource already has.
+ */
+var reservedFields = map[string]bool{"id": true, "created_at": true, "updated_at": true}
+
+/*
+ * ParseFields parses field declarations such as
+ * "sku:string:unique" or "stock:int:default=0".
+ */
+func ParseFields(specs []string) ([]Field, error) {
+	var fields []Field
+	seen := make(map[string]bool)
+
+	for _, spec := range specs {
+		field, err := parseField(spec)
+		if err != nil {
+			return nil, err
+		}
+		if reservedFields[field.Name] {
+			return nil, fmt.Errorf("field %q is generated automatically", field.Name)
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("duplicate field %q", field.Name)
+		}
+		seen[field.Name] = true
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func parseField(spec string) (Field, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || parts[0] == "" {
+		return Field{}, fmt.Errorf("invalid field %q (use name:type[:modifier...])", spec)
+	}
+
+	field := Field{Name: toSnakeCase(strings.ReplaceAll(parts[0], "-", "_")), Type: strings.ToLower(parts[1])}
+	if alias, ok := fieldTypeAliases[field.Type]; ok {
+		field.Type = alias
+	}
+	if _, ok := fieldTypes[field.Type]; !ok {
+		return Field{}, fmt.Errorf("field %q: unknown type %q (use %s)", field.Name, parts[1], strings.Join(FieldTypeNames(), ", "))
+	}
+	for _, r := range field.Name {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return Field{}, fmt.Errorf("invalid field name %q", parts[0])
+		}
+	}
+	if err := checkColumnName(field.Name); err != nil {
+		return Field{}, err
+	}
+
+	modifiers := make(map[string]bool)
+	for _, modifier := range parts[2:] {
+		key, value, hasValue := strings.Cut(modifier, "=")
+		name := key
+		if name == "optional" {
+			name = "nullable"
+		}
+		if modifiers[name] {
+			return Field{}, fmt.Errorf("field %q: duplicate modifier %q", field.Name, key)
+		}
+		modifiers[name] = true
+
+		switch {
+		case key == "required" && !hasValue:
+			field.Required = true
+		case key == "unique" && !hasValue:
+			field.Unique = true
+		case key == "index" && !hasValue:
+			field.Index = true
+		case (key == "nullable" || key == "optional") && !hasValue:
+			field.Nullable = true
+		case key == "default" && hasValue:
+			field.Default = value
+		case (key == "min" || key == "max") && hasValue:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return Field{}, fmt.Errorf("field %q: %s must be a number", field.Name, key)
+			}
+			if key == "min" {
+				field.Min = value
+			} else {
+				field.Max = value
+			}
+		default:
+			return Field{}, fmt.Errorf("field %q: unknown modifier %q (use required, unique, index, nullable, default=, min=, max=)", field.Name, modifier)
+		}
+	}
+
+	if field.Default != "" {
+		if err := field.checkDefault(); err != nil {
+			return Field{}, err
+		}
+		if field.Type == "bool" {
+			value, _ := strconv.ParseBool(field.Default)
+			field.Default = strconv.FormatBool(value)
+		}
+	}
+	if field.Required && field.Nullable {
+		return Field{}, fmt.Errorf("field %q cannot be both required and nullable", field.Name)
+	}
+	if field.Min != "" && field.Max != "" {
+		low, _ := strconv.ParseFloat(field.Min, 64)
+		high, _ := strconv.ParseFloat(field.Max, 64)
+		if low > high {
+			return Field{}, fmt.Errorf("field %q: min=%s is greater than max=%s", field.Name, field.Min, field.Max)
+		}
+	}
+
+	return field, nil
+}
+
+/*
+ * FieldTypeNames returns the supported field type names in order.
+ */
+func FieldTypeNames() []string {
+	return []string{"string", "text", "email", "url", "uuid", "int", "bigint", "float", "decimal", "bool", "time", "date", "json"}
+}
+
+func (f Field) kind() fieldType {
+	return fieldTypes[f.Type]
+}
+
+/*
+ * Numeric reports whether the field holds a number.
+ */
+func (f Field) Numeric() bool {
+	return f.kind().TSType == "number"
+}
+
+func (f Field) checkDefault() error {
+	switch {
+	case f.Numeric():
+		if _, err := strconv.ParseFloat(f.Default, 64); err != nil {
+			return fmt.Errorf("field %q: default must be a number", f.Name)
+		}
+	case f.Type == "bool":
+		if _, err := strconv.ParseBool(f.Default); err != nil {
+			return fmt.Errorf("field %q: default must be true or false", f.Name)
+		}
+	case f.Type == "time" || f.Type == "date" || f.Type == "json":
+		return fmt.Errorf("field %q: defaults are not supported for %s fields", f.Name, f.Type)
+	}
+	return nil
+}
+
+/*
+ * GoName returns the exported Go field name, e.g. "unit_price" → "UnitPrice".
+ */
+func (f Field) GoName() string {
+	var sb strings.Builder
+	for _, word := range strings.Split(f.Name, "_") {
+		if word == "" {
+			continue
+		}
+		switch upper := strings.ToUpper(word); upper {
+		case "ID", "URL", "UUID", "API", "HTTP", "IP", "SKU", "JSON", "HTML", "SQL":
+			sb.WriteString(upper)
+		default:
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return sb.String()
+}
+
+/*
+ * GoType returns the model field type; nullable fields are pointers and
+ * foreign keys are uint, like the IDs they hold.
+ */
+func (f Field) GoType() string {
+	if f.References != "" {
+		return "uint"
+	}
+	if f.Nullable && f.Type != "json" {
+		return "*" + f.kind().GoType
+	}
+	return f.kind().GoType
+}
+
+/*
+ * Label returns the human-readable field name, e.g. "Unit price".
+ */
+func (f Field) Label() string {
+	label := strings.ReplaceAll(f.Name, "_", " ")
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+/*
+ * rules returns the validate rules for the field's value, without
+ * required or omitempty.
+ */
+func (f Field) rules() []string {
+	var rules []string
+	if f.kind().Validate != "" {
+		rules = append(rules, f.kind().Validate)
+	}
+	if f.Min != "" {
+		rules = append(rules, "min="+f.Min)
+	}
+	if f.Max != "" {
+		rules = append(rules, "max="+f.Max)
+	}
+	return rules
+}
+
+/*
+ * CreateValidate returns the validate tag for create input.
+ * Booleans are never required, as false is their zero value.
+ */
+func (f Field) CreateValidate() string {
+	rules := f.rules()
+	switch {
+	case f.Required && f.Type != "bool":
+		rules = append([]string{"required"}, rules...)
+	case len(rules) > 0:
+		rules = append([]string{"omitempty"}, rules...)
+	}
+	return strings.Join(rules, ",")
+}
+
+/*
+ * UpdateValidate returns the validate tag for partial update input.
+ */
+func (f Field) UpdateValidate() string {
+	rules := f.rules()
+	if len(rules) == 0 {
+		return ""
+	}
+	return strings.Join(append([]string{"omitempty"}, rules...), ",")
+}
+
+/*
+ * ColumnType returns the SQL column type for a database driver.
+ */
+func (f Field) ColumnType(driver string) string {
+	columns := f.kind().Columns
+	column, ok := columns[driver]
+	if !ok {
+		column = columns["postgres"]
+	}
+	if strings.Contains(column, "%s") {
+		size := "255"
+		if f.Max != "" {
+			size = f.Max
+		}
+		column = fmt.Sprintf(column, size)
+	}
+	return column
+}
+
+/*
+ * SQLDefault returns the default as an SQL literal.
+ */
+func (f Field) SQLDefault() string {
+	if f.Numeric() || f.Type == "bool" {
+		return strings.ToLower(f.Default)
+	}
+	return "'" + strings.ReplaceAll(f.Default, "'", "''") + "'"
+}
+
+/*
+ * GraphQLType returns the GraphQL type of the field, without the
+ * non-null marker.
+ */
+func (f Field) GraphQLType() string {
+	return f.kind().GraphQL
+}
+
+/*
+ * EntType returns the Ent field builder of the field, e.g. "String".
+ */
+func (f Field) EntType() string {
+	if f.References != "" {
+		/* Edge fields have the type of the target's int ID */
+		return "Int"
+	}
+	return f.kind().Ent
+}
+
+/*
+ * ProtoType returns the protobuf scalar type of the field.
+ */
+func (f Field) ProtoType() string {
+	return f.kind().Proto
+}
+
+/*
+ * TSType returns the TypeScript type of the field.
+ */
+func (f Field) TSType() string {
+	if f.Nullable {
+		return f.kind().TSType + " | null"
+	}
+	return f.kind().TSType
+}
+
+/*
+ * GoDefault returns the default as a Go literal.
+ */
+func (f Field) GoDefault() string {
+	if f.Numeric() || f.Type == "bool" {
+		return strings.ToLower(f.Default)
+	}
+	return strconv.Quote(f.Default)
+}
+
+/*
+ * TSDefault returns the initial form value of the field.
+ */
+func (f Field) TSDefault() string {
+	switch {
+	case f.Default != "" && (f.Numeric() || f.Type == "bool"):
+		return strings.ToLower(f.Default)
+	case f.Default != "":
+		return "'" + strings.ReplaceAll(f.Default, "'", "\\'") + "'"
+	case f.Type == "bool":
+		return "false"
+	case f.Numeric() || f.Nullable:
+		return "null"
+	}
+	return "''"
+}
+
+/*
+ * Validators returns the Angular validators of the form control.
+ */
+func (f Field) Validators() []string {
+	var validators []string
+	if f.Required && f.Type != "bool" {
+		validators = append(validators, "Validators.required")
+	}
+	switch f.Type {
+	case "email":
+		validators = append(validators, "Validators.email")
+	}
+	if f.Numeric() {
+		if f.Min != "" {
+			validators = append(validators, fmt.Sprintf("Validators.min(%s)", f.Min))
+		}
+		if f.Max != "" {
+			validators = append(validators, fmt.Sprintf("Validators.max(%s)", f.Max))
+		}
+	} else {
+		if f.Min != "" {
+			validators = append(validators, fmt.Sprintf("Validators.minLength(%s)", f.Min))
+		}
+		if f.Max != "" {
+			validators = append(validators, fmt.Sprintf("Validators.maxLength(%s)", f.Max))
+		}
+	}
+	return validators
+}
+
+/*
+ * Listed reports whether the field is shown as a list column.
+ */
+func (f Field) Listed() bool {
+	return f.Type != "text" && f.Type != "json"
+}
+
+func containsImport(imports []string, imp string) bool {
+	for _, existing := range imports {
+		if existing == imp {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * importList returns the sorted standard library imports of a file
+ * using fields, in addition to base.
+ */
+func importList(fields []Field, base ...string) []string {
+	imports := append([]string(nil), base...)
+	for _, field := range fields {
+		if imp := field.kind().Import; imp != "" && !containsImport(imports, imp) {
+			imports = append(imports, imp)
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+/*
+ * InputType returns the Go type of the field in create or update
+ * input. Update input fields are pointers so omitted fields are left
+ * as is; so are create input fields with a default, which applies when
+ * they are omitted.
+ */
+func (f Field) InputType(update bool) string {
+	if (update || f.Default != "") && !f.Nullable && f.Type != "json" {
+		return "*" + f.GoType()
+	}
+	return f.GoType()
+}
+
+/*
+ * InputJSONTag returns the json tag of the field in create or update
+ * input, and InputValidate its validate tag.
+ */
+func (f Field) InputJSONTag(update bool) string {
+	if update || f.Default != "" {
+		return f.Name + ",omitempty"
+	}
+	return f.JSONTag()
+}
+
+func (f Field) InputValidate(update bool) string {
+	if update {
+		return f.UpdateValidate()
+	}
+	return f.CreateValidate()
+}
+
+/*
+ * placeholder returns the n-th query parameter for a database driver:
+ * numbered for PostgreSQL, positional for MySQL and SQLite.
+ */
+func placeholder(driver string, n int) string {
+	switch driver {
+	case "mysql", "sqlite":
+		return "?"
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
+/*
+ * sqlNow returns the current time in SQL for a database driver.
+ */
+func sqlNow(driver string) string {
+	switch driver {
+	case "mysql", "sqlite":
+		return "CURRENT_TIMESTAMP"
+	}
+	return "NOW()"
+}
+
+/*
+ * sqlReserved are the words PostgreSQL, MySQL or SQLite reserve that a
+ * table or column may be named after, such as user and order.
+ */
+var sqlReserved = map[string]bool{
+	"all": true, "alter": true, "and": true, "any": true, "array": true, "as": true,
+	"asc": true, "both": true, "case": true, "cast": true, "check": true, "collate": true,
+	"column": true, "condition": true, "constraint": true, "create": true, "cross": true,
+	"current_user": true, "database": true, "default": true, "delete": true, "desc": true,
+	"distinct": true, "do": true, "drop": true, "else": true, "end": true, "except": true,
+	"false": true, "fetch": true, "for": true, "foreign": true, "from": true, "grant": true,
+	"group": true, "groups": true, "having": true, "in": true, "index": true, "insert": true,
+	"intersect": true, "interval": true, "into": true, "is": true, "join": true, "key": true,
+	"keys": true, "leading": true, "left": true, "limit": true, "lock": true, "match": true,
+	"not": true, "null": true, "offset": true, "on": true, "only": true, "option": true,
+	"or": true, "order": true, "primary": true, "range": true, "rank": true, "read": true,
+	"references": true, "release": true, "returning": true, "right": true, "row": true,
+	"rows": true, "schema": true, "select": true, "session_user": true, "set": true,
+	"some": true, "table": true, "then": true, "to": true, "trailing": true, "true": true,
+	"union": true, "unique": true, "update": true, "usage": true, "user": true,
+	"using": true, "values": true, "when": true, "where": true, "window": true,
+	"with": true, "write": true,
+}
+
+/*
+ * checkColumnName rejects a column name that is a reserved word.
+ * Generated queries quote table names but write columns as they are.
+ */
+func checkColumnName(name string) error {
+	if sqlReserved[strings.ToLower(name)] {
+		return fmt.Errorf("column name %q is reserved in SQL; choose another name", name)
+	}
+	return nil
+}
+
+/*
+ * quoteIdent returns a table name for SQL, quoted for the database
+ * driver when it is a reserved word or not a lower-case identifier:
+ * "user" and "Customers" for PostgreSQL and SQLite, `+"`order`"+` for
+ * MySQL. Each part of a schema-qualified name is quoted on its own, so
+ * sales.orders is left as it is. Other names are left as they are.
+ */
+func quoteIdent(driver, name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if validIdentifier(part) && !sqlReserved[part] {
+			continue
+		}
+		if driver == "mysql" {
+			parts[i] = "`" + strings.ReplaceAll(part, "`", "``") + "`"
+		} else {
+			parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+/*
+ * tableKey returns the snake_case name of a table without its schema,
+ * e.g. "sales.orders" → "orders" or "OrderItems" → "order_items", which
+ * the resource name and the names of join tables and their key columns
+ * are made from. Other characters become underscores.
+ */
+func tableKey(table string) string {
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		table = table[i+1:]
+	}
+	if strings.ToUpper(table) == table {
+		table = strings.ToLower(table)
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, toSnakeCase(table))
+}
+
+/*
+ * Input returns the form input of the field: an input type, "textarea"
+ * or "checkbox".
+ */
+func (f Field) Input() string {
+	return f.kind().Input
+}
+
+/*
+ * Step returns the step attribute of a number input.
+ */
+func (f Field) Step() string {
+	switch f.Type {
+	case "float":
+		return "any"
+	case "decimal":
+		return "0.01"
+	}
+	return "1"
+}
+
+/*
+ * generatedGoName returns the Go name Ent and gqlgen give the field.
+ * They know the initialisms GoName uses, except SKU.
+ */
+func (f Field) generatedGoName() string {
+	return strings.ReplaceAll(f.GoName(), "SKU", "Sku")
+}
+
+/*
+ * protoGoName returns the Go name protoc-gen-go gives the field, which
+ * capitalizes every word: "image_url" → "ImageUrl".
+ */
+func (f Field) protoGoName() string {
+	var sb strings.Builder
+	for _, word := range strings.Split(f.Name, "_") {
+		if word != "" {
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return sb.String()
+}
+
+/*
+ * protoTSName returns the property name protobuf-es gives the field.
+ */
+func (f Field) protoTSName() string {
+	name := f.protoGoName()
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+/*
+ * conversion turns a field value of one layer's Go type into another's.
+ * Expr converts a non-pointer value; when Fails is set it yields a value
+ * and an error. An Interface source is checked against nil but used as
+ * is rather than dereferenced.
+ */
+type conversion struct {
+	Expr      func(value string) string
+	Fails     bool
+	Interface bool
+}
+
+/*
+ * identity is the conversion between identical types.
+ */
+var identity = conversion{Expr: func(value string) string { return value }}
+
+/*
+ * convertField returns the statements assigning src to dst through conv,
+ * where dstPtr and srcPtr tell which of them are pointers. A nil source
+ * leaves dst unset. Failing conversions assign an err declared by the
+ * enclosing function and return it with a nil first result.
+ */
+func convertField(dst, src string, dstPtr, srcPtr bool, conv conversion) string {
+	if !srcPtr && !conv.Interface {
+		body := convertValue(dst, src, dstPtr, conv)
+		if dstPtr {
+			/* Scope the temporary so fields can reuse its name */
+			return fmt.Sprintf("\t{\n%s\t}\n", indentLines(body))
+		}
+		return body
+	}
+	value := "*" + src
+	if conv.Interface {
+		value = src
+	}
+	if dstPtr && !conv.Fails && conv.Expr(value) == value {
+		return fmt.Sprintf("\t%s = %s\n", dst, src)
+	}
+	body := convertValue(dst, value, dstPtr, conv)
+	return fmt.Sprintf("\tif %s != nil {\n%s\t}\n", src, indentLines(body))
+}
+
+/*
+ * convertValue assigns the non-pointer value through conv.
+ */
+func convertValue(dst, value string, dstPtr bool, conv conversion) string {
+	expr := conv.Expr(value)
+	switch {
+	case !dstPtr && !conv.Fails:
+		return fmt.Sprintf("\t%s = %s\n", dst, expr)
+	case !dstPtr:
+		return fmt.Sprintf("\tif %s, err = %s; err != nil {\n\t\treturn nil, err\n\t}\n", dst, expr)
+	case !conv.Fails:
+		return fmt.Sprintf("\tv := %s\n\t%s = &v\n", expr, dst)
+	}
+	return fmt.Sprintf("\tv, err := %s\n\tif err != nil {\n\t\treturn nil, err\n\t}\n\t%s = &v\n", expr, dst)
+}
+
+/*
+ * operand parenthesizes a dereferenced value so a selector applies to
+ * the value rather than the pointer expression.
+ */
+func operand(value string) string {
+	if strings.HasPrefix(value, "*") {
+		return "(" + value + ")"
+	}
+	return value
+}
+
+/*
+ * indentLines indents every line of s by one tab.
+ */
+func indentLines(s string) string {
+	return "\t" + strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", "\n\t") + "\n"
+}
+
+/*
+ * JSONTag returns the json tag of the field; nullable fields are
+ * omitted when empty.
+ */
+func (f Field) JSONTag() string {
+	if f.Nullable {
+		return f.Name + ",omitempty"
+	}
+	return f.Name
+}
+
+/*
+ * formatGo formats Go source with gofmt, returning it unchanged when it
+ * does not parse.
+ */
+func formatGo(content string) string {
+	if formatted, err := format.Source([]byte(content)); err == nil {
+		return string(formatted)
+	}
+	return content
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		specs  []string
+		fields []Field
+		err    string // a substring of the error, "" when parsing succeeds
+	}{
+		{
+			name:   "type only",
+			specs:  []string{"title:string"},
+			fields: []Field{{Name: "title", Type: "string"}},
+		},
+		{
+			name:  "modifiers",
+			specs: []string{"sku:string:required:unique:index", "stock:int:default=0", "note:text:optional"},
+			fields: []Field{
+				{Name: "sku", Type: "string", Required: true, Unique: true, Index: true},
+				{Name: "stock", Type: "int", Default: "0"},
+				{Name: "note", Type: "text", Nullable: true},
+			},
+		},
+		{
+			name:   "bounds",
+			specs:  []string{"price:decimal:min=0:max=99.5", "rating:int:min=3:max=3"},
+			fields: []Field{{Name: "price", Type: "decimal", Min: "0", Max: "99.5"}, {Name: "rating", Type: "int", Min: "3", Max: "3"}},
+		},
+		{
+			name:   "names and aliases",
+			specs:  []string{"unitPrice:float64", "published-at:timestamp", "active:boolean:default=1"},
+			fields: []Field{{Name: "unit_price", Type: "float"}, {Name: "published_at", Type: "time"}, {Name: "active", Type: "bool", Default: "true"}},
+		},
+		{name: "missing type", specs: []string{"title"}, err: "use name:type"},
+		{name: "unknown type", specs: []string{"title:varchar"}, err: `unknown type "varchar"`},
+		{name: "invalid name", specs: []string{"tit.le:string"}, err: `invalid field name "tit.le"`},
+		{name: "generated column", specs: []string{"id:int"}, err: `field "id" is generated automatically`},
+		{name: "generated timestamp", specs: []string{"createdAt:time"}, err: `field "created_at" is generated automatically`},
+		{name: "reserved word", specs: []string{"order:int"}, err: `column name "order" is reserved`},
+		{name: "duplicate field", specs: []string{"title:string", "title:text"}, err: `duplicate field "title"`},
+		{name: "unknown modifier", specs: []string{"title:string:primary"}, err: `unknown modifier "primary"`},
+		{name: "duplicate modifier", specs: []string{"sku:string:unique:unique"}, err: `duplicate modifier "unique"`},
+		{name: "duplicate default", specs: []string{"stock:int:default=0:default=1"}, err: `duplicate modifier "default"`},
+		{name: "nullable and optional", specs: []string{"note:text:nullable:optional"}, err: `duplicate modifier "optional"`},
+		{name: "min above max", specs: []string{"price:int:min=5:max=2"}, err: "min=5 is greater than max=2"},
+		{name: "min not a number", specs: []string{"price:int:min=low"}, err: "min must be a number"},
+		{name: "default not a number", specs: []string{"stock:int:default=many"}, err: "default must be a number"},
+		{name: "default of a time", specs: []string{"due:date:default=now"}, err: "defaults are not supported"},
+		{name: "required and nullable", specs: []string{"title:string:required:nullable"}, err: "both required and nullable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := ParseFields(tt.specs)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ParseFields(%q) error = %v, want %q", tt.specs, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFields(%q): %v", tt.specs, err)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("ParseFields(%q) =\n%+v\nwant\n%+v", tt.specs, fields, tt.fields)
+			}
+		})
+	}
+}
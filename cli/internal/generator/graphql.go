@@ -7,18 +7,24 @@
 package generator
 
 import (
-	"fmt"
-	"os"
 	"path/filepath"
+	"strings"
 )
 
 /*
  * GraphQLGenerator handles GraphQL schema and resolver generation.
+ * Resolvers are stubs unless they are backed by the generated service.
  */
 type GraphQLGenerator struct {
 	name       string
 	pascalName string
 	camelName  string
+	fields     []Field
+	timestamps bool
+	paths      Paths
+	stack      Stack
+	services   bool
+	out        *Writer
 }
 
 /*
@@ -29,218 +35,278 @@ func NewGraphQLGenerator(name string) *GraphQLGenerator {
 		name:       name,
 		pascalName: toPascalCase(name),
 		camelName:  toCamelCase(name),
+		timestamps: true,
+		paths:      DefaultPaths(),
+		stack:      DefaultStack(),
+		out:        NewWriter(nil),
 	}
 }
 
 /*
- * GenerateSchema creates a GraphQL schema file for the resource.
+ * SetWriter sets the writer generated files go through.
  */
-func (g *GraphQLGenerator) GenerateSchema() error {
-	content := fmt.Sprintf(`# %s GraphQL Schema
-#
-# Generated by GoAstra CLI
-# Add this to your main schema.graphqls or keep as separate file
-
-# ============================================================================
-# %s QUERIES
-# ============================================================================
-
-extend type Query {
-  """Get a %s by ID"""
-  %s(id: ID!): %s
-
-  """List %s with pagination"""
-  %ss(page: Int = 1, pageSize: Int = 20): %sConnection!
-}
-
-# ============================================================================
-# %s MUTATIONS
-# ============================================================================
-
-extend type Mutation {
-  """Create a new %s"""
-  create%s(input: Create%sInput!): %s!
-
-  """Update an existing %s"""
-  update%s(id: ID!, input: Update%sInput!): %s!
-
-  """Delete a %s"""
-  delete%s(id: ID!): Boolean!
-}
-
-# ============================================================================
-# %s TYPES
-# ============================================================================
-
-"""%s entity"""
-type %s {
-  id: ID!
-  # TODO: Add your fields here
-  # name: String!
-  # description: String
-  createdAt: String!
-  updatedAt: String!
-}
-
-"""Paginated list of %s"""
-type %sConnection {
-  data: [%s!]!
-  total: Int!
-  page: Int!
-  pageSize: Int!
-  totalPages: Int!
-}
-
-# ============================================================================
-# %s INPUTS
-# ============================================================================
-
-"""Input for creating a %s"""
-input Create%sInput {
-  # TODO: Add your create input fields here
-  # name: String!
-  # description: String
-}
-
-"""Input for updating a %s"""
-input Update%sInput {
-  # TODO: Add your update input fields here
-  # name: String
-  # description: String
-}
-`,
-		g.pascalName,
-		g.pascalName,
-		g.name, g.camelName, g.pascalName,
-		g.name, g.camelName, g.pascalName,
-		g.pascalName,
-		g.name,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName,
-		g.name,
-		g.pascalName,
-		g.name,
-		g.pascalName,
-	)
-
-	path := filepath.Join("app/graph", g.name+".graphqls")
-	return os.WriteFile(path, []byte(content), 0644)
+func (g *GraphQLGenerator) SetWriter(out *Writer) {
+	g.out = out
 }
 
 /*
- * GenerateResolver creates resolver implementations for the resource.
+ * SetFields sets the fields of the GraphQL type and inputs.
  */
-func (g *GraphQLGenerator) GenerateResolver() error {
-	content := fmt.Sprintf(`/*
- * %s Resolvers
- *
- * GraphQL resolver implementations for %s operations.
- * Generated by GoAstra CLI
+func (g *GraphQLGenerator) SetFields(fields []Field) {
+	g.fields = fields
+}
+
+/*
+ * SetTimestamps sets whether the type has the createdAt and updatedAt
+ * fields.
+ */
+func (g *GraphQLGenerator) SetTimestamps(timestamps bool) {
+	g.timestamps = timestamps
+}
+
+/*
+ * SetPaths sets the graph directory and the packages resolvers import.
  */
-package graph
+func (g *GraphQLGenerator) SetPaths(paths Paths) {
+	g.paths = paths
+}
 
-import (
-	"context"
-	"errors"
+/*
+ * SetStack sets the project's ORM, which decides the database client
+ * service-backed resolvers build the repository on.
+ */
+func (g *GraphQLGenerator) SetStack(stack Stack) {
+	g.stack = stack
+}
+
+/*
+ * SetServices makes the resolvers call the service generated by
+ * `goastra generate api` instead of returning "not implemented".
+ */
+func (g *GraphQLGenerator) SetServices(services bool) {
+	g.services = services
+}
+
+/*
+ * render renders a template with the resource's names and fields.
+ */
+func (g *GraphQLGenerator) render(name string, data templateData) (string, error) {
+	return renderTemplate(g.paths.Templates, name, templateData{
+		"Name":       g.name,
+		"Pascal":     g.pascalName,
+		"Camel":      g.camelName,
+		"Snake":      toSnakeCase(g.name),
+		"Plural":     toPlural(g.name),
+		"Fields":     g.fields,
+		"Timestamps": g.timestamps,
+	}.with(data))
+}
+
+/*
+ * GenerateSchema creates a GraphQL schema file for the resource.
+ */
+func (g *GraphQLGenerator) GenerateSchema() error {
+	content, err := g.render("graphql/schema.graphqls.tmpl", nil)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(g.paths.Graph, g.name+".graphqls")
+	if err := g.out.Write(path, content); err != nil {
+		return err
+	}
+	return g.generateScalars()
+}
+
+/*
+ * graphShape selects the resource type or one of its inputs. Create
+ * input fields are non-null unless they are nullable or have a default;
+ * update input fields are all optional. JSON values are always nullable.
+ */
+type graphShape int
 
-	"app/graph/model"
+const (
+	graphType graphShape = iota
+	graphCreate
+	graphUpdate
 )
 
-// ============================================================================
-// %s QUERY RESOLVERS
-// ============================================================================
+/*
+ * graphNonNull reports whether field is non-null in shape, which makes
+ * its gqlgen Go type a value rather than a pointer.
+ */
+func graphNonNull(f Field, shape graphShape) bool {
+	switch {
+	case f.Type == "json" || f.Nullable:
+		return false
+	case shape == graphCreate:
+		return f.Default == ""
+	}
+	return shape == graphType
+}
 
 /*
- * %s returns a single %s by ID.
+ * generateScalars declares the Time and Any scalars generated schemas
+ * use, unless the graph directory already has them.
  */
-func (r *queryResolver) %s(ctx context.Context, id string) (*model.%s, error) {
-	// TODO: Implement %s lookup
-	return nil, errors.New("not implemented")
+func (g *GraphQLGenerator) generateScalars() error {
+	path := filepath.Join(g.paths.Graph, "scalars.graphqls")
+	if fileExists(path) {
+		return nil
+	}
+
+	content := `# Scalars
+#
+# Shared by schemas generated by GoAstra CLI. gqlgen maps Time to
+# time.Time and Any to interface{}.
+
+scalar Time
+scalar Any
+`
+	return g.out.WriteShared(path, content)
 }
 
 /*
- * %ss returns a paginated list of %s.
+ * GenerateResolver creates resolver implementations for the resource.
  */
-func (r *queryResolver) %ss(ctx context.Context, page *int, pageSize *int) (*model.%sConnection, error) {
-	p := 1
-	ps := 20
-	if page != nil {
-		p = *page
+func (g *GraphQLGenerator) GenerateResolver() error {
+	if g.services {
+		return g.generateServiceResolver()
 	}
-	if pageSize != nil {
-		ps = *pageSize
+
+	content, err := g.render("graphql/resolver.go.tmpl", templateData{
+		"GraphPackage":     packageName(g.paths.Graph),
+		"GraphModelImport": g.paths.ImportPath(filepath.Join(g.paths.Graph, "model")),
+	})
+	if err != nil {
+		return err
 	}
 
-	// TODO: Implement %s listing with pagination
-	return &model.%sConnection{
-		Data:       []*model.%s{},
-		Total:      0,
-		Page:       p,
-		PageSize:   ps,
-		TotalPages: 0,
-	}, nil
+	path := filepath.Join(g.paths.Graph, g.name+".resolvers.go")
+	return g.out.Write(path, content)
 }
 
-// ============================================================================
-// %s MUTATION RESOLVERS
-// ============================================================================
+/*
+ * generateServiceResolver creates resolvers delegating to the service
+ * generated for the resource, converting between the gqlgen models and
+ * the service's models and inputs.
+ */
+func (g *GraphQLGenerator) generateServiceResolver() error {
+	models := packageName(g.paths.Models)
+	services := packageName(g.paths.Services)
+	repository := packageName(g.paths.Repository)
+
+	content, err := g.render("graphql/resolver_service.go.tmpl", templateData{
+		"GraphPackage":           packageName(g.paths.Graph),
+		"ResolverImports":        g.resolverImports(),
+		"GraphModelImport":       g.paths.ImportPath(filepath.Join(g.paths.Graph, "model")),
+		"ModelsImport":           g.paths.ImportPath(g.paths.Models),
+		"RepositoryImport":       g.paths.ImportPath(g.paths.Repository),
+		"ServicesImport":         g.paths.ImportPath(g.paths.Services),
+		"ServicesPackage":        services,
+		"RepositoryPackage":      repository,
+		"DBType":                 g.stack.dbType(),
+		"ModelsPackage":          models,
+		"InputConversionsCreate": g.inputConversions("create", graphCreate),
+		"InputConversionsUpdate": g.inputConversions("update", graphUpdate),
+		"OutputConversions":      g.outputConversions(),
+	})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(g.paths.Graph, g.name+".resolvers.go")
+	if err := g.out.WriteGo(path, content); err != nil {
+		return err
+	}
+	return g.generateIDs()
+}
 
 /*
- * Create%s creates a new %s.
+ * resolverImports returns the standard library imports of the
+ * service-backed resolvers.
  */
-func (r *mutationResolver) Create%s(ctx context.Context, input model.Create%sInput) (*model.%s, error) {
-	// TODO: Implement %s creation
-	return nil, errors.New("not implemented")
+func (g *GraphQLGenerator) resolverImports() string {
+	imports := []string{"context"}
+	for _, field := range g.fields {
+		if field.Type == "json" {
+			imports = append(imports, "encoding/json")
+			break
+		}
+	}
+	return "\t\"" + strings.Join(imports, "\"\n\t\"") + "\"\n"
 }
 
 /*
- * Update%s updates an existing %s.
+ * graphConversion converts a field between its gqlgen and service
+ * types: GraphQL Int is int, foreign keys are uint in the service and
+ * JSON arrives as a decoded value.
  */
-func (r *mutationResolver) Update%s(ctx context.Context, id string, input model.Update%sInput) (*model.%s, error) {
-	// TODO: Implement %s update
-	return nil, errors.New("not implemented")
+func graphConversion(f Field, toGraph bool) conversion {
+	switch {
+	case f.References != "" && toGraph:
+		return conversion{Expr: func(v string) string { return "int(" + v + ")" }}
+	case f.References != "":
+		return conversion{Expr: func(v string) string { return "uint(" + v + ")" }}
+	case f.Type == "bigint" && toGraph:
+		return conversion{Expr: func(v string) string { return "int(" + v + ")" }}
+	case f.Type == "bigint":
+		return conversion{Expr: func(v string) string { return "int64(" + v + ")" }}
+	case f.Type == "json" && !toGraph:
+		return conversion{Expr: func(v string) string { return "json.Marshal(" + v + ")" }, Fails: true, Interface: true}
+	}
+	return identity
 }
 
 /*
- * Delete%s deletes a %s by ID.
+ * inputConversions returns the statements filling the service input
+ * named dst from the GraphQL input.
  */
-func (r *mutationResolver) Delete%s(ctx context.Context, id string) (bool, error) {
-	// TODO: Implement %s deletion
-	return false, errors.New("not implemented")
+func (g *GraphQLGenerator) inputConversions(dst string, shape graphShape) string {
+	if len(g.fields) == 0 {
+		return "\t/* TODO: Map input fields */\n"
+	}
+
+	var sb strings.Builder
+	for _, field := range g.fields {
+		/* Service inputs use pointers for the same fields as GraphQL, except JSON */
+		ptr := !graphNonNull(field, shape) && field.Type != "json"
+		sb.WriteString(convertField(dst+"."+field.GoName(), "input."+field.generatedGoName(), ptr, ptr, graphConversion(field, false)))
+	}
+	return sb.String()
 }
-`,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.name,
-	)
 
-	path := filepath.Join("app/graph", g.name+".resolvers.go")
-	return os.WriteFile(path, []byte(content), 0644)
+/*
+ * outputConversions returns the statements copying the model's fields
+ * to the GraphQL model out.
+ */
+func (g *GraphQLGenerator) outputConversions() string {
+	var sb strings.Builder
+	for _, field := range g.fields {
+		ptr := field.Nullable && field.Type != "json"
+		sb.WriteString(convertField("out."+field.generatedGoName(), "item."+field.GoName(), ptr, ptr, graphConversion(field, true)))
+	}
+	return sb.String()
+}
+
+/*
+ * generateIDs writes the ID helpers shared by service-backed
+ * resolvers, unless they already exist.
+ */
+func (g *GraphQLGenerator) generateIDs() error {
+	path := filepath.Join(g.paths.Graph, "ids.go")
+	if fileExists(path) {
+		return nil
+	}
+
+	content, err := g.render("graphql/ids.go.tmpl", templateData{
+		"GraphPackage": packageName(g.paths.Graph),
+	})
+	if err != nil {
+		return err
+	}
+
+	return g.out.WriteSharedGo(path, content)
 }
 
 /*
@@ -0,0 +1,531 @@
+/*
+ * GoAstra CLI - Table Introspection
+ *
+ * Reads an existing table's columns, keys and foreign keys from the
+ * database so `goastra generate crud --from-table` can build a resource
+ * on top of it instead of creating the table.
+ */
+package generator
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Column is a table column as the database reports it. DataType is the
+ * lower-case base type (e.g. "character varying", "tinyint") and
+ * ColumnType the full MySQL type (e.g. "tinyint(1)") or PostgreSQL type
+ * name (e.g. "varchar", "_int4" for arrays). Primary, Unique
+ * and Indexed describe single-column indexes; References names the
+ * column of a single-column foreign key as "table.column".
+ */
+type Column struct {
+	Name       string
+	DataType   string
+	ColumnType string
+	Length     int
+	Nullable   bool
+	Default    string
+	HasDefault bool
+	Primary    bool
+	Unique     bool
+	Indexed    bool
+	References string
+}
+
+/*
+ * Table is an introspected table.
+ */
+type Table struct {
+	Name    string
+	Columns []Column
+}
+
+/*
+ * IntrospectTable reads the named table of the current schema (or
+ * database, on MySQL), or of the schema it is qualified with, as in
+ * sales.orders. The name is matched as the database stores it, so
+ * Customers is a table created with a quoted mixed-case name; generated
+ * queries quote it. The driver is the migrator's driver name.
+ */
+func IntrospectTable(db *sql.DB, driver, table string) (*Table, error) {
+	schema, name, err := splitTable(table)
+	if err != nil {
+		return nil, err
+	}
+
+	var t *Table
+	switch driver {
+	case "postgres":
+		t, err = introspectPostgres(db, schema, name)
+	case "mysql":
+		t, err = introspectMySQL(db, schema, name)
+	default:
+		return nil, fmt.Errorf("introspecting %s databases is not supported (use postgres or mysql)", driver)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table %s: %w", table, err)
+	}
+	if len(t.Columns) == 0 {
+		return nil, fmt.Errorf("table %q not found", table)
+	}
+	t.Name = table
+
+	/* An explicit DEFAULT NULL is no default (MariaDB, NULL::text) */
+	for i, col := range t.Columns {
+		value, _, _ := strings.Cut(col.Default, "::")
+		if strings.EqualFold(value, "NULL") {
+			t.Columns[i].Default, t.Columns[i].HasDefault = "", false
+		}
+	}
+	return t, nil
+}
+
+/*
+ * splitTable splits a table name given as table or schema.table. An
+ * empty schema is the current one.
+ */
+func splitTable(name string) (schema, table string, err error) {
+	schema, table, qualified := strings.Cut(name, ".")
+	if !qualified {
+		schema, table = "", name
+	}
+	if table == "" || qualified && schema == "" || strings.Contains(table, ".") || strings.ContainsRune(name, 0) {
+		return "", "", fmt.Errorf("invalid table name %q (use table or schema.table)", name)
+	}
+	return schema, table, nil
+}
+
+func introspectPostgres(db *sql.DB, schema, table string) (*Table, error) {
+	t := &Table{Name: table}
+
+	rows, err := db.Query(`
+		SELECT column_name, data_type, udt_name, COALESCE(character_maximum_length, 0),
+			is_nullable = 'YES', column_default
+		FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2
+		ORDER BY ordinal_position`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			col        Column
+			defaultVal sql.NullString
+		)
+		if err := rows.Scan(&col.Name, &col.DataType, &col.ColumnType, &col.Length, &col.Nullable, &defaultVal); err != nil {
+			return nil, err
+		}
+		col.DataType = strings.ToLower(col.DataType)
+		col.Default, col.HasDefault = defaultVal.String, defaultVal.Valid
+		t.Columns = append(t.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	/* Single-column indexes; partial and expression indexes are skipped */
+	indexes, err := db.Query(`
+		SELECT a.attname, i.indisprimary, i.indisunique
+		FROM pg_index i
+		JOIN pg_class t ON t.oid = i.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = i.indkey[0]
+		WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND t.relname = $2
+			AND i.indnatts = 1 AND i.indpred IS NULL`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer indexes.Close()
+
+	for indexes.Next() {
+		var (
+			name            string
+			primary, unique bool
+		)
+		if err := indexes.Scan(&name, &primary, &unique); err != nil {
+			return nil, err
+		}
+		t.markIndex(name, primary, unique)
+	}
+	if err := indexes.Err(); err != nil {
+		return nil, err
+	}
+
+	keys, err := db.Query(`
+		SELECT a.attname, r.relname, ra.attname
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_class r ON r.oid = c.confrelid
+		JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+		JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = c.confkey[1]
+		WHERE c.contype = 'f' AND array_length(c.conkey, 1) = 1
+			AND n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND t.relname = $2`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer keys.Close()
+
+	for keys.Next() {
+		var name, refTable, refColumn string
+		if err := keys.Scan(&name, &refTable, &refColumn); err != nil {
+			return nil, err
+		}
+		t.markReference(name, refTable+"."+refColumn)
+	}
+	return t, keys.Err()
+}
+
+func introspectMySQL(db *sql.DB, schema, table string) (*Table, error) {
+	t := &Table{Name: table}
+
+	rows, err := db.Query(`
+		SELECT column_name, data_type, column_type, COALESCE(character_maximum_length, 0),
+			is_nullable = 'YES', column_default, extra
+		FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+		ORDER BY ordinal_position`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			col        Column
+			defaultVal sql.NullString
+			extra      string
+		)
+		if err := rows.Scan(&col.Name, &col.DataType, &col.ColumnType, &col.Length, &col.Nullable, &defaultVal, &extra); err != nil {
+			return nil, err
+		}
+		col.DataType = strings.ToLower(col.DataType)
+		col.ColumnType = strings.ToLower(col.ColumnType)
+		col.Default, col.HasDefault = defaultVal.String, defaultVal.Valid
+
+		/* MySQL 8 reports expression defaults unparenthesized */
+		if strings.Contains(strings.ToUpper(extra), "DEFAULT_GENERATED") {
+			col.Default = "(" + col.Default + ")"
+		}
+		t.Columns = append(t.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	indexes, err := db.Query(`
+		SELECT index_name, MAX(column_name), MIN(non_unique)
+		FROM information_schema.statistics
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+		GROUP BY index_name
+		HAVING COUNT(*) = 1`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer indexes.Close()
+
+	for indexes.Next() {
+		var (
+			index, name string
+			nonUnique   int
+		)
+		if err := indexes.Scan(&index, &name, &nonUnique); err != nil {
+			return nil, err
+		}
+		t.markIndex(name, index == "PRIMARY", nonUnique == 0)
+	}
+	if err := indexes.Err(); err != nil {
+		return nil, err
+	}
+
+	keys, err := db.Query(`
+		SELECT constraint_name, column_name, referenced_table_name, referenced_column_name
+		FROM information_schema.key_column_usage
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+			AND referenced_table_name IS NOT NULL`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer keys.Close()
+
+	/* Composite foreign keys list one row per column and are skipped */
+	columns := make(map[string][]string)
+	references := make(map[string]string)
+	for keys.Next() {
+		var constraint, name, refTable, refColumn string
+		if err := keys.Scan(&constraint, &name, &refTable, &refColumn); err != nil {
+			return nil, err
+		}
+		columns[constraint] = append(columns[constraint], name)
+		references[constraint] = refTable + "." + refColumn
+	}
+	if err := keys.Err(); err != nil {
+		return nil, err
+	}
+	for constraint, names := range columns {
+		if len(names) == 1 {
+			t.markReference(names[0], references[constraint])
+		}
+	}
+	return t, nil
+}
+
+func (t *Table) column(name string) *Column {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+func (t *Table) markIndex(name string, primary, unique bool) {
+	if col := t.column(name); col != nil {
+		col.Primary = col.Primary || primary
+		col.Unique = col.Unique || unique
+		col.Indexed = true
+	}
+}
+
+func (t *Table) markReference(name, reference string) {
+	if col := t.column(name); col != nil {
+		col.References = reference
+	}
+}
+
+/*
+ * ResourceName returns the resource name for the table, e.g.
+ * "order_items" → "order_item" or "sales.Orders" → "order".
+ */
+func (t *Table) ResourceName() string {
+	return toSingular(tableKey(t.Name))
+}
+
+/*
+ * ForeignKeys returns the table's single-column foreign keys as
+ * "column → table.column". Keys are not mapped to relations; a key
+ * named <resource>_id is followed by the --belongs-to flag that makes
+ * it one.
+ */
+func (t *Table) ForeignKeys() []string {
+	var keys []string
+	for _, col := range t.Columns {
+		if col.References == "" {
+			continue
+		}
+		key := col.Name + " → " + col.References
+		table, _, _ := strings.Cut(col.References, ".")
+		if target := toSingular(table); col.Name == target+"_id" {
+			key += fmt.Sprintf(" (--belongs-to %s relates it)", target)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+/*
+ * Timestamps reports whether the table has both the created_at and
+ * updated_at columns generated repositories maintain. Tables without
+ * them get resources ordered by id instead.
+ */
+func (t *Table) Timestamps() bool {
+	return t.column("created_at") != nil && t.column("updated_at") != nil
+}
+
+/*
+ * Fields maps the table's columns to resource fields. The table must
+ * have the integer id primary key generated repositories look rows up
+ * by; it is not a field, nor are the timestamps when the table has
+ * both. Warnings report columns whose type or default could not be
+ * carried over exactly.
+ */
+func (t *Table) Fields() ([]Field, []string, error) {
+	id := t.column("id")
+	if id == nil || !id.Primary || !integerColumn(*id) {
+		return nil, nil, fmt.Errorf("table %q needs an integer primary key named id; generated repositories look rows up by id", t.Name)
+	}
+
+	var (
+		fields   []Field
+		warnings []string
+	)
+	for _, col := range t.Columns {
+		if col.Name == "id" || (reservedFields[col.Name] && t.Timestamps()) {
+			continue
+		}
+		if !validIdentifier(col.Name) {
+			return nil, nil, fmt.Errorf("column %q cannot be used as a field name (use lower-case letters, digits and underscores)", col.Name)
+		}
+		if err := checkColumnName(col.Name); err != nil {
+			return nil, nil, fmt.Errorf("table %q: %w", t.Name, err)
+		}
+
+		field, warning := columnField(col)
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+		fields = append(fields, field)
+	}
+	return fields, warnings, nil
+}
+
+/*
+ * columnField maps a column to a field. The column's constraints become
+ * modifiers: NOT NULL without a default makes text and time fields
+ * required, and the length of a VARCHAR becomes its max. A foreign key
+ * becomes an indexed field, not a relation.
+ */
+func columnField(col Column) (Field, string) {
+	field := Field{
+		Name:     col.Name,
+		Type:     columnFieldType(col),
+		Nullable: col.Nullable,
+		Unique:   col.Unique && !col.Primary,
+		Index:    (col.Indexed && !col.Unique) || col.References != "",
+	}
+
+	var warning string
+	if field.Type == "" {
+		field.Type = "string"
+		warning = fmt.Sprintf("column %s: type %s is not supported; generated as a string", col.Name, col.ColumnType)
+	}
+	if field.Type == "string" && col.Length > 0 {
+		field.Max = strconv.Itoa(col.Length)
+	}
+
+	if col.HasDefault {
+		value, ok := literalDefault(col.Default)
+		if ok {
+			field.Default = value
+			if field.Type == "bool" {
+				parsed, _ := strconv.ParseBool(value)
+				field.Default = strconv.FormatBool(parsed)
+			}
+		}
+		if !ok || field.checkDefault() != nil {
+			field.Default = ""
+			warning = fmt.Sprintf("column %s: default %s is computed by the database and is not carried into the generated code", col.Name, col.Default)
+		}
+	}
+
+	field.Required = !col.Nullable && !col.HasDefault && !field.Numeric() && field.Type != "bool"
+	return field, warning
+}
+
+/*
+ * columnFieldType returns the field type of a column, or "" when no
+ * field type matches.
+ */
+func columnFieldType(col Column) string {
+	switch col.DataType {
+	case "character varying", "varchar", "character", "char":
+		return "string"
+	case "text", "tinytext", "mediumtext", "longtext":
+		return "text"
+	case "uuid":
+		return "uuid"
+	case "tinyint":
+		if strings.HasPrefix(col.ColumnType, "tinyint(1)") {
+			return "bool"
+		}
+		return "int"
+	case "smallint", "integer", "int", "mediumint":
+		return "int"
+	case "bigint":
+		return "bigint"
+	case "real", "double precision", "double", "float":
+		return "float"
+	case "numeric", "decimal":
+		return "decimal"
+	case "boolean", "bool":
+		return "bool"
+	case "timestamp with time zone", "timestamp without time zone", "timestamp", "datetime":
+		return "time"
+	case "date":
+		return "date"
+	case "json", "jsonb":
+		return "json"
+	}
+	return ""
+}
+
+func integerColumn(col Column) bool {
+	switch columnFieldType(col) {
+	case "int", "bigint":
+		return true
+	}
+	return false
+}
+
+/*
+ * literalDefault returns a column default as a plain value: quoted
+ * strings are unquoted and PostgreSQL casts such as 'x'::text dropped.
+ * It reports false for defaults computed by an expression.
+ */
+func literalDefault(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+
+	if strings.HasPrefix(value, "'") {
+		var sb strings.Builder
+		for i := 1; i < len(value); i++ {
+			if value[i] != '\'' {
+				sb.WriteByte(value[i])
+				continue
+			}
+			if i+1 < len(value) && value[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i++
+				continue
+			}
+			rest := value[i+1:]
+			if rest == "" || strings.HasPrefix(rest, "::") {
+				return sb.String(), true
+			}
+			return "", false
+		}
+		return "", false
+	}
+
+	if cast := strings.Index(value, "::"); cast >= 0 {
+		value = value[:cast]
+	}
+	if number := strings.TrimSuffix(strings.TrimPrefix(value, "("), ")"); number != "" {
+		if _, err := strconv.ParseFloat(number, 64); err == nil {
+			return number, true
+		}
+	}
+	switch strings.ToLower(value) {
+	case "true", "false":
+		return strings.ToLower(value), true
+	}
+
+	/* MySQL 8 reports string defaults without quotes */
+	if value != "" && !strings.ContainsAny(value, "()") && !strings.HasPrefix(strings.ToUpper(value), "CURRENT_") {
+		return value, true
+	}
+	return "", false
+}
+
+/*
+ * validIdentifier reports whether name is a lower-case SQL identifier
+ * of letters, digits and underscores, which SQL needs no quotes for
+ * unless it is a reserved word. Tables are quoted by quoteIdent, and
+ * columns are checked by checkColumnName.
+ */
+func validIdentifier(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
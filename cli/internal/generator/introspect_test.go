@@ -0,0 +1,243 @@
+package generator
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		driver string
+		name   string
+		want   string
+	}{
+		{"postgres", "orders", "orders"},
+		{"postgres", "user", `"user"`},
+		{"postgres", "Customers", `"Customers"`},
+		{"postgres", "sales.orders", "sales.orders"},
+		{"postgres", "Sales.order", `"Sales"."order"`},
+		{"postgres", `odd"name`, `"odd""name"`},
+		{"mysql", "orders", "orders"},
+		{"mysql", "order", "`order`"},
+		{"mysql", "Customers", "`Customers`"},
+		{"mysql", "sales.line items", "sales.`line items`"},
+		{"sqlite", "Customers", `"Customers"`},
+	}
+	for _, tt := range tests {
+		if got := quoteIdent(tt.driver, tt.name); got != tt.want {
+			t.Errorf("quoteIdent(%q, %q) = %s, want %s", tt.driver, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIntrospectTableName(t *testing.T) {
+	tests := []struct {
+		table string
+		valid bool
+	}{
+		{"orders", true},
+		{"Customers", true},
+		{"sales.orders", true},
+		{"sales.Order Items", true},
+		{"", false},
+		{".orders", false},
+		{"sales.", false},
+		{"db.sales.orders", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			/* SQLite is refused after the name is checked, without a connection */
+			_, err := IntrospectTable(nil, "sqlite", tt.table)
+			if invalid := err != nil && strings.HasPrefix(err.Error(), "invalid table name"); invalid == tt.valid {
+				t.Errorf("IntrospectTable(%q) error = %v, want valid %v", tt.table, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestTableResourceName(t *testing.T) {
+	for table, want := range map[string]string{
+		"orders":            "order",
+		"categories":        "category",
+		"Customers":         "customer",
+		"CUSTOMERS":         "customer",
+		"sales.order_items": "order_item",
+		"sales.OrderItems":  "order_item",
+	} {
+		if got := (&Table{Name: table}).ResourceName(); got != want {
+			t.Errorf("ResourceName of %s = %q, want %q", table, got, want)
+		}
+	}
+}
+
+func TestTableFields(t *testing.T) {
+	id := Column{Name: "id", DataType: "integer", ColumnType: "int4", Primary: true, Unique: true, Indexed: true}
+	timestamps := []Column{
+		{Name: "created_at", DataType: "timestamp with time zone", ColumnType: "timestamptz", HasDefault: true, Default: "now()"},
+		{Name: "updated_at", DataType: "timestamp with time zone", ColumnType: "timestamptz", HasDefault: true, Default: "now()"},
+	}
+
+	tests := []struct {
+		name     string
+		columns  []Column
+		fields   []Field
+		warnings []string
+		err      string
+	}{
+		{
+			name: "postgres columns",
+			columns: append([]Column{
+				id,
+				{Name: "name", DataType: "character varying", ColumnType: "varchar", Length: 120},
+				{Name: "status", DataType: "character varying", ColumnType: "varchar", Length: 20, HasDefault: true, Default: "'draft'::character varying"},
+				{Name: "price", DataType: "numeric", ColumnType: "numeric", HasDefault: true, Default: "0"},
+				{Name: "sku", DataType: "text", ColumnType: "text", Nullable: true, Unique: true, Indexed: true},
+				{Name: "category_id", DataType: "bigint", ColumnType: "int8", References: "categories.id"},
+				{Name: "code", DataType: "uuid", ColumnType: "uuid", HasDefault: true, Default: "gen_random_uuid()"},
+				{Name: "labels", DataType: "array", ColumnType: "_text", Nullable: true},
+			}, timestamps...),
+			fields: []Field{
+				{Name: "name", Type: "string", Max: "120", Required: true},
+				{Name: "status", Type: "string", Max: "20", Default: "draft"},
+				{Name: "price", Type: "decimal", Default: "0"},
+				{Name: "sku", Type: "text", Nullable: true, Unique: true},
+				{Name: "category_id", Type: "bigint", Index: true},
+				{Name: "code", Type: "uuid"},
+				{Name: "labels", Type: "string", Nullable: true},
+			},
+			warnings: []string{
+				"column code: default gen_random_uuid() is computed by the database and is not carried into the generated code",
+				"column labels: type _text is not supported; generated as a string",
+			},
+		},
+		{
+			name: "mysql columns without timestamps",
+			columns: []Column{
+				{Name: "id", DataType: "int", ColumnType: "int unsigned", Primary: true, Unique: true, Indexed: true},
+				{Name: "active", DataType: "tinyint", ColumnType: "tinyint(1)", HasDefault: true, Default: "1"},
+				{Name: "stock", DataType: "tinyint", ColumnType: "tinyint(4)", Indexed: true},
+				{Name: "notes", DataType: "mediumtext", ColumnType: "mediumtext", Nullable: true},
+				{Name: "created_at", DataType: "datetime", ColumnType: "datetime", HasDefault: true, Default: "(now())"},
+			},
+			fields: []Field{
+				{Name: "active", Type: "bool", Default: "true"},
+				{Name: "stock", Type: "int", Index: true},
+				{Name: "notes", Type: "text", Nullable: true},
+				{Name: "created_at", Type: "time"},
+			},
+			warnings: []string{
+				"column created_at: default (now()) is computed by the database and is not carried into the generated code",
+			},
+		},
+		{
+			name:    "no id",
+			columns: []Column{{Name: "code", DataType: "integer", Primary: true}},
+			err:     `table "products" needs an integer primary key named id`,
+		},
+		{
+			name:    "text id",
+			columns: []Column{{Name: "id", DataType: "uuid", Primary: true}},
+			err:     `table "products" needs an integer primary key named id`,
+		},
+		{
+			name:    "mixed-case column",
+			columns: []Column{id, {Name: "UnitPrice", DataType: "numeric"}},
+			err:     `column "UnitPrice" cannot be used as a field name`,
+		},
+		{
+			name:    "reserved column",
+			columns: []Column{id, {Name: "order", DataType: "integer"}},
+			err:     `column name "order" is reserved`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, warnings, err := (&Table{Name: "products", Columns: tt.columns}).Fields()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Fields error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("fields =\n%+v\nwant\n%+v", fields, tt.fields)
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("warnings =\n%q\nwant\n%q", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestFromTableRelations(t *testing.T) {
+	tests := []struct {
+		driver string
+		table  string
+		join   string
+		query  string // the FindTags query
+		key    string // the join table's foreign key to the resource's table
+	}{
+		{"postgres", "Customers", "customers_tag",
+			"SELECT t.id, t.label FROM tag t JOIN customers_tag j ON j.tag_id = t.id WHERE j.customers_id = $1 ORDER BY t.id",
+			`FOREIGN KEY (customers_id) REFERENCES "Customers"(id)`},
+		{"postgres", "sales.orders", "orders_tag",
+			"SELECT t.id, t.label FROM tag t JOIN orders_tag j ON j.tag_id = t.id WHERE j.orders_id = $1 ORDER BY t.id",
+			"FOREIGN KEY (orders_id) REFERENCES sales.orders(id)"},
+		{"mysql", "sales.Orders", "orders_tag",
+			"SELECT t.id, t.label FROM tag t JOIN orders_tag j ON j.tag_id = t.id WHERE j.orders_id = ? ORDER BY t.id",
+			"FOREIGN KEY (orders_id) REFERENCES sales.`Orders`(id)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver+" "+tt.table, func(t *testing.T) {
+			paths := relationPaths(t)
+			table := &Table{Name: tt.table}
+			relations, err := ParseRelations(nil, nil, []string{"tags"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gen := NewCRUDGenerator(table.ResourceName())
+			gen.SetTable(table.Name)
+			gen.SetDriver(tt.driver)
+			gen.SetPaths(paths)
+			gen.SetRelations(relations)
+			if err := gen.CheckRelations(); err != nil {
+				t.Fatal(err)
+			}
+			if err := gen.GenerateJoinMigrations(); err != nil {
+				t.Fatal(err)
+			}
+			if err := gen.apiGenerator().GenerateRepository(); err != nil {
+				t.Fatal(err)
+			}
+
+			migrations, _ := filepath.Glob(filepath.Join(paths.Migrations, "*_create_"+tt.join+"_table.sql"))
+			if len(migrations) != 1 {
+				t.Fatalf("no migration creates %s", tt.join)
+			}
+			if migration := readFile(t, migrations[0]); !strings.Contains(migration, tt.key) {
+				t.Errorf("join migration has no %s:\n%s", tt.key, migration)
+			}
+
+			repository := readFile(t, filepath.Join(paths.Repository, gen.name+"_repository.go"))
+			if !strings.Contains(repository, `"`+tt.query+`"`) {
+				t.Errorf("repository has no query %s:\n%s", tt.query, repository)
+			}
+			if from := "FROM " + gostrIdent(tt.driver, tt.table); !strings.Contains(repository, from) {
+				t.Errorf("repository does not read %s", from)
+			}
+		})
+	}
+}
+
+/*
+ * gostrIdent returns a quoted table name as it appears in a Go string.
+ */
+func gostrIdent(driver, table string) string {
+	return strings.ReplaceAll(quoteIdent(driver, table), `"`, `\"`)
+}
@@ -0,0 +1,381 @@
+/*
+ * GoAstra CLI - Generation Manifest
+ *
+ * Records the files generators write so they can be regenerated safely.
+ * Each entry keeps the hash of the generated content and a copy of it is
+ * stored beside the manifest; on the next run a file whose content no
+ * longer matches was edited by hand, and the new output is merged into
+ * it three ways instead of overwriting it.
+ */
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/channdev/goastra/cli/internal/textdiff"
+)
+
+/*
+ * ManifestFile is the manifest written at the project root.
+ */
+const ManifestFile = ".goastra/manifest.json"
+
+/*
+ * ManifestEntry describes one generated file. The first input is the
+ * resource name. Shared files serve every resource of a generator and
+ * are never destroyed with one of them.
+ */
+type ManifestEntry struct {
+	File      string   `json:"file"`
+	Generator string   `json:"generator"`
+	Inputs    []string `json:"inputs"`
+	Hash      string   `json:"hash"`
+	Shared    bool     `json:"shared,omitempty"`
+}
+
+/*
+ * Manifest lists the generated files of a project. Copies of the
+ * generated content are kept under the generated directory next to it.
+ */
+type Manifest struct {
+	path  string
+	Files []ManifestEntry `json:"files"`
+}
+
+/*
+ * LoadManifest reads the manifest at path. A missing manifest is empty.
+ */
+func LoadManifest(path string) (*Manifest, error) {
+	m := &Manifest{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return m, nil
+}
+
+/*
+ * Save writes the manifest, sorted by file.
+ */
+func (m *Manifest) Save() error {
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].File < m.Files[j].File })
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, append(data, '\n'), 0644)
+}
+
+/*
+ * Entry returns the entry for file, or nil.
+ */
+func (m *Manifest) Entry(file string) *ManifestEntry {
+	file = filepath.ToSlash(filepath.Clean(file))
+	for i := range m.Files {
+		if m.Files[i].File == file {
+			return &m.Files[i]
+		}
+	}
+	return nil
+}
+
+/*
+ * Remove drops the entry for file and its stored copy.
+ */
+func (m *Manifest) Remove(file string) error {
+	file = filepath.ToSlash(filepath.Clean(file))
+	for i := range m.Files {
+		if m.Files[i].File == file {
+			m.Files = append(m.Files[:i], m.Files[i+1:]...)
+			break
+		}
+	}
+	path := m.basePath(file)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	/* Remove directories of the stored copies left empty */
+	root := filepath.Join(filepath.Dir(m.path), "generated")
+	for dir := filepath.Dir(path); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+/*
+ * record stores entry, replacing an earlier one for the same file, and
+ * keeps content as the base of the next merge.
+ */
+func (m *Manifest) record(entry ManifestEntry, content string) error {
+	path := m.basePath(entry.File)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	if existing := m.Entry(entry.File); existing != nil {
+		*existing = entry
+		return nil
+	}
+	m.Files = append(m.Files, entry)
+	return nil
+}
+
+/*
+ * base returns the content last generated for the entry's file, if the
+ * stored copy is still intact.
+ */
+func (m *Manifest) base(entry *ManifestEntry) (string, bool) {
+	data, err := os.ReadFile(m.basePath(entry.File))
+	if err != nil || contentHash(string(data)) != entry.Hash {
+		return "", false
+	}
+	return string(data), true
+}
+
+func (m *Manifest) basePath(file string) string {
+	return filepath.Join(filepath.Dir(m.path), "generated", filepath.FromSlash(file))
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+/*
+ * Action is what a write did, or would do, to a file.
+ */
+type Action string
+
+const (
+	Created     Action = "create"
+	Updated     Action = "update"
+	Unchanged   Action = "identical"
+	Merged      Action = "merge"
+	Conflicted  Action = "conflict"
+	Overwritten Action = "force"
+)
+
+/*
+ * FileChange reports the outcome of writing one file. Diff is the
+ * unified diff from the file on disk to the content written.
+ */
+type FileChange struct {
+	Path      string
+	Action    Action
+	Conflicts int
+	Diff      string
+	Shared    bool
+}
+
+/*
+ * Writer writes generated files through the manifest. Files that were
+ * edited since they were generated are merged with the new output; with
+ * force they are overwritten, and in a dry run nothing is written.
+ */
+type Writer struct {
+	manifest  *Manifest
+	generator string
+	inputs    []string
+	force     bool
+	dryRun    bool
+	changes   []FileChange
+}
+
+/*
+ * NewWriter creates a writer recording into manifest. A nil manifest
+ * writes files directly, overwriting them.
+ */
+func NewWriter(manifest *Manifest) *Writer {
+	return &Writer{manifest: manifest}
+}
+
+/*
+ * SetGenerator sets the generator name and inputs recorded for files.
+ */
+func (w *Writer) SetGenerator(name string, inputs []string) {
+	w.generator = name
+	w.inputs = inputs
+}
+
+/*
+ * entry returns the manifest entry of file, or nil when the file was
+ * not generated or the writer has no manifest.
+ */
+func (w *Writer) entry(file string) *ManifestEntry {
+	if w == nil || w.manifest == nil {
+		return nil
+	}
+	return w.manifest.Entry(file)
+}
+
+/*
+ * SetForce overwrites edited files instead of merging.
+ */
+func (w *Writer) SetForce(force bool) {
+	w.force = force
+}
+
+/*
+ * SetDryRun computes changes without writing anything.
+ */
+func (w *Writer) SetDryRun(dryRun bool) {
+	w.dryRun = dryRun
+}
+
+/*
+ * DryRun reports whether the writer leaves the disk untouched.
+ */
+func (w *Writer) DryRun() bool {
+	return w.dryRun
+}
+
+/*
+ * Changes returns the outcome of every write so far.
+ */
+func (w *Writer) Changes() []FileChange {
+	return w.changes
+}
+
+/*
+ * Conflicts returns the number of files written with conflict markers.
+ */
+func (w *Writer) Conflicts() int {
+	count := 0
+	for _, change := range w.changes {
+		if change.Action == Conflicted {
+			count++
+		}
+	}
+	return count
+}
+
+/*
+ * Save writes the manifest after a run. It does nothing in a dry run.
+ */
+func (w *Writer) Save() error {
+	if w.manifest == nil || w.dryRun {
+		return nil
+	}
+	return w.manifest.Save()
+}
+
+/*
+ * Write writes generated content to path. A file that differs from what
+ * was last generated there is merged with content; files the manifest
+ * does not know are merged using the lines both versions share as base.
+ */
+func (w *Writer) Write(path, content string) error {
+	return w.write(path, content, false)
+}
+
+/*
+ * WriteShared writes a file used by every resource of the generator.
+ */
+func (w *Writer) WriteShared(path, content string) error {
+	return w.write(path, content, true)
+}
+
+func (w *Writer) write(path, content string, shared bool) error {
+	path = filepath.Clean(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	exists := err == nil
+	current := string(data)
+
+	change := FileChange{Path: path, Action: Created, Shared: shared}
+	result := content
+	switch {
+	case !exists:
+	case current == content:
+		change.Action = Unchanged
+	case w.manifest == nil:
+		change.Action = Updated
+	case w.force:
+		change.Action = Overwritten
+	default:
+		change.Action = Updated
+		entry := w.manifest.Entry(path)
+		if entry == nil || contentHash(current) != entry.Hash {
+			base, ok := "", false
+			if entry != nil {
+				base, ok = w.manifest.base(entry)
+			}
+			if !ok {
+				base = textdiff.Common(current, content)
+			}
+			result, change.Conflicts = textdiff.Merge(base, current, content, "current", "generated")
+			change.Action = Merged
+			if change.Conflicts > 0 {
+				change.Action = Conflicted
+			}
+			if result == current {
+				change.Action = Unchanged
+			}
+		}
+	}
+	change.Diff = textdiff.Unified("a/"+filepath.ToSlash(path), "b/"+filepath.ToSlash(path), current, result)
+	w.changes = append(w.changes, change)
+
+	if w.dryRun {
+		return nil
+	}
+	if change.Action != Unchanged {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(result), 0644); err != nil {
+			return err
+		}
+	}
+	if w.manifest == nil {
+		return nil
+	}
+	return w.manifest.record(ManifestEntry{
+		File:      filepath.ToSlash(path),
+		Generator: w.generator,
+		Inputs:    w.inputs,
+		Hash:      contentHash(content),
+		Shared:    shared,
+	}, content)
+}
+
+/*
+ * WriteGo writes Go source, formatted with gofmt when it parses.
+ */
+func (w *Writer) WriteGo(path, content string) error {
+	return w.Write(path, formatGo(content))
+}
+
+/*
+ * WriteSharedGo writes shared Go source, formatted with gofmt.
+ */
+func (w *Writer) WriteSharedGo(path, content string) error {
+	return w.WriteShared(path, formatGo(content))
+}
@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriterWrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		generated string // content of the previous run, recorded in the manifest
+		current   string // content on disk before the write
+		content   string // content of this run
+		force     bool
+		action    Action
+		want      string
+		conflicts int
+	}{
+		{
+			name:    "new file",
+			content: "a\nb\n",
+			action:  Created,
+			want:    "a\nb\n",
+		},
+		{
+			name:      "identical",
+			generated: "a\nb\n",
+			current:   "a\nb\n",
+			content:   "a\nb\n",
+			action:    Unchanged,
+			want:      "a\nb\n",
+		},
+		{
+			name:      "untouched file",
+			generated: "a\nb\n",
+			current:   "a\nb\n",
+			content:   "a\nB\n",
+			action:    Updated,
+			want:      "a\nB\n",
+		},
+		{
+			name:      "local edit merged",
+			generated: "a\nb\nc\nd\ne\n",
+			current:   "local\nb\nc\nd\ne\n",
+			content:   "a\nb\nc\nd\nE\n",
+			action:    Merged,
+			want:      "local\nb\nc\nd\nE\n",
+		},
+		{
+			name:      "local edit conflicts",
+			generated: "a\nb\nc\n",
+			current:   "a\nlocal\nc\n",
+			content:   "a\nnew\nc\n",
+			action:    Conflicted,
+			want:      "a\n<<<<<<< current\nlocal\n=======\nnew\n>>>>>>> generated\nc\n",
+			conflicts: 1,
+		},
+		{
+			name:      "local edit kept",
+			generated: "a\nb\n",
+			current:   "a\nlocal\n",
+			content:   "a\nb\n",
+			action:    Unchanged,
+			want:      "a\nlocal\n",
+		},
+		{
+			name:      "force",
+			generated: "a\nb\n",
+			current:   "a\nlocal\n",
+			content:   "a\nnew\n",
+			force:     true,
+			action:    Overwritten,
+			want:      "a\nnew\n",
+		},
+		{
+			name:    "no manifest base, additions merged",
+			current: "a\nb\n",
+			content: "a\nb\nc\n",
+			action:  Merged,
+			want:    "a\nb\nc\n",
+		},
+		{
+			name:      "no manifest base, differences conflict",
+			current:   "a\nlocal\nc\n",
+			content:   "a\nnew\nc\n",
+			action:    Conflicted,
+			want:      "a\n<<<<<<< current\nlocal\n=======\nnew\n>>>>>>> generated\nc\n",
+			conflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app", "file.go")
+			manifest, err := LoadManifest(filepath.Join(dir, ManifestFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.generated != "" {
+				previous := NewWriter(manifest)
+				if err := previous.Write(path, tt.generated); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.current != "" {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(tt.current), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w := NewWriter(manifest)
+			w.SetForce(tt.force)
+			if err := w.Write(path, tt.content); err != nil {
+				t.Fatal(err)
+			}
+
+			change := w.Changes()[0]
+			if change.Action != tt.action || change.Conflicts != tt.conflicts {
+				t.Errorf("action = %s with %d conflicts, want %s with %d", change.Action, change.Conflicts, tt.action, tt.conflicts)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("file = %q, want %q", data, tt.want)
+			}
+
+			/* The new output is the base of the next merge */
+			entry := manifest.Entry(path)
+			if entry == nil {
+				t.Fatal("no manifest entry")
+			}
+			if base, ok := manifest.base(entry); !ok || base != tt.content {
+				t.Errorf("stored base = %q, %v, want %q", base, ok, tt.content)
+			}
+		})
+	}
+}
+
+func TestWriterDryRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.go")
+	manifest, err := LoadManifest(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWriter(manifest)
+	w.SetDryRun(true)
+	if err := w.Write(path, "a\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if change := w.Changes()[0]; change.Action != Created {
+		t.Errorf("action = %s, want %s", change.Action, Created)
+	}
+	for _, file := range []string{path, filepath.Join(dir, ManifestFile)} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("%s was written in a dry run", file)
+		}
+	}
+}
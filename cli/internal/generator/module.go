@@ -7,9 +7,8 @@
 package generator
 
 import (
-	"fmt"
-	"os"
 	"path/filepath"
+	"strings"
 )
 
 /*
@@ -19,6 +18,13 @@ type ModuleGenerator struct {
 	name       string
 	pascalName string
 	camelName  string
+	fields     []Field
+	relations  []Relation
+	timestamps bool
+	features   string
+	templates  string
+	api        string
+	out        *Writer
 }
 
 /*
@@ -29,253 +35,197 @@ func NewModuleGenerator(name string) *ModuleGenerator {
 		name:       name,
 		pascalName: toPascalCase(name),
 		camelName:  toCamelCase(name),
+		timestamps: true,
+		features:   DefaultPaths().Features,
+		api:        DefaultStack().API,
+		out:        NewWriter(nil),
 	}
 }
 
 /*
- * GenerateModule creates the Angular module file.
+ * SetWriter sets the writer generated files go through.
  */
-func (g *ModuleGenerator) GenerateModule() error {
-	dir := filepath.Join("web/src/app/features", g.name)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
+func (g *ModuleGenerator) SetWriter(out *Writer) {
+	g.out = out
+}
 
-	content := fmt.Sprintf(`/*
- * %s Module
- *
- * Feature module for %s functionality.
- * Configured for lazy loading.
+/*
+ * SetFields sets the resource fields used for the service interfaces.
  */
-import { NgModule } from '@angular/core';
-import { CommonModule } from '@angular/common';
-import { ReactiveFormsModule } from '@angular/forms';
+func (g *ModuleGenerator) SetFields(fields []Field) {
+	g.fields = fields
+}
 
-import { %sRoutingModule } from './%s-routing.module';
-import { %sComponent } from './%s.component';
+/*
+ * SetRelations sets the related resources added to the model and DTO
+ * interfaces.
+ */
+func (g *ModuleGenerator) SetRelations(relations []Relation) {
+	g.relations = relations
+}
 
-@NgModule({
-  declarations: [],
-  imports: [
-    CommonModule,
-    ReactiveFormsModule,
-    %sRoutingModule,
-    %sComponent
-  ]
-})
-export class %sModule { }
-`,
-		g.pascalName, g.name,
-		g.pascalName, g.name,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-	)
+/*
+ * SetTimestamps sets whether the model interfaces and views have the
+ * created_at and updated_at members.
+ */
+func (g *ModuleGenerator) SetTimestamps(timestamps bool) {
+	g.timestamps = timestamps
+}
 
-	path := filepath.Join(dir, g.name+".module.ts")
-	return os.WriteFile(path, []byte(content), 0644)
+/*
+ * SetFeaturesPath sets the directory feature modules are written to.
+ */
+func (g *ModuleGenerator) SetFeaturesPath(dir string) {
+	g.features = dir
 }
 
 /*
- * GenerateRouting creates the routing module file.
+ * SetTemplatesPath sets the directory of the project's template
+ * overrides.
  */
-func (g *ModuleGenerator) GenerateRouting() error {
-	dir := filepath.Join("web/src/app/features", g.name)
+func (g *ModuleGenerator) SetTemplatesPath(dir string) {
+	g.templates = dir
+}
 
-	content := fmt.Sprintf(`/*
- * %s Routing Module
- *
- * Route configuration for %s feature.
+/*
+ * SetAPI sets the project's API style (rest, graphql or trpc), which
+ * decides the client the feature service calls the backend with.
  */
-import { NgModule } from '@angular/core';
-import { RouterModule, Routes } from '@angular/router';
+func (g *ModuleGenerator) SetAPI(api string) {
+	g.api = api
+}
 
-import { %sComponent } from './%s.component';
+/*
+ * render renders a template with the resource's names, fields and relations.
+ */
+func (g *ModuleGenerator) render(name string, data templateData) (string, error) {
+	return renderTemplate(g.templates, name, templateData{
+		"Name":       g.name,
+		"Pascal":     g.pascalName,
+		"Camel":      g.camelName,
+		"Snake":      toSnakeCase(g.name),
+		"Plural":     toPlural(g.name),
+		"Fields":     g.fields,
+		"Relations":  g.relations,
+		"Timestamps": g.timestamps,
+		"API":        g.api,
+	}.with(data))
+}
 
-const routes: Routes = [
-  {
-    path: '',
-    component: %sComponent,
-    children: [
-      {
-        path: '',
-        redirectTo: 'list',
-        pathMatch: 'full'
-      },
-      {
-        path: 'list',
-        loadComponent: () => import('./components/%s-list/%s-list.component')
-          .then(m => m.%sListComponent)
-      },
-      {
-        path: ':id',
-        loadComponent: () => import('./components/%s-detail/%s-detail.component')
-          .then(m => m.%sDetailComponent)
-      },
-      {
-        path: 'create',
-        loadComponent: () => import('./components/%s-form/%s-form.component')
-          .then(m => m.%sFormComponent)
-      },
-      {
-        path: ':id/edit',
-        loadComponent: () => import('./components/%s-form/%s-form.component')
-          .then(m => m.%sFormComponent)
-      }
-    ]
-  }
-];
+/*
+ * GenerateModule creates the Angular module file.
+ */
+func (g *ModuleGenerator) GenerateModule() error {
+	dir := filepath.Join(g.features, g.name)
+	content, err := g.render("module/module.ts.tmpl", nil)
+	if err != nil {
+		return err
+	}
 
-@NgModule({
-  imports: [RouterModule.forChild(routes)],
-  exports: [RouterModule]
-})
-export class %sRoutingModule { }
-`,
-		g.pascalName, g.name,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.name, g.name,
-		g.pascalName,
-		g.name, g.name,
-		g.pascalName,
-		g.name, g.name,
-		g.pascalName,
-		g.name, g.name,
-		g.pascalName,
-		g.pascalName,
-	)
+	path := filepath.Join(dir, g.name+".module.ts")
+	return g.out.Write(path, content)
+}
+
+/*
+ * GenerateRouting creates the routing module file.
+ */
+func (g *ModuleGenerator) GenerateRouting() error {
+	dir := filepath.Join(g.features, g.name)
+
+	content, err := g.render("module/routing.module.ts.tmpl", nil)
+	if err != nil {
+		return err
+	}
 
 	path := filepath.Join(dir, g.name+"-routing.module.ts")
-	return os.WriteFile(path, []byte(content), 0644)
+	return g.out.Write(path, content)
 }
 
 /*
  * GenerateComponent creates the main feature component.
  */
 func (g *ModuleGenerator) GenerateComponent() error {
-	dir := filepath.Join("web/src/app/features", g.name)
+	dir := filepath.Join(g.features, g.name)
 
-	content := fmt.Sprintf(`/*
- * %s Component
- *
- * Container component for %s feature.
- */
-import { Component } from '@angular/core';
-import { RouterOutlet } from '@angular/router';
-
-@Component({
-  selector: 'app-%s',
-  standalone: true,
-  imports: [RouterOutlet],
-  template: ` + "`" + `
-    <div class="%s-container">
-      <router-outlet></router-outlet>
-    </div>
-  ` + "`" + `,
-  styles: [` + "`" + `
-    .%s-container {
-      padding: 1rem;
-    }
-  ` + "`" + `]
-})
-export class %sComponent { }
-`,
-		g.pascalName, g.name,
-		g.name,
-		g.name,
-		g.name,
-		g.pascalName,
-	)
+	content, err := g.render("module/component.ts.tmpl", nil)
+	if err != nil {
+		return err
+	}
 
 	path := filepath.Join(dir, g.name+".component.ts")
-	return os.WriteFile(path, []byte(content), 0644)
+	return g.out.Write(path, content)
 }
 
 /*
  * GenerateService creates the feature service.
  */
 func (g *ModuleGenerator) GenerateService() error {
-	dir := filepath.Join("web/src/app/features", g.name)
-
-	content := fmt.Sprintf(`/*
- * %s Service
- *
- * API service for %s operations.
- */
-import { Injectable } from '@angular/core';
-import { Observable } from 'rxjs';
-import { ApiService } from '@core/services/api.service';
-
-export interface %s {
-  id: number;
-  createdAt: string;
-  updatedAt: string;
-  /* TODO: Add model fields */
-}
+	switch g.api {
+	case "graphql":
+		return g.generateGraphQLService()
+	case "trpc":
+		return g.generateTRPCService()
+	}
 
-export interface %sPaginatedResponse {
-  data: %s[];
-  total: number;
-  page: number;
-  pageSize: number;
-  totalPages: number;
-}
+	dir := filepath.Join(g.features, g.name)
 
-export interface Create%sDto {
-  /* TODO: Add create fields */
-}
+	content, err := g.render("module/service.ts.tmpl", nil)
+	if err != nil {
+		return err
+	}
 
-export interface Update%sDto {
-  /* TODO: Add update fields */
+	path := filepath.Join(dir, g.name+".service.ts")
+	return g.out.Write(path, content)
 }
 
-@Injectable({
-  providedIn: 'root'
-})
-export class %sService {
-  private readonly basePath = '/%s';
-
-  constructor(private api: ApiService) {}
-
-  list(page = 1, pageSize = 10): Observable<%sPaginatedResponse> {
-    return this.api.get(this.basePath, {
-      page: page.toString(),
-      page_size: pageSize.toString()
-    });
-  }
-
-  getById(id: number): Observable<%s> {
-    return this.api.get(` + "`${this.basePath}/${id}`" + `);
-  }
+/*
+ * generateGraphQLService creates a feature service calling the queries
+ * and mutations of the schema `goastra generate graphql` writes.
+ */
+func (g *ModuleGenerator) generateGraphQLService() error {
+	dir := filepath.Join(g.features, g.name)
 
-  create(data: Create%sDto): Observable<%s> {
-    return this.api.post(this.basePath, data);
-  }
+	selection := []string{"id"}
+	for _, field := range g.fields {
+		selection = append(selection, field.Name)
+	}
+	if g.timestamps {
+		selection = append(selection, "createdAt", "updatedAt")
+	}
+	constant := strings.ToUpper(toSnakeCase(g.name)) + "_FIELDS"
 
-  update(id: number, data: Update%sDto): Observable<%s> {
-    return this.api.put(` + "`${this.basePath}/${id}`" + `, data);
-  }
+	content, err := g.render("module/service_graphql.ts.tmpl", templateData{
+		"Constant":  constant,
+		"Selection": strings.Join(selection, " "),
+	})
+	if err != nil {
+		return err
+	}
 
-  delete(id: number): Observable<void> {
-    return this.api.delete(` + "`${this.basePath}/${id}`" + `);
-  }
+	path := filepath.Join(dir, g.name+".service.ts")
+	return g.out.Write(path, content)
 }
-`,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.pascalName, g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		toPlural(g.name),
-		g.pascalName,
-		g.pascalName,
-		g.pascalName, g.pascalName,
-		g.pascalName, g.pascalName,
-	)
+
+/*
+ * generateTRPCService creates a feature service calling the Connect
+ * client buf generates from the proto `goastra generate trpc` writes,
+ * mapping its messages to the service's interfaces.
+ */
+func (g *ModuleGenerator) generateTRPCService() error {
+	dir := filepath.Join(g.features, g.name)
+
+	snake := toSnakeCase(g.name)
+	message := Field{Name: snake}.protoTSName()
+	list := Field{Name: snake + "s"}.protoTSName()
+
+	content, err := g.render("module/service_trpc.ts.tmpl", templateData{
+		"List":    list,
+		"Message": message,
+	})
+	if err != nil {
+		return err
+	}
 
 	path := filepath.Join(dir, g.name+".service.ts")
-	return os.WriteFile(path, []byte(content), 0644)
+	return g.out.Write(path, content)
 }
@@ -0,0 +1,149 @@
+/*
+ * GoAstra CLI - Generator Paths
+ *
+ * Locates the backend packages and frontend features generators write
+ * to, and the Go module the backend packages are imported from.
+ */
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+/*
+ * Paths holds generator output directories, relative to the project
+ * root. Root is the directory of the backend's go.mod and Module the
+ * module path it declares. Graph, RPC, Proto and Ent are only used by
+ * GraphQL, tRPC and Ent projects, and Validator only by REST ones.
+ * Templates is the directory of the project's template overrides.
+ */
+type Paths struct {
+	Module     string
+	Root       string
+	Handlers   string
+	Services   string
+	Repository string
+	Models     string
+	Router     string
+	Database   string
+	Validator  string
+	Migrations string
+	Features   string
+	Graph      string
+	RPC        string
+	Proto      string
+	Ent        string
+	Templates  string
+}
+
+/*
+ * DefaultPaths returns the layout of a project created by `goastra new`.
+ * The module path is left empty.
+ */
+func DefaultPaths() Paths {
+	return Paths{
+		Root:       "app",
+		Handlers:   "app/internal/handlers",
+		Services:   "app/internal/services",
+		Repository: "app/internal/repository",
+		Models:     "app/internal/models",
+		Router:     "app/internal/router",
+		Database:   "app/internal/database",
+		Validator:  "app/internal/validator",
+		Migrations: "app/migrations",
+		Features:   "web/src/app/features",
+		Graph:      "app/graph",
+		RPC:        "app/internal/rpc",
+		Proto:      "app/proto/v1",
+		Ent:        "app/ent",
+	}
+}
+
+/*
+ * ImportPath returns the import path of the package in dir.
+ */
+func (p Paths) ImportPath(dir string) string {
+	rel, err := filepath.Rel(p.Root, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = dir
+	}
+	return path.Join(p.Module, filepath.ToSlash(rel))
+}
+
+/*
+ * packageName returns the package clause name for the Go package in dir.
+ */
+func packageName(dir string) string {
+	return strings.NewReplacer("-", "", ".", "", " ", "").Replace(filepath.Base(dir))
+}
+
+/*
+ * FindModule returns the directory of the go.mod enclosing dir, which
+ * need not exist yet, and the module path it declares.
+ */
+func FindModule(dir string) (string, string, error) {
+	for current := filepath.Clean(dir); ; current = filepath.Dir(current) {
+		modulePath, err := readModulePath(filepath.Join(current, "go.mod"))
+		if err == nil {
+			return current, modulePath, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", "", err
+		}
+		if parent := filepath.Dir(current); parent == current {
+			return "", "", fmt.Errorf("no go.mod found for %s", dir)
+		}
+	}
+}
+
+func readModulePath(goMod string) (string, error) {
+	file, err := os.Open(goMod)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			if i := strings.Index(rest, "//"); i >= 0 {
+				rest = rest[:i]
+			}
+			return strings.Trim(strings.TrimSpace(rest), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s: no module directive", goMod)
+}
+
+/*
+ * BuildCheck runs `go vet` on the packages in dirs from the module
+ * root, which compiles their tests too, and returns the output when
+ * they do not compile or vet reports problems.
+ */
+func BuildCheck(p Paths, dirs ...string) error {
+	args := []string{"vet"}
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(p.Root, dir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("%s is outside the backend module %s", dir, p.Root)
+		}
+		args = append(args, "./"+filepath.ToSlash(rel))
+	}
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = p.Root
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go %s: %w\n%s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
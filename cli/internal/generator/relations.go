@@ -0,0 +1,469 @@
+/*
+ * GoAstra CLI - Resource Relations
+ *
+ * Relations declared with `goastra generate crud --belongs-to,
+ * --has-many and --many-to-many`, and the foreign keys, join tables,
+ * loaders, nested routes, Ent edges and form selectors they add.
+ */
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*
+ * Relation kinds.
+ */
+const (
+	BelongsTo  = "belongs_to"
+	HasMany    = "has_many"
+	ManyToMany = "many_to_many"
+)
+
+/*
+ * Relation relates the generated resource to another resource, named
+ * by Target in singular form, whose rows are in Table and are ordered
+ * by created_at unless Timestamps is false. Columns lists the columns
+ * of the target's model, which loaders select. A belongs_to
+ * relation adds a <target>_id foreign key; a has_many relation reads
+ * the target's <resource>_id key; a many_to_many relation uses a
+ * <resource table>_<target> join table, named after the resource
+ * table without its schema (see tableKey).
+ */
+type Relation struct {
+	Kind       string
+	Target     string
+	Table      string
+	Timestamps bool
+	Columns    []string
+}
+
+/*
+ * ParseRelations parses the resource names given to --belongs-to,
+ * --has-many and --many-to-many. Plural names are made singular.
+ */
+func ParseRelations(belongsTo, hasMany, manyToMany []string) ([]Relation, error) {
+	var relations []Relation
+	seen := make(map[string]bool)
+
+	for _, group := range []struct {
+		kind  string
+		names []string
+	}{{BelongsTo, belongsTo}, {HasMany, hasMany}, {ManyToMany, manyToMany}} {
+		for _, name := range group.names {
+			target := toSingular(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-"))
+			if target == "" || !validIdentifier(strings.ReplaceAll(target, "-", "_")) {
+				return nil, fmt.Errorf("invalid resource name %q in %s", name, relationFlag(group.kind))
+			}
+			if seen[target] {
+				return nil, fmt.Errorf("%s is related more than once", target)
+			}
+			seen[target] = true
+			relations = append(relations, Relation{Kind: group.kind, Target: target, Table: toSnakeCase(target), Timestamps: true})
+		}
+	}
+
+	return relations, nil
+}
+
+/*
+ * AddRelationFields returns fields with the foreign key of every
+ * belongs_to relation. A declared or introspected integer field of the
+ * same name becomes the key instead.
+ */
+func AddRelationFields(fields []Field, relations []Relation) ([]Field, error) {
+	for _, rel := range relations {
+		if rel.Kind != BelongsTo {
+			continue
+		}
+
+		key := rel.ForeignKey()
+		found := false
+		for i := range fields {
+			if fields[i].Name != key {
+				continue
+			}
+			if fields[i].Type != "int" && fields[i].Type != "bigint" || fields[i].Nullable {
+				return nil, fmt.Errorf("field %q must be a non-null integer to reference %s", key, rel.Target)
+			}
+			fields[i].References = rel.Target
+			found = true
+		}
+		if !found {
+			fields = append(fields, Field{Name: key, Type: "int", Required: true, Index: true, References: rel.Target})
+		}
+	}
+	return fields, nil
+}
+
+/*
+ * RelationInputs returns the command line arguments declaring the
+ * relations, as recorded in the manifest.
+ */
+func RelationInputs(relations []Relation) []string {
+	var args []string
+	for _, rel := range relations {
+		args = append(args, relationFlag(rel.Kind), rel.Target)
+	}
+	return args
+}
+
+/*
+ * joins returns the many_to_many relations, which are kept in join
+ * tables and written together with the resource.
+ */
+func joins(relations []Relation) []Relation {
+	var joined []Relation
+	for _, rel := range relations {
+		if rel.Kind == ManyToMany {
+			joined = append(joined, rel)
+		}
+	}
+	return joined
+}
+
+/*
+ * String describes the relation, e.g. "has many comments".
+ */
+func (r Relation) String() string {
+	switch r.Kind {
+	case BelongsTo:
+		return "belongs to " + r.Target
+	case HasMany:
+		return "has many " + r.Plural()
+	}
+	return "many to many " + r.Plural()
+}
+
+func relationFlag(kind string) string {
+	return "--" + strings.ReplaceAll(kind, "_", "-")
+}
+
+/*
+ * Pascal, Camel, Snake and Plural return the names of the related
+ * resource, which templates use alongside Member, ForeignKey and the
+ * other accessors below.
+ */
+func (r Relation) Pascal() string {
+	return toPascalCase(r.Target)
+}
+
+func (r Relation) Camel() string {
+	return toCamelCase(r.Target)
+}
+
+func (r Relation) Snake() string {
+	return toSnakeCase(r.Target)
+}
+
+func (r Relation) Plural() string {
+	return toPlural(r.Target)
+}
+
+/*
+ * Member returns the model field holding the loaded relation: User for
+ * belongs_to, Comments for has_many and many_to_many.
+ */
+func (r Relation) Member() string {
+	if r.Kind == BelongsTo {
+		return r.Pascal()
+	}
+	return toPascalCase(r.Plural())
+}
+
+/*
+ * JSONName returns the JSON name of the loaded relation.
+ */
+func (r Relation) JSONName() string {
+	if r.Kind == BelongsTo {
+		return r.Snake()
+	}
+	return toSnakeCase(r.Plural())
+}
+
+/*
+ * ForeignKey returns the key column of a belongs_to relation.
+ */
+func (r Relation) ForeignKey() string {
+	return r.Snake() + "_id"
+}
+
+/*
+ * IDsName returns the Go name of the ID list a many_to_many relation
+ * is set from, e.g. TagIDs, and IDsJSON its JSON name, e.g. tag_ids.
+ */
+func (r Relation) IDsName() string {
+	return r.Pascal() + "IDs"
+}
+
+func (r Relation) IDsJSON() string {
+	return r.Snake() + "_ids"
+}
+
+/*
+ * CheckRelations verifies that every related resource has been
+ * generated with a repository, and that has_many targets carry the
+ * foreign key back to this resource. It sets the table of each related
+ * resource, which may have been generated from an existing table,
+ * whether the resource's model has timestamps, and its columns.
+ */
+func (g *CRUDGenerator) CheckRelations() error {
+	for i, rel := range g.relations {
+		model := filepath.Join(g.paths.Models, rel.Target+".go")
+		if !fileExists(model) {
+			return fmt.Errorf("%s %s: the %s resource does not exist yet; generate it first", relationFlag(rel.Kind), rel.Target, rel.Target)
+		}
+		repository := filepath.Join(g.paths.Repository, rel.Target+"_repository.go")
+		entry := g.out.entry(repository)
+		if entry == nil && !fileExists(repository) {
+			return fmt.Errorf("%s %s: %s has no generated repository; generate it with goastra generate crud %s", relationFlag(rel.Kind), rel.Target, rel.Target, rel.Target)
+		}
+		if g.stack.ORM == "ent" && !fileExists(filepath.Join(g.paths.Ent, "schema", rel.Snake()+".go")) {
+			return fmt.Errorf("%s %s: %s has no Ent schema", relationFlag(rel.Kind), rel.Target, rel.Target)
+		}
+		data, err := os.ReadFile(model)
+		if err != nil {
+			return err
+		}
+		g.relations[i].Table = g.relatedTable(rel, entry)
+		g.relations[i].Timestamps = strings.Contains(string(data), `db:"created_at"`)
+		g.relations[i].Columns = modelColumns(string(data), rel.Pascal())
+		if rel.Kind != HasMany {
+			continue
+		}
+
+		key := toSnakeCase(g.name) + "_id"
+		if !strings.Contains(string(data), fmt.Sprintf(`db:"%s"`, key)) {
+			return fmt.Errorf("--has-many %s: %s has no %s column; generate it with --belongs-to %s", rel.Target, rel.Target, key, g.name)
+		}
+	}
+	return nil
+}
+
+/*
+ * relatedTable returns the table of a related resource: the one it was
+ * generated from with --from-table, as its manifest entry records, else
+ * the one its create migration makes, else its snake_case name.
+ */
+func (g *CRUDGenerator) relatedTable(rel Relation, entry *ManifestEntry) string {
+	if entry != nil {
+		for i, input := range entry.Inputs {
+			if input == "--from-table" && i+1 < len(entry.Inputs) {
+				return entry.Inputs[i+1]
+			}
+		}
+	}
+	for _, table := range []string{rel.Snake(), toSnakeCase(rel.Plural())} {
+		if matches, _ := filepath.Glob(filepath.Join(g.paths.Migrations, "*_create_"+table+"_table.sql")); len(matches) > 0 {
+			return table
+		}
+	}
+	return rel.Snake()
+}
+
+/*
+ * modelColumns returns the db columns of the model struct named name
+ * in the source of a generated model, in declaration order.
+ */
+func modelColumns(src, name string) []string {
+	model := regexp.MustCompile(`(?s)type ` + name + ` struct \{\n(.*?)\n\}`).FindStringSubmatch(src)
+	if model == nil {
+		return nil
+	}
+	var columns []string
+	for _, match := range regexp.MustCompile(`db:"(\w+)"`).FindAllStringSubmatch(model[1], -1) {
+		columns = append(columns, match[1])
+	}
+	return columns
+}
+
+/*
+ * relatedTables returns the tables of the related resources by name,
+ * which foreign key columns reference.
+ */
+func (g *CRUDGenerator) relatedTables() map[string]string {
+	tables := make(map[string]string, len(g.relations))
+	for _, rel := range g.relations {
+		tables[rel.Target] = rel.Table
+	}
+	return tables
+}
+
+/*
+ * GenerateJoinMigrations creates the join table migration of every
+ * many_to_many relation. Like GenerateMigration, an existing migration
+ * of the table is regenerated in place.
+ */
+func (g *CRUDGenerator) GenerateJoinMigrations() error {
+	owner := g.tableName()
+	for _, rel := range g.relations {
+		if rel.Kind != ManyToMany {
+			continue
+		}
+
+		table := tableKey(owner) + "_" + rel.Snake()
+		suffix := fmt.Sprintf("_create_%s_table.sql", table)
+
+		/* One second after the resource's own table, so it sorts after it */
+		created := time.Now().Add(time.Second)
+		filename := created.Format("20060102150405") + suffix
+		existing, _ := filepath.Glob(filepath.Join(g.paths.Migrations, "*"+suffix))
+		if len(existing) > 0 {
+			filename = filepath.Base(existing[0])
+			if t, err := time.ParseInLocation("20060102150405", strings.TrimSuffix(filename, suffix), time.Local); err == nil {
+				created = t
+			}
+		}
+
+		content, err := g.render("crud/join_migration.sql.tmpl", templateData{
+			"Table":      table,
+			"Generated":  created.Format(time.RFC3339),
+			"OwnerTable": owner,
+			"OwnerKey":   tableKey(owner) + "_id",
+			"Relation":   rel,
+			"Driver":     g.driver,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := g.out.Write(filepath.Join(g.paths.Migrations, filename), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+ * NestedRoutes returns the nested REST routes the relations add, for
+ * the command's summary.
+ */
+func (g *CRUDGenerator) NestedRoutes() []string {
+	var routes []string
+	for _, rel := range g.relations {
+		switch rel.Kind {
+		case BelongsTo:
+			routes = append(routes, fmt.Sprintf("GET /%s/:id/%s", toPlural(rel.Target), toPlural(g.name)))
+		case ManyToMany:
+			routes = append(routes,
+				fmt.Sprintf("GET /%s/:id/%s", toPlural(g.name), rel.Plural()),
+				fmt.Sprintf("PUT /%s/:id/%s", toPlural(g.name), rel.Plural()))
+		}
+	}
+	return routes
+}
+
+/*
+ * AddInverseEdges adds the inverse edge of every many_to_many relation,
+ * rendered from ent/inverse_edge.go.tmpl, to the target's Ent schema,
+ * which Ent requires to create the join table. Schemas that already
+ * have the edge are left alone.
+ */
+func (g *EntGenerator) AddInverseEdges() error {
+	for _, rel := range g.relations {
+		if rel.Kind != ManyToMany {
+			continue
+		}
+
+		path := filepath.Join(g.paths.Ent, "schema", rel.Snake()+".go")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s schema: %w", rel.Target, err)
+		}
+		src := string(data)
+
+		name := toSnakeCase(toPlural(g.name))
+		if strings.Contains(src, fmt.Sprintf("edge.From(%q", name)) {
+			continue
+		}
+		edge, err := g.render("ent/inverse_edge.go.tmpl", templateData{"Relation": rel})
+		if err != nil {
+			return err
+		}
+
+		edges := regexp.MustCompile(`func \(` + rel.Pascal() + `\) Edges\(\) \[\]ent\.Edge \{\n(\s*return nil\n|\s*return \[\]ent\.Edge\{\n)`)
+		loc := edges.FindStringSubmatchIndex(src)
+		if loc == nil {
+			return fmt.Errorf("cannot find the Edges method of the %s schema; add %s to it by hand", rel.Target, strings.TrimSpace(edge))
+		}
+		if strings.Contains(src[loc[2]:loc[3]], "return nil") {
+			src = src[:loc[2]] + "\treturn []ent.Edge{\n" + edge + "\t}\n" + src[loc[3]:]
+		} else {
+			src = src[:loc[3]] + edge + src[loc[3]:]
+		}
+		if !strings.Contains(src, `"entgo.io/ent/schema/edge"`) {
+			src = strings.Replace(src, "\t\"entgo.io/ent\"\n", "\t\"entgo.io/ent\"\n\t\"entgo.io/ent/schema/edge\"\n", 1)
+		}
+
+		if err := g.out.WriteSharedGo(path, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+ * selectors returns the relations the form picks related resources
+ * for: belongs_to keys and, on REST APIs, many_to_many lists.
+ */
+func (g *CRUDGenerator) selectors() []Relation {
+	var selectors []Relation
+	for _, rel := range g.relations {
+		if rel.Kind == BelongsTo || rel.Kind == ManyToMany && g.stack.API == "rest" {
+			selectors = append(selectors, rel)
+		}
+	}
+	return selectors
+}
+
+/*
+ * optionLabels returns the model member each selector shows, by target.
+ */
+func (g *CRUDGenerator) optionLabels() map[string]string {
+	labels := make(map[string]string)
+	for _, rel := range g.selectors() {
+		labels[rel.Target] = g.optionLabel(rel)
+	}
+	return labels
+}
+
+/*
+ * labelMembers are the model members preferred as option labels.
+ */
+var labelMembers = []string{"name", "title", "label", "username", "email", "slug", "code"}
+
+/*
+ * optionLabel returns the member of the target's model shown in the
+ * selector: a name-like member, else its first string member, else id.
+ */
+func (g *CRUDGenerator) optionLabel(rel Relation) string {
+	data, err := os.ReadFile(filepath.Join(g.paths.Features, rel.Target, rel.Target+".service.ts"))
+	if err != nil {
+		return "id"
+	}
+	model := regexp.MustCompile(`(?s)export interface ` + rel.Pascal() + ` \{\n(.*?)\n\}`).FindStringSubmatch(string(data))
+	if model == nil {
+		return "id"
+	}
+
+	var strs []string
+	for _, match := range regexp.MustCompile(`(?m)^\s+(\w+): string;$`).FindAllStringSubmatch(model[1], -1) {
+		if match[1] != "createdAt" && match[1] != "updatedAt" && !strings.HasSuffix(match[1], "_at") {
+			strs = append(strs, match[1])
+		}
+	}
+	for _, name := range labelMembers {
+		if containsImport(strs, name) {
+			return name
+		}
+	}
+	if len(strs) > 0 {
+		return strs[0]
+	}
+	return "id"
+}
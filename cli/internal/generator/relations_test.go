@@ -0,0 +1,245 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+/*
+ * relationPaths lays out a project in a temporary directory with user,
+ * comment and tag resources to relate a post to. The user table was
+ * created as users; tags have no timestamps.
+ */
+func relationPaths(t *testing.T) Paths {
+	t.Helper()
+	dir := t.TempDir()
+	paths := DefaultPaths()
+	paths.Module = "github.com/demo/app"
+	for _, p := range []*string{
+		&paths.Root, &paths.Handlers, &paths.Services, &paths.Repository, &paths.Models,
+		&paths.Router, &paths.Database, &paths.Validator, &paths.Migrations, &paths.Features,
+	} {
+		*p = filepath.Join(dir, *p)
+	}
+
+	files := map[string]string{
+		filepath.Join(paths.Models, "user.go"): "package models\n\ntype User struct {\n" +
+			"\tID        uint      `db:\"id\"`\n\tName      string    `db:\"name\"`\n\tCreatedAt time.Time `db:\"created_at\"`\n}\n",
+		filepath.Join(paths.Models, "comment.go"): "package models\n\ntype Comment struct {\n" +
+			"\tID        uint      `db:\"id\"`\n\tBody      string    `db:\"body\"`\n\tPostID    uint      `db:\"post_id\"`\n\tCreatedAt time.Time `db:\"created_at\"`\n}\n",
+		filepath.Join(paths.Models, "tag.go"): "package models\n\ntype Tag struct {\n" +
+			"\tID    uint   `db:\"id\"`\n\tLabel string `db:\"label\"`\n}\n",
+		filepath.Join(paths.Repository, "user_repository.go"):                    "package repository\n",
+		filepath.Join(paths.Repository, "comment_repository.go"):                 "package repository\n",
+		filepath.Join(paths.Repository, "tag_repository.go"):                     "package repository\n",
+		filepath.Join(paths.Migrations, "20240101000000_create_users_table.sql"): "",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+/*
+ * newRelationCRUD returns a generator for a post resource that belongs
+ * to a user, has many comments and many tags.
+ */
+func newRelationCRUD(t *testing.T, driver string, paths Paths) *CRUDGenerator {
+	t.Helper()
+	relations, err := ParseRelations([]string{"users"}, []string{"comments"}, []string{"tags"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := ParseFields([]string{"title:string:required"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields, err = AddRelationFields(fields, relations); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := NewCRUDGenerator("post")
+	gen.SetDriver(driver)
+	gen.SetFields(fields)
+	gen.SetPaths(paths)
+	gen.SetRelations(relations)
+	if err := gen.CheckRelations(); err != nil {
+		t.Fatal(err)
+	}
+	return gen
+}
+
+func TestParseRelations(t *testing.T) {
+	tests := []struct {
+		name       string
+		belongsTo  []string
+		hasMany    []string
+		manyToMany []string
+		relations  []Relation
+		err        string
+	}{
+		{
+			name:       "plural names",
+			belongsTo:  []string{"Users"},
+			hasMany:    []string{"comments"},
+			manyToMany: []string{"order_items"},
+			relations: []Relation{
+				{Kind: BelongsTo, Target: "user", Table: "user", Timestamps: true},
+				{Kind: HasMany, Target: "comment", Table: "comment", Timestamps: true},
+				{Kind: ManyToMany, Target: "order-item", Table: "order_item", Timestamps: true},
+			},
+		},
+		{name: "invalid name", hasMany: []string{"comment.s"}, err: `invalid resource name "comment.s" in --has-many`},
+		{name: "related twice", belongsTo: []string{"user"}, manyToMany: []string{"users"}, err: "user is related more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relations, err := ParseRelations(tt.belongsTo, tt.hasMany, tt.manyToMany)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("ParseRelations error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(relations, tt.relations) {
+				t.Errorf("ParseRelations =\n%+v\nwant\n%+v", relations, tt.relations)
+			}
+		})
+	}
+}
+
+func TestCheckRelations(t *testing.T) {
+	gen := newRelationCRUD(t, "postgres", relationPaths(t))
+
+	want := []Relation{
+		{Kind: BelongsTo, Target: "user", Table: "users", Timestamps: true, Columns: []string{"id", "name", "created_at"}},
+		{Kind: HasMany, Target: "comment", Table: "comment", Timestamps: true, Columns: []string{"id", "body", "post_id", "created_at"}},
+		{Kind: ManyToMany, Target: "tag", Table: "tag", Columns: []string{"id", "label"}},
+	}
+	if !reflect.DeepEqual(gen.relations, want) {
+		t.Errorf("relations =\n%+v\nwant\n%+v", gen.relations, want)
+	}
+
+	if user := gen.fields[len(gen.fields)-1]; user.Name != "user_id" || user.References != "user" || !user.Index {
+		t.Errorf("foreign key field = %+v, want an indexed user_id referencing user", user)
+	}
+}
+
+func TestJoinMigrationNaming(t *testing.T) {
+	tests := []struct {
+		resource string
+		table    string
+		join     string
+	}{
+		{resource: "post", join: "post_tag"},
+		{resource: "blog-post", join: "blog_post_tag"},
+		{resource: "post", table: "blog_posts", join: "blog_posts_tag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.join, func(t *testing.T) {
+			paths := relationPaths(t)
+			relations, err := ParseRelations(nil, nil, []string{"tags"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			gen := NewCRUDGenerator(tt.resource)
+			gen.SetTable(tt.table)
+			gen.SetPaths(paths)
+			gen.SetRelations(relations)
+			if err := gen.GenerateMigration(); err != nil {
+				t.Fatal(err)
+			}
+			if err := gen.GenerateJoinMigrations(); err != nil {
+				t.Fatal(err)
+			}
+
+			/* The join table's migration sorts after the table it references */
+			migrations := migrationFiles(t, paths.Migrations)
+			owner := "_create_" + gen.tableName() + "_table.sql"
+			if len(migrations) != 3 || !strings.HasSuffix(migrations[1], owner) || !strings.HasSuffix(migrations[2], "_create_"+tt.join+"_table.sql") {
+				t.Fatalf("migrations = %v, want the %s migration after the %s one", migrations, tt.join, gen.tableName())
+			}
+			content := readFile(t, filepath.Join(paths.Migrations, migrations[2]))
+			if !strings.Contains(content, "CREATE TABLE IF NOT EXISTS "+tt.join+" (") {
+				t.Errorf("join migration does not create %s:\n%s", tt.join, content)
+			}
+
+			/* Regenerating keeps the migration in place */
+			if err := gen.GenerateJoinMigrations(); err != nil {
+				t.Fatal(err)
+			}
+			if again := migrationFiles(t, paths.Migrations); !reflect.DeepEqual(again, migrations) {
+				t.Errorf("after regenerating, migrations = %v, want %v", again, migrations)
+			}
+		})
+	}
+}
+
+/*
+ * relationQuery matches the SQL string literals of a repository.
+ */
+var relationQuery = regexp.MustCompile(`"((?:SELECT|INSERT|DELETE) [^"]*)"`)
+
+func TestRelationSQL(t *testing.T) {
+	for _, driver := range []string{"postgres", "mysql"} {
+		t.Run(driver, func(t *testing.T) {
+			paths := relationPaths(t)
+			gen := newRelationCRUD(t, driver, paths)
+			if err := gen.GenerateMigration(); err != nil {
+				t.Fatal(err)
+			}
+			if err := gen.GenerateJoinMigrations(); err != nil {
+				t.Fatal(err)
+			}
+			if err := gen.apiGenerator().GenerateRepository(); err != nil {
+				t.Fatal(err)
+			}
+
+			/* Foreign key constraints of the post table and the post_tag join table */
+			migrations := migrationFiles(t, paths.Migrations)
+			for _, migration := range migrations[1:] {
+				table := strings.TrimSuffix(fileTimestamp.ReplaceAllString(migration, ""), "_table.sql")
+				content := generatedTime.ReplaceAllString(readFile(t, filepath.Join(paths.Migrations, migration)), "Generated: <time>")
+				golden(t, filepath.Join("testdata", "relations", table+"."+driver+".golden"), content)
+			}
+
+			/* Queries of the repository, among them the batched IN loaders */
+			var queries []string
+			for _, match := range relationQuery.FindAllStringSubmatch(readFile(t, filepath.Join(paths.Repository, "post_repository.go")), -1) {
+				queries = append(queries, match[1])
+			}
+			golden(t, filepath.Join("testdata", "relations", "queries."+driver+".golden"), strings.Join(queries, "\n")+"\n")
+		})
+	}
+}
+
+/*
+ * migrationFiles returns the names of the migrations in dir, in the
+ * order they run.
+ */
+func migrationFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,203 @@
+/*
+ * GoAstra CLI - Project Stack
+ *
+ * Identifies the API style, ORM and database a project was created
+ * with, so generated resources fit it: gin handlers, gqlgen resolvers
+ * or Connect services on top of sqlx or Ent repositories, with SQL for
+ * its database.
+ */
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+ * Stack is a project's API style (rest, graphql, trpc) and ORM
+ * (sqlx, ent).
+ */
+type Stack struct {
+	API string
+	ORM string
+}
+
+/*
+ * DefaultStack returns the stack of a project created by `goastra new`
+ * without options.
+ */
+func DefaultStack() Stack {
+	return Stack{API: "rest", ORM: "sqlx"}
+}
+
+/*
+ * DetectStack returns the project's stack. The API style and ORM set
+ * in goastra.json win; unset ones are read from the layout of the
+ * backend module at root: gqlgen.yml marks a GraphQL API, buf.gen.yaml
+ * a tRPC API and an ent/schema directory the Ent ORM.
+ */
+func DetectStack(apiType, orm, root string) (Stack, error) {
+	stack := Stack{API: apiType, ORM: orm}
+
+	if stack.API == "" {
+		switch {
+		case fileExists(filepath.Join(root, "gqlgen.yml")):
+			stack.API = "graphql"
+		case fileExists(filepath.Join(root, "buf.gen.yaml")):
+			stack.API = "trpc"
+		default:
+			stack.API = "rest"
+		}
+	}
+	if stack.ORM == "" {
+		stack.ORM = "sqlx"
+		if info, err := os.Stat(filepath.Join(root, "ent", "schema")); err == nil && info.IsDir() {
+			stack.ORM = "ent"
+		}
+	}
+
+	switch stack.API {
+	case "rest", "graphql", "trpc":
+	default:
+		return stack, fmt.Errorf("unknown api.type %q in goastra.json (use rest, graphql or trpc)", stack.API)
+	}
+	switch stack.ORM {
+	case "sqlx", "ent":
+	default:
+		return stack, fmt.Errorf("unknown database.orm %q in goastra.json (use sqlx or ent)", stack.ORM)
+	}
+	return stack, nil
+}
+
+/*
+ * String returns the stack as shown to users, e.g. "graphql + ent".
+ */
+func (s Stack) String() string {
+	return s.API + " + " + s.ORM
+}
+
+/*
+ * dbType returns the connection type of the project's database package
+ * for the ORM. It embeds the sqlx.DB or ent.Client repositories are
+ * built on, under the same name.
+ */
+func (s Stack) dbType() string {
+	if s.ORM == "ent" {
+		return "Client"
+	}
+	return "DB"
+}
+
+/*
+ * driverModules maps the database/sql driver modules a backend can
+ * require to the database they connect to.
+ */
+var driverModules = map[string]string{
+	"github.com/lib/pq":              "postgres",
+	"github.com/jackc/pgx/v4":        "postgres",
+	"github.com/jackc/pgx/v5":        "postgres",
+	"github.com/go-sql-driver/mysql": "mysql",
+	"github.com/mattn/go-sqlite3":    "sqlite",
+	"modernc.org/sqlite":             "sqlite",
+}
+
+/*
+ * DetectDriver returns the database generated SQL is written for. It is
+ * the driver set in goastra.json unless the backend module at root
+ * requires the database/sql driver of exactly one other database, which
+ * happens in projects whose goastra.json was written with the default.
+ * Without either it is postgres.
+ */
+func DetectDriver(driver, root string) (string, error) {
+	switch driver {
+	case "", "postgres", "mysql", "sqlite":
+	default:
+		return driver, fmt.Errorf("unknown database.driver %q in goastra.json (use postgres, mysql or sqlite)", driver)
+	}
+
+	used := requiredDrivers(filepath.Join(root, "go.mod"))
+	switch {
+	case len(used) == 1 && (driver == "" || !containsImport(used, driver)):
+		return used[0], nil
+	case driver == "":
+		return "postgres", nil
+	}
+	return driver, nil
+}
+
+/*
+ * requiredDrivers returns the databases whose drivers goMod requires.
+ */
+func requiredDrivers(goMod string) []string {
+	data, err := os.ReadFile(goMod)
+	if err != nil {
+		return nil
+	}
+
+	found := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		for _, word := range strings.Fields(line) {
+			if driver, ok := driverModules[word]; ok {
+				found[driver] = true
+			}
+		}
+	}
+
+	drivers := make([]string, 0, len(found))
+	for driver := range found {
+		drivers = append(drivers, driver)
+	}
+	sort.Strings(drivers)
+	return drivers
+}
+
+/*
+ * sqlDriver is a database/sql driver: the package registering it and
+ * the name it is opened by.
+ */
+type sqlDriver struct {
+	Module string
+	Import string
+	Name   string
+}
+
+/*
+ * sqlDrivers lists the drivers of each database, the default first.
+ */
+var sqlDrivers = map[string][]sqlDriver{
+	"postgres": {
+		{"github.com/lib/pq", "github.com/lib/pq", "postgres"},
+		{"github.com/jackc/pgx/v5", "github.com/jackc/pgx/v5/stdlib", "pgx"},
+		{"github.com/jackc/pgx/v4", "github.com/jackc/pgx/v4/stdlib", "pgx"},
+	},
+	"mysql": {
+		{"github.com/go-sql-driver/mysql", "github.com/go-sql-driver/mysql", "mysql"},
+	},
+	"sqlite": {
+		{"github.com/mattn/go-sqlite3", "github.com/mattn/go-sqlite3", "sqlite3"},
+		{"modernc.org/sqlite", "modernc.org/sqlite", "sqlite"},
+	},
+}
+
+/*
+ * projectSQLDriver returns the driver of database the backend module at
+ * root requires, or the database's default driver.
+ */
+func projectSQLDriver(root, database string) sqlDriver {
+	drivers := sqlDrivers[database]
+	if len(drivers) == 0 {
+		drivers = sqlDrivers["postgres"]
+	}
+
+	data, _ := os.ReadFile(filepath.Join(root, "go.mod"))
+	words := strings.Fields(string(data))
+	for _, driver := range drivers {
+		if containsImport(words, driver.Module) {
+			return driver
+		}
+	}
+	return drivers[0]
+}
@@ -0,0 +1,325 @@
+/*
+ * GoAstra CLI - Generator Templates
+ *
+ * Generated files are rendered from text/template files embedded in
+ * the CLI. A project overrides any of them by placing a file of the
+ * same name in its templates directory (codegen.templates), and
+ * `goastra generate eject` copies the defaults there to start from.
+ * Templates whose name starts with an underscore are partials: they
+ * hold {{define}} blocks shared by the other templates of their group.
+ */
+package generator
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+/*
+ * defaultTemplates holds the built-in templates, one directory per
+ * template group.
+ */
+//go:embed all:templates
+var defaultTemplates embed.FS
+
+/*
+ * templateGroups lists the template groups each generator renders,
+ * including those of the generators it runs.
+ */
+var templateGroups = map[string][]string{
+	"api":     {"api", "graphql", "trpc", "crud"},
+	"crud":    {"crud", "api", "module", "ent", "graphql", "trpc"},
+	"module":  {"module"},
+	"graphql": {"graphql"},
+	"trpc":    {"trpc"},
+	"ent":     {"ent"},
+}
+
+/*
+ * templateData is the data a template is executed with. Keys are
+ * exported-style names, e.g. {{.Pascal}}.
+ */
+type templateData map[string]any
+
+/*
+ * with returns a copy of d with the entries of extra added.
+ */
+func (d templateData) with(extra templateData) templateData {
+	merged := make(templateData, len(d)+len(extra))
+	for key, value := range d {
+		merged[key] = value
+	}
+	for key, value := range extra {
+		merged[key] = value
+	}
+	return merged
+}
+
+/*
+ * templateFuncs are the functions available to templates besides the
+ * text/template builtins. gostr and goraw escape SQL put in interpreted
+ * and raw Go string literals.
+ */
+var templateFuncs = template.FuncMap{
+	"pascal":      toPascalCase,
+	"camel":       toCamelCase,
+	"snake":       toSnakeCase,
+	"plural":      toPlural,
+	"lower":       strings.ToLower,
+	"upper":       strings.ToUpper,
+	"join":        strings.Join,
+	"replace":     func(s, old, new string) string { return strings.ReplaceAll(s, old, new) },
+	"add":         func(a, b int) int { return a + b },
+	"goname":      func(name string) string { return Field{Name: name}.GoName() },
+	"entname":     func(name string) string { return Field{Name: name}.generatedGoName() },
+	"protots":     func(name string) string { return Field{Name: name}.protoTSName() },
+	"placeholder": placeholder,
+	"now":         sqlNow,
+	"ident":       quoteIdent,
+	"gostr":       func(s string) string { q := strconv.Quote(s); return q[1 : len(q)-1] },
+	"goraw":       func(s string) string { return strings.ReplaceAll(s, "`", "` + \"`\" + `") },
+	"extend":      extendData,
+}
+
+/*
+ * extendData returns a copy of data with the key/value pairs added, so a
+ * {{define}} block can be given the template's data and the item being
+ * ranged over: {{include "block" (extend $ "Relation" .)}}.
+ */
+func extendData(data templateData, pairs ...any) (templateData, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("extend needs key/value pairs")
+	}
+	extra := make(templateData, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("extend: key %v is not a string", pairs[i])
+		}
+		extra[key] = pairs[i+1]
+	}
+	return data.with(extra), nil
+}
+
+/*
+ * renderTemplate executes the template name, e.g. "api/handler.go.tmpl",
+ * with data. A file of that name under dir replaces the built-in
+ * template; an empty dir uses the built-in templates only. The
+ * partials of the template's group are parsed first, so the template
+ * can use and redefine their blocks. Besides {{template}}, blocks can
+ * be executed by a computed name with include, e.g.
+ * {{include (printf "write.%s" .Driver) .}}.
+ */
+func renderTemplate(dir, name string, data templateData) (string, error) {
+	tmpl, origin, err := parseTemplate(dir, name)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", origin, err)
+	}
+	return sb.String(), nil
+}
+
+/*
+ * parseTemplate parses the template name with the partials of its
+ * group, and returns it with the file it was read from.
+ */
+func parseTemplate(dir, name string) (*template.Template, string, error) {
+	tmpl := template.New(name)
+	tmpl.Funcs(templateFuncs).Funcs(template.FuncMap{
+		"include": func(block string, data any) (string, error) {
+			var sb strings.Builder
+			err := tmpl.ExecuteTemplate(&sb, block, data)
+			return sb.String(), err
+		},
+	}).Option("missingkey=error")
+
+	partials, err := partialNames(path.Dir(name))
+	if err != nil {
+		return nil, "", err
+	}
+	for _, partial := range partials {
+		if partial == name {
+			continue
+		}
+		source, origin, err := loadTemplate(dir, partial)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := tmpl.New(partial).Parse(source); err != nil {
+			return nil, "", fmt.Errorf("failed to parse template %s: %w", origin, err)
+		}
+	}
+
+	source, origin, err := loadTemplate(dir, name)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := tmpl.Parse(source); err != nil {
+		return nil, "", fmt.Errorf("failed to parse template %s: %w", origin, err)
+	}
+	return tmpl, origin, nil
+}
+
+/*
+ * ValidateTemplates parses every template a generator renders, with the
+ * overrides under dir, so a broken override is reported before any file
+ * is written rather than part way through generation.
+ */
+func ValidateTemplates(dir, generator string) error {
+	if dir == "" {
+		return nil
+	}
+	names, err := TemplateNames(generator)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	reported := make(map[string]bool)
+	for _, name := range names {
+		if strings.HasPrefix(path.Base(name), "_") {
+			continue
+		}
+		if _, _, err := parseTemplate(dir, name); err != nil && !reported[err.Error()] {
+			reported[err.Error()] = true
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+/*
+ * partialNames returns the built-in partials of a template group.
+ */
+func partialNames(group string) ([]string, error) {
+	entries, err := defaultTemplates.ReadDir(path.Join("templates", group))
+	if err != nil {
+		return nil, fmt.Errorf("unknown template group %s: %w", group, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), "_") {
+			names = append(names, path.Join(group, entry.Name()))
+		}
+	}
+	return names, nil
+}
+
+/*
+ * loadTemplate returns the source of a template and where it was read
+ * from, preferring the project's override.
+ */
+func loadTemplate(dir, name string) (string, string, error) {
+	if dir != "" {
+		override := filepath.Join(dir, filepath.FromSlash(name))
+		data, err := os.ReadFile(override)
+		if err == nil {
+			return string(data), override, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", "", fmt.Errorf("failed to read template override: %w", err)
+		}
+	}
+
+	data, err := defaultTemplates.ReadFile(path.Join("templates", name))
+	if err != nil {
+		return "", "", fmt.Errorf("unknown template %s: %w", name, err)
+	}
+	return string(data), name, nil
+}
+
+/*
+ * TemplateGenerators returns the generators whose templates can be
+ * ejected.
+ */
+func TemplateGenerators() []string {
+	names := make([]string, 0, len(templateGroups))
+	for name := range templateGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+ * TemplateNames returns the built-in templates a generator renders,
+ * sorted.
+ */
+func TemplateNames(generator string) ([]string, error) {
+	groups, ok := templateGroups[generator]
+	if !ok {
+		return nil, fmt.Errorf("unknown generator %q (use %s)", generator, strings.Join(TemplateGenerators(), ", "))
+	}
+
+	var names []string
+	for _, group := range groups {
+		err := fs.WalkDir(defaultTemplates, path.Join("templates", group), func(p string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			names = append(names, strings.TrimPrefix(p, "templates/"))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+/*
+ * EjectResult reports a template copied out by EjectTemplates, or
+ * skipped because the project already overrides it.
+ */
+type EjectResult struct {
+	Path    string
+	Skipped bool
+}
+
+/*
+ * EjectTemplates copies the built-in templates of a generator into
+ * dir, where they override the defaults. Existing files are kept
+ * unless force is set.
+ */
+func EjectTemplates(generator, dir string, force bool) ([]EjectResult, error) {
+	names, err := TemplateNames(generator)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []EjectResult
+	for _, name := range names {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if fileExists(target) && !force {
+			results = append(results, EjectResult{Path: target, Skipped: true})
+			continue
+		}
+
+		data, err := defaultTemplates.ReadFile(path.Join("templates", name))
+		if err != nil {
+			return results, err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return results, fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return results, fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		results = append(results, EjectResult{Path: target})
+	}
+	return results, nil
+}
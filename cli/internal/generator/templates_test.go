@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateTemplates(t *testing.T) {
+	handler, err := defaultTemplates.ReadFile("templates/api/handler.go.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		generator string
+		overrides map[string]string
+		errors    []string // the template files the error names
+	}{
+		{"no overrides", "api", nil, nil},
+		{"valid override", "api", map[string]string{"api/handler.go.tmpl": string(handler)}, nil},
+		{
+			name:      "broken template",
+			generator: "api",
+			overrides: map[string]string{"api/routes.go.tmpl": "{{if .Pascal}}"},
+			errors:    []string{filepath.Join("api", "routes.go.tmpl")},
+		},
+		{
+			name:      "broken partial reported once",
+			generator: "crud",
+			overrides: map[string]string{"crud/_sql.tmpl": "{{define \"x\"}}"},
+			errors:    []string{filepath.Join("crud", "_sql.tmpl")},
+		},
+		{
+			name:      "every broken template",
+			generator: "crud",
+			overrides: map[string]string{"api/routes.go.tmpl": "{{end}}", "module/component.ts.tmpl": "{{.Pascal"},
+			errors:    []string{filepath.Join("api", "routes.go.tmpl"), filepath.Join("module", "component.ts.tmpl")},
+		},
+		{
+			name:      "template of another generator",
+			generator: "module",
+			overrides: map[string]string{"api/routes.go.tmpl": "{{if .Pascal}}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, source := range tt.overrides {
+				path := filepath.Join(dir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := ValidateTemplates(dir, tt.generator)
+			if len(tt.errors) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateTemplates: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("ValidateTemplates succeeded")
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tt.errors) {
+				t.Fatalf("errors:\n%v\nwant one per template in %v", err, tt.errors)
+			}
+			for _, name := range tt.errors {
+				if !strings.Contains(err.Error(), filepath.Join(dir, name)) {
+					t.Errorf("errors do not name %s:\n%v", name, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+import { Routes } from '@angular/router';
+import { HomeComponent } from './home/home.component';
+
+/* Routes are listed in menu order, e.g. ['home', 'about'] */
+export const routes: Routes = [
+  {
+    path: '',
+    component: HomeComponent,
+  },
+  {
+    path: 'about',
+    loadComponent: () => import('./about/about.component')
+      .then(m => m.AboutComponent),
+  },
+];
@@ -0,0 +1,6 @@
+import { Routes } from '@angular/router';
+import { HomeComponent } from './home/home.component';
+
+export default [
+  { path: '', component: HomeComponent },
+] satisfies Routes;
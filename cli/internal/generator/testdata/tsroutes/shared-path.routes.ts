@@ -0,0 +1,9 @@
+import { Routes } from '@angular/router';
+import { authGuard } from '@core/guards/auth.guard';
+
+export const routes: Routes = [
+  { path: '', redirectTo: 'home', pathMatch: 'full' },
+  { path: 'widgets', loadComponent: () => import('./widgets/widgets.component').then(m => m.WidgetsComponent), canActivate: [authGuard] },
+  { path: 'admin/widgets', loadChildren: () => import('@features/widget/widget.module').then(m => m.WidgetModule), canActivate: [authGuard] },
+  { path: '**', redirectTo: 'home' }
+];
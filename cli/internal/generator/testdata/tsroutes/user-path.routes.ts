@@ -0,0 +1,7 @@
+import { Routes } from '@angular/router';
+
+export const routes: Routes = [
+  { path: '', redirectTo: 'home', pathMatch: 'full' },
+  { path: 'widgets', loadComponent: () => import('./widgets/widgets.component').then(m => m.WidgetsComponent) },
+  { path: '**', redirectTo: 'home' }
+];
@@ -0,0 +1,77 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/demo/app/internal/config"
+	"github.com/demo/app/internal/database"
+	"github.com/demo/app/internal/middleware"
+)
+
+/*
+ * Router wraps Gin engine with application dependencies.
+ */
+type Router struct {
+	engine *gin.Engine
+	db     *database.DB
+	config *config.Config
+}
+
+/*
+ * New creates a new router instance with middleware and routes configured.
+ */
+func New(db *database.DB, cfg *config.Config) *Router {
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	r := &Router{
+		engine: gin.New(),
+		db:     db,
+		config: cfg,
+	}
+
+	r.setupRoutes()
+
+	return r
+}
+
+func (r *Router) setupRoutes() {
+	// Health check endpoint
+	r.engine.GET("/health", r.healthCheck)
+
+	// API versioning
+	v1 := r.engine.Group("/api/v1")
+	{
+		// Public auth routes
+		auth := v1.Group("/auth")
+		{
+			auth.POST("/login", r.handleLogin)
+			auth.POST("/register", r.handleRegister)
+			auth.POST("/refresh", r.handleRefresh)
+			auth.POST("/logout", r.handleLogout)
+		}
+
+		// Protected routes
+		protected := v1.Group("", middleware.Auth(r.config.JWTSecret))
+		{
+			// User management
+			users := protected.Group("/users")
+			{
+				users.GET("", r.handleListUsers)
+				users.GET("/:id", r.handleGetUser)
+				users.PUT("/:id", r.handleUpdateUser)
+				users.DELETE("/:id", r.handleDeleteUser)
+			}
+
+			// Profile endpoints
+			protected.GET("/profile", r.handleGetProfile)
+			protected.PUT("/profile", r.handleUpdateProfile)
+		}
+	}
+
+	// Serve static files in production
+	if r.config.IsProduction() {
+		r.setupStaticFiles()
+	}
+}
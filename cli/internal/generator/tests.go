@@ -0,0 +1,525 @@
+/*
+ * GoAstra CLI - Resource Tests
+ *
+ * Tests `goastra generate crud` writes beside a resource when
+ * generators.crud.generateTests is set: handler tests against a fake
+ * service, service tests against an in-memory repository, repository
+ * tests against the test database and Angular specs of the list,
+ * detail and form components.
+ */
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+ * GenerateTests creates the tests of the resource. Handler tests are
+ * only written for REST APIs, whose handlers the resource has.
+ */
+func (g *CRUDGenerator) GenerateTests() error {
+	if g.stack.API == "rest" {
+		if err := g.generateHandlerTest(); err != nil {
+			return err
+		}
+	}
+
+	if err := g.generateServiceTest(); err != nil {
+		return err
+	}
+
+	if err := g.generateRepositoryTest(); err != nil {
+		return err
+	}
+
+	return g.generateComponentSpecs()
+}
+
+/*
+ * generateHandlerTest creates the handler test, which serves the
+ * resource's routes from a fake service.
+ */
+func (g *CRUDGenerator) generateHandlerTest() error {
+	content, err := g.render("crud/handler_test.go.tmpl", templateData{
+		"HandlersImport":  g.paths.ImportPath(g.paths.Handlers),
+		"HandlersPackage": packageName(g.paths.Handlers),
+		"ModelsImport":    g.paths.ImportPath(g.paths.Models),
+		"ModelsPackage":   packageName(g.paths.Models),
+		"RouterImport":    g.paths.ImportPath(g.paths.Router),
+		"RouterPackage":   packageName(g.paths.Router),
+		"ServicesImport":  g.paths.ImportPath(g.paths.Services),
+		"ServicesPackage": packageName(g.paths.Services),
+		"RequiredInput":   g.requiredInput(),
+	})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(g.paths.Handlers, g.name+"_handler_test.go")
+	return g.out.WriteGo(path, content)
+}
+
+/*
+ * requiredInput reports whether create input has a required field, so
+ * an empty request body is rejected.
+ */
+func (g *CRUDGenerator) requiredInput() bool {
+	for _, field := range g.fields {
+		if strings.HasPrefix(field.CreateValidate(), "required") {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * generateServiceTest creates the service test, which runs the service
+ * on an in-memory repository.
+ */
+func (g *CRUDGenerator) generateServiceTest() error {
+	content, err := g.render("crud/service_test.go.tmpl", templateData{
+		"ServicesPackage": packageName(g.paths.Services),
+		"ModelsImport":    g.paths.ImportPath(g.paths.Models),
+		"ModelsPackage":   packageName(g.paths.Models),
+		"Updated":         g.updatedField(),
+	})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(g.paths.Services, g.name+"_service_test.go")
+	return g.out.WriteGo(path, content)
+}
+
+/*
+ * updatedField returns the field the update tests change: the first
+ * text or bool field the resource sets itself, or nil.
+ */
+func (g *CRUDGenerator) updatedField() *Field {
+	for i, field := range g.fields {
+		if field.Nullable || field.References != "" {
+			continue
+		}
+		switch field.Type {
+		case "string", "text", "bool":
+			return &g.fields[i]
+		}
+	}
+	return nil
+}
+
+/*
+ * testValue is a field the repository test sets: to a sample value,
+ * or to the ID of the related row the test created.
+ */
+type testValue struct {
+	Field   Field
+	Related bool
+}
+
+/*
+ * testFixture is a row of a related resource the repository test
+ * creates before the resource's own.
+ */
+type testFixture struct {
+	Target string
+	Values []testValue
+}
+
+/*
+ * generateRepositoryTest creates the repository test, which runs the
+ * repository in a rolled back transaction of the test database, and
+ * the helper opening that transaction.
+ */
+func (g *CRUDGenerator) generateRepositoryTest() error {
+	/* Rows of the resources the keys and relations point at */
+	var fixtures []testFixture
+	created := make(map[string]bool)
+	var sampled []Field
+	for _, field := range g.fields {
+		if field.References != "" {
+			sampled = append(sampled, g.relatedFixtures(&fixtures, field.References, created)...)
+		}
+	}
+	for _, rel := range g.relations {
+		if rel.Kind == ManyToMany {
+			sampled = append(sampled, g.relatedFixtures(&fixtures, rel.Target, created)...)
+		}
+	}
+
+	var sample []testValue
+	for _, field := range g.fields {
+		switch {
+		case field.References != "" && created[field.References]:
+			sample = append(sample, testValue{Field: field, Related: true})
+		case field.RepositorySampled():
+			sample = append(sample, testValue{Field: field})
+			sampled = append(sampled, field)
+		}
+	}
+
+	tables := []string{g.tableName()}
+	for _, rel := range g.relations {
+		if rel.Kind == ManyToMany {
+			tables = append(tables, tableKey(g.tableName())+"_"+rel.Snake())
+		}
+	}
+
+	content, err := g.render("crud/repository_test.go.tmpl", templateData{
+		"RepositoryPackage": packageName(g.paths.Repository),
+		"Imports":           importList(sampled, "context", "testing"),
+		"ModelsImport":      g.paths.ImportPath(g.paths.Models),
+		"ModelsPackage":     packageName(g.paths.Models),
+		"ORM":               g.stack.ORM,
+		"Driver":            g.driver,
+		"Tables":            tables,
+		"Table":             g.table,
+		"Fixtures":          fixtures,
+		"Sample":            sample,
+		"Updated":           g.updatedField(),
+	})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(g.paths.Repository, g.name+"_repository_test.go")
+	if err := g.out.WriteGo(path, content); err != nil {
+		return err
+	}
+	return g.generateTestDB()
+}
+
+/*
+ * relatedFixtures adds the fixture of a row of target to fixtures,
+ * after the rows its own keys point at, and returns the fields it
+ * sampled. The fields are read from the target's model.
+ */
+func (g *CRUDGenerator) relatedFixtures(fixtures *[]testFixture, target string, created map[string]bool) []Field {
+	if created[target] || target == g.name {
+		return nil
+	}
+	created[target] = true
+
+	fields := g.modelFields(target)
+	var sampled []Field
+	for _, field := range fields {
+		if field.References != "" {
+			sampled = append(sampled, g.relatedFixtures(fixtures, field.References, created)...)
+		}
+	}
+
+	fixture := testFixture{Target: target}
+	for _, field := range fields {
+		switch {
+		case field.References != "" && created[field.References] && field.References != g.name:
+			fixture.Values = append(fixture.Values, testValue{Field: field, Related: true})
+		case field.References == "" && field.RepositorySampled():
+			fixture.Values = append(fixture.Values, testValue{Field: field})
+			sampled = append(sampled, field)
+		}
+	}
+	*fixtures = append(*fixtures, fixture)
+	return sampled
+}
+
+/*
+ * modelFields reads the column fields of a generated model. A key
+ * column named after a resource with a generated repository references
+ * it. Fields
+ * of types the generator does not write are left out.
+ */
+func (g *CRUDGenerator) modelFields(target string) []Field {
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(g.paths.Models, target+".go"), nil, 0)
+	if err != nil {
+		return nil
+	}
+
+	goTypes := map[string]string{
+		"string": "string", "int": "int", "uint": "int", "int64": "bigint", "float64": "float",
+		"bool": "bool", "time.Time": "time", "json.RawMessage": "json",
+	}
+
+	var fields []Field
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != toPascalCase(target) {
+			return true
+		}
+		model, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+		for _, member := range model.Fields.List {
+			if member.Tag == nil || len(member.Names) != 1 {
+				continue
+			}
+			tag, _ := strconv.Unquote(member.Tag.Value)
+			column := reflect.StructTag(tag).Get("db")
+			if column == "" || column == "-" || reservedFields[column] {
+				continue
+			}
+
+			expr, nullable := member.Type, false
+			if star, ok := expr.(*ast.StarExpr); ok {
+				expr, nullable = star.X, true
+			}
+			fieldType, ok := goTypes[typeString(expr)]
+			if !ok {
+				continue
+			}
+
+			field := Field{Name: column, Type: fieldType, Nullable: nullable}
+			for _, rule := range strings.Split(reflect.StructTag(tag).Get("validate"), ",") {
+				name, value, _ := strings.Cut(rule, "=")
+				switch {
+				case name == "min":
+					field.Min = value
+				case name == "max":
+					field.Max = value
+				case fieldType == "string" && (name == "email" || name == "url" || name == "uuid"):
+					field.Type = name
+				}
+			}
+			if fieldType == "json" && strings.Contains(tag, "omitempty") {
+				field.Nullable = true
+			}
+			if related := strings.ReplaceAll(strings.TrimSuffix(column, "_id"), "_", "-"); strings.HasSuffix(column, "_id") &&
+				fileExists(filepath.Join(g.paths.Repository, related+"_repository.go")) {
+				field.References = related
+			}
+			fields = append(fields, field)
+		}
+		return false
+	})
+	return fields
+}
+
+/*
+ * typeString returns the source form of a type name such as time.Time.
+ */
+func typeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			return pkg.Name + "." + t.Sel.Name
+		}
+	}
+	return ""
+}
+
+/*
+ * generateTestDB writes the helper repository tests open the test
+ * database with, unless it already exists. It opens DB_URL with the
+ * backend's database/sql driver rather than the database package, whose
+ * connection setup is the application's own.
+ */
+func (g *CRUDGenerator) generateTestDB() error {
+	path := filepath.Join(g.paths.Repository, "testdb_test.go")
+	if fileExists(path) {
+		return nil
+	}
+
+	name := "crud/testdb_test.go.tmpl"
+	if g.stack.ORM == "ent" {
+		name = "crud/testdb_ent_test.go.tmpl"
+	}
+	driver := projectSQLDriver(g.paths.Root, g.driver)
+	dialect := g.driver
+	if dialect == "sqlite" {
+		dialect = "sqlite3"
+	}
+	content, err := g.render(name, templateData{
+		"RepositoryPackage": packageName(g.paths.Repository),
+		"DriverImport":      driver.Import,
+		"DriverName":        driver.Name,
+		"Dialect":           dialect,
+		"EntImport":         g.paths.ImportPath(g.paths.Ent),
+	})
+	if err != nil {
+		return err
+	}
+
+	return g.out.WriteSharedGo(path, content)
+}
+
+/*
+ * generateComponentSpecs creates the specs of the list, detail and
+ * form components, which run them against a fake feature service.
+ */
+func (g *CRUDGenerator) generateComponentSpecs() error {
+	data := templateData{
+		"Selectors": g.selectors(),
+	}
+
+	for _, component := range []string{"list", "detail", "form"} {
+		content, err := g.render("crud/"+component+".component.spec.ts.tmpl", data)
+		if err != nil {
+			return err
+		}
+
+		name := g.name + "-" + component
+		path := filepath.Join(g.paths.Features, g.name, "components", name, name+".component.spec.ts")
+		if err := g.out.Write(path, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+ * ServiceSampled reports whether the service test sets the field on
+ * create: fields the input takes as plain values and the test can
+ * compare.
+ */
+func (f Field) ServiceSampled() bool {
+	switch {
+	case f.Nullable, f.Default != "", f.References != "":
+		return false
+	case f.Type == "json", f.Type == "time", f.Type == "date":
+		return false
+	}
+	return true
+}
+
+/*
+ * RepositorySampled reports whether the repository test sets the
+ * field. Nullable fields are left NULL, except JSON: sqlx cannot scan
+ * NULL into json.RawMessage.
+ */
+func (f Field) RepositorySampled() bool {
+	return !f.Nullable || f.Type == "json"
+}
+
+/*
+ * sampleText returns a value of a text field starting from word,
+ * padded or cut to the field's length limits.
+ */
+func (f Field) sampleText(word string) string {
+	switch f.Type {
+	case "email":
+		return word + "@example.com"
+	case "url":
+		return "https://example.com/" + word
+	case "uuid":
+		return "00000000-0000-4000-8000-000000000001"
+	}
+	if n, err := strconv.Atoi(f.Min); err == nil && len(word) < n {
+		word += strings.Repeat("x", n-len(word))
+	}
+	if n, err := strconv.Atoi(f.Max); err == nil && n > 0 && len(word) > n {
+		word = word[:n]
+	}
+	return word
+}
+
+/*
+ * sampleNumber returns a number within the field's limits.
+ */
+func (f Field) sampleNumber() string {
+	value := "1"
+	if f.kind().GoType == "float64" {
+		value = "1.5"
+	}
+	current, _ := strconv.ParseFloat(value, 64)
+	if min, err := strconv.ParseFloat(f.Min, 64); err == nil && min > current {
+		value, current = f.Min, min
+	}
+	if max, err := strconv.ParseFloat(f.Max, 64); err == nil && max < current {
+		value = f.Max
+	}
+	return value
+}
+
+/*
+ * GoSample returns a Go literal of a valid value of the field.
+ */
+func (f Field) GoSample() string {
+	switch {
+	case f.Numeric():
+		return f.sampleNumber()
+	case f.Type == "bool":
+		return "true"
+	case f.Type == "time", f.Type == "date":
+		return "time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)"
+	case f.Type == "json":
+		return "json.RawMessage(`{}`)"
+	}
+	return strconv.Quote(f.sampleText("sample"))
+}
+
+/*
+ * GoUpdateSample returns the literal the update tests set the field
+ * to, which differs from GoSample.
+ */
+func (f Field) GoUpdateSample() string {
+	if f.Type == "bool" {
+		return "false"
+	}
+	return strconv.Quote(f.sampleText("updated"))
+}
+
+/*
+ * JSONSample returns a JSON literal of a valid request value of the
+ * field.
+ */
+func (f Field) JSONSample() string {
+	switch {
+	case f.References != "":
+		return "1"
+	case f.Numeric():
+		return f.sampleNumber()
+	case f.Type == "bool":
+		return "true"
+	case f.Type == "time", f.Type == "date":
+		return `"2024-01-02T00:00:00Z"`
+	case f.Type == "json":
+		return "{}"
+	}
+	return strconv.Quote(f.sampleText("sample"))
+}
+
+/*
+ * TSSample returns a TypeScript literal of a valid form value of the
+ * field. Keys pick the first related item.
+ */
+func (f Field) TSSample() string {
+	switch {
+	case f.References != "":
+		return "1"
+	case f.Numeric():
+		return f.sampleNumber()
+	case f.Type == "bool":
+		return "true"
+	case f.Type == "time":
+		return "'2024-01-02T00:00'"
+	case f.Type == "date":
+		return "'2024-01-02'"
+	case f.Type == "json":
+		return "'{}'"
+	}
+	return "'" + strings.ReplaceAll(f.sampleText("sample"), "'", "\\'") + "'"
+}
+
+/*
+ * TSItemSample returns a TypeScript literal of the field in the item
+ * the component specs' fake service returns.
+ */
+func (f Field) TSItemSample() string {
+	switch {
+	case f.Nullable:
+		return "null"
+	case f.Type == "time":
+		return "'2024-01-02T00:00:00Z'"
+	case f.Type == "json":
+		return "{}"
+	}
+	return f.TSSample()
+}
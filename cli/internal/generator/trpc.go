@@ -8,18 +8,26 @@ package generator
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 /*
  * TRPCGenerator handles proto and service generation for tRPC.
+ * Services are stubs unless they are backed by the generated service.
  */
 type TRPCGenerator struct {
 	name       string
 	pascalName string
 	camelName  string
 	snakeName  string
+	fields     []Field
+	timestamps bool
+	paths      Paths
+	stack      Stack
+	services   bool
+	out        *Writer
 }
 
 /*
@@ -31,267 +39,271 @@ func NewTRPCGenerator(name string) *TRPCGenerator {
 		pascalName: toPascalCase(name),
 		camelName:  toCamelCase(name),
 		snakeName:  toSnakeCase(name),
+		timestamps: true,
+		paths:      DefaultPaths(),
+		stack:      DefaultStack(),
+		out:        NewWriter(nil),
 	}
 }
 
 /*
- * GenerateProto creates a Protocol Buffer definition for the resource.
+ * SetWriter sets the writer generated files go through.
  */
-func (g *TRPCGenerator) GenerateProto() error {
-	content := fmt.Sprintf(`syntax = "proto3";
-
-package proto.v1;
-
-option go_package = "app/internal/rpc/gen/proto/v1;protov1";
-
-// ============================================================================
-// %s SERVICE
-// ============================================================================
+func (g *TRPCGenerator) SetWriter(out *Writer) {
+	g.out = out
+}
 
 /*
- * %sService provides CRUD operations for %s resources.
+ * SetFields sets the fields of the proto messages.
  */
-service %sService {
-  rpc Get%s(Get%sRequest) returns (Get%sResponse);
-  rpc List%ss(List%ssRequest) returns (List%ssResponse);
-  rpc Create%s(Create%sRequest) returns (Create%sResponse);
-  rpc Update%s(Update%sRequest) returns (Update%sResponse);
-  rpc Delete%s(Delete%sRequest) returns (Delete%sResponse);
+func (g *TRPCGenerator) SetFields(fields []Field) {
+	g.fields = fields
 }
 
-// ============================================================================
-// %s MESSAGES
-// ============================================================================
-
 /*
- * %s represents a %s entity.
+ * SetTimestamps sets whether the message has the created_at and
+ * updated_at fields.
  */
-message %s {
-  uint64 id = 1;
-  // TODO: Add your fields here
-  // string name = 2;
-  // string description = 3;
-  string created_at = 10;
-  string updated_at = 11;
+func (g *TRPCGenerator) SetTimestamps(timestamps bool) {
+	g.timestamps = timestamps
 }
 
-// ============================================================================
-// REQUEST/RESPONSE MESSAGES
-// ============================================================================
-
-message Get%sRequest {
-  uint64 id = 1;
-}
-
-message Get%sResponse {
-  %s %s = 1;
-}
-
-message List%ssRequest {
-  int32 page = 1;
-  int32 page_size = 2;
+/*
+ * SetPaths sets the proto and rpc directories and the packages the
+ * service imports.
+ */
+func (g *TRPCGenerator) SetPaths(paths Paths) {
+	g.paths = paths
 }
 
-message List%ssResponse {
-  repeated %s %ss = 1;
-  int32 total = 2;
-  int32 page = 3;
-  int32 page_size = 4;
-  int32 total_pages = 5;
+/*
+ * SetStack sets the project's ORM, which decides the database
+ * connection the service is constructed with.
+ */
+func (g *TRPCGenerator) SetStack(stack Stack) {
+	g.stack = stack
 }
 
-message Create%sRequest {
-  // TODO: Add your create fields here
-  // string name = 1;
-  // string description = 2;
+/*
+ * SetServices makes the RPC service call the service generated by
+ * `goastra generate api` instead of returning "not implemented".
+ */
+func (g *TRPCGenerator) SetServices(services bool) {
+	g.services = services
 }
 
-message Create%sResponse {
-  %s %s = 1;
+/*
+ * render renders a template with the resource's names and fields.
+ */
+func (g *TRPCGenerator) render(name string, data templateData) (string, error) {
+	return renderTemplate(g.paths.Templates, name, templateData{
+		"Name":       g.name,
+		"Pascal":     g.pascalName,
+		"Camel":      g.camelName,
+		"Snake":      toSnakeCase(g.name),
+		"Plural":     toPlural(g.name),
+		"Fields":     g.fields,
+		"Timestamps": g.timestamps,
+	}.with(data))
 }
 
-message Update%sRequest {
-  uint64 id = 1;
-  // TODO: Add your update fields here
-  // optional string name = 2;
-  // optional string description = 3;
-}
+/*
+ * GenerateProto creates a Protocol Buffer definition for the resource.
+ * 64-bit integers are numbers in TypeScript clients.
+ */
+func (g *TRPCGenerator) GenerateProto() error {
+	content, err := g.render("trpc/service.proto.tmpl", templateData{
+		"RPCImport":       g.paths.ImportPath(g.paths.RPC),
+		"TimestampNumber": max(10, len(g.fields)+2),
+	})
+	if err != nil {
+		return err
+	}
 
-message Update%sResponse {
-  %s %s = 1;
+	path := filepath.Join(g.paths.Proto, g.snakeName+".proto")
+	return g.out.Write(path, content)
 }
 
-message Delete%sRequest {
-  uint64 id = 1;
-}
+/*
+ * protoShape selects the message a field is declared in.
+ */
+type protoShape int
 
-message Delete%sResponse {
-  bool success = 1;
-}
-`,
-		g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.pascalName, g.pascalName, g.pascalName,
-		g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName, g.snakeName,
-		g.pascalName,
-		g.pascalName, g.pascalName, g.snakeName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName, g.snakeName,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName, g.snakeName,
-		g.pascalName,
-		g.pascalName,
-	)
+const (
+	protoMessage protoShape = iota
+	protoCreate
+	protoUpdate
+)
 
-	path := filepath.Join("app/proto/v1", g.snakeName+".proto")
-	return os.WriteFile(path, []byte(content), 0644)
+/*
+ * protoOptional reports whether field is an optional proto3 field in
+ * shape, which makes its Go type a pointer. JSON is sent as text and is
+ * always optional; update fields are optional so unset ones are kept.
+ */
+func protoOptional(f Field, shape protoShape) bool {
+	switch {
+	case f.Type == "json" || shape == protoUpdate:
+		return true
+	case shape == protoCreate:
+		return f.Nullable || f.Default != ""
+	}
+	return f.Nullable
 }
 
 /*
  * GenerateService creates a Connect-Go service implementation.
  */
 func (g *TRPCGenerator) GenerateService() error {
-	content := fmt.Sprintf(`package rpc
-
-import (
-	"context"
-	"errors"
-
-	"connectrpc.com/connect"
+	if g.services {
+		return g.generateBackedService()
+	}
 
-	"app/internal/config"
-	"app/internal/database"
-	pb "app/internal/rpc/gen/proto/v1"
-	"app/internal/rpc/gen/proto/v1/protov1connect"
-)
+	content, err := g.render("trpc/service.go.tmpl", templateData{
+		"RPCPackage":     packageName(g.paths.RPC),
+		"ConfigImport":   g.paths.ImportPath(g.configDir()),
+		"DatabaseImport": g.paths.ImportPath(g.paths.Database),
+		"ProtoImport":    g.paths.ImportPath(filepath.Join(g.paths.RPC, "gen/proto/v1")),
+		"ConnectImport":  g.paths.ImportPath(filepath.Join(g.paths.RPC, "gen/proto/v1/protov1connect")),
+		"DBType":         g.stack.dbType(),
+	})
+	if err != nil {
+		return err
+	}
 
-// ============================================================================
-// %s SERVICE
-// ============================================================================
+	path := filepath.Join(g.paths.RPC, g.snakeName+"_service.go")
+	return g.out.WriteGo(path, content)
+}
 
 /*
- * %sService implements the %s RPC service.
+ * configDir returns the directory of the backend's config package,
+ * next to the database package.
  */
-type %sService struct {
-	protov1connect.Unimplemented%sServiceHandler
-	db  *database.Client
-	cfg *config.Config
+func (g *TRPCGenerator) configDir() string {
+	return filepath.Join(filepath.Dir(g.paths.Database), "config")
 }
 
 /*
- * New%sService creates a new %s service.
+ * generateBackedService creates a Connect-Go service delegating to the
+ * service generated for the resource, converting between the proto
+ * messages and the service's models and inputs.
  */
-func New%sService(db *database.Client, cfg *config.Config) *%sService {
-	return &%sService{db: db, cfg: cfg}
+func (g *TRPCGenerator) generateBackedService() error {
+	models := packageName(g.paths.Models)
+	services := packageName(g.paths.Services)
+	repository := packageName(g.paths.Repository)
+
+	content, err := g.render("trpc/service_backed.go.tmpl", templateData{
+		"RPCPackage":             packageName(g.paths.RPC),
+		"ServiceImports":         g.serviceImports(),
+		"ConfigImport":           g.paths.ImportPath(g.configDir()),
+		"DatabaseImport":         g.paths.ImportPath(g.paths.Database),
+		"ModelsImport":           g.paths.ImportPath(g.paths.Models),
+		"RepositoryImport":       g.paths.ImportPath(g.paths.Repository),
+		"ProtoImport":            g.paths.ImportPath(filepath.Join(g.paths.RPC, "gen/proto/v1")),
+		"ConnectImport":          g.paths.ImportPath(filepath.Join(g.paths.RPC, "gen/proto/v1/protov1connect")),
+		"ServicesImport":         g.paths.ImportPath(g.paths.Services),
+		"ServicesPackage":        services,
+		"DBType":                 g.stack.dbType(),
+		"RepositoryPackage":      repository,
+		"ModelsPackage":          models,
+		"InputConversionsCreate": g.inputConversions("create", protoCreate),
+		"InputConversionsUpdate": g.inputConversions("update", protoUpdate),
+		"OutputConversions":      g.outputConversions(),
+	})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(g.paths.RPC, g.snakeName+"_service.go")
+	return g.out.WriteGo(path, content)
 }
 
 /*
- * Get%s returns a %s by ID.
+ * serviceImports returns the standard library imports of the backed
+ * service. Timestamps and time fields are converted with package time.
  */
-func (s *%sService) Get%s(
-	ctx context.Context,
-	req *connect.Request[pb.Get%sRequest],
-) (*connect.Response[pb.Get%sResponse], error) {
-	// TODO: Implement get %s
-	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("not implemented"))
+func (g *TRPCGenerator) serviceImports() string {
+	imports := []string{"context"}
+	if g.timestamps {
+		imports = append(imports, "time")
+	}
+	for _, field := range g.fields {
+		switch {
+		case field.Type == "json" && !containsImport(imports, "encoding/json"):
+			imports = append(imports, "encoding/json")
+		case (field.Type == "time" || field.Type == "date") && !containsImport(imports, "time"):
+			imports = append(imports, "time")
+		}
+	}
+	sort.Strings(imports)
+	return "\t\"" + strings.Join(imports, "\"\n\t\"") + "\"\n"
 }
 
 /*
- * List%ss returns a paginated list of %s.
+ * protoConversion converts a field between its proto and service
+ * types: ints and uint foreign keys travel as int64, and timestamps
+ * and JSON as text.
  */
-func (s *%sService) List%ss(
-	ctx context.Context,
-	req *connect.Request[pb.List%ssRequest],
-) (*connect.Response[pb.List%ssResponse], error) {
-	// TODO: Implement list %ss
-	return connect.NewResponse(&pb.List%ssResponse{
-		%ss:        []*pb.%s{},
-		Total:      0,
-		Page:       1,
-		PageSize:   20,
-		TotalPages: 0,
-	}), nil
+func protoConversion(f Field, toProto bool) conversion {
+	switch {
+	case f.References != "" && toProto:
+		return conversion{Expr: func(v string) string { return "int64(" + v + ")" }}
+	case f.References != "":
+		return conversion{Expr: func(v string) string { return "uint(" + v + ")" }}
+	case f.Type == "int" && toProto:
+		return conversion{Expr: func(v string) string { return "int64(" + v + ")" }}
+	case f.Type == "int":
+		return conversion{Expr: func(v string) string { return "int(" + v + ")" }}
+	case (f.Type == "time" || f.Type == "date") && toProto:
+		return conversion{Expr: func(v string) string { return operand(v) + ".Format(time.RFC3339)" }}
+	case f.Type == "time" || f.Type == "date":
+		return conversion{Expr: func(v string) string { return "time.Parse(time.RFC3339, " + v + ")" }, Fails: true}
+	case f.Type == "json" && toProto:
+		return conversion{Expr: func(v string) string { return "string(" + v + ")" }}
+	case f.Type == "json":
+		return conversion{Expr: func(v string) string { return "json.RawMessage(" + v + ")" }}
+	}
+	return identity
 }
 
 /*
- * Create%s creates a new %s.
+ * inputConversions returns the statements filling the service input
+ * named dst from the request message.
  */
-func (s *%sService) Create%s(
-	ctx context.Context,
-	req *connect.Request[pb.Create%sRequest],
-) (*connect.Response[pb.Create%sResponse], error) {
-	// TODO: Implement create %s
-	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("not implemented"))
+func (g *TRPCGenerator) inputConversions(dst string, shape protoShape) string {
+	if len(g.fields) == 0 {
+		return "\t/* TODO: Map request fields */\n"
+	}
+
+	var sb strings.Builder
+	for _, field := range g.fields {
+		dstPtr := field.Type != "json" && (shape == protoUpdate || field.Nullable || field.Default != "")
+		sb.WriteString(convertField(dst+"."+field.GoName(), "req.Msg."+field.protoGoName(), dstPtr, protoOptional(field, shape), protoConversion(field, false)))
+	}
+	return sb.String()
 }
 
 /*
- * Update%s updates an existing %s.
+ * outputConversions returns the statements copying the model's fields
+ * to the proto message out.
  */
-func (s *%sService) Update%s(
-	ctx context.Context,
-	req *connect.Request[pb.Update%sRequest],
-) (*connect.Response[pb.Update%sResponse], error) {
-	// TODO: Implement update %s
-	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("not implemented"))
+func (g *TRPCGenerator) outputConversions() string {
+	var sb strings.Builder
+	for _, field := range g.fields {
+		srcPtr := field.Nullable && field.Type != "json"
+		sb.WriteString(convertField("out."+field.protoGoName(), "item."+field.GoName(), protoOptional(field, protoMessage), srcPtr, protoConversion(field, true)))
+	}
+	return sb.String()
 }
 
 /*
- * Delete%s deletes a %s.
+ * RegisterSnippet returns the lines mounting the service in main.go.
  */
-func (s *%sService) Delete%s(
-	ctx context.Context,
-	req *connect.Request[pb.Delete%sRequest],
-) (*connect.Response[pb.Delete%sResponse], error) {
-	// TODO: Implement delete %s
-	return connect.NewResponse(&pb.Delete%sResponse{Success: true}), nil
-}
-`,
-		g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName,
-		g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName,
-		g.pascalName, g.pascalName,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName, g.name,
-		g.pascalName, g.pascalName,
-		g.pascalName, g.pascalName,
-		g.name,
-		g.pascalName,
-	)
-
-	path := filepath.Join("app/internal/rpc", g.snakeName+"_service.go")
-	return os.WriteFile(path, []byte(content), 0644)
+func (g *TRPCGenerator) RegisterSnippet() string {
+	return fmt.Sprintf(`	%sService := rpc.New%sService(db, cfg)
+	path, handler = protov1connect.New%sServiceHandler(%sService, interceptor)
+	mux.Handle(path, handler)
+`, g.camelName, g.pascalName, g.pascalName, g.camelName)
 }
 
 /*
@@ -0,0 +1,523 @@
+/*
+ * GoAstra CLI - Angular Routes Editor
+ *
+ * Inserts and removes lazy routes in app.routes.ts. A small TypeScript
+ * scanner skips strings, template literals and comments so the routes
+ * array can be located reliably; the file is edited in place and keeps
+ * its layout.
+ */
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+ * angularRoutesFile is the Angular route configuration edited by default.
+ */
+const angularRoutesFile = "web/src/app/app.routes.ts"
+
+/*
+ * authGuardFile is the guard protecting generated routes.
+ */
+const authGuardFile = "web/src/app/core/guards/auth.guard.ts"
+
+/*
+ * authGuardImport is the import added for routes that use authGuard.
+ */
+const authGuardImport = "import { authGuard } from '@core/guards/auth.guard';"
+
+/*
+ * lazyRoute is a route entry loading a feature module on demand.
+ */
+type lazyRoute struct {
+	Path   string
+	Import string
+	Module string
+	Guard  bool
+}
+
+/*
+ * RoutesEditError reports a routes file that cannot be edited safely.
+ */
+type RoutesEditError struct {
+	File   string
+	Reason string
+}
+
+func (e *RoutesEditError) Error() string {
+	return fmt.Sprintf("%s: %s", e.File, e.Reason)
+}
+
+/*
+ * tsToken is a significant token of TypeScript source: an identifier or
+ * number, a string or template literal, or a single punctuation byte.
+ */
+type tsToken struct {
+	kind       byte /* 'i' identifier, 's' string, 'p' punctuation */
+	text       string
+	start, end int
+}
+
+/*
+ * scanTS splits src into tokens, skipping whitespace and comments.
+ * Template literal substitutions are skipped as part of the literal.
+ */
+func scanTS(src string) ([]tsToken, error) {
+	var tokens []tsToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`':
+			end, err := scanString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tsToken{kind: 's', text: src[i:end], start: i, end: end})
+			i = end
+		case isIdentByte(c):
+			start := i
+			for i < len(src) && isIdentByte(src[i]) {
+				i++
+			}
+			tokens = append(tokens, tsToken{kind: 'i', text: src[start:i], start: start, end: i})
+		default:
+			tokens = append(tokens, tsToken{kind: 'p', text: src[i : i+1], start: i, end: i + 1})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+/*
+ * scanString returns the offset just past the string or template
+ * literal starting at src[start].
+ */
+func scanString(src string, start int) (int, error) {
+	quote := src[start]
+	depth := 0
+	for i := start + 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '\\':
+			i++
+		case depth > 0 && c == '{':
+			depth++
+		case depth > 0 && c == '}':
+			depth--
+		case depth > 0:
+		case quote == '`' && c == '$' && i+1 < len(src) && src[i+1] == '{':
+			depth++
+			i++
+		case c == quote:
+			return i + 1, nil
+		case c == '\n' && quote != '`':
+			return 0, fmt.Errorf("unterminated string at offset %d", start)
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+/*
+ * routeElement is a top-level element of the routes array, spanning
+ * tokens[first:last+1].
+ */
+type routeElement struct {
+	first, last int
+	path        string
+	load        string
+	object      bool
+}
+
+/*
+ * insertRoute adds route to the routes array of src, before the
+ * wildcard route when there is one. It reports false when a route
+ * loading the same module is already present, and fails when another
+ * route uses the path.
+ */
+func insertRoute(file, src string, route lazyRoute) (string, bool, error) {
+	fail := func(reason string) (string, bool, error) {
+		return "", false, &RoutesEditError{File: file, Reason: reason}
+	}
+
+	tokens, err := scanTS(src)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	open := routesArray(tokens)
+	if open < 0 {
+		return fail("cannot find the routes array (expected `export const routes: Routes = [...]`)")
+	}
+	elements, closing, err := arrayElements(tokens, open)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	for _, el := range elements {
+		if el.load == route.Import {
+			return src, false, nil
+		}
+	}
+	for _, el := range elements {
+		if el.path == route.Path {
+			return fail(fmt.Sprintf("path '%s' is already used by a route that does not load %s", route.Path, route.Import))
+		}
+	}
+
+	var wildcard *routeElement
+	for i := range elements {
+		if elements[i].path == "**" {
+			wildcard = &elements[i]
+			break
+		}
+	}
+
+	elementIndent := lineIndentAt(src, tokens[closing].start) + "  "
+	propertyIndent := ""
+	for _, el := range elements {
+		if !onOwnLine(src, tokens[el.first].start) {
+			return fail("routes are not written one per line")
+		}
+		elementIndent = lineIndentAt(src, tokens[el.first].start)
+		if el.object && propertyIndent == "" && lineOf(src, tokens[el.first].start) != lineOf(src, tokens[el.last].start) {
+			propertyIndent = lineIndentAt(src, tokens[el.first+1].start)
+		}
+	}
+	entry := route.render(elementIndent, propertyIndent)
+
+	var out string
+	switch {
+	case wildcard != nil:
+		at := lineStartAt(src, tokens[wildcard.first].start)
+		out = src[:at] + entry + ",\n" + src[at:]
+	case len(elements) == 0:
+		at := tokens[open].end
+		out = src[:at] + "\n" + entry + "\n" + lineIndentAt(src, tokens[open].start) + src[tokens[closing].start:]
+	default:
+		last := elements[len(elements)-1]
+		if next := tokens[last.last+1]; next.text == "," {
+			out = src[:next.end] + "\n" + entry + "," + src[next.end:]
+		} else {
+			at := tokens[last.last].end
+			out = src[:at] + ",\n" + entry + src[at:]
+		}
+	}
+
+	if route.Guard && !importsName(tokens, "authGuard") {
+		out = addImport(out, authGuardImport)
+	}
+	return out, true, nil
+}
+
+/*
+ * removeRoute removes the entry loading route's module from the routes
+ * array of src, together with the authGuard import when nothing else
+ * uses it. Routes only sharing the path are left alone. It reports
+ * false when there is no entry.
+ */
+func removeRoute(file, src string, route lazyRoute) (string, bool, error) {
+	fail := func(reason string) (string, bool, error) {
+		return "", false, &RoutesEditError{File: file, Reason: reason}
+	}
+
+	tokens, err := scanTS(src)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	open := routesArray(tokens)
+	if open < 0 {
+		return fail("cannot find the routes array (expected `export const routes: Routes = [...]`)")
+	}
+	elements, _, err := arrayElements(tokens, open)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	target := -1
+	for i, el := range elements {
+		if el.load == route.Import {
+			target = i
+		}
+	}
+	if target < 0 {
+		return src, false, nil
+	}
+
+	el := elements[target]
+	if !onOwnLine(src, tokens[el.first].start) {
+		return fail("routes are not written one per line")
+	}
+
+	var out string
+	if next := tokens[el.last+1]; next.text == "," {
+		out = src[:lineStartAt(src, tokens[el.first].start)] + src[lineEndAt(src, next.end):]
+	} else if prev := tokens[el.first-1]; prev.text == "," {
+		/* Last entry without a trailing comma: drop the previous comma */
+		out = src[:prev.start] + src[prev.end:lineStartAt(src, tokens[el.first].start)-1] + src[lineEndAt(src, tokens[el.last].end)-1:]
+	} else {
+		out = src[:lineStartAt(src, tokens[el.first].start)] + src[lineEndAt(src, tokens[el.last].end):]
+	}
+
+	return removeUnusedImport(out, "authGuard"), true, nil
+}
+
+/*
+ * removeUnusedImport removes a single-line import binding only name
+ * when name is not referenced anywhere else.
+ */
+func removeUnusedImport(src, name string) string {
+	tokens, err := scanTS(src)
+	if err != nil {
+		return src
+	}
+
+	uses := 0
+	for _, tok := range tokens {
+		if tok.text == name {
+			uses++
+		}
+	}
+	if uses != 1 {
+		return src
+	}
+
+	for i, tok := range tokens {
+		if tok.text != "import" || i+5 >= len(tokens) {
+			continue
+		}
+		if tokens[i+1].text == "{" && tokens[i+2].text == name && tokens[i+3].text == "}" && tokens[i+4].text == "from" {
+			start := lineStartAt(src, tok.start)
+			if lineOf(src, tok.start) == lineOf(src, tokens[i+5].end) {
+				return src[:start] + src[lineEndAt(src, tok.start):]
+			}
+		}
+	}
+	return src
+}
+
+/*
+ * render formats the route like the existing entries: on one line when
+ * they are, otherwise one property per line.
+ */
+func (r lazyRoute) render(indent, propertyIndent string) string {
+	load := fmt.Sprintf("() => import('%s')", r.Import)
+	then := fmt.Sprintf(".then(m => m.%s)", r.Module)
+
+	if propertyIndent == "" {
+		line := fmt.Sprintf("%s{ path: '%s', loadChildren: %s%s", indent, r.Path, load, then)
+		if r.Guard {
+			line += ", canActivate: [authGuard]"
+		}
+		return line + " }"
+	}
+
+	lines := []string{
+		indent + "{",
+		fmt.Sprintf("%spath: '%s',", propertyIndent, r.Path),
+		fmt.Sprintf("%sloadChildren: %s", propertyIndent, load),
+		fmt.Sprintf("%s  %s", propertyIndent, then),
+	}
+	if r.Guard {
+		lines[len(lines)-1] += ","
+		lines = append(lines, propertyIndent+"canActivate: [authGuard]")
+	}
+	return strings.Join(append(lines, indent+"}"), "\n")
+}
+
+/*
+ * routesArray returns the index of the [ token opening the array
+ * assigned to the routes variable, or -1.
+ */
+func routesArray(tokens []tsToken) int {
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i].text != "routes" {
+			continue
+		}
+		switch tokens[i-1].text {
+		case "const", "let", "var":
+		default:
+			continue
+		}
+		for j := i + 1; j < len(tokens) && tokens[j].text != ";"; j++ {
+			if tokens[j].text == "=" {
+				if j+1 < len(tokens) && tokens[j+1].text == "[" {
+					return j + 1
+				}
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+/*
+ * arrayElements splits the array opened at tokens[open] into its
+ * top-level elements and returns the index of the closing bracket.
+ */
+func arrayElements(tokens []tsToken, open int) ([]routeElement, int, error) {
+	var (
+		elements []routeElement
+		stack    []string
+		first    = -1
+	)
+	for i := open + 1; i < len(tokens); i++ {
+		tok := tokens[i]
+		if len(stack) == 0 {
+			switch tok.text {
+			case "]":
+				if first >= 0 {
+					elements = append(elements, newRouteElement(tokens, first, i-1))
+				}
+				return elements, i, nil
+			case ",":
+				if first >= 0 {
+					elements = append(elements, newRouteElement(tokens, first, i-1))
+				}
+				first = -1
+				continue
+			}
+			if first < 0 {
+				first = i
+			}
+		}
+
+		switch tok.text {
+		case "(", "[", "{":
+			stack = append(stack, tok.text)
+		case ")", "]", "}":
+			if len(stack) == 0 || stack[len(stack)-1] != map[string]string{")": "(", "]": "[", "}": "{"}[tok.text] {
+				return nil, 0, fmt.Errorf("unbalanced %q in the routes array", tok.text)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return nil, 0, fmt.Errorf("the routes array is not closed")
+}
+
+/*
+ * newRouteElement describes tokens[first:last+1], reading the path of
+ * object routes from their top-level path property and the module they
+ * load from the import in their loadChildren property.
+ */
+func newRouteElement(tokens []tsToken, first, last int) routeElement {
+	el := routeElement{first: first, last: last, object: tokens[first].text == "{" && tokens[last].text == "}"}
+	if !el.object {
+		return el
+	}
+
+	depth := 0
+	for i := first + 1; i < last; i++ {
+		switch tokens[i].text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		case "path":
+			if depth == 0 && i+2 < last && tokens[i+1].text == ":" && tokens[i+2].kind == 's' {
+				el.path = strings.Trim(tokens[i+2].text, "'\"`")
+			}
+		case "loadChildren":
+			if depth != 0 || i+1 >= last || tokens[i+1].text != ":" {
+				continue
+			}
+			for j := i + 2; j+2 < last && tokens[j].text != ","; j++ {
+				if tokens[j].text == "import" && tokens[j+1].text == "(" && tokens[j+2].kind == 's' {
+					el.load = strings.Trim(tokens[j+2].text, "'\"`")
+					break
+				}
+			}
+		}
+	}
+	return el
+}
+
+/*
+ * importsName reports whether a static import binds name.
+ */
+func importsName(tokens []tsToken, name string) bool {
+	for i, tok := range tokens {
+		if tok.text != "import" || i+1 < len(tokens) && tokens[i+1].text == "(" {
+			continue
+		}
+		for j := i + 1; j < len(tokens) && tokens[j].text != "from" && tokens[j].text != ";"; j++ {
+			if tokens[j].text == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+ * addImport inserts line after the last static import, or at the top.
+ */
+func addImport(src, line string) string {
+	tokens, err := scanTS(src)
+	if err != nil {
+		return src
+	}
+
+	at := 0
+	for i, tok := range tokens {
+		if tok.text != "import" || i+1 < len(tokens) && tokens[i+1].text == "(" {
+			continue
+		}
+		for j := i + 1; j < len(tokens); j++ {
+			if tokens[j].kind == 's' && (tokens[j-1].text == "from" || j == i+1) {
+				end := tokens[j].end
+				if j+1 < len(tokens) && tokens[j+1].text == ";" {
+					end = tokens[j+1].end
+				}
+				at = lineEndAt(src, end)
+				break
+			}
+		}
+	}
+	return src[:at] + line + "\n" + src[at:]
+}
+
+func lineStartAt(src string, offset int) int {
+	return strings.LastIndexByte(src[:offset], '\n') + 1
+}
+
+func lineEndAt(src string, offset int) int {
+	if i := strings.IndexByte(src[offset:], '\n'); i >= 0 {
+		return offset + i + 1
+	}
+	return len(src)
+}
+
+func lineIndentAt(src string, offset int) string {
+	start := lineStartAt(src, offset)
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return src[start:end]
+}
+
+func onOwnLine(src string, offset int) bool {
+	return strings.TrimSpace(src[lineStartAt(src, offset):offset]) == ""
+}
+
+func lineOf(src string, offset int) int {
+	return strings.Count(src[:offset], "\n")
+}
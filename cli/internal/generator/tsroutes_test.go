@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var widgetRoute = lazyRoute{
+	Path:   "widgets",
+	Import: "@features/widget/widget.module",
+	Module: "WidgetModule",
+	Guard:  true,
+}
+
+func TestAppRoutes(t *testing.T) {
+	for _, fixture := range []string{"app.routes", "multiline.routes"} {
+		t.Run(fixture, func(t *testing.T) {
+			original := readFile(t, filepath.Join("testdata", "tsroutes", fixture+".ts"))
+
+			/* Insert */
+			inserted, changed, err := insertRoute(fixture, original, widgetRoute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !changed {
+				t.Fatal("insertRoute() = false on routes without the entry")
+			}
+			golden(t, filepath.Join("testdata", "tsroutes", fixture+".inserted.golden"), inserted)
+
+			/* Rerun */
+			again, changed, err := insertRoute(fixture, inserted, widgetRoute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed || again != inserted {
+				t.Error("insertRoute() changed routes that already have the entry")
+			}
+
+			/* Removal */
+			removed, changed, err := removeRoute(fixture, inserted, widgetRoute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !changed {
+				t.Fatal("removeRoute() = false on routes with the entry")
+			}
+			if removed != original {
+				t.Errorf("removeRoute() did not restore the routes:\n%s", removed)
+			}
+
+			removed, changed, err = removeRoute(fixture, original, widgetRoute)
+			if err != nil || changed || removed != original {
+				t.Errorf("removeRoute() on routes without the entry = %v, %v, want false, nil", changed, err)
+			}
+		})
+	}
+}
+
+func TestAppRoutesRestructured(t *testing.T) {
+	src := readFile(t, filepath.Join("testdata", "tsroutes", "restructured.routes.ts"))
+
+	edits := map[string]func(string, string, lazyRoute) (string, bool, error){
+		"insertRoute": insertRoute,
+		"removeRoute": removeRoute,
+	}
+	for name, edit := range edits {
+		_, _, err := edit("app.routes.ts", src, widgetRoute)
+		var editErr *RoutesEditError
+		if !errors.As(err, &editErr) || !strings.Contains(editErr.Reason, "cannot find the routes array") {
+			t.Errorf("%s() error = %v, want a missing routes array", name, err)
+		}
+	}
+
+	inline := "import { Routes } from '@angular/router';\n\nexport const routes: Routes = [{ path: '', redirectTo: 'home' }, { path: '**', redirectTo: '' }];\n"
+	_, _, err := insertRoute("app.routes.ts", inline, widgetRoute)
+	var editErr *RoutesEditError
+	if !errors.As(err, &editErr) || editErr.Reason != "routes are not written one per line" {
+		t.Errorf("insertRoute() error = %v, want routes not written one per line", err)
+	}
+}
+
+func TestAppRoutesPathInUse(t *testing.T) {
+	original := readFile(t, filepath.Join("testdata", "tsroutes", "user-path.routes.ts"))
+
+	/* A hand-written route owns the path: neither skipped nor removed */
+	_, _, err := insertRoute("app.routes.ts", original, widgetRoute)
+	var editErr *RoutesEditError
+	if !errors.As(err, &editErr) {
+		t.Fatalf("insertRoute() error = %v, want a RoutesEditError", err)
+	}
+	golden(t, filepath.Join("testdata", "tsroutes", "user-path.routes.error.golden"), err.Error()+"\n")
+
+	removed, changed, err := removeRoute("app.routes.ts", original, widgetRoute)
+	if err != nil || changed || removed != original {
+		t.Errorf("removeRoute() = %v, %v, want the hand-written route kept", changed, err)
+	}
+}
+
+func TestAppRoutesSharedPath(t *testing.T) {
+	original := readFile(t, filepath.Join("testdata", "tsroutes", "shared-path.routes.ts"))
+
+	/* The generated route was moved to another path */
+	inserted, changed, err := insertRoute("app.routes.ts", original, widgetRoute)
+	if err != nil || changed || inserted != original {
+		t.Errorf("insertRoute() = %v, %v, want the moved route recognized", changed, err)
+	}
+
+	removed, changed, err := removeRoute("app.routes.ts", original, widgetRoute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("removeRoute() = false on routes with the entry")
+	}
+	golden(t, filepath.Join("testdata", "tsroutes", "shared-path.routes.removed.golden"), removed)
+}
@@ -0,0 +1,587 @@
+/*
+ * GoAstra CLI - Router Wiring
+ *
+ * Edits the backend router so generated APIs are served without manual
+ * setup. The router is parsed with go/ast to locate the edit points and
+ * changed in place, leaving the rest of the file byte for byte intact.
+ */
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+ * routerFile is the file of the router package that is edited.
+ */
+const routerFile = "router.go"
+
+/*
+ * RouterWiring adds or removes the construction of a resource's
+ * repository, service and handler in Router.setupRoutes, together with
+ * the call to its Register<Name>Routes function on the protected group.
+ */
+type RouterWiring struct {
+	path       string
+	pascalName string
+	camelName  string
+	paths      Paths
+	stack      Stack
+}
+
+/*
+ * NewRouterWiring creates a wiring editor for the router file at path.
+ */
+func NewRouterWiring(path, name string) *RouterWiring {
+	return &RouterWiring{
+		path:       path,
+		pascalName: toPascalCase(name),
+		camelName:  toCamelCase(name),
+		paths:      DefaultPaths(),
+		stack:      DefaultStack(),
+	}
+}
+
+/*
+ * SetPaths sets the package directories and module path the wiring
+ * imports. Without a module path it is taken from the router's imports.
+ */
+func (w *RouterWiring) SetPaths(paths Paths) {
+	w.paths = paths
+}
+
+/*
+ * SetStack sets the project's stack, whose ORM decides the connection
+ * the repository is constructed with.
+ */
+func (w *RouterWiring) SetStack(stack Stack) {
+	w.stack = stack
+}
+
+/*
+ * textEdit replaces src[start:end] with text.
+ */
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+/*
+ * routerSource is a parsed router file.
+ */
+type routerSource struct {
+	src   []byte
+	fset  *token.FileSet
+	file  *ast.File
+	setup *ast.FuncDecl
+}
+
+/*
+ * Wire registers the resource in the router. It reports false when the
+ * resource is already registered.
+ */
+func (w *RouterWiring) Wire() (bool, error) {
+	rs, err := w.load()
+	if err != nil {
+		return false, err
+	}
+	if w.registerCall(rs) != nil {
+		return false, nil
+	}
+
+	group, stmts, index, err := w.protectedGroup(rs)
+	if err != nil {
+		return false, err
+	}
+
+	imports, err := w.wiredImports(rs)
+	if err != nil {
+		return false, err
+	}
+
+	var edits []textEdit
+	edits = append(edits, w.wiringEdit(rs, group, stmts, index))
+	sort.Strings(imports)
+	grouped := false
+	for _, importPath := range imports {
+		edit, ok := w.importEdit(rs, importPath)
+		if !ok {
+			continue
+		}
+		if edit.group {
+			/* Start one new import group for all unrelated imports */
+			if !grouped {
+				edit.text = "\n" + edit.text
+			}
+			grouped = true
+		}
+		edits = append(edits, edit.textEdit)
+	}
+
+	return true, w.save(rs, edits)
+}
+
+/*
+ * Unwire removes the resource from the router, along with imports that
+ * are no longer used. It reports false when the resource is not wired.
+ */
+func (w *RouterWiring) Unwire() (bool, error) {
+	rs, err := w.load()
+	if err != nil {
+		return false, err
+	}
+
+	var edits []textEdit
+	ast.Inspect(rs.setup.Body, func(n ast.Node) bool {
+		if guard, ok := n.(*ast.IfStmt); ok && w.isWiring(guard) {
+			return false
+		}
+		block, ok := n.(*ast.BlockStmt)
+		if !ok {
+			return true
+		}
+		for i, stmt := range block.List {
+			if !w.isWiring(stmt) {
+				continue
+			}
+			start := lineStart(rs, stmt.Pos())
+			if i == 0 || !w.isWiring(block.List[i-1]) {
+				start = w.markerStart(rs, stmt)
+			}
+			edits = append(edits, textEdit{start: start, end: lineEnd(rs, stmt.End())})
+		}
+		return true
+	})
+	if len(edits) == 0 {
+		return false, nil
+	}
+
+	imports, err := w.wiredImports(rs)
+	if err != nil {
+		return false, err
+	}
+	if err := w.save(rs, edits); err != nil {
+		return false, err
+	}
+	return true, w.pruneImports(imports)
+}
+
+func (w *RouterWiring) load() (*routerSource, error) {
+	src, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read router: %w", err)
+	}
+	return parseRouter(w.path, src)
+}
+
+func parseRouter(filename string, src []byte) (*routerSource, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse router: %w", err)
+	}
+
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "setupRoutes" && fn.Recv != nil && fn.Body != nil {
+			return &routerSource{src: src, fset: fset, file: file, setup: fn}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: setupRoutes method not found", filename)
+}
+
+/*
+ * save applies edits and writes the router back. A router that was
+ * gofmt-clean is kept gofmt-clean; the edited source must still parse.
+ */
+func (w *RouterWiring) save(rs *routerSource, edits []textEdit) error {
+	/* Apply back to front; insertions at one offset keep their order */
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+
+	out := append([]byte(nil), rs.src...)
+	for _, edit := range edits {
+		out = append(out[:edit.start], append([]byte(edit.text), out[edit.end:]...)...)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), w.path, out, parser.ParseComments); err != nil {
+		return fmt.Errorf("edited router does not parse: %w", err)
+	}
+	if formatted, err := format.Source(rs.src); err == nil && bytes.Equal(formatted, rs.src) {
+		if formatted, err := format.Source(out); err == nil {
+			out = formatted
+		}
+	}
+
+	return os.WriteFile(w.path, out, 0644)
+}
+
+/*
+ * registerCall returns the Register<Name>Routes call in setupRoutes.
+ */
+func (w *RouterWiring) registerCall(rs *routerSource) *ast.CallExpr {
+	var found *ast.CallExpr
+	ast.Inspect(rs.setup.Body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && calleeName(call) == "Register"+w.pascalName+"Routes" {
+			found = call
+		}
+		return found == nil
+	})
+	return found
+}
+
+/*
+ * protectedGroup finds the route group that uses middleware.Auth. It
+ * returns the group variable and the statement list holding the Use
+ * call, with the index of the statement after which routes go: the
+ * group's { } block when there is one, or the Use call itself.
+ */
+func (w *RouterWiring) protectedGroup(rs *routerSource) (string, []ast.Stmt, int, error) {
+	var (
+		group string
+		stmts []ast.Stmt
+		index int
+	)
+	ast.Inspect(rs.setup.Body, func(n ast.Node) bool {
+		block, ok := n.(*ast.BlockStmt)
+		if !ok || group != "" {
+			return group == ""
+		}
+		for i, stmt := range block.List {
+			if name, ok := authGroup(stmt); ok {
+				group, stmts, index = name, block.List, i
+				if i+1 < len(block.List) {
+					if _, ok := block.List[i+1].(*ast.BlockStmt); ok {
+						index = i + 1
+					}
+				}
+				return false
+			}
+		}
+		return true
+	})
+	if group == "" {
+		return "", nil, 0, fmt.Errorf("%s: no route group using middleware.Auth found in setupRoutes", w.path)
+	}
+	return group, stmts, index, nil
+}
+
+/*
+ * authGroup reports whether stmt is a <group>.Use(...) call passing
+ * an Auth middleware, and returns the group variable.
+ */
+func authGroup(stmt ast.Stmt) (string, bool) {
+	expr, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return "", false
+	}
+	call, ok := expr.X.(*ast.CallExpr)
+	if !ok {
+		return "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Use" {
+		return "", false
+	}
+	recv, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	for _, arg := range call.Args {
+		if inner, ok := arg.(*ast.CallExpr); ok && calleeName(inner) == "Auth" {
+			return recv.Name, true
+		}
+	}
+	return "", false
+}
+
+/*
+ * wiringEdit inserts the wiring at the end of the protected group's
+ * block, or after its Use call when the group has no block. The wiring
+ * is guarded by a nil check of r.db: database.Connect returns no
+ * connection when no database is configured, and the server then
+ * starts without the resource's routes.
+ */
+func (w *RouterWiring) wiringEdit(rs *routerSource, group string, stmts []ast.Stmt, index int) textEdit {
+	anchor := stmts[index]
+	indent := lineIndent(rs, anchor.Pos())
+	offset := lineEnd(rs, anchor.End())
+	separator := "\n"
+
+	if block, ok := anchor.(*ast.BlockStmt); ok {
+		offset = lineStart(rs, block.Rbrace)
+		if len(block.List) > 0 {
+			indent = lineIndent(rs, block.List[0].Pos())
+		} else {
+			indent += "\t"
+			separator = ""
+		}
+	}
+
+	/* Match the comment style already used in setupRoutes */
+	marker := "// " + w.pascalName + " routes"
+	for _, cg := range rs.file.Comments {
+		if cg.Pos() > rs.setup.Pos() && cg.End() < rs.setup.End() {
+			if strings.HasPrefix(cg.List[0].Text, "/*") {
+				marker = "/* " + w.pascalName + " routes */"
+			}
+			break
+		}
+	}
+
+	lines := []string{
+		marker,
+		"if r.db != nil {",
+		"\t" + fmt.Sprintf("%sRepository := %s.New%sRepository(r.db.%s)", w.camelName, packageName(w.paths.Repository), w.pascalName, w.stack.dbType()),
+		"\t" + fmt.Sprintf("%sService := %s.New%sService(%sRepository)", w.camelName, packageName(w.paths.Services), w.pascalName, w.camelName),
+		"\t" + fmt.Sprintf("%sHandler := %s.New%sHandler(%sService)", w.camelName, packageName(w.paths.Handlers), w.pascalName, w.camelName),
+		"\t" + fmt.Sprintf("Register%sRoutes(%s, %sHandler)", w.pascalName, group, w.camelName),
+		"}",
+	}
+
+	var sb strings.Builder
+	sb.WriteString(separator)
+	for _, line := range lines {
+		sb.WriteString(indent + line + "\n")
+	}
+	return textEdit{start: offset, end: offset, text: sb.String()}
+}
+
+/*
+ * importEdit is the insertion of an import. Group is set when the
+ * import has no related imports and starts a group of its own.
+ */
+type importEdit struct {
+	textEdit
+	group bool
+}
+
+/*
+ * wiredImports returns the import paths of the repository, service and
+ * handler packages the wiring refers to.
+ */
+func (w *RouterWiring) wiredImports(rs *routerSource) ([]string, error) {
+	paths := w.paths
+	if paths.Module == "" {
+		base, err := w.importBase(rs)
+		if err != nil {
+			return nil, err
+		}
+		paths.Module = base
+	}
+	return []string{
+		paths.ImportPath(paths.Handlers),
+		paths.ImportPath(paths.Repository),
+		paths.ImportPath(paths.Services),
+	}, nil
+}
+
+/*
+ * importBase returns the module path internal packages are imported
+ * under, taken from the router's own internal imports.
+ */
+func (w *RouterWiring) importBase(rs *routerSource) (string, error) {
+	for _, spec := range rs.file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if i := strings.Index(importPath, "/internal/"); i > 0 {
+			return importPath[:i], nil
+		}
+		if strings.HasPrefix(importPath, "internal/") {
+			return "", fmt.Errorf("%s: cannot determine module path from import %q", w.path, importPath)
+		}
+	}
+	return "", fmt.Errorf("%s: no internal package imports to determine the module path from", w.path)
+}
+
+/*
+ * importEdit adds importPath in sorted position next to the imports of
+ * its sibling packages, or else of other packages of the same module.
+ * It reports false when the path is already imported.
+ */
+func (w *RouterWiring) importEdit(rs *routerSource, importPath string) (importEdit, bool) {
+	for _, spec := range rs.file.Imports {
+		if existing, _ := strconv.Unquote(spec.Path.Value); existing == importPath {
+			return importEdit{}, false
+		}
+	}
+
+	line := strconv.Quote(importPath)
+	prefixes := []string{path.Dir(importPath) + "/"}
+	if w.paths.Module != "" {
+		prefixes = append(prefixes, w.paths.Module+"/")
+	}
+	for _, prefix := range prefixes {
+		var before, after *ast.ImportSpec
+		for _, spec := range rs.file.Imports {
+			existing, _ := strconv.Unquote(spec.Path.Value)
+			if !strings.HasPrefix(existing, prefix) {
+				continue
+			}
+			if existing < importPath {
+				before = spec
+			} else if after == nil {
+				after = spec
+			}
+		}
+
+		switch {
+		case before != nil:
+			offset := lineEnd(rs, before.End())
+			return importEdit{textEdit: textEdit{start: offset, end: offset, text: lineIndent(rs, before.Pos()) + line + "\n"}}, true
+		case after != nil:
+			offset := lineStart(rs, after.Pos())
+			return importEdit{textEdit: textEdit{start: offset, end: offset, text: lineIndent(rs, after.Pos()) + line + "\n"}}, true
+		}
+	}
+
+	last := rs.file.Imports[len(rs.file.Imports)-1]
+	offset := lineEnd(rs, last.End())
+	return importEdit{textEdit: textEdit{start: offset, end: offset, text: "\t" + line + "\n"}, group: true}, true
+}
+
+/*
+ * isWiring reports whether stmt is one of the resource's wiring lines,
+ * or the r.db nil check holding only wiring lines.
+ */
+func (w *RouterWiring) isWiring(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.IfStmt:
+		if s.Init != nil || s.Else != nil || len(s.Body.List) == 0 {
+			return false
+		}
+		for _, inner := range s.Body.List {
+			if !w.isWiring(inner) {
+				return false
+			}
+		}
+		return true
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		return ok && calleeName(call) == "Register"+w.pascalName+"Routes"
+	case *ast.AssignStmt:
+		if len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+			return false
+		}
+		ident, ok := s.Lhs[0].(*ast.Ident)
+		call, isCall := s.Rhs[0].(*ast.CallExpr)
+		if !ok || !isCall {
+			return false
+		}
+		for _, kind := range []string{"Repository", "Service", "Handler"} {
+			if ident.Name == w.camelName+kind && calleeName(call) == "New"+w.pascalName+kind {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+ * markerStart extends the removal of the first wiring statement to the
+ * "<Name> routes" comment and blank line written above it.
+ */
+func (w *RouterWiring) markerStart(rs *routerSource, stmt ast.Stmt) int {
+	start := stmt.Pos()
+	line := rs.fset.Position(stmt.Pos()).Line
+	for _, cg := range rs.file.Comments {
+		if cg.End() < stmt.Pos() && rs.fset.Position(cg.End()).Line == line-1 &&
+			strings.TrimSpace(cg.Text()) == w.pascalName+" routes" {
+			start = cg.Pos()
+		}
+	}
+
+	offset := lineStart(rs, start)
+	if offset > 1 && rs.src[offset-1] == '\n' && rs.src[offset-2] == '\n' {
+		offset--
+	}
+	return offset
+}
+
+/*
+ * pruneImports removes wired package imports the router no longer uses.
+ */
+func (w *RouterWiring) pruneImports(imports []string) error {
+	rs, err := w.load()
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(rs.file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	var edits []textEdit
+	for _, spec := range rs.file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if used[name] || !containsImport(imports, importPath) {
+			continue
+		}
+		edits = append(edits, textEdit{start: lineStart(rs, spec.Pos()), end: lineEnd(rs, spec.End())})
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+	return w.save(rs, edits)
+}
+
+/*
+ * calleeName returns the name of the called function or method.
+ */
+func calleeName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+func lineStart(rs *routerSource, pos token.Pos) int {
+	offset := rs.fset.Position(pos).Offset
+	for offset > 0 && rs.src[offset-1] != '\n' {
+		offset--
+	}
+	return offset
+}
+
+func lineEnd(rs *routerSource, pos token.Pos) int {
+	offset := rs.fset.Position(pos).Offset
+	for offset < len(rs.src) && rs.src[offset] != '\n' {
+		offset++
+	}
+	if offset < len(rs.src) {
+		offset++
+	}
+	return offset
+}
+
+func lineIndent(rs *routerSource, pos token.Pos) string {
+	start := lineStart(rs, pos)
+	end := start
+	for end < len(rs.src) && (rs.src[end] == '\t' || rs.src[end] == ' ') {
+		end++
+	}
+	return string(rs.src[start:end])
+}
@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of editor tests")
+
+/*
+ * golden compares got with the golden file at path, or rewrites the
+ * file with -update.
+ */
+func golden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match %s (rerun with -update to accept it):\n%s", path, got)
+	}
+}
+
+/*
+ * copyFixture copies a testdata file into a temporary directory and
+ * returns the copy's path and content.
+ */
+func copyFixture(t *testing.T, fixture, name string) (string, string) {
+	t.Helper()
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, string(data)
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func newTestWiring(path string) *RouterWiring {
+	paths := DefaultPaths()
+	paths.Module = "github.com/demo/app"
+	wiring := NewRouterWiring(path, "widget")
+	wiring.SetPaths(paths)
+	return wiring
+}
+
+func TestRouterWiring(t *testing.T) {
+	path, original := copyFixture(t, filepath.Join("testdata", "wiring", "router.go"), routerFile)
+
+	/* Insert */
+	wired, err := newTestWiring(path).Wire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wired {
+		t.Fatal("Wire() = false on an unwired router")
+	}
+	golden(t, filepath.Join("testdata", "wiring", "router.wired.golden"), readFile(t, path))
+
+	/* Rerun */
+	before := readFile(t, path)
+	wired, err = newTestWiring(path).Wire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wired || readFile(t, path) != before {
+		t.Error("Wire() changed a router that already wires the resource")
+	}
+
+	/* Removal */
+	unwired, err := newTestWiring(path).Unwire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unwired {
+		t.Fatal("Unwire() = false on a wired router")
+	}
+	if got := readFile(t, path); got != original {
+		t.Errorf("Unwire() did not restore the router:\n%s", got)
+	}
+
+	unwired, err = newTestWiring(path).Unwire()
+	if err != nil || unwired {
+		t.Errorf("Unwire() on an unwired router = %v, %v, want false, nil", unwired, err)
+	}
+}
+
+func TestRouterWiringUnrecognized(t *testing.T) {
+	path, original := copyFixture(t, filepath.Join("testdata", "wiring", "restructured.go"), routerFile)
+
+	_, err := newTestWiring(path).Wire()
+	if err == nil || !strings.Contains(err.Error(), "no route group using middleware.Auth") {
+		t.Errorf("Wire() error = %v, want a missing protected group", err)
+	}
+	if readFile(t, path) != original {
+		t.Error("Wire() changed a router it does not recognize")
+	}
+
+	if err := os.WriteFile(path, []byte("package router\n\nfunc setup() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTestWiring(path).Wire(); err == nil || !strings.Contains(err.Error(), "setupRoutes method not found") {
+		t.Errorf("Wire() error = %v, want a missing setupRoutes", err)
+	}
+}
@@ -67,6 +67,17 @@ func (m *Migrator) Connect(databaseURL string) error {
 	return nil
 }
 
+/***
+ * DB returns the connection opened by Connect, for callers that read
+ * the schema rather than run migrations.
+ *
+ * Author: channdev
+ * Date: 18/10/2026
+ ***/
+func (m *Migrator) DB() *sql.DB {
+	return m.db
+}
+
 /***
  * Close terminates the database connection gracefully.
  * Should be called when migration operations are complete.
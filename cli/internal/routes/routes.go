@@ -0,0 +1,731 @@
+/*
+ * GoAstra CLI - Route Analysis
+ *
+ * Statically discovers the HTTP routes of a gin application. Route
+ * groups are followed through variables, `Use` calls and helper
+ * functions that receive a router, and each handler body is inspected
+ * for the request bodies it binds and the JSON responses it writes.
+ */
+package routes
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const ginPath = "github.com/gin-gonic/gin"
+
+/*
+ * Route is a registered endpoint and what its handler exchanges.
+ * Path uses gin syntax, e.g. /api/v1/users/:id. IntegerParams lists
+ * the path parameters the handler parses as integers.
+ */
+type Route struct {
+	Method        string
+	Path          string
+	Handler       string
+	Doc           string
+	Auth          bool
+	IntegerParams []string
+	QueryParams   []QueryParam
+	Query         types.Type
+	Body          types.Type
+	Responses     []Response
+}
+
+/*
+ * QueryParam is a query string parameter read with c.Query or
+ * c.DefaultQuery. Integer is set when the handler parses it with
+ * strconv, as in strconv.Atoi(c.Query("page")).
+ */
+type QueryParam struct {
+	Name    string
+	Default string
+	Integer bool
+}
+
+/*
+ * Response is a status code written by a handler. Type is nil when the
+ * response has no body.
+ */
+type Response struct {
+	Status int
+	Type   types.Type
+}
+
+/*
+ * Analysis holds the discovered routes and the packages they came from.
+ */
+type Analysis struct {
+	Routes   []Route
+	Packages []*packages.Package
+}
+
+/*
+ * group is the symbolic value of a gin router: its path prefix and
+ * whether the auth middleware applies to it.
+ */
+type group struct {
+	prefix string
+	auth   bool
+}
+
+type analyzer struct {
+	pkgs      []*packages.Package
+	funcs     map[*types.Func]*funcDecl
+	mounted   map[*types.Func]bool
+	mount     string
+	routes    []Route
+	handlers  map[*types.Func]*handlerInfo
+	analyzing map[*types.Func]bool
+}
+
+type funcDecl struct {
+	decl *ast.FuncDecl
+	pkg  *packages.Package
+}
+
+type handlerInfo struct {
+	query     []QueryParam
+	integers  []string
+	queryType types.Type
+	body      types.Type
+	responses []Response
+	parsed    map[string]bool
+}
+
+/*
+ * Analyze loads the Go packages under dir and returns their routes.
+ * Functions that receive a router but are never called with one, such
+ * as generated Register<Name>Routes functions, are assumed to be
+ * mounted at mount.
+ */
+func Analyze(dir, mount string) (*Analysis, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid app path: %w", err)
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Dir: absDir,
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load app packages: %w", err)
+	}
+
+	var msgs []string
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, e := range pkg.Errors {
+			msgs = append(msgs, e.Error())
+		}
+	})
+	if len(msgs) > 0 {
+		return nil, fmt.Errorf("failed to type-check app packages:\n  %s", strings.Join(msgs, "\n  "))
+	}
+
+	a := &analyzer{
+		pkgs:      pkgs,
+		funcs:     make(map[*types.Func]*funcDecl),
+		mounted:   make(map[*types.Func]bool),
+		mount:     mount,
+		handlers:  make(map[*types.Func]*handlerInfo),
+		analyzing: make(map[*types.Func]bool),
+	}
+	a.indexFuncs()
+
+	for _, fn := range a.entryPoints() {
+		a.walkFunc(fn, nil, 0)
+	}
+
+	sort.SliceStable(a.routes, func(i, j int) bool {
+		return a.routes[i].Path < a.routes[j].Path
+	})
+
+	return &Analysis{Routes: a.routes, Packages: pkgs}, nil
+}
+
+/*
+ * indexFuncs records every function declaration and which of them are
+ * called with a router argument somewhere in the application.
+ */
+func (a *analyzer) indexFuncs() {
+	for _, pkg := range a.pkgs {
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				fd, ok := decl.(*ast.FuncDecl)
+				if !ok || fd.Body == nil {
+					continue
+				}
+				if fn, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func); ok {
+					a.funcs[fn] = &funcDecl{decl: fd, pkg: pkg}
+				}
+			}
+		}
+	}
+
+	for _, fd := range a.funcs {
+		info := fd.pkg.TypesInfo
+		ast.Inspect(fd.decl.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			callee := calledFunc(info, call.Fun)
+			if callee == nil || a.funcs[callee] == nil {
+				return true
+			}
+			for _, arg := range call.Args {
+				if isRouter(info.TypeOf(arg)) {
+					a.mounted[callee] = true
+				}
+			}
+			return true
+		})
+	}
+}
+
+/*
+ * entryPoints returns the functions that are not reached through a call
+ * passing a router, in source order.
+ */
+func (a *analyzer) entryPoints() []*types.Func {
+	var fns []*types.Func
+	for fn := range a.funcs {
+		if !a.mounted[fn] {
+			fns = append(fns, fn)
+		}
+	}
+	sort.Slice(fns, func(i, j int) bool {
+		pi, pj := a.position(fns[i]), a.position(fns[j])
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+	return fns
+}
+
+func (a *analyzer) position(fn *types.Func) token.Position {
+	fd := a.funcs[fn]
+	return fd.pkg.Fset.Position(fd.decl.Pos())
+}
+
+/*
+ * walkFunc follows router values through a function body. args holds
+ * the groups passed for router parameters; unknown router parameters
+ * are treated as mounted at the configured prefix.
+ */
+func (a *analyzer) walkFunc(fn *types.Func, args map[int]group, depth int) {
+	fd := a.funcs[fn]
+	if fd == nil || depth > 16 {
+		return
+	}
+	info := fd.pkg.TypesInfo
+
+	env := make(map[types.Object]group)
+	index := 0
+	for _, field := range fd.decl.Type.Params.List {
+		names := field.Names
+		if len(names) == 0 {
+			index++
+			continue
+		}
+		for _, name := range names {
+			obj := info.Defs[name]
+			if obj != nil && isRouter(obj.Type()) {
+				if g, ok := args[index]; ok {
+					env[obj] = g
+				} else {
+					env[obj] = group{prefix: a.mount}
+				}
+			}
+			index++
+		}
+	}
+
+	ast.Inspect(fd.decl.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) != len(node.Rhs) {
+				return true
+			}
+			for i, rhs := range node.Rhs {
+				if g, ok := a.evalGroup(info, env, rhs); ok {
+					if obj := exprObject(info, node.Lhs[i]); obj != nil {
+						env[obj] = g
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			for i, value := range node.Values {
+				if i >= len(node.Names) {
+					break
+				}
+				if g, ok := a.evalGroup(info, env, value); ok {
+					if obj := info.Defs[node.Names[i]]; obj != nil {
+						env[obj] = g
+					}
+				}
+			}
+		case *ast.CallExpr:
+			a.visitCall(info, env, node, depth)
+		}
+		return true
+	})
+}
+
+/*
+ * visitCall handles Use, route registration and calls into helper
+ * functions that receive a router.
+ */
+func (a *analyzer) visitCall(info *types.Info, env map[types.Object]group, call *ast.CallExpr, depth int) {
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && isRouter(info.TypeOf(sel.X)) {
+		recv, ok := a.evalGroup(info, env, sel.X)
+		if !ok {
+			return
+		}
+
+		switch method := sel.Sel.Name; method {
+		case "Use":
+			if hasAuth(info, call.Args) {
+				recv.auth = true
+				if obj := exprObject(info, sel.X); obj != nil {
+					env[obj] = recv
+				}
+			}
+		case "GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS":
+			a.register(info, recv, method, call.Args)
+		case "Handle":
+			if len(call.Args) > 0 {
+				if verb, ok := stringValue(info, call.Args[0]); ok {
+					a.register(info, recv, strings.ToUpper(verb), call.Args[1:])
+				}
+			}
+		}
+		return
+	}
+
+	callee := calledFunc(info, call.Fun)
+	if callee == nil || a.funcs[callee] == nil {
+		return
+	}
+
+	args := make(map[int]group)
+	for i, arg := range call.Args {
+		if g, ok := a.evalGroup(info, env, arg); ok {
+			args[i] = g
+		}
+	}
+	if len(args) > 0 {
+		a.walkFunc(callee, args, depth+1)
+	}
+}
+
+/*
+ * evalGroup returns the group an expression evaluates to. Engines that
+ * have not been seen before are the root group.
+ */
+func (a *analyzer) evalGroup(info *types.Info, env map[types.Object]group, expr ast.Expr) (group, bool) {
+	expr = ast.Unparen(expr)
+	if !isRouter(info.TypeOf(expr)) {
+		return group{}, false
+	}
+
+	if call, ok := expr.(*ast.CallExpr); ok {
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Group" || len(call.Args) == 0 {
+			return group{}, false
+		}
+		parent, ok := a.evalGroup(info, env, sel.X)
+		if !ok {
+			return group{}, false
+		}
+		prefix, ok := stringValue(info, call.Args[0])
+		if !ok {
+			return group{}, false
+		}
+		return group{
+			prefix: joinPath(parent.prefix, prefix),
+			auth:   parent.auth || hasAuth(info, call.Args[1:]),
+		}, true
+	}
+
+	if obj := exprObject(info, expr); obj != nil {
+		if g, ok := env[obj]; ok {
+			return g, true
+		}
+		if isEngine(obj.Type()) {
+			env[obj] = group{}
+			return group{}, true
+		}
+	}
+
+	return group{}, false
+}
+
+/*
+ * register records a route. The last handler is the endpoint; earlier
+ * ones are route-level middleware.
+ */
+func (a *analyzer) register(info *types.Info, recv group, method string, args []ast.Expr) {
+	if len(args) < 2 {
+		return
+	}
+	relative, ok := stringValue(info, args[0])
+	if !ok {
+		return
+	}
+
+	handlers := args[1:]
+	route := Route{
+		Method: method,
+		Path:   joinPath(recv.prefix, relative),
+		Auth:   recv.auth || hasAuth(info, handlers[:len(handlers)-1]),
+	}
+
+	handler := ast.Unparen(handlers[len(handlers)-1])
+	var details *handlerInfo
+	switch h := handler.(type) {
+	case *ast.FuncLit:
+		details = a.inspectHandler(info, h.Body)
+	default:
+		if fn := calledFunc(info, h); fn != nil {
+			route.Handler = handlerName(fn)
+			if fd := a.funcs[fn]; fd != nil {
+				if fd.decl.Doc != nil {
+					route.Doc = fd.decl.Doc.Text()
+				}
+				details = a.handlerDetails(fn)
+			}
+		}
+	}
+
+	if details != nil {
+		route.QueryParams = details.query
+		route.IntegerParams = details.integers
+		route.Query = details.queryType
+		route.Body = details.body
+		route.Responses = details.responses
+	}
+
+	a.routes = append(a.routes, route)
+}
+
+func (a *analyzer) handlerDetails(fn *types.Func) *handlerInfo {
+	if details, ok := a.handlers[fn]; ok {
+		return details
+	}
+	fd := a.funcs[fn]
+	details := a.inspectHandler(fd.pkg.TypesInfo, fd.decl.Body)
+	a.handlers[fn] = details
+	return details
+}
+
+/*
+ * inspectHandler collects the gin.Context calls of a handler body,
+ * following helper functions the context is passed to.
+ */
+func (a *analyzer) inspectHandler(info *types.Info, body *ast.BlockStmt) *handlerInfo {
+	details := &handlerInfo{parsed: make(map[string]bool)}
+	a.collectContextCalls(info, body, details)
+	for i, param := range details.query {
+		details.query[i].Integer = details.parsed["query "+param.Name]
+	}
+	return details
+}
+
+func (a *analyzer) collectContextCalls(info *types.Info, body *ast.BlockStmt, details *handlerInfo) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		if method, name, ok := parsedInteger(info, call); ok {
+			if method == "Param" && !details.parsed["path "+name] {
+				details.integers = append(details.integers, name)
+				details.parsed["path "+name] = true
+			} else if method != "Param" {
+				details.parsed["query "+name] = true
+			}
+		}
+
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if ok && isContext(info.TypeOf(sel.X)) {
+			a.contextCall(info, sel.Sel.Name, call.Args, details)
+			return true
+		}
+
+		callee := calledFunc(info, call.Fun)
+		if callee == nil || a.funcs[callee] == nil || a.analyzing[callee] {
+			return true
+		}
+		for _, arg := range call.Args {
+			if isContext(info.TypeOf(arg)) {
+				fd := a.funcs[callee]
+				a.analyzing[callee] = true
+				a.collectContextCalls(fd.pkg.TypesInfo, fd.decl.Body, details)
+				delete(a.analyzing, callee)
+				break
+			}
+		}
+		return true
+	})
+}
+
+func (a *analyzer) contextCall(info *types.Info, method string, args []ast.Expr, details *handlerInfo) {
+	switch method {
+	case "ShouldBindJSON", "BindJSON", "ShouldBind", "Bind":
+		if len(args) > 0 && details.body == nil {
+			details.body = bindTarget(info, args[0])
+		}
+	case "ShouldBindQuery", "BindQuery":
+		if len(args) > 0 && details.queryType == nil {
+			details.queryType = bindTarget(info, args[0])
+		}
+	case "Query", "DefaultQuery", "GetQuery", "QueryArray":
+		if len(args) == 0 {
+			return
+		}
+		name, ok := stringValue(info, args[0])
+		if !ok {
+			return
+		}
+		param := QueryParam{Name: name}
+		if method == "DefaultQuery" && len(args) > 1 {
+			param.Default, _ = stringValue(info, args[1])
+		}
+		for _, existing := range details.query {
+			if existing.Name == name {
+				return
+			}
+		}
+		details.query = append(details.query, param)
+	case "JSON", "IndentedJSON", "PureJSON", "SecureJSON", "AbortWithStatusJSON":
+		if len(args) < 2 {
+			return
+		}
+		var value types.Type
+		if tv, ok := info.Types[args[1]]; ok && !tv.IsNil() {
+			value = tv.Type
+		}
+		addResponse(info, details, args[0], value)
+	case "Status", "AbortWithStatus":
+		if len(args) > 0 {
+			addResponse(info, details, args[0], nil)
+		}
+	}
+}
+
+/*
+ * parsedInteger recognises a parameter parsed as an integer, as in
+ * strconv.Atoi(c.Query("page")) or strconv.ParseUint(c.Param("id"), 10,
+ * 32), and returns the context method reading it and its name.
+ */
+func parsedInteger(info *types.Info, call *ast.CallExpr) (string, string, bool) {
+	fn := calledFunc(info, call.Fun)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "strconv" || len(call.Args) == 0 {
+		return "", "", false
+	}
+	switch fn.Name() {
+	case "Atoi", "ParseInt", "ParseUint":
+	default:
+		return "", "", false
+	}
+
+	inner, ok := ast.Unparen(call.Args[0]).(*ast.CallExpr)
+	if !ok || len(inner.Args) == 0 {
+		return "", "", false
+	}
+	sel, ok := inner.Fun.(*ast.SelectorExpr)
+	if !ok || !isContext(info.TypeOf(sel.X)) {
+		return "", "", false
+	}
+	switch sel.Sel.Name {
+	case "Param", "Query", "DefaultQuery":
+	default:
+		return "", "", false
+	}
+	name, ok := stringValue(info, inner.Args[0])
+	return sel.Sel.Name, name, ok
+}
+
+/*
+ * addResponse records a status code; the first body type seen for a
+ * status wins.
+ */
+func addResponse(info *types.Info, details *handlerInfo, statusExpr ast.Expr, value types.Type) {
+	tv, ok := info.Types[statusExpr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return
+	}
+	status, ok := constant.Int64Val(tv.Value)
+	if !ok {
+		return
+	}
+
+	for i, existing := range details.responses {
+		if existing.Status == int(status) {
+			if existing.Type == nil {
+				details.responses[i].Type = value
+			}
+			return
+		}
+	}
+	details.responses = append(details.responses, Response{Status: int(status), Type: value})
+}
+
+/*
+ * bindTarget returns the type bound by c.ShouldBindJSON(&input).
+ */
+func bindTarget(info *types.Info, arg ast.Expr) types.Type {
+	t := info.TypeOf(arg)
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+/*
+ * hasAuth reports whether any middleware expression is a call to an
+ * Auth function from a middleware package.
+ */
+func hasAuth(info *types.Info, exprs []ast.Expr) bool {
+	for _, expr := range exprs {
+		call, ok := ast.Unparen(expr).(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		fn := calledFunc(info, call.Fun)
+		if fn != nil && fn.Name() == "Auth" && fn.Pkg() != nil && fn.Pkg().Name() == "middleware" {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * handlerName names a handler function, qualifying methods with their
+ * receiver type: UserHandler.List.
+ */
+func handlerName(fn *types.Func) string {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return fn.Name()
+	}
+	recv := sig.Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	if named, ok := recv.(*types.Named); ok {
+		return named.Obj().Name() + "." + fn.Name()
+	}
+	return fn.Name()
+}
+
+/*
+ * calledFunc resolves a function or method expression to its object.
+ */
+func calledFunc(info *types.Info, expr ast.Expr) *types.Func {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		fn, _ := info.Uses[e].(*types.Func)
+		return fn
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[e]; ok {
+			fn, _ := sel.Obj().(*types.Func)
+			return fn
+		}
+		fn, _ := info.Uses[e.Sel].(*types.Func)
+		return fn
+	}
+	return nil
+}
+
+/*
+ * exprObject returns the variable or field an expression refers to.
+ */
+func exprObject(info *types.Info, expr ast.Expr) types.Object {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if obj := info.Defs[e]; obj != nil {
+			return obj
+		}
+		return info.Uses[e]
+	case *ast.SelectorExpr:
+		return info.Uses[e.Sel]
+	}
+	return nil
+}
+
+func stringValue(info *types.Info, expr ast.Expr) (string, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+/*
+ * joinPath joins route segments the way gin does, keeping a trailing
+ * slash from the relative path.
+ */
+func joinPath(base, relative string) string {
+	if relative == "" {
+		if base == "" {
+			return "/"
+		}
+		return base
+	}
+	joined := path.Join("/"+base, relative)
+	if strings.HasSuffix(relative, "/") && !strings.HasSuffix(joined, "/") {
+		joined += "/"
+	}
+	return joined
+}
+
+func isRouter(t types.Type) bool {
+	switch ginName(t) {
+	case "Engine", "RouterGroup", "IRouter", "IRoutes":
+		return true
+	}
+	return false
+}
+
+func isEngine(t types.Type) bool {
+	return ginName(t) == "Engine"
+}
+
+func isContext(t types.Type) bool {
+	return ginName(t) == "Context"
+}
+
+/*
+ * ginName returns the name of a gin type, looking through pointers.
+ */
+func ginName(t types.Type) string {
+	if t == nil {
+		return ""
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != ginPath {
+		return ""
+	}
+	return named.Obj().Name()
+}
@@ -0,0 +1,149 @@
+package routes
+
+import (
+	"fmt"
+	"go/types"
+	"os/exec"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+var fixture = sync.OnceValues(func() (*Analysis, error) {
+	return Analyze("testdata/app", "/api/v1")
+})
+
+/*
+ * analyzeFixture returns the routes of testdata/app by method and path.
+ * Its gin module is a local stub, so the analysis needs no downloads.
+ */
+func analyzeFixture(t *testing.T) map[string]Route {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	analysis, err := fixture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := make(map[string]Route, len(analysis.Routes))
+	for _, route := range analysis.Routes {
+		routes[route.Method+" "+route.Path] = route
+	}
+	return routes
+}
+
+func TestAnalyzeGroupsAndAuth(t *testing.T) {
+	routes := analyzeFixture(t)
+
+	tests := []struct {
+		route   string
+		handler string
+		auth    bool
+	}{
+		{"GET /health", "", false},
+		{"GET /api/v1/users", "UserHandler.List", false},
+		{"GET /api/v1/users/:id", "UserHandler.Get", false},
+		{"GET /api/v1/users/search", "UserHandler.Search", false},
+		{"DELETE /api/v1/users/:id", "UserHandler.Delete", true},
+		{"POST /api/v1/users", "UserHandler.Create", true},
+		{"PUT /api/v1/admin/users/:id/", "UserHandler.Update", true},
+		{"GET /api/v1/widgets", "", false},
+	}
+	for _, tt := range tests {
+		route, ok := routes[tt.route]
+		if !ok {
+			t.Errorf("%s: route not found", tt.route)
+			continue
+		}
+		if route.Handler != tt.handler || route.Auth != tt.auth {
+			t.Errorf("%s: handler %q, auth %v, want %q, %v", tt.route, route.Handler, route.Auth, tt.handler, tt.auth)
+		}
+	}
+	if len(routes) != len(tests) {
+		t.Errorf("found %d routes, want %d", len(routes), len(tests))
+	}
+
+	if doc := routes["GET /api/v1/users"].Doc; doc != "List returns a page of users.\n" {
+		t.Errorf("List doc = %q", doc)
+	}
+}
+
+func TestAnalyzeHandlerBodies(t *testing.T) {
+	routes := analyzeFixture(t)
+
+	tests := []struct {
+		route     string
+		body      string
+		query     string
+		responses string
+	}{
+		{"POST /api/v1/users", "CreateUserInput", "", "400 gin.H, 201 handlers.User"},
+		{"PUT /api/v1/admin/users/:id/", "UpdateUserInput", "", "400 gin.H, 200 handlers.User"},
+		{"GET /api/v1/users/search", "", "SearchQuery", "400 gin.H, 200 []handlers.User"},
+		{"GET /api/v1/users/:id", "", "", "404 gin.H, 200 handlers.User"},
+		{"DELETE /api/v1/users/:id", "", "", "204 <nil>"},
+		{"GET /health", "", "", "200 gin.H"},
+	}
+	for _, tt := range tests {
+		route := routes[tt.route]
+		if got := typeName(route.Body); got != tt.body {
+			t.Errorf("%s: body %q, want %q", tt.route, got, tt.body)
+		}
+		if got := typeName(route.Query); got != tt.query {
+			t.Errorf("%s: query %q, want %q", tt.route, got, tt.query)
+		}
+		if got := responses(route.Responses); got != tt.responses {
+			t.Errorf("%s: responses %q, want %q", tt.route, got, tt.responses)
+		}
+	}
+
+	want := []QueryParam{{Name: "page", Integer: true}, {Name: "page_size", Default: "10", Integer: true}, {Name: "sort"}}
+	if got := routes["GET /api/v1/users"].QueryParams; !reflect.DeepEqual(got, want) {
+		t.Errorf("List query params = %v, want %v", got, want)
+	}
+	if got := routes["GET /api/v1/users/:id"].IntegerParams; !reflect.DeepEqual(got, []string{"id"}) {
+		t.Errorf("Get integer params = %v, want [id]", got)
+	}
+	if got := routes["DELETE /api/v1/users/:id"].IntegerParams; got != nil {
+		t.Errorf("Delete integer params = %v, want none", got)
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	tests := []struct {
+		base, relative, want string
+	}{
+		{"", "", "/"},
+		{"/api/v1", "", "/api/v1"},
+		{"", "users", "/users"},
+		{"/api/v1", "/users/:id", "/api/v1/users/:id"},
+		{"/api/v1", "/users/:id/", "/api/v1/users/:id/"},
+		{"/api/v1/", "/files/*path", "/api/v1/files/*path"},
+	}
+	for _, tt := range tests {
+		if got := joinPath(tt.base, tt.relative); got != tt.want {
+			t.Errorf("joinPath(%q, %q) = %q, want %q", tt.base, tt.relative, got, tt.want)
+		}
+	}
+}
+
+func typeName(t types.Type) string {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return ""
+	}
+	return named.Obj().Name()
+}
+
+func responses(rs []Response) string {
+	s := ""
+	for i, r := range rs {
+		if i > 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("%d %s", r.Status, types.TypeString(r.Type, (*types.Package).Name))
+	}
+	return s
+}
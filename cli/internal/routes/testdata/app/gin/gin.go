@@ -0,0 +1,52 @@
+// Package gin is a stub of the gin API used by the route analysis fixture.
+package gin
+
+type H map[string]any
+
+type HandlerFunc func(*Context)
+
+type Context struct{}
+
+func (c *Context) ShouldBindJSON(obj any) error                 { return nil }
+func (c *Context) ShouldBindQuery(obj any) error                { return nil }
+func (c *Context) Param(key string) string                      { return "" }
+func (c *Context) Query(key string) string                      { return "" }
+func (c *Context) DefaultQuery(key, defaultValue string) string { return defaultValue }
+func (c *Context) JSON(code int, obj any)                       {}
+func (c *Context) Status(code int)                              {}
+
+type IRoutes interface {
+	Use(...HandlerFunc) IRoutes
+	Handle(string, string, ...HandlerFunc) IRoutes
+	GET(string, ...HandlerFunc) IRoutes
+	POST(string, ...HandlerFunc) IRoutes
+	PUT(string, ...HandlerFunc) IRoutes
+	PATCH(string, ...HandlerFunc) IRoutes
+	DELETE(string, ...HandlerFunc) IRoutes
+}
+
+type IRouter interface {
+	IRoutes
+	Group(string, ...HandlerFunc) *RouterGroup
+}
+
+type RouterGroup struct{}
+
+func (g *RouterGroup) Use(middleware ...HandlerFunc) IRoutes { return g }
+func (g *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) *RouterGroup {
+	return g
+}
+func (g *RouterGroup) Handle(method, relativePath string, handlers ...HandlerFunc) IRoutes {
+	return g
+}
+func (g *RouterGroup) GET(relativePath string, handlers ...HandlerFunc) IRoutes    { return g }
+func (g *RouterGroup) POST(relativePath string, handlers ...HandlerFunc) IRoutes   { return g }
+func (g *RouterGroup) PUT(relativePath string, handlers ...HandlerFunc) IRoutes    { return g }
+func (g *RouterGroup) PATCH(relativePath string, handlers ...HandlerFunc) IRoutes  { return g }
+func (g *RouterGroup) DELETE(relativePath string, handlers ...HandlerFunc) IRoutes { return g }
+
+type Engine struct {
+	RouterGroup
+}
+
+func New() *Engine { return &Engine{} }
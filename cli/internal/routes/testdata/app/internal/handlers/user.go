@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type User struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type CreateUserInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type UpdateUserInput struct {
+	Name *string `json:"name"`
+}
+
+type SearchQuery struct {
+	Term string `form:"q"`
+}
+
+type UserHandler struct{}
+
+func NewUserHandler() *UserHandler {
+	return &UserHandler{}
+}
+
+// List returns a page of users.
+func (h *UserHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	_, _ = page, pageSize
+	_ = c.Query("sort")
+	c.JSON(http.StatusOK, []User{})
+}
+
+func (h *UserHandler) Get(c *gin.Context) {
+	if _, err := strconv.ParseUint(c.Param("id"), 10, 32); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	c.JSON(http.StatusOK, User{})
+}
+
+func (h *UserHandler) Search(c *gin.Context) {
+	var query SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		badRequest(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, []User{})
+}
+
+func (h *UserHandler) Create(c *gin.Context) {
+	var input CreateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		badRequest(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, User{Name: input.Name})
+}
+
+func (h *UserHandler) Update(c *gin.Context) {
+	var input UpdateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		badRequest(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, User{})
+}
+
+func (h *UserHandler) Delete(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+}
+
+func badRequest(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterWidgetRoutes is never called with a router, so its routes are
+// mounted at the analysis prefix.
+func RegisterWidgetRoutes(r gin.IRouter) {
+	r.GET("/widgets", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+}
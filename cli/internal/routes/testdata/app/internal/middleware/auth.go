@@ -0,0 +1,7 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+func Auth(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {}
+}
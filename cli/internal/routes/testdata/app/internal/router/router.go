@@ -0,0 +1,37 @@
+package router
+
+import (
+	"net/http"
+
+	"example.com/app/internal/handlers"
+	"example.com/app/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+const usersPath = "/users"
+
+func Setup(secret string) *gin.Engine {
+	r := gin.New()
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	users := handlers.NewUserHandler()
+	api := r.Group("/api")
+	v1 := api.Group("/v1")
+	v1.GET(usersPath, users.List)
+	v1.GET(usersPath+"/:id", users.Get)
+	v1.GET(usersPath+"/search", users.Search)
+	v1.DELETE(usersPath+"/:id", middleware.Auth(secret), users.Delete)
+
+	protected := v1.Group("")
+	protected.Use(middleware.Auth(secret))
+	protected.POST(usersPath, users.Create)
+
+	registerAdmin(v1.Group("/admin", middleware.Auth(secret)), users)
+	return r
+}
+
+func registerAdmin(admin *gin.RouterGroup, users *handlers.UserHandler) {
+	admin.Handle("PUT", "/users/:id/", users.Update)
+}
@@ -48,7 +48,7 @@ func CreateProject(opts Options) error {
 	}
 
 	color.Yellow("[2/7] Generating configuration files...\n")
-	if err := generateConfigFiles(opts.ProjectPath, opts.ProjectName); err != nil {
+	if err := generateConfigFiles(opts.ProjectPath, opts.ProjectName, opts.APIType, opts.ORMType, opts.DBDriver); err != nil {
 		return err
 	}
 
@@ -175,9 +175,9 @@ func createDirectories(projectPath, template, apiType, ormType string) error {
 	return nil
 }
 
-func generateConfigFiles(projectPath, projectName string) error {
+func generateConfigFiles(projectPath, projectName, apiType, ormType, db string) error {
 	files := map[string]string{
-		"goastra.json": config.GoastraJSON(projectName),
+		"goastra.json": config.GoastraJSON(projectName, apiType, ormType, db),
 		".gitignore":   config.Gitignore(),
 	}
 
@@ -206,6 +206,7 @@ func generateEnvFiles(projectPath, db string) error {
 
 func generateBackend(projectPath, projectName, db, apiType, ormType string) error {
 	files := make(map[string]string)
+	module := common.ModulePath(projectName)
 
 	// Common files for all API types
 	files["app/internal/config/config.go"] = common.ConfigGo()
@@ -225,38 +226,38 @@ func generateBackend(projectPath, projectName, db, apiType, ormType string) erro
 	// ORM-specific files
 	switch ormType {
 	case "ent":
-		files["app/internal/database/database.go"] = entorm.ClientGo(db)
+		files["app/internal/database/database.go"] = entorm.ClientGo(db, module)
 		files["app/ent/generate.go"] = entorm.GenerateGo()
 		files["app/ent/schema/user.go"] = entorm.UserSchemaGo()
 		files["app/ent/schema/mixin.go"] = entorm.BaseMixinGo()
 	default: // sqlx
 		files["app/internal/database/database.go"] = sqlx.DatabaseGo(db)
-		files["app/internal/repository/repository.go"] = sqlx.RepositoryGo()
+		files["app/internal/repository/repository.go"] = sqlx.RepositoryGo(module)
 	}
 
 	// API-specific files
 	switch apiType {
 	case "graphql":
-		files["app/cmd/server/main.go"] = graphql.MainGo()
-		files["app/gqlgen.yml"] = graphql.GqlgenYML()
+		files["app/cmd/server/main.go"] = graphql.MainGo(module)
+		files["app/gqlgen.yml"] = graphql.GqlgenYML(module)
 		files["app/graph/generate.go"] = graphql.GenerateGo()
 		files["app/graph/schema.graphqls"] = graphql.SchemaGraphQL()
-		files["app/graph/resolver.go"] = graphql.ResolverGo()
-		files["app/graph/schema.resolvers.go"] = graphql.SchemaResolversGo()
+		files["app/graph/resolver.go"] = graphql.ResolverGo(module)
+		files["app/graph/schema.resolvers.go"] = graphql.SchemaResolversGo(module)
 		files["app/tools.go"] = graphql.ToolsGo()
 
 	case "trpc":
-		files["app/cmd/server/main.go"] = trpc.MainGo()
-		files["app/proto/v1/service.proto"] = trpc.ServiceProto()
+		files["app/cmd/server/main.go"] = trpc.MainGo(module)
+		files["app/proto/v1/service.proto"] = trpc.ServiceProto(module)
 		files["app/buf.yaml"] = trpc.BufYAML()
-		files["app/buf.gen.yaml"] = trpc.BufGenYAML()
+		files["app/buf.gen.yaml"] = trpc.BufGenYAML(module)
 		files["app/buf.work.yaml"] = trpc.BufWorkYAML()
-		files["app/internal/rpc/service.go"] = trpc.ServiceGo()
-		files["app/internal/rpc/interceptor.go"] = trpc.InterceptorGo()
+		files["app/internal/rpc/service.go"] = trpc.ServiceGo(module)
+		files["app/internal/rpc/interceptor.go"] = trpc.InterceptorGo(module)
 
 	default: // rest
-		files["app/cmd/server/main.go"] = rest.MainGo()
-		files["app/internal/router/router.go"] = rest.RouterGo()
+		files["app/cmd/server/main.go"] = rest.MainGo(module)
+		files["app/internal/router/router.go"] = rest.RouterGo(module)
 		files["app/internal/handlers/handlers.go"] = rest.HandlersGo()
 		files["app/internal/services/services.go"] = rest.ServicesGo()
 		files["app/internal/validator/validator.go"] = common.ValidatorGo()
@@ -302,6 +303,7 @@ func generateFrontend(projectPath, projectName, template, apiType string) error
 	// Common frontend files
 	files["web/angular.json"] = frontend.AngularJSON(projectName)
 	files["web/tsconfig.app.json"] = frontend.TSConfigApp()
+	files["web/tsconfig.spec.json"] = frontend.TSConfigSpec()
 	files["web/proxy.conf.json"] = frontend.ProxyConf()
 	files["web/src/index.html"] = frontend.IndexHTML(projectName)
 	files["web/src/main.ts"] = frontend.MainTS()
@@ -0,0 +1,79 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+/*
+ * scaffoldBackend writes the backend of a new project named demo to a
+ * temporary directory and returns its app directory.
+ */
+func scaffoldBackend(t *testing.T, db, apiType, ormType string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := createDirectories(dir, "default", apiType, ormType); err != nil {
+		t.Fatal(err)
+	}
+	if err := generateBackend(dir, "demo", db, apiType, ormType); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "app")
+}
+
+func TestBackendBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("downloads the backend's dependencies")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	for _, db := range []string{"postgres", "mysql"} {
+		t.Run(db, func(t *testing.T) {
+			app := scaffoldBackend(t, db, "rest", "sqlx")
+			for _, args := range [][]string{{"mod", "tidy"}, {"build", "./..."}} {
+				cmd := exec.Command("go", args...)
+				cmd.Dir = app
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+				}
+			}
+		})
+	}
+}
+
+func TestBackendImportsModule(t *testing.T) {
+	for _, apiType := range []string{"rest", "graphql", "trpc"} {
+		for _, ormType := range []string{"sqlx", "ent"} {
+			t.Run(apiType+"-"+ormType, func(t *testing.T) {
+				app := scaffoldBackend(t, "postgres", apiType, ormType)
+				err := filepath.WalkDir(app, func(path string, entry fs.DirEntry, err error) error {
+					if err != nil || entry.IsDir() || filepath.Ext(path) != ".go" {
+						return err
+					}
+					file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+					if err != nil {
+						return err
+					}
+					for _, spec := range file.Imports {
+						imported, _ := strconv.Unquote(spec.Path.Value)
+						if imported == "app" || strings.HasPrefix(imported, "app/") {
+							t.Errorf("%s imports %s outside module github.com/demo/app", path, imported)
+						}
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			})
+		}
+	}
+}
@@ -16,6 +16,12 @@ type GoModOptions struct {
 	ORMType     string // sqlx, ent
 }
 
+// ModulePath returns the module path go.mod declares for the project,
+// which the backend templates import their packages under.
+func ModulePath(projectName string) string {
+	return "github.com/" + projectName + "/app"
+}
+
 // GoMod returns the go.mod template with conditional dependencies.
 func GoMod(opts GoModOptions) string {
 	// Base dependencies (always included)
@@ -61,14 +67,14 @@ func GoMod(opts GoModOptions) string {
 	github.com/jmoiron/sqlx v1.3.5`
 	}
 
-	return fmt.Sprintf(`module github.com/%s/app
+	return fmt.Sprintf(`module %s
 
 go 1.21
 
 require (
 %s%s%s%s
 )
-`, opts.ProjectName, deps, dbDep, apiDeps, ormDeps)
+`, ModulePath(opts.ProjectName), deps, dbDep, apiDeps, ormDeps)
 }
 
 // GoModREST returns go.mod for REST API projects (backward compatibility).
@@ -108,12 +108,16 @@ func NewPaginatedResponse(data interface{}, total int64, page, pageSize int) *Pa
 `
 }
 
-// ValidatorGo returns the validator.go template for custom validations.
+// ValidatorGo returns the validator.go template for custom validations
+// and the validator generated handlers check their input with.
 func ValidatorGo() string {
 	return `package validator
 
 import (
+	"fmt"
+	"reflect"
 	"regexp"
+	"strings"
 
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
@@ -129,6 +133,118 @@ func Setup() {
 	}
 }
 
+/*
+ * Validator wraps go-playground/validator with custom rules.
+ */
+type Validator struct {
+	validate *validator.Validate
+}
+
+/*
+ * ValidationError represents a single field validation error.
+ */
+type ValidationError struct {
+	Field   string ` + "`json:\"field\"`" + `
+	Message string ` + "`json:\"message\"`" + `
+	Tag     string ` + "`json:\"tag\"`" + `
+	Value   string ` + "`json:\"value,omitempty\"`" + `
+}
+
+/*
+ * ValidationErrors is a collection of validation errors.
+ */
+type ValidationErrors struct {
+	Errors []ValidationError ` + "`json:\"errors\"`" + `
+}
+
+/*
+ * New creates a validator that names fields by their JSON names and
+ * applies the custom rules.
+ */
+func New() *Validator {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return fld.Name
+		}
+		return name
+	})
+	registerCustomValidations(v)
+	return &Validator{validate: v}
+}
+
+/*
+ * Validate validates a struct and returns formatted errors, or nil.
+ */
+func (v *Validator) Validate(i interface{}) *ValidationErrors {
+	err := v.validate.Struct(i)
+	if err == nil {
+		return nil
+	}
+
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return &ValidationErrors{Errors: []ValidationError{{Message: err.Error()}}}
+	}
+
+	result := &ValidationErrors{Errors: make([]ValidationError, 0, len(fieldErrors))}
+	for _, fe := range fieldErrors {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fe.Field(),
+			Message: formatErrorMessage(fe),
+			Tag:     fe.Tag(),
+			Value:   fmt.Sprintf("%v", fe.Value()),
+		})
+	}
+	return result
+}
+
+/*
+ * Error implements the error interface for ValidationErrors.
+ */
+func (ve *ValidationErrors) Error() string {
+	if len(ve.Errors) == 0 {
+		return "validation failed"
+	}
+	messages := make([]string, len(ve.Errors))
+	for i, e := range ve.Errors {
+		messages[i] = e.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func formatErrorMessage(fe validator.FieldError) string {
+	field := fe.Field()
+
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max":
+		return fmt.Sprintf("%s must not exceed %s", field, fe.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s", field, fe.Param())
+	case "lte":
+		return fmt.Sprintf("%s must be less than or equal to %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, fe.Param())
+	case "uuid":
+		return fmt.Sprintf("%s must be a valid UUID", field)
+	case "url":
+		return fmt.Sprintf("%s must be a valid URL", field)
+	case "username":
+		return fmt.Sprintf("%s must be 3-20 letters, numbers or underscores", field)
+	case "slug":
+		return fmt.Sprintf("%s must be lowercase letters, numbers and hyphens", field)
+	default:
+		return fmt.Sprintf("%s failed validation: %s", field, fe.Tag())
+	}
+}
+
 func registerCustomValidations(v *validator.Validate) {
 	// Username validation: alphanumeric, underscores, 3-20 chars
 	v.RegisterValidation("username", func(fl validator.FieldLevel) bool {
@@ -7,7 +7,7 @@
 package graphql
 
 // GqlgenYML returns the gqlgen.yml configuration template.
-func GqlgenYML() string {
+func GqlgenYML(module string) string {
 	return `# gqlgen Configuration
 # See https://gqlgen.com/config/ for documentation
 
@@ -34,7 +34,7 @@ resolver:
 
 # Optional: autobind Go types to GraphQL types
 autobind:
-  - "app/graph/model"
+  - "` + module + `/graph/model"
 
 # Model mappings (map GraphQL types to Go types)
 models:
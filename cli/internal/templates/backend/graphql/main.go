@@ -7,12 +7,11 @@
 package graphql
 
 // MainGo returns the main.go template for GraphQL servers.
-func MainGo() string {
+func MainGo(module string) string {
 	return `package main
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
@@ -24,12 +23,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
-	"app/graph"
-	"app/graph/generated"
-	"app/internal/config"
-	"app/internal/database"
-	"app/internal/logger"
-	"app/internal/middleware"
+	"` + module + `/graph"
+	"` + module + `/graph/generated"
+	"` + module + `/internal/config"
+	"` + module + `/internal/database"
+	"` + module + `/internal/logger"
+	"` + module + `/internal/middleware"
 )
 
 func main() {
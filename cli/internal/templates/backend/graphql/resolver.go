@@ -7,11 +7,11 @@
 package graphql
 
 // ResolverGo returns the resolver.go template.
-func ResolverGo() string {
+func ResolverGo(module string) string {
 	return `package graph
 
 import (
-	"app/internal/database"
+	"` + module + `/internal/database"
 )
 
 // This file will not be regenerated automatically.
@@ -37,7 +37,7 @@ func NewResolver(db *database.Client) *Resolver {
 }
 
 // SchemaResolversGo returns the schema.resolvers.go template.
-func SchemaResolversGo() string {
+func SchemaResolversGo(module string) string {
 	return `package graph
 
 // This file will be automatically regenerated based on the schema.
@@ -47,8 +47,8 @@ import (
 	"context"
 	"errors"
 
-	"app/graph/generated"
-	"app/graph/model"
+	"` + module + `/graph/generated"
+	"` + module + `/graph/model"
 )
 
 // ============================================================================
@@ -7,12 +7,11 @@
 package rest
 
 // MainGo returns the main.go template for REST API servers.
-func MainGo() string {
+func MainGo(module string) string {
 	return `package main
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
@@ -22,10 +21,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
-	"app/internal/config"
-	"app/internal/database"
-	"app/internal/logger"
-	"app/internal/router"
+	"` + module + `/internal/config"
+	"` + module + `/internal/database"
+	"` + module + `/internal/logger"
+	"` + module + `/internal/router"
 )
 
 func main() {
@@ -7,7 +7,7 @@
 package rest
 
 // RouterGo returns the router.go template for REST APIs.
-func RouterGo() string {
+func RouterGo(module string) string {
 	return `package router
 
 import (
@@ -19,10 +19,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 
-	"app/internal/config"
-	"app/internal/database"
-	"app/internal/logger"
-	"app/internal/middleware"
+	"` + module + `/internal/config"
+	"` + module + `/internal/database"
+	"` + module + `/internal/logger"
+	"` + module + `/internal/middleware"
 )
 
 /*
@@ -22,12 +22,12 @@ lint:
 }
 
 // BufGenYAML returns the buf.gen.yaml code generation template.
-func BufGenYAML() string {
+func BufGenYAML(module string) string {
 	return `version: v1
 managed:
   enabled: true
   go_package_prefix:
-    default: app/internal/rpc/gen
+    default: ` + module + `/internal/rpc/gen
 plugins:
   - plugin: buf.build/protocolbuffers/go
     out: internal/rpc/gen
@@ -47,7 +47,7 @@ directories:
 }
 
 // ServiceGo returns the RPC service implementations template.
-func ServiceGo() string {
+func ServiceGo(module string) string {
 	return `package rpc
 
 import (
@@ -56,10 +56,10 @@ import (
 
 	"connectrpc.com/connect"
 
-	"app/internal/config"
-	"app/internal/database"
-	pb "app/internal/rpc/gen/proto/v1"
-	"app/internal/rpc/gen/proto/v1/protov1connect"
+	"` + module + `/internal/config"
+	"` + module + `/internal/database"
+	pb "` + module + `/internal/rpc/gen/proto/v1"
+	"` + module + `/internal/rpc/gen/proto/v1/protov1connect"
 )
 
 // ============================================================================
@@ -251,7 +251,7 @@ func (s *UserService) DeleteUser(
 }
 
 // InterceptorGo returns the Connect interceptor template.
-func InterceptorGo() string {
+func InterceptorGo(module string) string {
 	return `package rpc
 
 import (
@@ -260,7 +260,7 @@ import (
 
 	"connectrpc.com/connect"
 
-	"app/internal/logger"
+	"` + module + `/internal/logger"
 )
 
 /*
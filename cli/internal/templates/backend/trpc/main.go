@@ -7,12 +7,11 @@
 package trpc
 
 // MainGo returns the main.go template for tRPC servers.
-func MainGo() string {
+func MainGo(module string) string {
 	return `package main
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
@@ -24,11 +23,11 @@ import (
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 
-	"app/internal/config"
-	"app/internal/database"
-	"app/internal/logger"
-	"app/internal/rpc"
-	"app/internal/rpc/gen/proto/v1/protov1connect"
+	"` + module + `/internal/config"
+	"` + module + `/internal/database"
+	"` + module + `/internal/logger"
+	"` + module + `/internal/rpc"
+	"` + module + `/internal/rpc/gen/proto/v1/protov1connect"
 )
 
 func main() {
@@ -7,12 +7,12 @@
 package trpc
 
 // ServiceProto returns the service.proto template.
-func ServiceProto() string {
+func ServiceProto(module string) string {
 	return `syntax = "proto3";
 
 package proto.v1;
 
-option go_package = "app/internal/rpc/gen/proto/v1;protov1";
+option go_package = "` + module + `/internal/rpc/gen/proto/v1;protov1";
 
 // ============================================================================
 // HEALTH SERVICE
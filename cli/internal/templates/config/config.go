@@ -2,12 +2,12 @@ package config
 
 import "fmt"
 
-func GoastraJSON(projectName string) string {
+func GoastraJSON(projectName, apiType, ormType, db string) string {
 	return fmt.Sprintf(`{
   "name": "%s",
   "version": "1.0.0",
   "api": {
-    "type": "rest",
+    "type": "%s",
     "prefix": "/api/v1"
   },
   "backend": {
@@ -23,10 +23,11 @@ func GoastraJSON(projectName string) string {
     "outputPath": "web/src/app/core/models"
   },
   "database": {
-    "driver": "postgres",
+    "driver": "%s",
+    "orm": "%s",
     "migrationsPath": "app/migrations"
   }
-}`, projectName, projectName)
+}`, projectName, apiType, projectName, db, ormType)
 }
 
 func Gitignore() string {
@@ -7,7 +7,7 @@
 package ent
 
 // ClientGoMySQL returns the Ent client template for MySQL.
-func ClientGoMySQL() string {
+func ClientGoMySQL(module string) string {
 	return `package database
 
 import (
@@ -19,7 +19,7 @@ import (
 	"entgo.io/ent/dialect/sql"
 	_ "github.com/go-sql-driver/mysql"
 
-	"app/ent"
+	"` + module + `/ent"
 )
 
 /*
@@ -121,7 +121,7 @@ func (c *Client) Close() error {
 }
 
 // ClientGoPostgres returns the Ent client template for PostgreSQL.
-func ClientGoPostgres() string {
+func ClientGoPostgres(module string) string {
 	return `package database
 
 import (
@@ -133,7 +133,7 @@ import (
 	"entgo.io/ent/dialect/sql"
 	_ "github.com/lib/pq"
 
-	"app/ent"
+	"` + module + `/ent"
 )
 
 /*
@@ -233,10 +233,11 @@ func (c *Client) Close() error {
 `
 }
 
-// ClientGo returns the appropriate Ent client template based on driver.
-func ClientGo(driver string) string {
+// ClientGo returns the appropriate Ent client template based on driver,
+// importing the Ent package under module.
+func ClientGo(driver, module string) string {
 	if driver == "mysql" {
-		return ClientGoMySQL()
+		return ClientGoMySQL(module)
 	}
-	return ClientGoPostgres()
+	return ClientGoPostgres(module)
 }
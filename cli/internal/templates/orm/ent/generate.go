@@ -15,11 +15,11 @@ func GenerateGo() string {
 }
 
 // EntrcGo returns the .entrc configuration file template.
-func EntrcGo() string {
+func EntrcGo(module string) string {
 	return `{
   "schema": "./ent/schema",
   "target": "./ent",
-  "package": "app/ent",
+  "package": "` + module + `/ent",
   "features": [
     "sql/modifier",
     "sql/upsert",
@@ -7,7 +7,7 @@
 package sqlx
 
 // RepositoryGo returns the repository.go template.
-func RepositoryGo() string {
+func RepositoryGo(module string) string {
 	return `package repository
 
 import (
@@ -17,7 +17,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 
-	"app/internal/database"
+	"` + module + `/internal/database"
 )
 
 /*
@@ -0,0 +1,104 @@
+package textdiff
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		conflicts          int
+	}{
+		{
+			name:   "clean",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:      "adjacent edits",
+			base:      "a\nb\nc\nd\n",
+			ours:      "a\nB\nc\nd\n",
+			theirs:    "a\nb\nC\nd\n",
+			want:      "a\n<<<<<<< current\nB\nc\n=======\nb\nC\n>>>>>>> generated\nd\n",
+			conflicts: 1,
+		},
+		{
+			name:   "same edit on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:      "different edits of a line",
+			base:      "a\nb\nc\n",
+			ours:      "a\nours\nc\n",
+			theirs:    "a\ntheirs\nc\n",
+			want:      "a\n<<<<<<< current\nours\n=======\ntheirs\n>>>>>>> generated\nc\n",
+			conflicts: 1,
+		},
+		{
+			name:   "missing trailing newline",
+			base:   "a\nb\nc",
+			ours:   "A\nb\nc",
+			theirs: "a\nb\nC",
+			want:   "A\nb\nC",
+		},
+		{
+			name:      "conflict without trailing newline",
+			base:      "a\nb",
+			ours:      "a\nours",
+			theirs:    "a\ntheirs",
+			want:      "a\n<<<<<<< current\nours\n=======\ntheirs\n>>>>>>> generated\n",
+			conflicts: 1,
+		},
+		{
+			name:   "empty base, same content",
+			base:   "",
+			ours:   "a\nb\n",
+			theirs: "a\nb\n",
+			want:   "a\nb\n",
+		},
+		{
+			name:      "empty base",
+			base:      "",
+			ours:      "ours\n",
+			theirs:    "theirs\n",
+			want:      "<<<<<<< current\nours\n=======\ntheirs\n>>>>>>> generated\n",
+			conflicts: 1,
+		},
+		{
+			name:   "empty base, one side empty",
+			base:   "",
+			ours:   "",
+			theirs: "a\n",
+			want:   "a\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge(tt.base, tt.ours, tt.theirs, "current", "generated")
+			if got != tt.want || conflicts != tt.conflicts {
+				t.Errorf("Merge() = %q, %d conflicts, want %q, %d conflicts", got, conflicts, tt.want, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestCommon(t *testing.T) {
+	tests := []struct {
+		a, b, want string
+	}{
+		{"a\nb\nc\n", "a\nx\nc\n", "a\nc\n"},
+		{"a\nb\n", "a\nb\nc\n", "a\nb\n"},
+		{"", "a\n", ""},
+	}
+	for _, tt := range tests {
+		if got := Common(tt.a, tt.b); got != tt.want {
+			t.Errorf("Common(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
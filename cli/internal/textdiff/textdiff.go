@@ -0,0 +1,485 @@
+/*
+ * GoAstra CLI - Text Diff
+ *
+ * Line-based diffing for generated files. Produces unified diffs for
+ * display, the edit scripts used to compare file versions, and
+ * three-way merges of regenerated files with local edits.
+ */
+package textdiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+ * OpKind identifies an edit operation.
+ */
+type OpKind int
+
+const (
+	Equal OpKind = iota
+	Delete
+	Insert
+)
+
+/*
+ * Op is one line of an edit script turning a into b.
+ */
+type Op struct {
+	Kind OpKind
+	Line string
+}
+
+/*
+ * Lines splits text into lines, keeping the trailing newline on each.
+ */
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+/*
+ * Diff returns a minimal edit script turning a into b. It uses Myers'
+ * linear-space algorithm, so memory grows with the length of the texts
+ * rather than their product, and lists the deletions of each changed
+ * region before its insertions.
+ */
+func Diff(a, b []string) []Op {
+	/* Compare lines by number */
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+
+	d := &differ{a: a, b: b, ia: intern(a), ib: intern(b)}
+	d.compare(0, len(a), 0, len(b))
+
+	/* Order each run of changes as deletions, then insertions */
+	ops := d.ops
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == Equal {
+			i++
+			continue
+		}
+		j := i
+		for j < len(ops) && ops[j].Kind != Equal {
+			j++
+		}
+		sort.SliceStable(ops[i:j], func(x, y int) bool {
+			return ops[i+x].Kind == Delete && ops[i+y].Kind == Insert
+		})
+		i = j
+	}
+	return ops
+}
+
+/*
+ * differ builds the edit script turning a into b; ia and ib hold the
+ * line numbers compared in place of the lines.
+ */
+type differ struct {
+	a, b   []string
+	ia, ib []int
+	ops    []Op
+}
+
+/*
+ * compare appends the edit script turning a[aLo:aHi] into b[bLo:bHi].
+ * The regions are split at the middle snake of their shortest edit
+ * script and compared in halves.
+ */
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	prefix := aLo
+	for prefix < aHi && bLo+prefix-aLo < bHi && d.ia[prefix] == d.ib[bLo+prefix-aLo] {
+		prefix++
+	}
+	d.equal(aLo, prefix)
+	bLo += prefix - aLo
+	aLo = prefix
+
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.ia[aHi-1-suffix] == d.ib[bHi-1-suffix] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	switch {
+	case aLo == aHi:
+		for _, line := range d.b[bLo:bHi] {
+			d.ops = append(d.ops, Op{Insert, line})
+		}
+	case bLo == bHi:
+		for _, line := range d.a[aLo:aHi] {
+			d.ops = append(d.ops, Op{Delete, line})
+		}
+	default:
+		x, y, ok := d.middleSnake(aLo, aHi, bLo, bHi)
+		if ok {
+			d.compare(aLo, x, bLo, y)
+			d.compare(x, aHi, y, bHi)
+		} else {
+			for _, line := range d.a[aLo:aHi] {
+				d.ops = append(d.ops, Op{Delete, line})
+			}
+			for _, line := range d.b[bLo:bHi] {
+				d.ops = append(d.ops, Op{Insert, line})
+			}
+		}
+	}
+
+	d.equal(aHi, aHi+suffix)
+}
+
+func (d *differ) equal(from, to int) {
+	for _, line := range d.a[from:to] {
+		d.ops = append(d.ops, Op{Equal, line})
+	}
+}
+
+/*
+ * middleSnake searches the shortest edit script of a[aLo:aHi] and
+ * b[bLo:bHi] from both ends at once and returns the point where the
+ * two searches meet, which splits it in two.
+ */
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	a, b := d.ia[aLo:aHi], d.ib[bLo:bHi]
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	size := 2*maxD + 2
+
+	/* forward[k] and backward[k] are the furthest x reached on diagonal k */
+	forward := make([]int, size)
+	backward := make([]int, size)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	odd := delta%2 != 0
+	var fStart, fEnd, bStart, bEnd int
+	for step := 0; step < maxD; step++ {
+		for k := -step + fStart; k <= step-fEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -step || k != step && forward[i-1] < forward[i+1] {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				if j := offset + delta - k; j >= 0 && j < size && backward[j] != -1 && x >= n-backward[j] {
+					return aLo + x, bLo + y, true
+				}
+			}
+		}
+
+		for k := -step + bStart; k <= step-bEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -step || k != step && backward[i-1] < backward[i+1] {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				if j := offset + delta - k; j >= 0 && j < size && forward[j] != -1 {
+					fx := forward[j]
+					if fx >= n-x {
+						return aLo + fx, bLo + fx - (j - offset), true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+/*
+ * Unified renders a unified diff between two texts with three lines of
+ * context. It returns an empty string when the texts are equal.
+ */
+func Unified(aName, bName, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	ops := Diff(Lines(a), Lines(b))
+	const context = 3
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", aName, bName))
+
+	/* Positions of each op in a and b, 1-based */
+	aLine := make([]int, len(ops)+1)
+	bLine := make([]int, len(ops)+1)
+	aLine[0], bLine[0] = 1, 1
+	for k, op := range ops {
+		aLine[k+1], bLine[k+1] = aLine[k], bLine[k]
+		if op.Kind != Insert {
+			aLine[k+1]++
+		}
+		if op.Kind != Delete {
+			bLine[k+1]++
+		}
+	}
+
+	for k := 0; k < len(ops); {
+		if ops[k].Kind == Equal {
+			k++
+			continue
+		}
+
+		/* Extend the hunk while changes are within 2*context lines */
+		start := k - context
+		if start < 0 {
+			start = 0
+		}
+		end := k
+		for end < len(ops) {
+			if ops[end].Kind != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].Kind == Equal {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end += min(context, run-end)
+				break
+			}
+			end = run
+		}
+
+		aCount, bCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.Kind != Insert {
+				aCount++
+			}
+			if op.Kind != Delete {
+				bCount++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(aLine[start], aCount), hunkRange(bLine[start], bCount)))
+
+		for _, op := range ops[start:end] {
+			prefix := " "
+			switch op.Kind {
+			case Delete:
+				prefix = "-"
+			case Insert:
+				prefix = "+"
+			}
+			sb.WriteString(prefix + op.Line)
+			if !strings.HasSuffix(op.Line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		k = end
+	}
+
+	return sb.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+/*
+ * hunk replaces base[start:end] with lines.
+ */
+type hunk struct {
+	start, end int
+	lines      []string
+}
+
+/*
+ * hunks groups the changes of an edit script by the base lines they
+ * replace.
+ */
+func hunks(ops []Op) []hunk {
+	var (
+		result  []hunk
+		current *hunk
+	)
+	i := 0
+	for _, op := range ops {
+		if op.Kind == Equal {
+			if current != nil {
+				result = append(result, *current)
+				current = nil
+			}
+			i++
+			continue
+		}
+		if current == nil {
+			current = &hunk{start: i, end: i}
+		}
+		if op.Kind == Delete {
+			i++
+			current.end = i
+		} else {
+			current.lines = append(current.lines, op.Line)
+		}
+	}
+	if current != nil {
+		result = append(result, *current)
+	}
+	return result
+}
+
+/*
+ * apply returns base[start:end] with the hunks inside that range applied.
+ */
+func apply(base []string, start, end int, changes []hunk) []string {
+	var lines []string
+	at := start
+	for _, h := range changes {
+		lines = append(lines, base[at:h.start]...)
+		lines = append(lines, h.lines...)
+		at = h.end
+	}
+	return append(lines, base[at:end]...)
+}
+
+/*
+ * Merge combines the changes made to base in ours and in theirs. Where
+ * both sides changed the same lines differently, the region is written
+ * with conflict markers labelled with oursName and theirsName, and the
+ * conflict count is returned.
+ */
+func Merge(base, ours, theirs, oursName, theirsName string) (string, int) {
+	baseLines := Lines(base)
+	a := hunks(Diff(baseLines, Lines(ours)))
+	b := hunks(Diff(baseLines, Lines(theirs)))
+
+	var (
+		sb        strings.Builder
+		conflicts int
+		at        int
+	)
+	write := func(lines []string) {
+		for _, line := range lines {
+			sb.WriteString(line)
+		}
+	}
+	writeSide := func(marker string, lines []string) {
+		sb.WriteString(marker + "\n")
+		write(lines)
+		if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+			sb.WriteString("\n")
+		}
+	}
+
+	for len(a) > 0 || len(b) > 0 {
+		/* Take the next hunk from either side, then absorb every hunk
+		 * of both sides that overlaps or touches the region */
+		start, end := 0, 0
+		var fromA, fromB []hunk
+		if len(b) == 0 || len(a) > 0 && a[0].start <= b[0].start {
+			start, end = a[0].start, a[0].end
+			fromA, a = a[:1], a[1:]
+		} else {
+			start, end = b[0].start, b[0].end
+			fromB, b = b[:1], b[1:]
+		}
+		for grew := true; grew; {
+			grew = false
+			for len(a) > 0 && a[0].start <= end {
+				end = max(end, a[0].end)
+				fromA, a = append(fromA, a[0]), a[1:]
+				grew = true
+			}
+			for len(b) > 0 && b[0].start <= end {
+				end = max(end, b[0].end)
+				fromB, b = append(fromB, b[0]), b[1:]
+				grew = true
+			}
+		}
+
+		write(baseLines[at:start])
+		at = end
+
+		oursRegion := apply(baseLines, start, end, fromA)
+		theirsRegion := apply(baseLines, start, end, fromB)
+		switch {
+		case len(fromB) == 0:
+			write(oursRegion)
+		case len(fromA) == 0:
+			write(theirsRegion)
+		case strings.Join(oursRegion, "") == strings.Join(theirsRegion, ""):
+			write(oursRegion)
+		default:
+			conflicts++
+			writeSide("<<<<<<< "+oursName, oursRegion)
+			writeSide("=======", theirsRegion)
+			sb.WriteString(">>>>>>> " + theirsName + "\n")
+		}
+	}
+	write(baseLines[at:])
+
+	return sb.String(), conflicts
+}
+
+/*
+ * Common returns the lines a and b share, in order. It stands in for the
+ * base of a merge when the original version is not known, so that only
+ * the regions where the texts differ conflict.
+ */
+func Common(a, b string) string {
+	var sb strings.Builder
+	for _, op := range Diff(Lines(a), Lines(b)) {
+		if op.Kind == Equal {
+			sb.WriteString(op.Line)
+		}
+	}
+	return sb.String()
+}
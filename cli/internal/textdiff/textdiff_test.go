@@ -0,0 +1,113 @@
+package textdiff
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+/*
+ * lcsLength returns the length of the longest common subsequence of a
+ * and b, against which the length of Diff's edit scripts is checked.
+ */
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+/*
+ * checkScript verifies that ops turns a into b with the given number
+ * of edits.
+ */
+func checkScript(t *testing.T, a, b []string, ops []Op, want int) {
+	t.Helper()
+	var gotA, gotB []string
+	edits := 0
+	for _, op := range ops {
+		if op.Kind != Insert {
+			gotA = append(gotA, op.Line)
+		}
+		if op.Kind != Delete {
+			gotB = append(gotB, op.Line)
+		}
+		if op.Kind != Equal {
+			edits++
+		}
+	}
+	if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+		t.Fatalf("Diff(%q, %q) = %v does not turn a into b", a, b, ops)
+	}
+	if edits != want {
+		t.Fatalf("Diff(%q, %q) has %d edits, want %d", a, b, edits, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Op
+	}{
+		{"equal", "a\nb\n", "a\nb\n", []Op{{Equal, "a\n"}, {Equal, "b\n"}}},
+		{"empty a", "", "a\n", []Op{{Insert, "a\n"}}},
+		{"empty b", "a\n", "", []Op{{Delete, "a\n"}}},
+		{"replace", "a\nb\nc\n", "a\nx\nc\n", []Op{{Equal, "a\n"}, {Delete, "b\n"}, {Insert, "x\n"}, {Equal, "c\n"}}},
+		{"deletions first", "a\nb\n", "x\ny\n", []Op{{Delete, "a\n"}, {Delete, "b\n"}, {Insert, "x\n"}, {Insert, "y\n"}}},
+		{"no trailing newline", "a\nb", "a\nb\n", []Op{{Equal, "a\n"}, {Delete, "b"}, {Insert, "b\n"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(Lines(tt.a), Lines(tt.b))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Diff(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a'+rng.Intn(4))) + "\n"
+		}
+		return lines
+	}
+	for i := 0; i < 2000; i++ {
+		a, b := random(), random()
+		checkScript(t, a, b, Diff(a, b), len(a)+len(b)-2*lcsLength(a, b))
+	}
+}
+
+func TestDiffLargeFile(t *testing.T) {
+	a := make([]string, 15000)
+	for i := range a {
+		a[i] = fmt.Sprintf("line %d\n", i)
+	}
+	b := append([]string(nil), a...)
+	b[0], b[len(b)-1] = "first\n", "last\n"
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	ops := Diff(a, b)
+	runtime.ReadMemStats(&after)
+
+	checkScript(t, a, b, ops, 4)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+		t.Errorf("Diff allocated %d MB", allocated>>20)
+	}
+}
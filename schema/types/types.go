@@ -21,6 +21,16 @@ type BaseModel struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+/*
+ * Role identifies a user's permission level.
+ */
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
 /*
  * User represents an authenticated user in the system.
  */
@@ -28,7 +38,7 @@ type User struct {
 	BaseModel
 	Email    string `json:"email"`
 	Name     string `json:"name"`
-	Role     string `json:"role"`
+	Role     Role   `json:"role"`
 	Active   bool   `json:"active"`
 	Avatar   string `json:"avatar,omitempty"`
 }
@@ -46,12 +56,12 @@ type UserProfile struct {
 /*
  * PaginatedResponse wraps list responses with pagination metadata.
  */
-type PaginatedResponse struct {
-	Data       interface{} `json:"data"`
-	Total      int         `json:"total"`
-	Page       int         `json:"page"`
-	PageSize   int         `json:"page_size"`
-	TotalPages int         `json:"total_pages"`
+type PaginatedResponse[T any] struct {
+	Data       []T `json:"data"`
+	Total      int `json:"total"`
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	TotalPages int `json:"total_pages"`
 }
 
 /*
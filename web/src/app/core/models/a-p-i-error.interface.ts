@@ -1,8 +1,9 @@
 /*
- *  * APIError represents a standardized error response.
+ * APIError interface
  * Auto-generated by GoAstra typesync
  */
 
+/** APIError represents a standardized error response. */
 export interface APIError {
   code: string;
   message: string;
@@ -1,8 +1,9 @@
 /*
- *  * AuthTokens contains authentication token pair.
+ * AuthTokens interface
  * Auto-generated by GoAstra typesync
  */
 
+/** AuthTokens contains authentication token pair. */
 export interface AuthTokens {
   access_token: string;
   refresh_token: string;
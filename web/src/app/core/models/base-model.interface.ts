@@ -1,11 +1,16 @@
 /*
- *  * BaseModel provides common fields for all database models.
- * Automatically handles ID, timestamps, and soft deletes.
+ * BaseModel interface
  * Auto-generated by GoAstra typesync
  */
 
+/**
+ * BaseModel provides common fields for all database models.
+ * Automatically handles ID, timestamps, and soft deletes.
+ */
 export interface BaseModel {
   id: number;
+  /** @format date-time */
   created_at: string;
+  /** @format date-time */
   updated_at: string;
 }
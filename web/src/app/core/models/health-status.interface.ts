@@ -1,8 +1,9 @@
 /*
- *  * HealthStatus represents system health check response.
+ * HealthStatus interface
  * Auto-generated by GoAstra typesync
  */
 
+/** HealthStatus represents system health check response. */
 export interface HealthStatus {
   status: string;
   database: string;
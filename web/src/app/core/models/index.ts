@@ -4,6 +4,7 @@
  */
 
 export * from './base-model.interface';
+export * from './role.enum';
 export * from './user.interface';
 export * from './user-profile.interface';
 export * from './paginated-response.interface';
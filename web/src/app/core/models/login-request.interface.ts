@@ -1,8 +1,9 @@
 /*
- *  * LoginRequest defines login endpoint payload.
+ * LoginRequest interface
  * Auto-generated by GoAstra typesync
  */
 
+/** LoginRequest defines login endpoint payload. */
 export interface LoginRequest {
   email: string;
   password: string;
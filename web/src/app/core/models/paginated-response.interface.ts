@@ -1,10 +1,11 @@
 /*
- *  * PaginatedResponse wraps list responses with pagination metadata.
+ * PaginatedResponse interface
  * Auto-generated by GoAstra typesync
  */
 
-export interface PaginatedResponse {
-  data: unknown;
+/** PaginatedResponse wraps list responses with pagination metadata. */
+export interface PaginatedResponse<T> {
+  data: T[];
   total: number;
   page: number;
   page_size: number;
@@ -1,8 +1,9 @@
 /*
- *  * RegisterRequest defines registration endpoint payload.
+ * RegisterRequest interface
  * Auto-generated by GoAstra typesync
  */
 
+/** RegisterRequest defines registration endpoint payload. */
 export interface RegisterRequest {
   email: string;
   password: string;
@@ -0,0 +1,14 @@
+/*
+ * Role enum
+ * Auto-generated by GoAstra typesync
+ */
+
+/** Role identifies a user's permission level. */
+export type Role = 'user' | 'admin';
+
+export const RoleValues: readonly Role[] = ['user', 'admin'];
+
+export const RoleLabels: Record<Role, string> = {
+  'user': 'User',
+  'admin': 'Admin',
+};
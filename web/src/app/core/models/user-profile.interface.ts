@@ -1,8 +1,9 @@
 /*
- *  * UserProfile represents publicly visible user information.
+ * UserProfile interface
  * Auto-generated by GoAstra typesync
  */
 
+/** UserProfile represents publicly visible user information. */
 export interface UserProfile {
   id: number;
   name: string;
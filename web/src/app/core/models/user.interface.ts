@@ -1,12 +1,16 @@
 /*
- *  * User represents an authenticated user in the system.
+ * User interface
  * Auto-generated by GoAstra typesync
  */
 
-export interface User {
+import { BaseModel } from './base-model.interface';
+import { Role } from './role.enum';
+
+/** User represents an authenticated user in the system. */
+export interface User extends BaseModel {
   email: string;
   name: string;
-  role: string;
+  role: Role;
   active: boolean;
   avatar?: string;
 }
@@ -1,8 +1,9 @@
 /*
- *  * ValidationError represents a field-level validation error.
+ * ValidationError interface
  * Auto-generated by GoAstra typesync
  */
 
+/** ValidationError represents a field-level validation error. */
 export interface ValidationError {
   field: string;
   message: string;